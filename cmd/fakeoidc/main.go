@@ -0,0 +1,33 @@
+// Command fakeoidc runs a minimal OpenID Connect provider for exercising the
+// social login flow locally. Point GOOGLE_ISSUER_URL at it (for example
+// http://localhost:9000) and every authorization request is approved
+// immediately for the email given in login_hint, or FAKE_OIDC_EMAIL.
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+
+	"github.com/muga20/artsMarket/modules/users/auth/Oauth/oidctest"
+)
+
+func main() {
+	addr := getEnv("FAKE_OIDC_ADDR", ":9000")
+	issuer := getEnv("FAKE_OIDC_ISSUER", "http://localhost:9000")
+
+	server, err := oidctest.New(issuer, getEnv("FAKE_OIDC_EMAIL", "artist@example.com"))
+	if err != nil {
+		log.Fatalf("Failed to generate signing key: %v", err)
+	}
+
+	log.Printf("Fake OIDC provider listening on %s (issuer %s)", addr, issuer)
+	log.Fatal(http.ListenAndServe(addr, server.Handler()))
+}
+
+func getEnv(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
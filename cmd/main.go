@@ -9,11 +9,18 @@ import (
 	"github.com/muga20/artsMarket/config"
 	"github.com/muga20/artsMarket/database"
 	arts_module "github.com/muga20/artsMarket/modules/artwork-management/routes"
+	exhibitions_module "github.com/muga20/artsMarket/modules/exhibitions/routes"
+	feed_module "github.com/muga20/artsMarket/modules/feed/routes"
+	messaging_module "github.com/muga20/artsMarket/modules/messaging/routes"
 	"github.com/muga20/artsMarket/modules/notifications/services"
+	organizations_module "github.com/muga20/artsMarket/modules/organizations/routes"
+	recommendations_module "github.com/muga20/artsMarket/modules/recommendations/routes"
+	trending_module "github.com/muga20/artsMarket/modules/trending/routes"
 	user_module "github.com/muga20/artsMarket/modules/users/routes"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	logs_module "github.com/muga20/artsMarket/pkg/logs/routes"
 	"github.com/muga20/artsMarket/pkg/middleware"
+	"github.com/muga20/artsMarket/pkg/tokens"
 	"github.com/muga20/artsMarket/pkg/worker"
 
 	//"github.com/muga20/artsMarket/pkg/middleware"
@@ -30,6 +37,11 @@ import (
 // @BasePath /api/v1
 
 func main() {
+	// Refuse to start without a usable token signing key
+	if err := tokens.Init(); err != nil {
+		log.Fatalf("Failed to load token signing keys: %v", err)
+	}
+
 	initializeRedis()
 	db := initializeDatabase()
 	responseHandler := handlers.NewResponseHandler(db)
@@ -38,8 +50,15 @@ func main() {
 	notificationService := services.NewNotificationService(responseHandler)
 
 	// Create and start the worker
-	worker := worker.NewNotificationWorker(notificationService, responseHandler, db)
-	go worker.Start()
+	notificationWorker := worker.NewNotificationWorker(notificationService, responseHandler, db)
+	go notificationWorker.Start()
+
+	// Enqueue periodic maintenance tasks
+	go worker.StartScheduler()
+
+	// Relay committed outbox messages onto the task queue
+	outboxRelay := services.NewOutboxRelay(db)
+	go outboxRelay.Start()
 
 	// Start the Fiber app
 	app := fiber.New()
@@ -98,6 +117,13 @@ func setupRoutes(app *fiber.App, db *gorm.DB, responseHandler *handlers.Response
 	user_module.UserModuleSetupRoutes(apiV1, db, responseHandler, cld)
 	logs_module.LogsModuleSetupRoutes(apiV1, db, responseHandler)
 	arts_module.ArtsManagementSetupRoutes(apiV1, db, cld, responseHandler)
+	messaging_module.MessagingSetupRoutes(apiV1, db, cld, responseHandler)
+	exhibitions_module.ExhibitionsSetupRoutes(apiV1, db, cld, responseHandler)
+	organizations_module.OrganizationsSetupRoutes(apiV1, db, responseHandler)
+	feed_module.FeedSetupRoutes(apiV1, db, responseHandler)
+	recommendations_module.RecommendationsSetupRoutes(apiV1, db, responseHandler)
+	trending_module.TrendingSetupRoutes(apiV1, db, responseHandler)
+	worker.SetupTaskAdminRoutes(apiV1, db, responseHandler)
 }
 
 func startServer(app *fiber.App) {
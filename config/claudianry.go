@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"mime/multipart"
+	"path"
+	"strings"
 
 	"github.com/cloudinary/cloudinary-go/v2"
 	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
@@ -51,8 +53,16 @@ func (c *CloudinaryClient) UploadFile(fileHeader *multipart.FileHeader, folder s
 	return uploadResult.SecureURL, nil
 }
 
-// DeleteFile removes an image from Cloudinary
+// DeleteFile removes an image from Cloudinary. It accepts either a public ID
+// or the delivery URL returned by UploadFile.
 func (c *CloudinaryClient) DeleteFile(publicID string) error {
+	if strings.HasPrefix(publicID, "http://") || strings.HasPrefix(publicID, "https://") {
+		publicID = PublicIDFromURL(publicID)
+	}
+	if publicID == "" {
+		return fmt.Errorf("failed to delete file from Cloudinary: missing public ID")
+	}
+
 	ctx := context.Background()
 	_, err := c.Cloudinary.Upload.Destroy(ctx, uploader.DestroyParams{
 		PublicID: publicID,
@@ -62,3 +72,20 @@ func (c *CloudinaryClient) DeleteFile(publicID string) error {
 	}
 	return nil
 }
+
+// PublicIDFromURL extracts the public ID from a delivery URL returned by
+// UploadFile, such as https://res.cloudinary.com/<cloud>/image/upload/v1712/folder/name.jpg
+func PublicIDFromURL(url string) string {
+	_, rest, found := strings.Cut(url, "/upload/")
+	if !found {
+		return ""
+	}
+	rest, _, _ = strings.Cut(rest, "?")
+
+	// Drop the version segment that precedes the public ID
+	segments := strings.SplitN(rest, "/", 2)
+	if len(segments) == 2 && len(segments[0]) > 1 && segments[0][0] == 'v' && strings.Trim(segments[0][1:], "0123456789") == "" {
+		rest = segments[1]
+	}
+	return strings.TrimSuffix(rest, path.Ext(rest))
+}
@@ -16,8 +16,20 @@ type Config struct {
 	DBAddress  string
 	DBName     string
 
-	JWTSecret              string
-	JWTExpirationInSeconds int64
+	// Token signing, see pkg/tokens. The active key signs new tokens; the
+	// previous key is only accepted for verification until it retires.
+	JWTIssuer                     string
+	JWTKeyID                      string
+	JWTAlgorithm                  string // HS256 or EdDSA
+	JWTSecret                     string // HS256 secret
+	JWTPrivateKeyFile             string // EdDSA PEM private key
+	JWTPreviousKeyID              string
+	JWTPreviousAlgorithm          string
+	JWTPreviousSecret             string
+	JWTPreviousPublicKeyFile      string // EdDSA PEM public key
+	JWTPreviousKeyRetiresAt       string // RFC 3339; defaults to one access token lifetime after startup
+	JWTExpirationInSeconds        int64  // Access token lifetime
+	JWTRefreshExpirationInSeconds int64
 
 	SMTPHost     string
 	SMTPPort     string
@@ -26,6 +38,30 @@ type Config struct {
 
 	ClientURL string
 
+	// Base URL of this API, used for links that point straight at it
+	APIBaseURL string
+
+	// Where generated data export archives are written, and how long their
+	// download links stay valid
+	DataExportDir      string
+	DataExportTTLHours int64
+
+	// Key used to encrypt sensitive values such as two-factor secrets at rest
+	DataEncryptionKey string
+
+	// Accounts that never verify their email are removed after this many days
+	UnverifiedAccountRetentionDays int64
+
+	// Accounts scheduled for deletion can be reactivated for this many days
+	// before they are purged
+	AccountDeletionGraceDays int64
+
+	// Google sign-in configuration
+	GoogleClientID     string
+	GoogleClientSecret string
+	GoogleRedirectURL  string
+	GoogleIssuerURL    string
+
 	// Cloudinary configuration
 	CloudinaryCloudName string
 	CloudinaryAPIKey    string
@@ -48,8 +84,18 @@ func LoadConfig() Config {
 		DBAddress:  getEnv("DB_HOST", "localhost"),
 		DBName:     getEnv("DB_NAME", ""),
 
-		JWTSecret:              getEnv("JWT_SECRET", ""),
-		JWTExpirationInSeconds: getEnvAsInt("JWT_EXPIRATION_IN_SECONDS", 3600*24*7),
+		JWTIssuer:                     getEnv("JWT_ISSUER", "arts-market-api"),
+		JWTKeyID:                      getEnv("JWT_KEY_ID", "default"),
+		JWTAlgorithm:                  getEnv("JWT_ALGORITHM", "HS256"),
+		JWTSecret:                     getEnv("JWT_SECRET", ""),
+		JWTPrivateKeyFile:             getEnv("JWT_PRIVATE_KEY_FILE", ""),
+		JWTPreviousKeyID:              getEnv("JWT_PREVIOUS_KEY_ID", ""),
+		JWTPreviousAlgorithm:          getEnv("JWT_PREVIOUS_ALGORITHM", "HS256"),
+		JWTPreviousSecret:             getEnv("JWT_PREVIOUS_SECRET", ""),
+		JWTPreviousPublicKeyFile:      getEnv("JWT_PREVIOUS_PUBLIC_KEY_FILE", ""),
+		JWTPreviousKeyRetiresAt:       getEnv("JWT_PREVIOUS_KEY_RETIRES_AT", ""),
+		JWTExpirationInSeconds:        getEnvAsInt("JWT_EXPIRATION_IN_SECONDS", 15*60),
+		JWTRefreshExpirationInSeconds: getEnvAsInt("JWT_REFRESH_EXPIRATION_IN_SECONDS", 3600*24*30),
 
 		SMTPHost:     getEnv("SMTP_HOST", ""),
 		SMTPPort:     getEnv("SMTP_PORT", ""),
@@ -58,6 +104,21 @@ func LoadConfig() Config {
 
 		ClientURL: getEnv("CLIENT_URL", ""),
 
+		APIBaseURL: getEnv("API_BASE_URL", "http://localhost:8080"),
+
+		DataExportDir:      getEnv("DATA_EXPORT_DIR", "storage/exports"),
+		DataExportTTLHours: getEnvAsInt("DATA_EXPORT_TTL_HOURS", 48),
+
+		DataEncryptionKey: getEnv("DATA_ENCRYPTION_KEY", ""),
+
+		UnverifiedAccountRetentionDays: getEnvAsInt("UNVERIFIED_ACCOUNT_RETENTION_DAYS", 7),
+		AccountDeletionGraceDays:       getEnvAsInt("ACCOUNT_DELETION_GRACE_DAYS", 30),
+
+		GoogleClientID:     getEnv("GOOGLE_CLIENT_ID", ""),
+		GoogleClientSecret: getEnv("GOOGLE_CLIENT_SECRET", ""),
+		GoogleRedirectURL:  getEnv("GOOGLE_REDIRECT_URL", ""),
+		GoogleIssuerURL:    getEnv("GOOGLE_ISSUER_URL", "https://accounts.google.com"),
+
 		// Load Cloudinary credentials
 		CloudinaryCloudName: getEnv("CLOUDINARY_CLOUD_NAME", ""),
 		CloudinaryAPIKey:    getEnv("CLOUDINARY_API_KEY", ""),
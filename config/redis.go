@@ -1,8 +1,11 @@
 package config
 
 import (
-	"github.com/hibiken/asynq"
 	"log"
+	"sync"
+
+	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 )
 
 var RedisConfig = &asynq.RedisClientOpt{
@@ -11,6 +14,11 @@ var RedisConfig = &asynq.RedisClientOpt{
 	DB:       0,
 }
 
+var (
+	redisClient     *redis.Client
+	redisClientOnce sync.Once
+)
+
 func InitializeRedis() {
 	// Create the Asynq client
 	client := asynq.NewClient(*RedisConfig)
@@ -23,3 +31,15 @@ func InitializeRedis() {
 
 	log.Println("Connected to Redis successfully")
 }
+
+// Redis returns the shared client used for counters and caches outside the task queue
+func Redis() *redis.Client {
+	redisClientOnce.Do(func() {
+		redisClient = redis.NewClient(&redis.Options{
+			Addr:     RedisConfig.Addr,
+			Password: RedisConfig.Password,
+			DB:       RedisConfig.DB,
+		})
+	})
+	return redisClient
+}
@@ -6,6 +6,7 @@ import (
 
 	// User module imports
 	notification "github.com/muga20/artsMarket/modules/notifications/models"
+	artist_profile "github.com/muga20/artsMarket/modules/users/models"
 	blocked_user "github.com/muga20/artsMarket/modules/users/models"
 	follower "github.com/muga20/artsMarket/modules/users/models"
 	roles "github.com/muga20/artsMarket/modules/users/models"
@@ -35,13 +36,27 @@ import (
 	artwork_tag "github.com/muga20/artsMarket/modules/artwork-management/models/tags"
 	tag "github.com/muga20/artsMarket/modules/artwork-management/models/tags"
 	technique "github.com/muga20/artsMarket/modules/artwork-management/models/technique"
+	// Feed module imports
+	feed "github.com/muga20/artsMarket/modules/feed/models"
+	// Messaging module imports
+	messaging "github.com/muga20/artsMarket/modules/messaging/models"
+	// Exhibitions module imports
+	exhibition "github.com/muga20/artsMarket/modules/exhibitions/models"
+	// Organizations module imports
+	organization "github.com/muga20/artsMarket/modules/organizations/models"
 
+	"github.com/gosimple/slug"
 	"gorm.io/gorm"
 )
 
 func AutoMigrate(db *gorm.DB) error {
 	log.Println("🔄 Running database migrations...")
 
+	// Accounts created before email verification was required have no
+	// verification token column yet; remember that before it is added
+	verifyLegacyAccounts := tableExists(db, "user_securities") &&
+		!db.Migrator().HasColumn(&user_security.UserSecurity{}, "email_verification_token")
+
 	migrations := []interface{}{
 		// User module
 		&roles.Role{},
@@ -53,14 +68,28 @@ func AutoMigrate(db *gorm.DB) error {
 		&user_location.UserLocation{},
 		&user_detail.UserDetail{},
 		&social_link.SocialLink{},
+		&artist_profile.ArtistProfile{},
+		&artist_profile.ArtistCVEntry{},
+		&artist_profile.ArtistVerificationRequest{},
+		&artist_profile.ArtistVerificationDocument{},
 		&follower.Follower{},
 		&blocked_user.BlockedUser{},
+		&users.UserIdentity{},
+		&users.UserRecoveryCode{},
+		&users.PersonalAccessToken{},
+		&users.DataExport{},
 
 		// Error logs
 		&error_log.ErrorLog{},
 
 		// Notification
 		&notification.Notification{},
+		&notification.OutboxMessage{},
+
+		// Organizations, before the artworks and collections they list
+		&organization.Organization{},
+		&organization.OrganizationMember{},
+		&organization.Representation{},
 
 		// Artwork analytics
 		&artwork_view.ArtworkView{},
@@ -72,6 +101,8 @@ func AutoMigrate(db *gorm.DB) error {
 		&artwork.Artwork{},
 		&artwork_edition.Edition{},
 		&artwork_image.ArtworkImage{},
+		&artwork.DimensionMigration{},
+		&artwork.DimensionParseFailure{},
 
 		// Categories
 		&category.Category{},
@@ -79,6 +110,9 @@ func AutoMigrate(db *gorm.DB) error {
 
 		// Collections
 		&collection.Collection{},
+		&collection.CollectionCollaborator{},
+		&collection.CollectionArtistGrant{},
+		&collection.CollectionEntry{},
 
 		// Engagement
 		&artwork_comment.ArtworkComment{},
@@ -91,9 +125,23 @@ func AutoMigrate(db *gorm.DB) error {
 		// Tags
 		&tag.Tag{},
 		&artwork_tag.ArtworkTag{},
+		&tag.TagAlias{},
 
 		// Technique
 		&technique.Technique{},
+
+		// Messaging
+		&messaging.Conversation{},
+		&messaging.Message{},
+
+		// Feed
+		&feed.Activity{},
+
+		// Exhibitions
+		&exhibition.Exhibition{},
+		&exhibition.ExhibitionRoom{},
+		&exhibition.ExhibitionArtwork{},
+		&exhibition.ExhibitionRSVP{},
 	}
 
 	for _, model := range migrations {
@@ -103,10 +151,142 @@ func AutoMigrate(db *gorm.DB) error {
 		log.Printf("✅ Successfully migrated model: %T", model)
 	}
 
+	if err := backfillCategoryPaths(db); err != nil {
+		return err
+	}
+	if err := backfillAttributeValues(db); err != nil {
+		return err
+	}
+	if err := backfillTagKeys(db); err != nil {
+		return err
+	}
+	if err := backfillCollectionEntries(db); err != nil {
+		return err
+	}
+	if verifyLegacyAccounts {
+		if err := backfillEmailVerification(db); err != nil {
+			return err
+		}
+	}
+
 	log.Println("✅ Database migration completed successfully")
 	return nil
 }
 
+// backfillCategoryPaths places categories created before the hierarchy at the root
+func backfillCategoryPaths(db *gorm.DB) error {
+	if err := db.Exec("UPDATE categories SET path = CONCAT('/', id, '/'), depth = 0 WHERE path = '' AND parent_id IS NULL").Error; err != nil {
+		return fmt.Errorf("failed to backfill category paths: %w", err)
+	}
+	return nil
+}
+
+// backfillAttributeValues gives attributes created before slugs one, and
+// copies numeric values stored only as text into the indexed column
+func backfillAttributeValues(db *gorm.DB) error {
+	var unnamed []artwork_attribute.Attribute
+	if err := db.Where("slug = ''").Find(&unnamed).Error; err != nil {
+		return fmt.Errorf("failed to load attributes without slugs: %w", err)
+	}
+	for _, attribute := range unnamed {
+		if err := db.Model(&attribute).Update("slug", slug.Make(attribute.AttributeName)).Error; err != nil {
+			return fmt.Errorf("failed to backfill attribute slugs: %w", err)
+		}
+	}
+
+	if err := db.Exec(`UPDATE artwork_attributes
+		JOIN attributes ON attributes.id = artwork_attributes.attribute_id
+		SET artwork_attributes.numeric_value = artwork_attributes.value + 0
+		WHERE attributes.type IN ('integer', 'float')
+		AND artwork_attributes.numeric_value IS NULL
+		AND artwork_attributes.value REGEXP '^-?[0-9]+(\\.[0-9]+)?$'`).Error; err != nil {
+		return fmt.Errorf("failed to backfill numeric attribute values: %w", err)
+	}
+	return nil
+}
+
+// backfillTagKeys gives tags created before normalized keys theirs. Where
+// several tags normalize to one key, the oldest gets it and the others are
+// left for an admin to merge.
+func backfillTagKeys(db *gorm.DB) error {
+	var unkeyed []tag.Tag
+	if err := db.Where("normalized_key IS NULL").Order("created_at, id").Find(&unkeyed).Error; err != nil {
+		return fmt.Errorf("failed to load tags without keys: %w", err)
+	}
+
+	duplicates := 0
+	for _, t := range unkeyed {
+		key := tag.NormalizeTag(t.TagName)
+		if key == "" {
+			continue
+		}
+		taken, err := tag.KeyTaken(db, key, t.ID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			duplicates++
+			continue
+		}
+		if err := db.Model(&tag.Tag{}).Where("id = ?", t.ID).Update("normalized_key", key).Error; err != nil {
+			return fmt.Errorf("failed to backfill tag keys: %w", err)
+		}
+	}
+	if duplicates > 0 {
+		log.Printf("⚠️ %d tags duplicate the name of another tag and should be merged", duplicates)
+	}
+	return nil
+}
+
+// backfillCollectionEntries moves artworks placed in a collection through the
+// legacy artworks.collection_id column into collection entries, ordered by
+// creation, then drops the column
+func backfillCollectionEntries(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasColumn(&artwork.Artwork{}, "collection_id") {
+		return nil
+	}
+
+	if err := db.Exec(`INSERT IGNORE INTO collection_entries
+			(id, collection_id, artwork_id, position, added_by, created_at, updated_at)
+		SELECT UUID(), collection_id, id,
+			ROW_NUMBER() OVER (PARTITION BY collection_id ORDER BY created_at, id),
+			user_id, created_at, created_at
+		FROM artworks
+		WHERE collection_id IS NOT NULL`).Error; err != nil {
+		return fmt.Errorf("failed to backfill collection entries: %w", err)
+	}
+
+	const legacyConstraint = "fk_artworks_collection"
+	if migrator.HasConstraint(&artwork.Artwork{}, legacyConstraint) {
+		if err := migrator.DropConstraint(&artwork.Artwork{}, legacyConstraint); err != nil {
+			return fmt.Errorf("failed to drop legacy collection constraint: %w", err)
+		}
+	}
+	if err := migrator.DropColumn(&artwork.Artwork{}, "collection_id"); err != nil {
+		return fmt.Errorf("failed to drop legacy collection column: %w", err)
+	}
+	return nil
+}
+
+// backfillEmailVerification marks accounts that were active before email
+// verification was required as verified, so the verified-email gate does not
+// lock them out. It runs once, when the verification token column is added.
+// Accounts waiting to confirm an address are left unverified; email changes
+// made before then kept their confirmation token in password_reset_token.
+func backfillEmailVerification(db *gorm.DB) error {
+	if err := db.Exec(`UPDATE user_securities
+		JOIN users ON users.id = user_securities.user_id
+		SET user_securities.is_email_verified_at = users.created_at
+		WHERE users.status = ?
+		AND user_securities.is_email_verified_at IS NULL
+		AND user_securities.email_verification_token IS NULL
+		AND user_securities.password_reset_token IS NULL`, users.UserStatusActive).Error; err != nil {
+		return fmt.Errorf("failed to backfill email verification: %w", err)
+	}
+	return nil
+}
+
 func tableExists(db *gorm.DB, tableName string) bool {
 	var count int64
 	db.Raw("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?", tableName).Scan(&count)
@@ -0,0 +1,133 @@
+// Package dimensions moves artworks from free-text dimensions to structured
+// height, width and depth.
+package dimensions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	models "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	"gorm.io/gorm"
+)
+
+// batchSize is how many artworks are parsed per query
+const batchSize = 500
+
+type legacyRow struct {
+	ID         uuid.UUID
+	Dimensions string
+}
+
+// Migrate parses the free-text dimensions of every artwork without
+// structured ones and records the artworks it could not read on the
+// migration. Artworks parsed by an earlier run are skipped, so a failed run
+// can be retried.
+func Migrate(ctx context.Context, db *gorm.DB, migrationID uuid.UUID) error {
+	var migration models.DimensionMigration
+	if err := db.Where("id = ?", migrationID).First(&migration).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load dimension migration: %w", err)
+	}
+	if migration.Status != models.DimensionMigrationPending && migration.Status != models.DimensionMigrationProcessing {
+		return nil
+	}
+
+	// A retry starts the report over
+	if err := db.Where("migration_id = ?", migration.ID).Delete(&models.DimensionParseFailure{}).Error; err != nil {
+		return fmt.Errorf("failed to reset dimension migration: %w", err)
+	}
+	if err := db.Model(&migration).Updates(map[string]interface{}{
+		"status":   models.DimensionMigrationProcessing,
+		"scanned":  0,
+		"parsed":   0,
+		"unparsed": 0,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to start dimension migration: %w", err)
+	}
+
+	started := time.Now()
+	scanned, parsed, unparsed, err := migrate(ctx, db, migration.ID)
+	updates := map[string]interface{}{
+		"scanned":  scanned,
+		"parsed":   parsed,
+		"unparsed": unparsed,
+	}
+	if err != nil {
+		updates["error"] = err.Error()
+		db.Model(&migration).Updates(updates)
+		return err
+	}
+
+	now := time.Now()
+	updates["status"] = models.DimensionMigrationCompleted
+	updates["completed_at"] = &now
+	if err := db.Model(&migration).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to complete dimension migration: %w", err)
+	}
+	log.Printf("Migrated dimensions of %d artworks, %d could not be parsed, in %s",
+		parsed, unparsed, time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+func migrate(ctx context.Context, db *gorm.DB, migrationID uuid.UUID) (scanned, parsed, unparsed int, err error) {
+	after := ""
+	for {
+		if err := ctx.Err(); err != nil {
+			return scanned, parsed, unparsed, err
+		}
+
+		var rows []legacyRow
+		if err := db.Model(&models.Artwork{}).
+			Select("id, dimensions").
+			Where("height IS NULL AND dimensions <> '' AND id > ?", after).
+			Order("id").
+			Limit(batchSize).
+			Scan(&rows).Error; err != nil {
+			return scanned, parsed, unparsed, fmt.Errorf("failed to load artworks: %w", err)
+		}
+		if len(rows) == 0 {
+			return scanned, parsed, unparsed, nil
+		}
+		after = rows[len(rows)-1].ID.String()
+		scanned += len(rows)
+
+		var failures []models.DimensionParseFailure
+		err := db.Transaction(func(tx *gorm.DB) error {
+			for _, row := range rows {
+				measurements, err := models.ParseDimensions(row.Dimensions)
+				if err != nil {
+					failures = append(failures, models.DimensionParseFailure{
+						MigrationID: migrationID,
+						ArtworkID:   row.ID,
+						Dimensions:  row.Dimensions,
+						Reason:      err.Error(),
+					})
+					continue
+				}
+
+				// The original text stays as the display label
+				artwork := models.Artwork{Dimensions: row.Dimensions}
+				artwork.SetMeasurements(measurements)
+				if err := tx.Model(&models.Artwork{}).Where("id = ?", row.ID).
+					UpdateColumns(artwork.MeasurementColumns()).Error; err != nil {
+					return fmt.Errorf("failed to update artwork %s: %w", row.ID, err)
+				}
+			}
+			if len(failures) > 0 {
+				return tx.Create(&failures).Error
+			}
+			return nil
+		})
+		if err != nil {
+			return scanned, parsed, unparsed, err
+		}
+		parsed += len(rows) - len(failures)
+		unparsed += len(failures)
+	}
+}
@@ -2,6 +2,7 @@ package artworks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"runtime"
@@ -20,11 +21,20 @@ import (
 	tag "github.com/muga20/artsMarket/modules/artwork-management/models/tags"
 	"github.com/muga20/artsMarket/modules/artwork-management/repository"
 	image_handler "github.com/muga20/artsMarket/modules/artwork-management/services"
+	notifications "github.com/muga20/artsMarket/modules/notifications/services"
+	organizations "github.com/muga20/artsMarket/modules/organizations/models"
 	user_details "github.com/muga20/artsMarket/modules/users/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"gorm.io/gorm"
 )
 
+const (
+	// maxNewTagsPerArtwork bounds how many tags an artist can create while tagging one artwork
+	maxNewTagsPerArtwork = 5
+	// maxNewTagLength bounds the length of tags created inline
+	maxNewTagLength = 50
+)
+
 // AttributeInput represents a single attribute with ID and value
 type AttributeInput struct {
 	ID    string `form:"id"`    // Attribute ID as string
@@ -41,15 +51,23 @@ type CreateArtworkRequest struct {
 	Type       string   `form:"type" validate:"required,oneof=traditional digital photography mixed_media sculpture performance"`
 	Categories []string `form:"categories"` // Comma-separated category IDs
 	Tags       []string `form:"tags"`       // Comma-separated tag IDs
+	NewTags    []string `form:"new_tags"`   // Comma-separated names of tags to create, pending moderation
 
 	// Ownership & Collection
-	CollectionID string `form:"collection_id"`
+	CollectionID   string `form:"collection_id"`
+	OrganizationID string `form:"organization_id"` // List on behalf of an artist the organization represents
+	ArtistID       string `form:"artist_id"`       // The represented artist, when listing for an organization
 
 	// Physical Details
-	Dimensions string  `form:"dimensions"`
-	Weight     float64 `form:"weight"`
-	IsFramed   bool    `form:"is_framed"`
-	Condition  string  `form:"condition" validate:"omitempty,oneof=pristine excellent good acceptable restored damaged"`
+	Dimensions    string  `form:"dimensions"` // Free-text label, parsed when height and width are not given
+	Height        float64 `form:"height"`
+	Width         float64 `form:"width"`
+	Depth         float64 `form:"depth"`
+	DimensionUnit string  `form:"dimension_unit" validate:"omitempty,oneof=cm in"`
+	Weight        float64 `form:"weight"`
+	WeightUnit    string  `form:"weight_unit" validate:"omitempty,oneof=kg lb"`
+	IsFramed      bool    `form:"is_framed"`
+	Condition     string  `form:"condition" validate:"omitempty,oneof=pristine excellent good acceptable restored damaged"`
 
 	// Creation Details
 	CreationDate string `form:"creation_date" validate:"omitempty,datetime=2006-01-02"`
@@ -87,7 +105,7 @@ type ErrorResponse struct {
 
 // CreateArtworkHandler godoc
 // @Summary Create a new artwork
-// @Description Creates a new artwork with all related data including images, tags, categories, and attributes
+// @Description Creates a new artwork with all related data including images, tags, categories, and attributes. Organization members can list artworks for the artists the organization represents; the artwork belongs to the artist and records the organization and the commission agreed with the artist.
 // @Tags Artworks
 // @Accept multipart/form-data
 // @Produce json
@@ -97,9 +115,17 @@ type ErrorResponse struct {
 // @Param type formData string true "Artwork type" Enums(traditional,digital,photography,mixed_media,sculpture,performance)
 // @Param categories formData string false "Comma-separated category IDs (e.g., 'id1,id2,id3')"
 // @Param tags formData []string false "Array of tag IDs (format: tags[0]=id1, tags[1]=id2)"
+// @Param new_tags formData string false "Comma-separated tag names. Names matching an existing tag or alias use it; others create tags that await moderation"
 // @Param collection_id formData string false "Collection ID"
-// @Param dimensions formData string false "Dimensions"
-// @Param weight formData number false "Weight in kg"
+// @Param organization_id formData string false "Organization listing the artwork on the artist's behalf. The authenticated user must be a member"
+// @Param artist_id formData string false "Artist the organization lists for, which it must actively represent. Defaults to the authenticated user"
+// @Param dimensions formData string false "Dimensions as text, such as 50 x 70 cm. Parsed into height, width and depth when those are not given"
+// @Param height formData number false "Height"
+// @Param width formData number false "Width"
+// @Param depth formData number false "Depth"
+// @Param dimension_unit formData string false "Unit of height, width and depth (default cm)" Enums(cm,in)
+// @Param weight formData number false "Weight"
+// @Param weight_unit formData string false "Unit of weight (default kg)" Enums(kg,lb)
 // @Param is_framed formData boolean false "Is framed"
 // @Param condition formData string false "Condition" Enums(pristine,excellent,good,acceptable,restored,damaged)
 // @Param creation_date formData string false "Creation date (YYYY-MM-DD)"
@@ -143,6 +169,12 @@ func CreateArtworkHandler(db *gorm.DB, cld *config.CloudinaryClient, responseHan
 			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusBadRequest, err.Error()))
 		}
 
+		// Attribute values are checked against their definitions before anything is written
+		attributeRows, err := resolveAttributes(db, req.Type, req.Attributes)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
 		tx := db.Begin()
 		if tx.Error != nil {
 			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to start transaction"))
@@ -160,12 +192,37 @@ func CreateArtworkHandler(db *gorm.DB, cld *config.CloudinaryClient, responseHan
 			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to check existing artworks"))
 		}
 
-		artwork, err := createArtwork(ctx, tx, user.ID, req, responseHandler)
+		owner, err := resolveListing(tx, user.ID, req)
+		if err != nil {
+			tx.Rollback()
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		artwork, err := createArtwork(ctx, tx, user.ID, owner, req, responseHandler)
 		if err != nil {
 			tx.Rollback()
 			return err
 		}
 
+		if owner.ArtistID != user.ID {
+			message := fmt.Sprintf("%s listed %q on your behalf for %s", user.Username, artwork.Title, owner.Organization.Name)
+			if err := notifications.WriteNotificationFrom(tx, owner.ArtistID, user.ID, "artwork_listed_by_organization",
+				message, "artwork", artwork.ID); err != nil {
+				tx.Rollback()
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+		}
+
+		// Tags typed by the artist join the chosen ones before they are saved
+		if len(req.NewTags) > 0 {
+			tagIDs, err := resolveNewTags(tx, user.ID, req.NewTags)
+			if err != nil {
+				tx.Rollback()
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+			req.Tags = append(req.Tags, tagIDs...)
+		}
+
 		var wg sync.WaitGroup
 		errChan := make(chan error, 5)
 
@@ -203,11 +260,11 @@ func CreateArtworkHandler(db *gorm.DB, cld *config.CloudinaryClient, responseHan
 		}
 
 		// Process attributes in bulk
-		if len(req.Attributes) > 0 {
+		if len(attributeRows) > 0 {
 			wg.Add(1)
 			go func() {
 				defer wg.Done()
-				if err := processAttributes(ctx, tx, artwork.ID, req.Attributes); err != nil {
+				if err := processAttributes(ctx, tx, artwork.ID, attributeRows); err != nil {
 					errChan <- err
 				}
 			}()
@@ -259,9 +316,21 @@ func parseFormData(form *multipart.Form, req *CreateArtworkRequest) error {
 	if collectionIDs := form.Value["collection_id"]; len(collectionIDs) > 0 {
 		req.CollectionID = collectionIDs[0]
 	}
+	if organizationIDs := form.Value["organization_id"]; len(organizationIDs) > 0 {
+		req.OrganizationID = organizationIDs[0]
+	}
+	if artistIDs := form.Value["artist_id"]; len(artistIDs) > 0 {
+		req.ArtistID = artistIDs[0]
+	}
 	if dimensions := form.Value["dimensions"]; len(dimensions) > 0 {
 		req.Dimensions = dimensions[0]
 	}
+	if units := form.Value["dimension_unit"]; len(units) > 0 {
+		req.DimensionUnit = units[0]
+	}
+	if units := form.Value["weight_unit"]; len(units) > 0 {
+		req.WeightUnit = units[0]
+	}
 	if conditions := form.Value["condition"]; len(conditions) > 0 {
 		req.Condition = conditions[0]
 	}
@@ -288,6 +357,13 @@ func parseFormData(form *multipart.Form, req *CreateArtworkRequest) error {
 	if tags := form.Value["tags"]; len(tags) > 0 {
 		req.Tags = strings.Split(tags[0], ",")
 	}
+	if newTags := form.Value["new_tags"]; len(newTags) > 0 {
+		for _, name := range strings.Split(newTags[0], ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				req.NewTags = append(req.NewTags, name)
+			}
+		}
+	}
 
 	// Handle numeric values
 	if weights := form.Value["weight"]; len(weights) > 0 {
@@ -295,6 +371,15 @@ func parseFormData(form *multipart.Form, req *CreateArtworkRequest) error {
 			req.Weight = weight
 		}
 	}
+	for field, target := range map[string]*float64{"height": &req.Height, "width": &req.Width, "depth": &req.Depth} {
+		if values := form.Value[field]; len(values) > 0 && values[0] != "" {
+			value, err := strconv.ParseFloat(values[0], 64)
+			if err != nil {
+				return fmt.Errorf("%s must be a number", field)
+			}
+			*target = value
+		}
+	}
 	if prices := form.Value["price"]; len(prices) > 0 {
 		if price, err := strconv.ParseFloat(prices[0], 64); err == nil {
 			req.Price = price
@@ -354,11 +439,103 @@ func validateArtworkRequest(req *CreateArtworkRequest) error {
 			return fmt.Errorf("invalid creation date format, use YYYY-MM-DD")
 		}
 	}
+	if req.WeightUnit != "" && !models.WeightUnit(req.WeightUnit).Valid() {
+		return fmt.Errorf("weight unit must be kg or lb")
+	}
+	if req.Weight < 0 {
+		return fmt.Errorf("weight cannot be negative")
+	}
+	if _, err := requestMeasurements(req); err != nil {
+		return err
+	}
+	if len(req.NewTags) > maxNewTagsPerArtwork {
+		return fmt.Errorf("at most %d new tags can be created per artwork", maxNewTagsPerArtwork)
+	}
+	for _, name := range req.NewTags {
+		if len(name) > maxNewTagLength {
+			return fmt.Errorf("tag names cannot be longer than %d characters", maxNewTagLength)
+		}
+	}
 	return nil
 }
 
-// createArtwork creates the base artwork record
-func createArtwork(ctx context.Context, tx *gorm.DB, userID uuid.UUID, req CreateArtworkRequest, responseHandler *handlers.ResponseHandler) (*models.Artwork, error) {
+// requestMeasurements returns the structured dimensions of the request: the
+// height, width and depth fields when given, or else the parsed text
+// dimensions. Text that cannot be parsed is kept as a label only.
+func requestMeasurements(req *CreateArtworkRequest) (*models.Measurements, error) {
+	if req.Height == 0 && req.Width == 0 && req.Depth == 0 {
+		if req.Dimensions == "" {
+			return nil, nil
+		}
+		if m, err := models.ParseDimensions(req.Dimensions); err == nil {
+			return &m, nil
+		}
+		return nil, nil
+	}
+
+	m := models.Measurements{Height: req.Height, Width: req.Width, Unit: models.LengthUnit(req.DimensionUnit)}
+	if m.Unit == "" {
+		m.Unit = models.Centimeters
+	}
+	if req.Depth != 0 {
+		depth := req.Depth
+		m.Depth = &depth
+	}
+	if err := m.Validate(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// listingOwner is who a new artwork is listed for: the artist, and the
+// organization listing it on their behalf, if any
+type listingOwner struct {
+	ArtistID       uuid.UUID
+	Organization   *organizations.Organization
+	CommissionRate *float64
+}
+
+// resolveListing works out who a new artwork is listed for. Artists list
+// their own work; organization members list for the artists the
+// organization actively represents, at the commission agreed with them.
+func resolveListing(tx *gorm.DB, userID uuid.UUID, req CreateArtworkRequest) (*listingOwner, error) {
+	artistID := userID
+	if req.ArtistID != "" {
+		parsed, err := uuid.Parse(req.ArtistID)
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid artist ID")
+		}
+		artistID = parsed
+	}
+	if req.OrganizationID == "" {
+		if artistID != userID {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Listing for another artist requires an organization_id")
+		}
+		return &listingOwner{ArtistID: userID}, nil
+	}
+
+	organization, _, err := organizations.Authorize(tx, req.OrganizationID, userID, organizations.RoleStaff)
+	if err != nil {
+		if errors.Is(err, organizations.ErrOrganizationNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Organization not found")
+		}
+		return nil, fmt.Errorf("failed to check organization membership: %w", err)
+	}
+	representation, err := organizations.ActiveRepresentation(tx, organization.ID, artistID)
+	if err != nil {
+		if errors.Is(err, organizations.ErrNotRepresented) {
+			return nil, fiber.NewError(fiber.StatusForbidden, "The organization does not represent this artist")
+		}
+		return nil, fmt.Errorf("failed to check representation: %w", err)
+	}
+
+	rate := organization.CommissionFor(representation)
+	return &listingOwner{ArtistID: artistID, Organization: organization, CommissionRate: &rate}, nil
+}
+
+// createArtwork creates the base artwork record. userID is the user
+// creating it, who may be listing for another artist.
+func createArtwork(ctx context.Context, tx *gorm.DB, userID uuid.UUID, owner *listingOwner, req CreateArtworkRequest, responseHandler *handlers.ResponseHandler) (*models.Artwork, error) {
 	collectionID, err := parseCollectionID(ctx, tx, req.CollectionID, userID)
 	if err != nil {
 		return nil, err
@@ -374,16 +551,17 @@ func createArtwork(ctx context.Context, tx *gorm.DB, userID uuid.UUID, req Creat
 	}
 
 	artwork := &models.Artwork{
-		UserID:         userID,
+		UserID:         owner.ArtistID,
+		CommissionRate: owner.CommissionRate,
 		Title:          req.Title,
 		Description:    req.Description,
-		CollectionID:   collectionID,
 		CreationDate:   creationDate,
 		Price:          &req.Price,
 		IsForSale:      req.IsForSale,
 		Type:           models.ArtworkType(req.Type),
 		Dimensions:     req.Dimensions,
 		Weight:         &req.Weight,
+		WeightUnit:     models.WeightUnit(req.WeightUnit),
 		IsFramed:       req.IsFramed,
 		Condition:      models.ConditionType(req.Condition),
 		MediumID:       parseUUIDPointer(req.MediumID),
@@ -391,14 +569,66 @@ func createArtwork(ctx context.Context, tx *gorm.DB, userID uuid.UUID, req Creat
 		LicenseType:    models.LicenseType(req.LicenseType),
 		LicenseDetails: req.LicenseDetails,
 	}
+	if owner.Organization != nil {
+		artwork.OrganizationID = &owner.Organization.ID
+	}
+
+	measurements, err := requestMeasurements(&req)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	if measurements != nil {
+		artwork.SetMeasurements(*measurements)
+	}
 
 	if err := tx.Create(artwork).Error; err != nil {
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to create artwork")
 	}
 
+	if collectionID != nil {
+		if err := appendToCollection(tx, *collectionID, artwork.ID, owner.ArtistID, userID); err != nil {
+			return nil, err
+		}
+	}
+
 	return artwork, nil
 }
 
+// appendToCollection places a new artwork at the end of the collection,
+// subject to the same size limit and artist permission as adding it later
+func appendToCollection(tx *gorm.DB, collectionID, artworkID, artistID, userID uuid.UUID) error {
+	allowed, err := collection.MayInclude(tx, collectionID, artistID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check artist permission: %w", err)
+	}
+	if !allowed {
+		return fiber.NewError(fiber.StatusForbidden, "The artist has not allowed this collection to include their work")
+	}
+
+	var entries int64
+	if err := tx.Model(&collection.CollectionEntry{}).Where("collection_id = ?", collectionID).Count(&entries).Error; err != nil {
+		return fmt.Errorf("failed to count collection entries: %w", err)
+	}
+	if entries >= collection.MaxEntries {
+		return fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("A collection can hold at most %d artworks", collection.MaxEntries))
+	}
+
+	position, err := collection.NextPosition(tx, collectionID)
+	if err != nil {
+		return fmt.Errorf("failed to find collection position: %w", err)
+	}
+	if err := tx.Create(&collection.CollectionEntry{
+		CollectionID: collectionID,
+		ArtworkID:    artworkID,
+		Position:     position,
+		AddedBy:      &userID,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to add artwork to collection: %w", err)
+	}
+	return nil
+}
+
 // processEditions handles edition creation
 func processEditions(ctx context.Context, tx *gorm.DB, artworkID uuid.UUID, editionNumber, totalEditions int) error {
 	edition := models.Edition{
@@ -411,13 +641,71 @@ func processEditions(ctx context.Context, tx *gorm.DB, artworkID uuid.UUID, edit
 	return tx.Create(&edition).Error
 }
 
+// resolveNewTags returns the IDs of the tags the names refer to, creating
+// tags for unknown names that await moderation. Names of rejected tags are
+// dropped.
+func resolveNewTags(tx *gorm.DB, userID uuid.UUID, names []string) ([]string, error) {
+	ids := make([]string, 0, len(names))
+	for _, name := range names {
+		existing, err := tag.Resolve(tx, name)
+		switch {
+		case err == nil:
+			if existing.Status != tag.TagStatusRejected {
+				ids = append(ids, existing.ID.String())
+			}
+			continue
+		case errors.Is(err, tag.ErrEmptyTagName):
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Invalid tag name %q: %v", name, err))
+		case !errors.Is(err, gorm.ErrRecordNotFound):
+			return nil, fmt.Errorf("failed to look up tag %q: %w", name, err)
+		}
+
+		created := tag.Tag{
+			TagName:   name,
+			Status:    tag.TagStatusPending,
+			CreatedBy: &userID,
+			IsActive:  true,
+		}
+		if err := tx.Create(&created).Error; err != nil {
+			return nil, fmt.Errorf("failed to create tag %q: %w", name, err)
+		}
+		ids = append(ids, created.ID.String())
+	}
+	return ids, nil
+}
+
 func processTags(ctx context.Context, tx *gorm.DB, artworkID uuid.UUID, tagIDs []string) error {
-	artworkTags := make([]tag.ArtworkTag, 0, len(tagIDs))
+	parsed := make([]uuid.UUID, 0, len(tagIDs))
 	for _, tagID := range tagIDs {
-		tagUUID, err := uuid.Parse(tagID)
+		tagUUID, err := uuid.Parse(strings.TrimSpace(tagID))
 		if err != nil {
 			return fmt.Errorf("invalid tag ID format: %s", tagID)
 		}
+		parsed = append(parsed, tagUUID)
+	}
+	if len(parsed) == 0 {
+		return nil
+	}
+
+	// Rejected tags cannot be attached, the same as their names in new_tags
+	var usable []uuid.UUID
+	if err := tx.Model(&tag.Tag{}).
+		Where("id IN ? AND status <> ?", parsed, tag.TagStatusRejected).
+		Pluck("id", &usable).Error; err != nil {
+		return fmt.Errorf("failed to check tags: %w", err)
+	}
+	allowed := make(map[uuid.UUID]bool, len(usable))
+	for _, id := range usable {
+		allowed[id] = true
+	}
+
+	artworkTags := make([]tag.ArtworkTag, 0, len(parsed))
+	seen := make(map[uuid.UUID]bool, len(parsed))
+	for _, tagUUID := range parsed {
+		if seen[tagUUID] || !allowed[tagUUID] {
+			continue
+		}
+		seen[tagUUID] = true
 		artworkTags = append(artworkTags, tag.ArtworkTag{
 			ArtworkID: artworkID,
 			TagID:     tagUUID,
@@ -447,23 +735,26 @@ func processCategories(ctx context.Context, tx *gorm.DB, artworkID uuid.UUID, ca
 	return nil
 }
 
-func processAttributes(ctx context.Context, tx *gorm.DB, artworkID uuid.UUID, attrs []AttributeInput) error {
-	artworkAttributes := make([]attributes.ArtworkAttribute, 0, len(attrs))
-	for _, attr := range attrs {
-		attrID, err := uuid.Parse(attr.ID)
-		if err != nil {
-			return fmt.Errorf("invalid attribute ID format: %s", attr.ID)
-		}
-		artworkAttributes = append(artworkAttributes, attributes.ArtworkAttribute{
-			ArtworkID:   artworkID,
-			AttributeID: attrID,
-			Value:       attr.Value,
-		})
+// resolveAttributes validates the submitted attribute values for the artwork type
+func resolveAttributes(db *gorm.DB, artworkType string, attrs []AttributeInput) ([]attributes.ArtworkAttribute, error) {
+	inputs := make([]attributes.ValueInput, len(attrs))
+	for i, attr := range attrs {
+		inputs[i] = attributes.ValueInput{AttributeID: attr.ID, Value: attr.Value}
 	}
-	if len(artworkAttributes) > 0 {
-		return tx.Create(&artworkAttributes).Error
+
+	rows, err := attributes.ResolveValues(db, artworkType, inputs)
+	var invalid *attributes.ValidationError
+	if errors.As(err, &invalid) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, invalid.Error())
 	}
-	return nil
+	return rows, err
+}
+
+func processAttributes(ctx context.Context, tx *gorm.DB, artworkID uuid.UUID, rows []attributes.ArtworkAttribute) error {
+	for i := range rows {
+		rows[i].ArtworkID = artworkID
+	}
+	return tx.Create(&rows).Error
 }
 
 func processImages(ctx context.Context, tx *gorm.DB, cld *config.CloudinaryClient, artworkID uuid.UUID, files []*multipart.FileHeader) error {
@@ -551,17 +842,15 @@ func parseCollectionID(ctx context.Context, tx *gorm.DB, collectionIDStr string,
 		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid collection ID format")
 	}
 
-	var count int64
-	if err := tx.Model(&collection.Collection{}).
-		Where("id = ? AND user_id = ?", collectionID, userID).
-		Count(&count).Error; err != nil {
+	// Editors of a shared collection can add new work to it
+	_, _, err = collection.Authorize(tx, collectionID, userID, collection.RoleEditor)
+	switch {
+	case errors.Is(err, collection.ErrCollectionNotFound), errors.Is(err, collection.ErrInsufficientRole):
+		return nil, fiber.NewError(fiber.StatusForbidden, "You cannot add artworks to this collection")
+	case err != nil:
 		return nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to verify collection")
 	}
 
-	if count == 0 {
-		return nil, fiber.NewError(fiber.StatusForbidden, "Collection does not belong to you")
-	}
-
 	return &collectionID, nil
 }
 
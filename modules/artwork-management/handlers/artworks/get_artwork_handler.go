@@ -0,0 +1,280 @@
+package artworks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	analytics "github.com/muga20/artsMarket/modules/artwork-management/models/analytics"
+	models "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	collection "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
+	"github.com/muga20/artsMarket/modules/artwork-management/repository"
+	organizations "github.com/muga20/artsMarket/modules/organizations/models"
+	"github.com/muga20/artsMarket/modules/trending/ranking"
+	user_details "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/privacy"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+// EditionSummary describes one edition of an artwork
+type EditionSummary struct {
+	ID            uuid.UUID `json:"id"`
+	EditionNumber int       `json:"edition_number"`
+	TotalEditions int       `json:"total_editions"`
+	Status        string    `json:"status"`
+}
+
+// CollectionRef is a published collection an artwork appears in
+type CollectionRef struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Slug string    `json:"slug"`
+}
+
+// OrganizationRef is the organization listing an artwork for its artist
+type OrganizationRef struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Slug string    `json:"slug"`
+}
+
+// ProceedsSplit is how a sale at the listed price is shared between the
+// listing organization and the artist
+type ProceedsSplit struct {
+	CommissionRate    float64 `json:"commission_rate"`
+	OrganizationShare float64 `json:"organization_share"`
+	ArtistShare       float64 `json:"artist_share"`
+}
+
+// ArtworkDetail is the public representation of an artwork and its owner
+type ArtworkDetail struct {
+	ID             uuid.UUID             `json:"id"`
+	Title          string                `json:"title"`
+	Slug           string                `json:"slug"`
+	Description    string                `json:"description"`
+	CreationDate   *time.Time            `json:"creation_date"`
+	Price          *float64              `json:"price"`
+	IsForSale      bool                  `json:"is_for_sale"`
+	Status         models.ArtworkStatus  `json:"status"`
+	Type           models.ArtworkType    `json:"type"`
+	Dimensions     string                `json:"dimensions"`
+	Height         *float64              `json:"height"`
+	Width          *float64              `json:"width"`
+	Depth          *float64              `json:"depth"`
+	DimensionUnit  models.LengthUnit     `json:"dimension_unit"`
+	SizeClass      *models.SizeClass     `json:"size_class"`
+	Weight         *float64              `json:"weight"`
+	WeightUnit     models.WeightUnit     `json:"weight_unit"`
+	IsFramed       bool                  `json:"is_framed"`
+	Condition      models.ConditionType  `json:"condition"`
+	Collections    []CollectionRef       `json:"collections"`
+	MediumID       *uuid.UUID            `json:"medium_id"`
+	TechniqueID    *uuid.UUID            `json:"technique_id"`
+	LicenseType    models.LicenseType    `json:"license_type"`
+	LicenseDetails string                `json:"license_details"`
+	ViewCount      int                   `json:"view_count"`
+	Images         []models.ArtworkImage `json:"images"`
+	Editions       []EditionSummary      `json:"editions"`
+	Owner          privacy.UserView      `json:"owner"`
+	Organization   *OrganizationRef      `json:"organization,omitempty"`
+	Proceeds       *ProceedsSplit        `json:"proceeds,omitempty"` // Only shown to the artist and the organization's members
+	CreatedAt      time.Time             `json:"created_at"`
+	UpdatedAt      time.Time             `json:"updated_at"`
+}
+
+// GetArtworkHandler godoc
+// @Summary Get an artwork
+// @Description Retrieves an artwork by ID or slug. Approved artworks are visible to everyone; others only to their owner and the members of the organization listing it, who also see how proceeds are split. Artworks of private accounts are visible to approved followers only. Owner contact details follow the owner's privacy settings.
+// @Tags Artworks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param identifier path string true "Artwork ID or slug"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /artworks/{identifier} [get]
+func GetArtworkHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler, artworkRepo repository.ArtworkRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		identifier := c.Params("identifier")
+
+		var artwork *models.Artwork
+		var err error
+		if id, parseErr := uuid.Parse(identifier); parseErr == nil {
+			artwork, err = artworkRepo.GetByID(id)
+		} else {
+			artwork, err = artworkRepo.GetBySlug(identifier)
+		}
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusNotFound, "Artwork not found"))
+			}
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve artwork"))
+		}
+
+		viewer, _ := c.Locals("user").(user_details.User)
+		isOwner := viewer.ID == artwork.UserID
+		// Members of the listing organization manage the artwork with the artist
+		if !isOwner && artwork.OrganizationID != nil && viewer.ID != uuid.Nil {
+			role, err := organizations.RoleOf(db, *artwork.OrganizationID, viewer.ID)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to check organization membership"))
+			}
+			isOwner = role != ""
+		}
+		// Sold artworks kept after their seller's account was purged load
+		// without a user and stay visible with an anonymous owner
+		ownerDeactivated := artwork.User.ID != uuid.Nil && !artwork.User.IsActive
+		if !isOwner && (artwork.Status != models.ApprovedStatus || ownerDeactivated) {
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusNotFound, "Artwork not found"))
+		}
+
+		var ownerDetail user_details.UserDetail
+		if err := db.Where("user_id = ?", artwork.UserID).First(&ownerDetail).Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve owner details"))
+		}
+
+		owner := privacy.UserView{
+			ID:           artwork.UserID,
+			Username:     artwork.User.Username,
+			FirstName:    ownerDetail.FirstName,
+			LastName:     ownerDetail.LastName,
+			ProfileImage: ownerDetail.ProfileImage,
+		}
+		policy, err := privacy.ForRequest(c, db)
+		if err == nil {
+			err = policy.Load(owner.ID)
+		}
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to apply privacy settings"))
+		}
+		// Private accounts share their artworks with approved followers only
+		if !policy.CanSeeContent(owner.ID) {
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusForbidden, "This account is private"))
+		}
+		policy.Apply(&owner)
+
+		if !isOwner {
+			recordView(c, db, artwork.ID, viewer.ID)
+		}
+
+		editions := make([]EditionSummary, 0, len(artwork.Editions))
+		for _, edition := range artwork.Editions {
+			editions = append(editions, EditionSummary{
+				ID:            edition.ID,
+				EditionNumber: edition.EditionNumber,
+				TotalEditions: edition.TotalEditions,
+				Status:        edition.Status,
+			})
+		}
+
+		collections := []CollectionRef{}
+		if err := db.Table("collection_entries").
+			Select("collections.id, collections.name, collections.slug").
+			Joins("JOIN collections ON collections.id = collection_entries.collection_id").
+			Joins("JOIN users ON users.id = collections.user_id").
+			Where("collection_entries.artwork_id = ? AND collections.status = ? AND users.is_active = ?",
+				artwork.ID, collection.PublishedStatus, true).
+			Order("collections.name ASC").
+			Scan(&collections).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve collections"))
+		}
+
+		var organization *OrganizationRef
+		if artwork.Organization != nil {
+			organization = &OrganizationRef{
+				ID:   artwork.Organization.ID,
+				Name: artwork.Organization.Name,
+				Slug: artwork.Organization.Slug,
+			}
+		}
+		var proceeds *ProceedsSplit
+		if organizationShare, artistShare, ok := artwork.Proceeds(); ok && isOwner {
+			proceeds = &ProceedsSplit{
+				CommissionRate:    *artwork.CommissionRate,
+				OrganizationShare: organizationShare,
+				ArtistShare:       artistShare,
+			}
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"artwork": ArtworkDetail{
+				ID:             artwork.ID,
+				Title:          artwork.Title,
+				Slug:           artwork.Slug,
+				Description:    artwork.Description,
+				CreationDate:   artwork.CreationDate,
+				Price:          artwork.Price,
+				IsForSale:      artwork.IsForSale,
+				Status:         artwork.Status,
+				Type:           artwork.Type,
+				Dimensions:     artwork.Dimensions,
+				Height:         artwork.Height,
+				Width:          artwork.Width,
+				Depth:          artwork.Depth,
+				DimensionUnit:  artwork.DimensionUnit,
+				SizeClass:      artwork.SizeClass,
+				Weight:         artwork.Weight,
+				WeightUnit:     artwork.WeightUnit,
+				IsFramed:       artwork.IsFramed,
+				Condition:      artwork.Condition,
+				Collections:    collections,
+				MediumID:       artwork.MediumID,
+				TechniqueID:    artwork.TechniqueID,
+				LicenseType:    artwork.LicenseType,
+				LicenseDetails: artwork.LicenseDetails,
+				ViewCount:      artwork.ViewCount,
+				Images:         artwork.Images,
+				Editions:       editions,
+				Owner:          owner,
+				Organization:   organization,
+				Proceeds:       proceeds,
+				CreatedAt:      artwork.CreatedAt,
+				UpdatedAt:      artwork.UpdatedAt,
+			},
+		}, nil)
+	}
+}
+
+// viewDedupWindow is how long repeated views of an artwork by the same user
+// count as one
+const viewDedupWindow = 30 * time.Minute
+
+// recordView stores a view of the artwork for statistics and recommendations.
+// Failures are logged and never fail the request.
+func recordView(c *fiber.Ctx, db *gorm.DB, artworkID, viewerID uuid.UUID) {
+	key := fmt.Sprintf("artwork:viewed:%s:%s", artworkID, viewerID)
+	first, err := config.Redis().SetNX(c.Context(), key, 1, viewDedupWindow).Result()
+	if err != nil {
+		log.Printf("Failed to check recent views of artwork %s: %v", artworkID, err)
+	}
+	if err == nil && !first {
+		return
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&analytics.ArtworkView{
+			ArtworkID:   artworkID,
+			UserID:      viewerID,
+			IPAddress:   c.IP(),
+			UserAgent:   c.Get(fiber.HeaderUserAgent),
+			ReferrerURL: c.Get(fiber.HeaderReferer),
+			ViewedAt:    time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Artwork{}).Where("id = ?", artworkID).
+			UpdateColumn("view_count", gorm.Expr("view_count + 1")).Error
+	})
+	if err != nil {
+		log.Printf("Failed to record view of artwork %s: %v", artworkID, err)
+		return
+	}
+	go ranking.RecordArtworkEvent(context.Background(), db, artworkID, ranking.EventView)
+}
@@ -0,0 +1,371 @@
+package artworks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	models "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	attributes "github.com/muga20/artsMarket/modules/artwork-management/models/arttributes"
+	category "github.com/muga20/artsMarket/modules/artwork-management/models/category"
+	user_details "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 50
+	// attributeFilterPrefix marks query parameters that filter on an attribute, as in attr.height=50..100
+	attributeFilterPrefix = "attr."
+)
+
+// attributeFilter restricts results to artworks with matching values of one attribute
+type attributeFilter struct {
+	attribute attributes.Attribute
+	values    []string
+	min, max  *float64
+}
+
+// Facet summarizes the values of one attribute among the matching artworks
+type Facet struct {
+	AttributeID uuid.UUID      `json:"attribute_id"`
+	Name        string         `json:"name"`
+	Slug        string         `json:"slug"`
+	Type        string         `json:"type"`
+	Unit        string         `json:"unit,omitempty"`
+	Values      map[string]int `json:"values,omitempty"` // Artwork count per value, for enum and boolean attributes
+	Min         *float64       `json:"min,omitempty"`    // Lowest value, for integer and float attributes
+	Max         *float64       `json:"max,omitempty"`    // Highest value, for integer and float attributes
+}
+
+// parseAttributeFilter reads a filter value: a comma separated list of values,
+// or for numeric attributes a number or a min..max range with either end open
+func parseAttributeFilter(attribute attributes.Attribute, raw string) (attributeFilter, error) {
+	filter := attributeFilter{attribute: attribute}
+	if !attribute.IsNumeric() {
+		for _, value := range strings.Split(raw, ",") {
+			normalized, _, err := attribute.Normalize(value)
+			if err != nil {
+				return filter, err
+			}
+			filter.values = append(filter.values, normalized)
+		}
+		return filter, nil
+	}
+
+	low, high, isRange := strings.Cut(raw, "..")
+	if !isRange {
+		high = low
+	}
+	parse := func(s string) (*float64, error) {
+		if s = strings.TrimSpace(s); s == "" && isRange {
+			return nil, nil
+		}
+		number, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s filter must be a number or a min..max range", attribute.Slug)
+		}
+		return &number, nil
+	}
+	var err error
+	if filter.min, err = parse(low); err != nil {
+		return filter, err
+	}
+	if filter.max, err = parse(high); err != nil {
+		return filter, err
+	}
+	if filter.min == nil && filter.max == nil {
+		return filter, fmt.Errorf("%s filter needs a minimum or a maximum", attribute.Slug)
+	}
+	return filter, nil
+}
+
+// apply restricts the query to artworks matching the filter
+func (f attributeFilter) apply(db, query *gorm.DB) *gorm.DB {
+	values := db.Model(&attributes.ArtworkAttribute{}).
+		Select("artwork_id").
+		Where("attribute_id = ?", f.attribute.ID)
+	if f.values != nil {
+		values = values.Where("value IN ?", f.values)
+	}
+	if f.min != nil {
+		values = values.Where("numeric_value >= ?", *f.min)
+	}
+	if f.max != nil {
+		values = values.Where("numeric_value <= ?", *f.max)
+	}
+	return query.Where("artworks.id IN (?)", values)
+}
+
+// SearchArtworksHandler godoc
+// @Summary Search artworks
+// @Description Searches approved artworks the viewer may browse, newest first. Size filters use the longest side of artworks with structured dimensions. Attributes filter with attr.<slug> parameters: a comma separated list of values, or for numeric attributes a number or a min..max range in the attribute's unit, such as attr.height=50..100. The first page also returns facets summarizing the attribute values of all matching artworks.
+// @Tags Artworks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param q query string false "Text to find in the title"
+// @Param type query string false "Artwork type"
+// @Param category query string false "Category ID or slug, including its subcategories"
+// @Param price_min query number false "Lowest price"
+// @Param price_max query number false "Highest price"
+// @Param max_dimension query number false "Longest side at most this long"
+// @Param dimension_unit query string false "Unit of max_dimension (default cm)" Enums(cm,in)
+// @Param size query string false "Comma separated size classes" Enums(small,medium,large,oversized)
+// @Param verified_artist query bool false "Only artworks by verified artists"
+// @Param attr.slug query string false "Attribute filter, where slug is the attribute's slug"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 50)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /artworks/search [get]
+func SearchArtworksHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		viewer, _ := c.Locals("user").(user_details.User)
+
+		limit := c.QueryInt("limit", defaultSearchPageSize)
+		if limit < 1 || limit > maxSearchPageSize {
+			limit = defaultSearchPageSize
+		}
+
+		var definitions []attributes.Attribute
+		if err := db.Order("attribute_name").Find(&definitions).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to load attributes: %w", err))
+		}
+		bySlug := make(map[string]attributes.Attribute, len(definitions))
+		for _, attribute := range definitions {
+			bySlug[attribute.Slug] = attribute
+		}
+
+		var filters []attributeFilter
+		var filterErr error
+		c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+			name, ok := strings.CutPrefix(string(key), attributeFilterPrefix)
+			if !ok || filterErr != nil {
+				return
+			}
+			attribute, ok := bySlug[name]
+			if !ok {
+				filterErr = fmt.Errorf("unknown attribute %q", name)
+				return
+			}
+			filter, err := parseAttributeFilter(attribute, string(value))
+			if err != nil {
+				filterErr = err
+				return
+			}
+			filters = append(filters, filter)
+		})
+		if filterErr != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, filterErr.Error()))
+		}
+
+		var subtree *category.Category
+		if identifier := c.Query("category"); identifier != "" {
+			query := db.Model(&category.Category{})
+			if _, err := uuid.Parse(identifier); err == nil {
+				query = query.Where("id = ?", identifier)
+			} else {
+				query = query.Where("slug = ?", identifier)
+			}
+			var found category.Category
+			if err := query.First(&found).Error; err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Category not found"))
+			}
+			subtree = &found
+		}
+
+		var priceMin, priceMax *float64
+		for param, bound := range map[string]**float64{"price_min": &priceMin, "price_max": &priceMax} {
+			if raw := c.Query(param); raw != "" {
+				number, err := strconv.ParseFloat(raw, 64)
+				if err != nil {
+					return responseHandler.HandleResponse(c, nil,
+						fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Invalid %s", param)))
+				}
+				*bound = &number
+			}
+		}
+
+		var maxDimensionCm *float64
+		if raw := c.Query("max_dimension"); raw != "" {
+			value, err := strconv.ParseFloat(raw, 64)
+			unit := models.LengthUnit(c.Query("dimension_unit", string(models.Centimeters)))
+			if err != nil || value <= 0 || !unit.Valid() {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid max_dimension. Give a positive number in cm or in"))
+			}
+			cm := models.ToCentimeters(value, unit)
+			maxDimensionCm = &cm
+		}
+		var sizes []models.SizeClass
+		if raw := c.Query("size"); raw != "" {
+			for _, size := range strings.Split(raw, ",") {
+				switch class := models.SizeClass(strings.TrimSpace(size)); class {
+				case models.SmallSize, models.MediumSize, models.LargeSize, models.OversizedSize:
+					sizes = append(sizes, class)
+				default:
+					return responseHandler.HandleResponse(c, nil,
+						fiber.NewError(fiber.StatusBadRequest, "Invalid size. Must be small, medium, large, or oversized"))
+				}
+			}
+		}
+
+		verifiedOnly := c.QueryBool("verified_artist")
+
+		// matching builds a fresh query each time since gorm chains mutate in place
+		matching := func() *gorm.DB {
+			query := models.VisibleTo(db, viewer.ID)
+			if q := strings.TrimSpace(c.Query("q")); q != "" {
+				query = query.Where("artworks.title LIKE ?", "%"+q+"%")
+			}
+			if artworkType := c.Query("type"); artworkType != "" {
+				query = query.Where("artworks.type = ?", artworkType)
+			}
+			if subtree != nil {
+				query = query.Where("artworks.id IN (?)", db.Model(&category.ArtworkCategory{}).
+					Select("artwork_id").
+					Where("category_id IN (?)", category.Subtree(db, subtree).Select("id")))
+			}
+			if priceMin != nil {
+				query = query.Where("artworks.price >= ?", *priceMin)
+			}
+			if priceMax != nil {
+				query = query.Where("artworks.price <= ?", *priceMax)
+			}
+			// Artworks without structured dimensions have no longest side and are left out
+			if maxDimensionCm != nil {
+				query = query.Where("artworks.max_dimension_cm <= ?", *maxDimensionCm)
+			}
+			if sizes != nil {
+				query = query.Where("artworks.size_class IN ?", sizes)
+			}
+			if verifiedOnly {
+				query = query.Where("artworks.user_id IN (?)", user_details.VerifiedArtistIDs(db))
+			}
+			for _, filter := range filters {
+				query = filter.apply(db, query)
+			}
+			return query
+		}
+
+		query := matching().Preload("Images")
+		cursor := c.Query("cursor")
+		if cursor != "" {
+			at, id, err := utils.DecodeCursor(cursor)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid cursor"))
+			}
+			query = query.Where("artworks.created_at < ? OR (artworks.created_at = ? AND artworks.id < ?)", at, at, id)
+		}
+
+		var artworks []models.Artwork
+		if err := query.
+			Order("artworks.created_at DESC, artworks.id DESC").
+			Limit(limit + 1).
+			Find(&artworks).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to search artworks: %w", err))
+		}
+
+		nextCursor := ""
+		if len(artworks) > limit {
+			artworks = artworks[:limit]
+			last := artworks[limit-1]
+			nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+		}
+
+		response := fiber.Map{
+			"artworks":    artworks,
+			"next_cursor": nextCursor,
+		}
+		// Facets describe the whole result set, so they only come with the first page
+		if cursor == "" {
+			facets, err := searchFacets(db, definitions, matching().Select("artworks.id"))
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+			response["facets"] = facets
+		}
+		return responseHandler.HandleResponse(c, response, nil)
+	}
+}
+
+// searchFacets counts the values of enum and boolean attributes and finds the
+// range of numeric ones among the matching artworks
+func searchFacets(db *gorm.DB, definitions []attributes.Attribute, matchingIDs *gorm.DB) ([]Facet, error) {
+	var counts []struct {
+		AttributeID uuid.UUID
+		Value       string
+		Count       int
+	}
+	if err := db.Model(&attributes.ArtworkAttribute{}).
+		Select("artwork_attributes.attribute_id, artwork_attributes.value, COUNT(*) AS count").
+		Joins("JOIN attributes ON attributes.id = artwork_attributes.attribute_id").
+		Where("attributes.type IN ?", []string{attributes.TypeEnum, attributes.TypeBoolean}).
+		Where("artwork_attributes.artwork_id IN (?)", matchingIDs).
+		Group("artwork_attributes.attribute_id, artwork_attributes.value").
+		Scan(&counts).Error; err != nil {
+		return nil, fmt.Errorf("failed to count attribute values: %w", err)
+	}
+
+	var ranges []struct {
+		AttributeID uuid.UUID
+		Min         float64
+		Max         float64
+	}
+	if err := db.Model(&attributes.ArtworkAttribute{}).
+		Select("attribute_id, MIN(numeric_value) AS min, MAX(numeric_value) AS max").
+		Where("numeric_value IS NOT NULL").
+		Where("artwork_id IN (?)", matchingIDs).
+		Group("attribute_id").
+		Scan(&ranges).Error; err != nil {
+		return nil, fmt.Errorf("failed to find attribute ranges: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*Facet, len(definitions))
+	facets := make([]*Facet, 0, len(definitions))
+	for _, attribute := range definitions {
+		facet := &Facet{
+			AttributeID: attribute.ID,
+			Name:        attribute.AttributeName,
+			Slug:        attribute.Slug,
+			Type:        attribute.Type,
+			Unit:        attribute.Unit,
+		}
+		byID[attribute.ID] = facet
+		facets = append(facets, facet)
+	}
+	for _, row := range counts {
+		if facet := byID[row.AttributeID]; facet != nil {
+			if facet.Values == nil {
+				facet.Values = make(map[string]int)
+			}
+			facet.Values[row.Value] = row.Count
+		}
+	}
+	for _, row := range ranges {
+		if facet := byID[row.AttributeID]; facet != nil {
+			low, high := row.Min, row.Max
+			facet.Min, facet.Max = &low, &high
+		}
+	}
+
+	// Attributes no matching artwork sets are left out
+	result := make([]Facet, 0, len(facets))
+	for _, facet := range facets {
+		if facet.Values != nil || facet.Min != nil {
+			result = append(result, *facet)
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,351 @@
+package artworks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	models "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	attributes "github.com/muga20/artsMarket/modules/artwork-management/models/arttributes"
+	feed "github.com/muga20/artsMarket/modules/feed/models"
+	"github.com/muga20/artsMarket/modules/feed/timeline"
+	notifications "github.com/muga20/artsMarket/modules/notifications/services"
+	user_details "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+// UpdatePriceRequest is the new price and sale state of an artwork
+type UpdatePriceRequest struct {
+	Price     *float64 `json:"price"`
+	IsForSale *bool    `json:"is_for_sale"`
+}
+
+// UpdateAttributesRequest is the full set of attribute values of an artwork
+type UpdateAttributesRequest struct {
+	Attributes []attributes.ValueInput `json:"attributes"`
+}
+
+// UpdateDimensionsRequest is the size and weight of an artwork
+type UpdateDimensionsRequest struct {
+	Height     float64  `json:"height"`
+	Width      float64  `json:"width"`
+	Depth      *float64 `json:"depth"`
+	Unit       string   `json:"unit"`  // cm or in, default cm
+	Label      *string  `json:"label"` // Free-text dimensions shown with the artwork; generated when empty
+	Weight     *float64 `json:"weight"`
+	WeightUnit string   `json:"weight_unit"` // kg or lb, default kg
+}
+
+// UpdateArtworkStatusHandler godoc
+// @Summary Moderate an artwork
+// @Description Approves or rejects an artwork (admin only). Approved artworks become public and appear in the feeds of the owner's followers. The owner is notified.
+// @Tags Artworks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Artwork ID"
+// @Param status path string true "New status (approve|reject)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /artworks/{id}/status/{status} [put]
+func UpdateArtworkStatusHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var newStatus models.ArtworkStatus
+		switch strings.ToLower(c.Params("status")) {
+		case "approve":
+			newStatus = models.ApprovedStatus
+		case "reject":
+			newStatus = models.RejectedStatus
+		default:
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid status. Must be approve or reject"))
+		}
+
+		var artwork models.Artwork
+		if err := db.Where("id = ?", c.Params("id")).First(&artwork).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusNotFound, "Artwork not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve artwork: %w", err))
+		}
+		if artwork.Status == newStatus {
+			return responseHandler.HandleResponse(c, fiber.Map{
+				"message": "Artwork status unchanged",
+				"status":  artwork.Status,
+			}, nil)
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&artwork).Updates(map[string]interface{}{
+				"status":     newStatus,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update artwork status: %w", err)
+			}
+
+			message := fmt.Sprintf("Your artwork \"%s\" was rejected", artwork.Title)
+			if newStatus == models.ApprovedStatus {
+				message = fmt.Sprintf("Your artwork \"%s\" was approved", artwork.Title)
+				// Followers see newly approved artworks in their feed
+				if err := timeline.Record(tx, artwork.UserID, feed.ActivityArtworkPublished, feed.EntityArtwork, artwork.ID, nil); err != nil {
+					return err
+				}
+			}
+			return notifications.WriteNotification(tx, artwork.UserID, "artwork_"+string(newStatus), message, "artwork", artwork.ID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Artwork status updated successfully",
+			"status":  newStatus,
+		}, nil)
+	}
+}
+
+// UpdateArtworkPriceHandler godoc
+// @Summary Update an artwork's price
+// @Description Changes the price and sale state of one of the authenticated user's artworks, or of an artwork their organization lists for a represented artist. A lower price on an approved artwork that is for sale appears as a price drop in the feeds of the owner's followers.
+// @Tags Artworks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Artwork ID"
+// @Param request body UpdatePriceRequest true "New price and sale state"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /artworks/{id}/price [put]
+func UpdateArtworkPriceHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(user_details.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req UpdatePriceRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		if req.Price == nil && req.IsForSale == nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Price or is_for_sale is required"))
+		}
+		if req.Price != nil && *req.Price < 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Price cannot be negative"))
+		}
+
+		var artwork models.Artwork
+		if err := models.EditableBy(db, user.ID).Where("artworks.id = ?", c.Params("id")).First(&artwork).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Artwork not found or you don't have permission"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve artwork: %w", err))
+		}
+
+		oldPrice := artwork.Price
+		updates := map[string]interface{}{"updated_at": time.Now()}
+		if req.Price != nil {
+			updates["price"] = *req.Price
+			artwork.Price = req.Price
+		}
+		if req.IsForSale != nil {
+			updates["is_for_sale"] = *req.IsForSale
+			artwork.IsForSale = *req.IsForSale
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(&models.Artwork{}).Where("id = ?", artwork.ID).Updates(updates).Error; err != nil {
+				return fmt.Errorf("failed to update artwork price: %w", err)
+			}
+
+			dropped := oldPrice != nil && artwork.Price != nil && *artwork.Price < *oldPrice
+			if dropped && artwork.IsForSale && artwork.Status == models.ApprovedStatus {
+				return timeline.Record(tx, artwork.UserID, feed.ActivityPriceDrop, feed.EntityArtwork, artwork.ID, feed.PriceDrop{
+					OldPrice: *oldPrice,
+					NewPrice: *artwork.Price,
+				})
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":     "Artwork price updated successfully",
+			"price":       artwork.Price,
+			"is_for_sale": artwork.IsForSale,
+		}, nil)
+	}
+}
+
+// UpdateArtworkAttributesHandler godoc
+// @Summary Replace an artwork's attributes
+// @Description Replaces all attribute values of one of the authenticated user's artworks, or of an artwork their organization lists for a represented artist. Values are checked against each attribute's type, allowed values and bounds, and every attribute required for the artwork's type must be set.
+// @Tags Artworks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Artwork ID"
+// @Param request body UpdateAttributesRequest true "Attribute values"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /artworks/{id}/attributes [put]
+func UpdateArtworkAttributesHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(user_details.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req UpdateAttributesRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+
+		var artwork models.Artwork
+		if err := models.EditableBy(db, user.ID).Where("artworks.id = ?", c.Params("id")).First(&artwork).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Artwork not found or you don't have permission"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve artwork: %w", err))
+		}
+
+		rows, err := attributes.ResolveValues(db, string(artwork.Type), req.Attributes)
+		if err != nil {
+			var invalid *attributes.ValidationError
+			if errors.As(err, &invalid) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, invalid.Error()))
+			}
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			if err := attributes.ReplaceValues(tx, artwork.ID, rows); err != nil {
+				return err
+			}
+			return tx.Model(&models.Artwork{}).Where("id = ?", artwork.ID).Update("updated_at", time.Now()).Error
+		}); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":    "Artwork attributes updated successfully",
+			"attributes": rows,
+		}, nil)
+	}
+}
+
+// UpdateArtworkDimensionsHandler godoc
+// @Summary Update an artwork's dimensions
+// @Description Sets the height, width and optional depth in cm or in of one of the authenticated user's artworks, or of an artwork their organization lists for a represented artist, and optionally its weight in kg or lb. The longest side decides the artwork's size class (small, medium, large or oversized).
+// @Tags Artworks
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Artwork ID"
+// @Param request body UpdateDimensionsRequest true "Dimensions and weight"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /artworks/{id}/dimensions [put]
+func UpdateArtworkDimensionsHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(user_details.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req UpdateDimensionsRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+
+		measurements := models.Measurements{Height: req.Height, Width: req.Width, Depth: req.Depth, Unit: models.LengthUnit(req.Unit)}
+		if measurements.Unit == "" {
+			measurements.Unit = models.Centimeters
+		}
+		if err := measurements.Validate(); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		}
+		weightUnit := models.WeightUnit(req.WeightUnit)
+		if weightUnit == "" {
+			weightUnit = models.Kilograms
+		}
+		if !weightUnit.Valid() {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Weight unit must be kg or lb"))
+		}
+		if req.Weight != nil && *req.Weight < 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Weight cannot be negative"))
+		}
+
+		var artwork models.Artwork
+		if err := models.EditableBy(db, user.ID).Where("artworks.id = ?", c.Params("id")).First(&artwork).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Artwork not found or you don't have permission"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve artwork: %w", err))
+		}
+
+		// The label follows the new measurements unless one is given
+		artwork.Dimensions = ""
+		if req.Label != nil {
+			artwork.Dimensions = strings.TrimSpace(*req.Label)
+		}
+		artwork.SetMeasurements(measurements)
+
+		updates := artwork.MeasurementColumns()
+		updates["updated_at"] = time.Now()
+		if req.Weight != nil {
+			updates["weight"] = *req.Weight
+			updates["weight_unit"] = weightUnit
+			artwork.Weight, artwork.WeightUnit = req.Weight, weightUnit
+		}
+		if err := db.Model(&models.Artwork{}).Where("id = ?", artwork.ID).Updates(updates).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to update artwork dimensions: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":          "Artwork dimensions updated successfully",
+			"dimensions":       artwork.Dimensions,
+			"height":           artwork.Height,
+			"width":            artwork.Width,
+			"depth":            artwork.Depth,
+			"dimension_unit":   artwork.DimensionUnit,
+			"max_dimension_cm": artwork.MaxDimensionCm,
+			"size_class":       artwork.SizeClass,
+			"weight":           artwork.Weight,
+			"weight_unit":      artwork.WeightUnit,
+		}, nil)
+	}
+}
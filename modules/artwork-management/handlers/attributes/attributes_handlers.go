@@ -7,6 +7,8 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/gosimple/slug"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
 	models "github.com/muga20/artsMarket/modules/artwork-management/models/arttributes"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"gorm.io/gorm"
@@ -14,19 +16,50 @@ import (
 
 // CreateAttributeRequest represents the request body for creating an attribute
 type CreateAttributeRequest struct {
-	AttributeName string `json:"attribute_name" validate:"required"`
-	Type          string `json:"type" validate:"required,oneof=string integer float boolean"`
+	AttributeName string   `json:"attribute_name" validate:"required"`
+	Type          string   `json:"type" validate:"required,oneof=string integer float boolean enum"`
+	Unit          string   `json:"unit" validate:"max=32"`
+	AllowedValues []string `json:"allowed_values"`
+	MinValue      *float64 `json:"min_value"`
+	MaxValue      *float64 `json:"max_value"`
+	RequiredFor   []string `json:"required_for"` // Artwork types that must set the attribute
 }
 
-// UpdateAttributeRequest represents the request body for updating an attribute
+// UpdateAttributeRequest represents the request body for updating an attribute.
+// Omitted fields keep their current value.
 type UpdateAttributeRequest struct {
-	AttributeName string `json:"attribute_name"`
-	Type          string `json:"type" validate:"omitempty,oneof=string integer float boolean"`
+	AttributeName string    `json:"attribute_name"`
+	Type          string    `json:"type" validate:"omitempty,oneof=string integer float boolean enum"`
+	Unit          *string   `json:"unit" validate:"omitempty,max=32"`
+	AllowedValues *[]string `json:"allowed_values"`
+	MinValue      *float64  `json:"min_value"`
+	MaxValue      *float64  `json:"max_value"`
+	ClearBounds   bool      `json:"clear_bounds"` // Removes min_value and max_value
+	RequiredFor   *[]string `json:"required_for"`
+}
+
+// checkDefinition validates an attribute's settings before it is saved
+func checkDefinition(attribute *models.Attribute) error {
+	if len(attribute.Unit) > 32 {
+		return fiber.NewError(fiber.StatusBadRequest, "Unit cannot be longer than 32 characters")
+	}
+	if err := attribute.CheckDefinition(); err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	for _, artworkType := range attribute.RequiredFor {
+		switch artwork.ArtworkType(artworkType) {
+		case artwork.TraditionalType, artwork.DigitalType, artwork.PhotographyType,
+			artwork.MixedMediaType, artwork.SculptureType, artwork.PerformanceType:
+		default:
+			return fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Invalid artwork type in required_for: %s", artworkType))
+		}
+	}
+	return nil
 }
 
 // CreateAttributeHandler handles creating a new attribute
 // @Summary Create a new attribute
-// @Description Create a new attribute with name and type
+// @Description Create a new attribute with name and type. Numeric attributes can have a unit and bounds, enum attributes list their allowed values, and required_for names the artwork types that must set the attribute.
 // @Tags Attributes
 // @Accept  json
 // @Produce  json
@@ -46,7 +79,7 @@ func CreateAttributeHandler(db *gorm.DB, responseHandler *handlers.ResponseHandl
 
 		// First check if attribute name already exists
 		var existingAttr models.Attribute
-		if err := db.Where("attribute_name = ?", req.AttributeName).First(&existingAttr).Error; err == nil {
+		if err := db.Where("attribute_name = ? OR slug = ?", req.AttributeName, slug.Make(req.AttributeName)).First(&existingAttr).Error; err == nil {
 			return responseHandler.HandleResponse(c, nil,
 				fiber.NewError(fiber.StatusConflict, "Attribute with this name already exists"))
 		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -57,10 +90,22 @@ func CreateAttributeHandler(db *gorm.DB, responseHandler *handlers.ResponseHandl
 		attribute := models.Attribute{
 			ID:            uuid.New(),
 			AttributeName: req.AttributeName,
+			Slug:          slug.Make(req.AttributeName),
 			Type:          req.Type,
+			Unit:          req.Unit,
+			AllowedValues: req.AllowedValues,
+			MinValue:      req.MinValue,
+			MaxValue:      req.MaxValue,
+			RequiredFor:   req.RequiredFor,
 			CreatedAt:     time.Now(),
 			UpdatedAt:     time.Now(),
 		}
+		if attribute.Type == "" {
+			attribute.Type = models.TypeString
+		}
+		if err := checkDefinition(&attribute); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
 
 		if err := db.Create(&attribute).Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
@@ -135,7 +180,7 @@ func GetAttributeByIDHandler(db *gorm.DB, responseHandler *handlers.ResponseHand
 
 // UpdateAttributeHandler handles updating an existing attribute
 // @Summary Update an existing attribute
-// @Description Update attribute details (name, type)
+// @Description Update attribute details (name, type, unit, allowed values, bounds, required artwork types). The type of an attribute artworks already use cannot change.
 // @Tags Attributes
 // @Accept  json
 // @Produce  json
@@ -174,7 +219,7 @@ func UpdateAttributeHandler(db *gorm.DB, responseHandler *handlers.ResponseHandl
 		// Check if new name already exists (if being changed)
 		if req.AttributeName != "" && req.AttributeName != attribute.AttributeName {
 			var existingAttr models.Attribute
-			if err := db.Where("attribute_name = ? AND id != ?", req.AttributeName, attributeID).
+			if err := db.Where("(attribute_name = ? OR slug = ?) AND id != ?", req.AttributeName, slug.Make(req.AttributeName), attributeID).
 				First(&existingAttr).Error; err == nil {
 				return responseHandler.HandleResponse(c, nil,
 					fiber.NewError(fiber.StatusConflict, "Attribute with this name already exists"))
@@ -184,13 +229,48 @@ func UpdateAttributeHandler(db *gorm.DB, responseHandler *handlers.ResponseHandl
 			}
 		}
 
+		// Stored values were checked against the current type
+		if req.Type != "" && req.Type != attribute.Type {
+			var used int64
+			if err := db.Model(&models.ArtworkAttribute{}).Where("attribute_id = ?", attribute.ID).Count(&used).Error; err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fmt.Errorf("failed to check attribute usage: %w", err))
+			}
+			if used > 0 {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusConflict, "Cannot change the type of an attribute used in artworks"))
+			}
+		}
+
 		// Update fields if provided
 		if req.AttributeName != "" {
 			attribute.AttributeName = req.AttributeName
+			attribute.Slug = slug.Make(req.AttributeName)
 		}
 		if req.Type != "" {
 			attribute.Type = req.Type
 		}
+		if req.Unit != nil {
+			attribute.Unit = *req.Unit
+		}
+		if req.AllowedValues != nil {
+			attribute.AllowedValues = *req.AllowedValues
+		}
+		if req.ClearBounds {
+			attribute.MinValue, attribute.MaxValue = nil, nil
+		}
+		if req.MinValue != nil {
+			attribute.MinValue = req.MinValue
+		}
+		if req.MaxValue != nil {
+			attribute.MaxValue = req.MaxValue
+		}
+		if req.RequiredFor != nil {
+			attribute.RequiredFor = *req.RequiredFor
+		}
+		if err := checkDefinition(&attribute); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
 		attribute.UpdatedAt = time.Now()
 
 		if err := db.Save(&attribute).Error; err != nil {
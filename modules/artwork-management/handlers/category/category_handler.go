@@ -3,6 +3,7 @@ package category
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -16,6 +17,7 @@ import (
 type CreateCategoryRequest struct {
 	CategoryName string `json:"category_name" validate:"required"`
 	Description  string `json:"description"`
+	ParentID     string `json:"parent_id"` // Optional; the category is a root without it
 }
 
 // UpdateCategoryRequest represents the request body for updating a category
@@ -26,7 +28,7 @@ type UpdateCategoryRequest struct {
 
 // CreateCategoryHandler handles creating a new category
 // @Summary Create a new category
-// @Description Create a new category with name, description, and active status
+// @Description Create a new category with name, description, and active status, optionally under a parent category
 // @Tags Categories
 // @Accept  json
 // @Produce  json
@@ -43,9 +45,19 @@ func CreateCategoryHandler(db *gorm.DB, responseHandler *handlers.ResponseHandle
 				fiber.NewError(fiber.StatusBadRequest, "Invalid request payload"))
 		}
 
-		// First check if category name already exists
+		var parentID *uuid.UUID
+		if req.ParentID != "" {
+			id, err := uuid.Parse(req.ParentID)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid parent category ID"))
+			}
+			parentID = &id
+		}
+
+		// First check if category name already exists under the same parent
 		var existingCategory models.Category
-		if err := db.Where("category_name = ?", req.CategoryName).First(&existingCategory).Error; err == nil {
+		if err := db.Where("category_name = ? AND parent_id <=> ?", req.CategoryName, parentID).First(&existingCategory).Error; err == nil {
 			return responseHandler.HandleResponse(c, nil,
 				fiber.NewError(fiber.StatusConflict, "Category with this name already exists"))
 		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
@@ -59,12 +71,16 @@ func CreateCategoryHandler(db *gorm.DB, responseHandler *handlers.ResponseHandle
 			ID:           uuid.New(),
 			CategoryName: req.CategoryName,
 			Description:  req.Description,
+			ParentID:     parentID,
 			IsActive:     true,
 			CreatedAt:    time.Now(),
 			UpdatedAt:    time.Now(),
 		}
 
 		if err := db.Create(&category).Error; err != nil {
+			if treeErr := treeError(err); treeErr != err {
+				return responseHandler.HandleResponse(c, nil, treeErr)
+			}
 			return responseHandler.HandleResponse(c, nil,
 				fmt.Errorf("failed to create category: %w", err))
 		}
@@ -101,7 +117,7 @@ func GetAllCategoriesHandler(db *gorm.DB, responseHandler *handlers.ResponseHand
 
 // GetCategoryHandler handles fetching a category by either ID or slug
 // @Summary Get category by ID or slug
-// @Description Retrieves a category by its ID or slug
+// @Description Retrieves a category by its ID or slug, with its breadcrumb from the root and its direct subcategories
 // @Tags Categories
 // @Accept json
 // @Produce json
@@ -140,8 +156,20 @@ func GetCategoryHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler)
 				fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve category"))
 		}
 
+		breadcrumb, err := models.Breadcrumb(db, &category)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		var children []models.Category
+		if err := db.Where("parent_id = ?", category.ID).Order("category_name").Find(&children).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve subcategories"))
+		}
+
 		return responseHandler.HandleResponse(c, fiber.Map{
-			"data": category,
+			"data":       category,
+			"breadcrumb": crumbs(breadcrumb),
+			"children":   children,
 		}, nil)
 	}
 }
@@ -258,12 +286,15 @@ func ToggleCategoryActiveHandler(db *gorm.DB, responseHandler *handlers.Response
 
 // DeleteCategoryHandler handles permanently deleting a category
 // @Summary Delete a category
-// @Description Permanently delete a category (use with caution)
+// @Description Permanently delete a category. With children=reparent (the default) its subcategories and artworks move to its parent, or to new_parent_id when given; subcategories of a root become roots and its artworks lose the category. With children=cascade the whole subtree is deleted and its artworks lose those categories.
 // @Tags Categories
 // @Accept  json
 // @Produce  json
 // @Param id path string true "Category ID"
+// @Param children query string false "What happens to subcategories (reparent|cascade)"
+// @Param new_parent_id query string false "Category to move subcategories and artworks to when reparenting"
 // @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /categories/{id} [delete]
@@ -275,37 +306,98 @@ func DeleteCategoryHandler(db *gorm.DB, responseHandler *handlers.ResponseHandle
 				fiber.NewError(fiber.StatusBadRequest, "Category ID is required"))
 		}
 
-		// First check if category exists
-		var category models.Category
-		if err := db.Where("id = ?", categoryID).First(&category).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
+		strategy := c.Query("children", "reparent")
+		if strategy != "reparent" && strategy != "cascade" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid children option. Must be reparent or cascade"))
+		}
+		newParentID := c.Query("new_parent_id")
+		if newParentID != "" {
+			if _, err := uuid.Parse(newParentID); err != nil {
 				return responseHandler.HandleResponse(c, nil,
-					fiber.NewError(fiber.StatusNotFound, "Category not found"))
+					fiber.NewError(fiber.StatusBadRequest, "Invalid new parent category ID"))
 			}
-			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve category"))
 		}
 
-		// Check if category is used in any artwork before deleting
-		var count int64
-		if err := db.Model(&models.ArtworkCategory{}).Where("category_id = ?", categoryID).Count(&count).Error; err != nil {
-			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusInternalServerError, "Failed to check category usage"))
-		}
+		var removed int64
+		err := db.Transaction(func(tx *gorm.DB) error {
+			category, err := models.LockForMove(tx, categoryID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(fiber.StatusNotFound, "Category not found")
+				}
+				return fmt.Errorf("failed to retrieve category: %w", err)
+			}
 
-		if count > 0 {
-			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusConflict, "Cannot delete category - it's being used in artworks"))
-		}
+			if strategy == "cascade" {
+				subtree, err := models.SubtreeIDs(tx, category)
+				if err != nil {
+					return err
+				}
+				if err := tx.Where("category_id IN ?", subtree).Delete(&models.ArtworkCategory{}).Error; err != nil {
+					return fmt.Errorf("failed to remove category from artworks: %w", err)
+				}
+				result := tx.Where("id IN ?", subtree).Delete(&models.Category{})
+				if result.Error != nil {
+					return fmt.Errorf("failed to delete categories: %w", result.Error)
+				}
+				removed = result.RowsAffected
+				return nil
+			}
 
-		// Perform the delete
-		if err := db.Delete(&category).Error; err != nil {
-			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusInternalServerError, "Failed to delete category"))
+			// Children move to the requested category, or else to the deleted one's parent
+			var parent *models.Category
+			if newParentID == "" && category.ParentID != nil {
+				newParentID = category.ParentID.String()
+			}
+			if newParentID != "" {
+				if parent, err = models.LockForMove(tx, newParentID); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return fiber.NewError(fiber.StatusNotFound, "New parent category not found")
+					}
+					return fmt.Errorf("failed to retrieve new parent category: %w", err)
+				}
+				if strings.HasPrefix(parent.Path, category.Path) {
+					return treeError(models.ErrCategoryCycle)
+				}
+			}
+
+			var children []models.Category
+			if err := tx.Where("parent_id = ?", category.ID).Find(&children).Error; err != nil {
+				return fmt.Errorf("failed to retrieve subcategories: %w", err)
+			}
+			for i := range children {
+				if err := models.Move(tx, &children[i], parent); err != nil {
+					return treeError(err)
+				}
+			}
+
+			// Artworks keep a category by moving to the parent, unless they already have it
+			if parent != nil {
+				if err := tx.Exec(`UPDATE artwork_categories SET category_id = ?, updated_at = ?
+					WHERE category_id = ? AND artwork_id NOT IN (
+						SELECT artwork_id FROM (SELECT artwork_id FROM artwork_categories WHERE category_id = ?) AS already
+					)`, parent.ID, time.Now(), category.ID, parent.ID).Error; err != nil {
+					return fmt.Errorf("failed to move artworks to the parent category: %w", err)
+				}
+			}
+			if err := tx.Where("category_id = ?", category.ID).Delete(&models.ArtworkCategory{}).Error; err != nil {
+				return fmt.Errorf("failed to remove category from artworks: %w", err)
+			}
+
+			if err := tx.Delete(category).Error; err != nil {
+				return fmt.Errorf("failed to delete category: %w", err)
+			}
+			removed = 1
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
 		}
 
 		return responseHandler.HandleResponse(c, fiber.Map{
 			"message": "Category deleted successfully",
+			"deleted": removed,
 		}, nil)
 	}
 }
@@ -0,0 +1,309 @@
+package category
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	models "github.com/muga20/artsMarket/modules/artwork-management/models/category"
+	user_models "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultArtworkPageSize = 20
+	maxArtworkPageSize     = 50
+)
+
+// MoveCategoryRequest names the new parent of a category
+type MoveCategoryRequest struct {
+	ParentID string `json:"parent_id"` // Empty moves the category to the root
+}
+
+// Crumb is one step of a category breadcrumb
+type Crumb struct {
+	ID           uuid.UUID `json:"id"`
+	CategoryName string    `json:"category_name"`
+	Slug         string    `json:"slug"`
+}
+
+// CategoryNode is a category with its subcategories
+type CategoryNode struct {
+	ID           uuid.UUID      `json:"id"`
+	CategoryName string         `json:"category_name"`
+	Slug         string         `json:"slug"`
+	Description  string         `json:"description"`
+	IsActive     bool           `json:"is_active"`
+	Depth        int            `json:"depth"`
+	Children     []CategoryNode `json:"children"`
+}
+
+// treeError turns the errors of tree operations into client errors
+func treeError(err error) error {
+	switch {
+	case errors.Is(err, models.ErrParentNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "Parent category not found")
+	case errors.Is(err, models.ErrCategoryCycle), errors.Is(err, models.ErrCategoryTooDeep):
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return err
+}
+
+func crumbs(categories []models.Category) []Crumb {
+	result := make([]Crumb, len(categories))
+	for i, category := range categories {
+		result[i] = Crumb{ID: category.ID, CategoryName: category.CategoryName, Slug: category.Slug}
+	}
+	return result
+}
+
+// findCategory loads a category by ID or slug
+func findCategory(db *gorm.DB, identifier string) (*models.Category, error) {
+	query := db.Model(&models.Category{})
+	if _, err := uuid.Parse(identifier); err == nil {
+		query = query.Where("id = ?", identifier)
+	} else {
+		query = query.Where("slug = ?", identifier)
+	}
+
+	var category models.Category
+	if err := query.First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Category not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve category: %w", err)
+	}
+	return &category, nil
+}
+
+// buildTree nests the categories under their parents, sorted by name. Categories
+// whose parent is not in the list become roots.
+func buildTree(categories []models.Category) []CategoryNode {
+	present := make(map[uuid.UUID]bool, len(categories))
+	for _, category := range categories {
+		present[category.ID] = true
+	}
+	children := make(map[uuid.UUID][]models.Category)
+	var roots []models.Category
+	for _, category := range categories {
+		if category.ParentID != nil && present[*category.ParentID] {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		} else {
+			roots = append(roots, category)
+		}
+	}
+
+	var build func(level []models.Category) []CategoryNode
+	build = func(level []models.Category) []CategoryNode {
+		nodes := make([]CategoryNode, 0, len(level))
+		for _, category := range level {
+			nodes = append(nodes, CategoryNode{
+				ID:           category.ID,
+				CategoryName: category.CategoryName,
+				Slug:         category.Slug,
+				Description:  category.Description,
+				IsActive:     category.IsActive,
+				Depth:        category.Depth,
+				Children:     build(children[category.ID]),
+			})
+		}
+		return nodes
+	}
+	return build(roots)
+}
+
+// GetCategoryTreeHandler returns the category hierarchy
+// @Summary Get the category tree
+// @Description Returns all categories nested under their parents. With root set, returns only that category's subtree together with its breadcrumb from the top of the hierarchy.
+// @Tags Categories
+// @Produce json
+// @Param root query string false "Category ID or slug to return the subtree of"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /categories/tree [get]
+func GetCategoryTreeHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		query := db.Model(&models.Category{}).Order("category_name")
+		var breadcrumb []Crumb
+
+		if identifier := c.Query("root"); identifier != "" {
+			root, err := findCategory(db, identifier)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+			ancestors, err := models.Breadcrumb(db, root)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+			breadcrumb = crumbs(ancestors)
+			query = models.Subtree(query, root)
+		}
+
+		var categories []models.Category
+		if err := query.Find(&categories).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve categories"))
+		}
+
+		response := fiber.Map{
+			"tree":  buildTree(categories),
+			"count": len(categories),
+		}
+		if breadcrumb != nil {
+			response["breadcrumb"] = breadcrumb
+		}
+		return responseHandler.HandleResponse(c, response, nil)
+	}
+}
+
+// MoveCategoryHandler moves a category and its subcategories under a new parent
+// @Summary Move a category
+// @Description Moves a category, with all of its subcategories, under another category or to the root. A category cannot be moved under itself or its own descendants.
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param request body MoveCategoryRequest true "New parent"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /categories/{id}/move [put]
+func MoveCategoryHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req MoveCategoryRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request payload"))
+		}
+		if req.ParentID != "" {
+			if _, err := uuid.Parse(req.ParentID); err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid parent category ID"))
+			}
+		}
+
+		var moved *models.Category
+		err := db.Transaction(func(tx *gorm.DB) error {
+			category, err := models.LockForMove(tx, c.Params("id"))
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(fiber.StatusNotFound, "Category not found")
+				}
+				return fmt.Errorf("failed to retrieve category: %w", err)
+			}
+
+			var parent *models.Category
+			if req.ParentID != "" {
+				if parent, err = models.LockForMove(tx, req.ParentID); err != nil {
+					if errors.Is(err, gorm.ErrRecordNotFound) {
+						return treeError(models.ErrParentNotFound)
+					}
+					return fmt.Errorf("failed to retrieve parent category: %w", err)
+				}
+			}
+
+			// Sibling names stay unique at the destination
+			var clashes int64
+			if err := tx.Model(&models.Category{}).
+				Where("category_name = ? AND parent_id <=> ? AND id != ?", category.CategoryName, parentIDOf(parent), category.ID).
+				Count(&clashes).Error; err != nil {
+				return fmt.Errorf("failed to check category names: %w", err)
+			}
+			if clashes > 0 {
+				return fiber.NewError(fiber.StatusConflict, "A category with this name already exists under the new parent")
+			}
+
+			if err := models.Move(tx, category, parent); err != nil {
+				return treeError(err)
+			}
+			moved = category
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Category moved successfully",
+			"data":    moved,
+		}, nil)
+	}
+}
+
+func parentIDOf(parent *models.Category) *uuid.UUID {
+	if parent == nil {
+		return nil
+	}
+	return &parent.ID
+}
+
+// GetCategoryArtworksHandler lists the artworks in a category and all of its subcategories
+// @Summary List artworks in a category
+// @Description Lists approved artworks in the category or any of its subcategories, newest first. Artworks of private accounts are included for approved followers only.
+// @Tags Categories
+// @Produce json
+// @Param identifier path string true "Category ID (UUID) or slug"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 50)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /categories/{identifier}/artworks [get]
+func GetCategoryArtworksHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		viewer, _ := c.Locals("user").(user_models.User)
+
+		category, err := findCategory(db, c.Params("identifier"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		limit := c.QueryInt("limit", defaultArtworkPageSize)
+		if limit < 1 || limit > maxArtworkPageSize {
+			limit = defaultArtworkPageSize
+		}
+
+		query := artwork.VisibleTo(db, viewer.ID).
+			Preload("Images").
+			Where("artworks.id IN (?)", db.Model(&models.ArtworkCategory{}).
+				Select("artwork_id").
+				Where("category_id IN (?)", models.Subtree(db, category).Select("id")))
+		if cursor := c.Query("cursor"); cursor != "" {
+			at, id, err := utils.DecodeCursor(cursor)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid cursor"))
+			}
+			query = query.Where("artworks.created_at < ? OR (artworks.created_at = ? AND artworks.id < ?)", at, at, id)
+		}
+
+		var artworks []artwork.Artwork
+		if err := query.
+			Order("artworks.created_at DESC, artworks.id DESC").
+			Limit(limit + 1).
+			Find(&artworks).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to list category artworks: %w", err))
+		}
+
+		nextCursor := ""
+		if len(artworks) > limit {
+			artworks = artworks[:limit]
+			last := artworks[limit-1]
+			nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"category":    category,
+			"artworks":    artworks,
+			"next_cursor": nextCursor,
+		}, nil)
+	}
+}
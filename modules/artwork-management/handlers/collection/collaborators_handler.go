@@ -0,0 +1,487 @@
+package collection
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	collectionModels "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
+	notifications "github.com/muga20/artsMarket/modules/notifications/services"
+	userModels "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InviteCollaboratorRequest invites a user by username or email
+type InviteCollaboratorRequest struct {
+	User string `json:"user" example:"jane_doe"`
+	Role string `json:"role" example:"editor"`
+}
+
+// UpdateCollaboratorRequest changes a collaborator's role
+type UpdateCollaboratorRequest struct {
+	Role string `json:"role" example:"viewer"`
+}
+
+// Collaborator is a collection collaborator with their public profile
+type Collaborator struct {
+	UserID       uuid.UUID  `json:"user_id"`
+	Username     string     `json:"username"`
+	ProfileImage string     `json:"profile_image"`
+	Role         string     `json:"role"`
+	Status       string     `json:"status"`
+	InvitedBy    *uuid.UUID `json:"invited_by,omitempty"`
+	RespondedAt  *time.Time `json:"responded_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
+
+// CollectionInvitation is a pending invitation to the authenticated user
+type CollectionInvitation struct {
+	CollectionID   uuid.UUID `json:"collection_id"`
+	CollectionName string    `json:"collection_name"`
+	CollectionSlug string    `json:"collection_slug"`
+	Role           string    `json:"role"`
+	InvitedBy      uuid.UUID `json:"invited_by"`
+	InviterName    string    `json:"inviter_username"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// authorizeCollection loads a collection the user holds at least the need
+// role on, turning permission failures into client errors
+func authorizeCollection(db *gorm.DB, collectionID string, userID uuid.UUID, need collectionModels.CollaboratorRole) (*collectionModels.Collection, collectionModels.CollaboratorRole, error) {
+	collection, role, err := collectionModels.Authorize(db, collectionID, userID, need)
+	switch {
+	case errors.Is(err, collectionModels.ErrCollectionNotFound):
+		return nil, "", fiber.NewError(fiber.StatusNotFound, "Collection not found or you don't have permission")
+	case errors.Is(err, collectionModels.ErrInsufficientRole):
+		return nil, "", fiber.NewError(fiber.StatusForbidden,
+			fmt.Sprintf("This action requires the %s role on the collection", need))
+	case err != nil:
+		return nil, "", fmt.Errorf("failed to retrieve collection: %w", err)
+	}
+	return collection, role, nil
+}
+
+// ListCollaboratorsHandler lists the people a collection is shared with
+// @Summary List collection collaborators
+// @Description Lists the collection's creator and every invited collaborator with their role and invitation status. Available to anyone collaborating on the collection.
+// @Tags Collections
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Collection ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/collaborators [get]
+func ListCollaboratorsHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		collection, _, err := authorizeCollection(db, c.Params("id"), user.ID, collectionModels.RoleViewer)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		var creator Collaborator
+		if err := db.Table("users").
+			Select("users.id AS user_id, users.username, COALESCE(user_details.profile_image, '') AS profile_image").
+			Joins("LEFT JOIN user_details ON user_details.user_id = users.id").
+			Where("users.id = ?", collection.UserID).
+			Scan(&creator).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to load collection owner: %w", err))
+		}
+		creator.Role = string(collectionModels.RoleOwner)
+		creator.Status = collectionModels.InvitationAccepted
+		creator.CreatedAt = collection.CreatedAt
+
+		var collaborators []Collaborator
+		if err := db.Table("collection_collaborators").
+			Select("collection_collaborators.user_id, users.username, COALESCE(user_details.profile_image, '') AS profile_image, "+
+				"collection_collaborators.role, collection_collaborators.status, collection_collaborators.invited_by, "+
+				"collection_collaborators.responded_at, collection_collaborators.created_at").
+			Joins("JOIN users ON users.id = collection_collaborators.user_id").
+			Joins("LEFT JOIN user_details ON user_details.user_id = users.id").
+			Where("collection_collaborators.collection_id = ?", collection.ID).
+			Order("collection_collaborators.created_at ASC").
+			Scan(&collaborators).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to load collaborators: %w", err))
+		}
+
+		collaborators = append([]Collaborator{creator}, collaborators...)
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"collaborators": collaborators,
+			"count":         len(collaborators),
+		}, nil)
+	}
+}
+
+// InviteCollaboratorHandler invites a user to collaborate on a collection
+// @Summary Invite a collection collaborator
+// @Description Invites a user, found by username or email, to collaborate on the collection as an owner, editor or viewer. The invitee must accept before the role applies. Only owners can invite.
+// @Tags Collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Collection ID"
+// @Param request body InviteCollaboratorRequest true "Invitee and role"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/collaborators [post]
+func InviteCollaboratorHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req InviteCollaboratorRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		req.User = strings.TrimSpace(req.User)
+		if req.User == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Username or email of the invitee is required"))
+		}
+		role := collectionModels.CollaboratorRole(strings.ToLower(req.Role))
+		if !role.Valid() {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid role. Must be owner, editor or viewer"))
+		}
+
+		collection, _, err := authorizeCollection(db, c.Params("id"), user.ID, collectionModels.RoleOwner)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		var invitee userModels.User
+		if err := db.Where("(username = ? OR email = ?) AND is_active = ?", req.User, req.User, true).
+			First(&invitee).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "User not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to find user: %w", err))
+		}
+		if invitee.ID == collection.UserID || invitee.ID == user.ID {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "This user already owns the collection"))
+		}
+
+		// Blocked users in either direction cannot be invited
+		var blockCount int64
+		if err := db.Model(&userModels.BlockedUser{}).
+			Where("(user_id = ? AND blocked_user_id = ?) OR (user_id = ? AND blocked_user_id = ?)",
+				invitee.ID, user.ID, user.ID, invitee.ID).
+			Count(&blockCount).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to check block status: %w", err))
+		}
+		if blockCount > 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusForbidden, "You cannot invite this user"))
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var existing collectionModels.CollectionCollaborator
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("collection_id = ? AND user_id = ?", collection.ID, invitee.ID).
+				First(&existing).Error
+			switch {
+			case err == nil && existing.Status != collectionModels.InvitationDeclined:
+				return fiber.NewError(fiber.StatusConflict, "This user is already invited to the collection")
+			case err == nil:
+				// A declined invitation can be sent again
+				if err := tx.Model(&existing).Updates(map[string]interface{}{
+					"role":         role,
+					"status":       collectionModels.InvitationPending,
+					"invited_by":   user.ID,
+					"responded_at": nil,
+					"updated_at":   time.Now(),
+				}).Error; err != nil {
+					return fmt.Errorf("failed to renew invitation: %w", err)
+				}
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Create(&collectionModels.CollectionCollaborator{
+					CollectionID: collection.ID,
+					UserID:       invitee.ID,
+					Role:         role,
+					InvitedBy:    user.ID,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to create invitation: %w", err)
+				}
+			default:
+				return fmt.Errorf("failed to check existing invitation: %w", err)
+			}
+
+			message := fmt.Sprintf("%s invited you to collaborate on the collection %q as %s",
+				user.Username, collection.Name, role)
+			return notifications.WriteNotificationFrom(tx, invitee.ID, user.ID, "collection_invitation",
+				message, "collection", collection.ID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Invitation sent successfully",
+		}, nil)
+	}
+}
+
+// UpdateCollaboratorHandler changes a collaborator's role
+// @Summary Change a collaborator's role
+// @Description Changes the role of an invited collaborator. Only owners can change roles, and the collection's creator keeps the owner role.
+// @Tags Collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Collection ID"
+// @Param userId path string true "Collaborator user ID"
+// @Param request body UpdateCollaboratorRequest true "New role"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/collaborators/{userId} [put]
+func UpdateCollaboratorHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req UpdateCollaboratorRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		role := collectionModels.CollaboratorRole(strings.ToLower(req.Role))
+		if !role.Valid() {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid role. Must be owner, editor or viewer"))
+		}
+
+		collaboratorID, err := uuid.Parse(c.Params("userId"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid user ID"))
+		}
+
+		collection, _, err := authorizeCollection(db, c.Params("id"), user.ID, collectionModels.RoleOwner)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if collaboratorID == collection.UserID {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "The collection's creator is always an owner"))
+		}
+
+		result := db.Model(&collectionModels.CollectionCollaborator{}).
+			Where("collection_id = ? AND user_id = ?", collection.ID, collaboratorID).
+			Updates(map[string]interface{}{"role": role, "updated_at": time.Now()})
+		if result.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to update collaborator: %w", result.Error))
+		}
+		if result.RowsAffected == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Collaborator not found"))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Collaborator role updated successfully",
+		}, nil)
+	}
+}
+
+// RemoveCollaboratorHandler removes a collaborator or withdraws an invitation
+// @Summary Remove a collaborator
+// @Description Removes a collaborator from the collection or withdraws a pending invitation. Owners can remove anyone but the creator; collaborators can remove themselves to leave the collection.
+// @Tags Collections
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Collection ID"
+// @Param userId path string true "Collaborator user ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/collaborators/{userId} [delete]
+func RemoveCollaboratorHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		collaboratorID, err := uuid.Parse(c.Params("userId"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid user ID"))
+		}
+
+		// Leaving only needs access to the collection, removing others needs ownership
+		need := collectionModels.RoleOwner
+		if collaboratorID == user.ID {
+			need = collectionModels.RoleViewer
+		}
+		collection, _, err := authorizeCollection(db, c.Params("id"), user.ID, need)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if collaboratorID == collection.UserID {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "The collection's creator cannot be removed"))
+		}
+
+		result := db.Where("collection_id = ? AND user_id = ?", collection.ID, collaboratorID).
+			Delete(&collectionModels.CollectionCollaborator{})
+		if result.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to remove collaborator: %w", result.Error))
+		}
+		if result.RowsAffected == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Collaborator not found"))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Collaborator removed successfully",
+		}, nil)
+	}
+}
+
+// ListCollectionInvitationsHandler lists invitations awaiting the user's answer
+// @Summary List collection invitations
+// @Description Lists the pending invitations to collaborate on other users' collections, newest first
+// @Tags Collections
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/invitations [get]
+func ListCollectionInvitationsHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var invitations []CollectionInvitation
+		if err := db.Table("collection_collaborators").
+			Select("collections.id AS collection_id, collections.name AS collection_name, collections.slug AS collection_slug, "+
+				"collection_collaborators.role, collection_collaborators.invited_by, users.username AS inviter_name, "+
+				"collection_collaborators.created_at").
+			Joins("JOIN collections ON collections.id = collection_collaborators.collection_id").
+			Joins("JOIN users ON users.id = collection_collaborators.invited_by").
+			Where("collection_collaborators.user_id = ? AND collection_collaborators.status = ?",
+				user.ID, collectionModels.InvitationPending).
+			Order("collection_collaborators.created_at DESC").
+			Scan(&invitations).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to load invitations: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"invitations": invitations,
+			"count":       len(invitations),
+		}, nil)
+	}
+}
+
+// RespondToInvitationHandler accepts or declines a collection invitation
+// @Summary Respond to a collection invitation
+// @Description Accepts or declines a pending invitation to collaborate on a collection. The inviter is notified when the invitation is accepted.
+// @Tags Collections
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Collection ID"
+// @Param action path string true "accept or decline"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/invitation/{action} [put]
+func RespondToInvitationHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var status string
+		switch strings.ToLower(c.Params("action")) {
+		case "accept":
+			status = collectionModels.InvitationAccepted
+		case "decline":
+			status = collectionModels.InvitationDeclined
+		default:
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid action. Must be accept or decline"))
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var invitation collectionModels.CollectionCollaborator
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Preload("Collection").
+				Where("collection_id = ? AND user_id = ? AND status = ?",
+					c.Params("id"), user.ID, collectionModels.InvitationPending).
+				First(&invitation).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(fiber.StatusNotFound, "Invitation not found")
+				}
+				return fmt.Errorf("failed to retrieve invitation: %w", err)
+			}
+
+			now := time.Now()
+			if err := tx.Model(&invitation).Updates(map[string]interface{}{
+				"status":       status,
+				"responded_at": now,
+				"updated_at":   now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update invitation: %w", err)
+			}
+
+			if status != collectionModels.InvitationAccepted {
+				return nil
+			}
+			message := fmt.Sprintf("%s accepted your invitation to the collection %q",
+				user.Username, invitation.Collection.Name)
+			return notifications.WriteNotificationFrom(tx, invitation.InvitedBy, user.ID, "collection_invitation_accepted",
+				message, "collection", invitation.CollectionID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": fmt.Sprintf("Invitation %s", status),
+		}, nil)
+	}
+}
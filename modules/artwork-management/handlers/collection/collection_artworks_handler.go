@@ -0,0 +1,517 @@
+package collection
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	art "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	collectionModels "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
+	userModels "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxArtworksPerRequest bounds how many artworks can be added in one call
+	maxArtworksPerRequest = 100
+	// maxSectionLength bounds section headings
+	maxSectionLength = 255
+)
+
+// AddCollectionArtworksRequest lists the artworks to add to a collection
+type AddCollectionArtworksRequest struct {
+	ArtworkIDs []string `json:"artwork_ids"`
+	Section    *string  `json:"section" example:"Early works"`
+}
+
+// EntryInput adds an artwork to a collection or changes its entry. Omitted
+// fields keep their current value.
+type EntryInput struct {
+	ArtworkID string  `json:"artwork_id"`
+	Section   *string `json:"section" example:"Early works"`
+	Notes     *string `json:"notes" example:"Painted during the artist's residency in Lamu"`
+}
+
+// UpdateEntriesRequest applies several changes to a collection's entries at
+// once: removals first, then additions and edits, then the new order. Order,
+// when given, must list every artwork left in the collection exactly once.
+type UpdateEntriesRequest struct {
+	Upsert []EntryInput `json:"upsert"`
+	Remove []string     `json:"remove"`
+	Order  []string     `json:"order"`
+}
+
+// parseArtworkIDs parses artwork IDs, rejecting duplicates
+func parseArtworkIDs(raw []string) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool, len(raw))
+	ids := make([]uuid.UUID, 0, len(raw))
+	for _, value := range raw {
+		id, err := uuid.Parse(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Invalid artwork ID: %s", value))
+		}
+		if seen[id] {
+			return nil, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Artwork %s is listed more than once", id))
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// applyEntryChanges updates the collection's entries inside tx and returns
+// how many artworks were added. Artworks by other artists need the artist's
+// grant before they can be added.
+func applyEntryChanges(tx *gorm.DB, collection *collectionModels.Collection, actorID uuid.UUID, req UpdateEntriesRequest) (int, error) {
+	// Serialize layout changes to the same collection
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id").
+		First(&collectionModels.Collection{}, "id = ?", collection.ID).Error; err != nil {
+		return 0, fmt.Errorf("failed to lock collection: %w", err)
+	}
+
+	removeIDs, err := parseArtworkIDs(req.Remove)
+	if err != nil {
+		return 0, err
+	}
+	if len(removeIDs) > 0 {
+		if err := tx.Where("collection_id = ? AND artwork_id IN ?", collection.ID, removeIDs).
+			Delete(&collectionModels.CollectionEntry{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to remove entries: %w", err)
+		}
+	}
+
+	var entries []collectionModels.CollectionEntry
+	if err := tx.Where("collection_id = ?", collection.ID).
+		Order("position ASC, created_at ASC").
+		Find(&entries).Error; err != nil {
+		return 0, fmt.Errorf("failed to load entries: %w", err)
+	}
+	existing := make(map[uuid.UUID]*collectionModels.CollectionEntry, len(entries))
+	for i := range entries {
+		existing[entries[i].ArtworkID] = &entries[i]
+	}
+
+	rawUpserts := make([]string, len(req.Upsert))
+	for i, input := range req.Upsert {
+		rawUpserts[i] = input.ArtworkID
+	}
+	upsertIDs, err := parseArtworkIDs(rawUpserts)
+	if err != nil {
+		return 0, err
+	}
+
+	var newIDs []uuid.UUID
+	for _, id := range upsertIDs {
+		if existing[id] == nil {
+			newIDs = append(newIDs, id)
+		}
+	}
+	if len(entries)+len(newIDs) > collectionModels.MaxEntries {
+		return 0, fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("A collection can hold at most %d artworks", collectionModels.MaxEntries))
+	}
+	if err := checkArtistPermissions(tx, collection.ID, actorID, newIDs); err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	position, err := collectionModels.NextPosition(tx, collection.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find collection position: %w", err)
+	}
+	inCollection := make(map[uuid.UUID]bool, len(entries)+len(newIDs))
+	for _, entry := range entries {
+		inCollection[entry.ArtworkID] = true
+	}
+	for i, input := range req.Upsert {
+		if input.Section != nil && len(strings.TrimSpace(*input.Section)) > maxSectionLength {
+			return 0, fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("Section headings can be at most %d characters", maxSectionLength))
+		}
+
+		entry := existing[upsertIDs[i]]
+		if entry == nil {
+			added := collectionModels.CollectionEntry{
+				CollectionID: collection.ID,
+				ArtworkID:    upsertIDs[i],
+				Position:     position,
+				AddedBy:      &actorID,
+			}
+			applyEntryInput(&added, input)
+			if err := tx.Create(&added).Error; err != nil {
+				return 0, fmt.Errorf("failed to add artwork to collection: %w", err)
+			}
+			inCollection[added.ArtworkID] = true
+			position++
+			continue
+		}
+
+		if input.Section == nil && input.Notes == nil {
+			continue
+		}
+		applyEntryInput(entry, input)
+		if err := tx.Model(&collectionModels.CollectionEntry{}).
+			Where("id = ?", entry.ID).
+			Updates(map[string]interface{}{"section": entry.Section, "notes": entry.Notes, "updated_at": now}).Error; err != nil {
+			return 0, fmt.Errorf("failed to update entry: %w", err)
+		}
+	}
+
+	if len(req.Order) == 0 {
+		return len(newIDs), collectionModels.Renumber(tx, collection.ID)
+	}
+
+	orderIDs, err := parseArtworkIDs(req.Order)
+	if err != nil {
+		return 0, err
+	}
+	if len(orderIDs) != len(inCollection) {
+		return 0, fiber.NewError(fiber.StatusBadRequest, "Order must list every artwork in the collection exactly once")
+	}
+	for i, id := range orderIDs {
+		if !inCollection[id] {
+			return 0, fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Artwork %s is not in the collection", id))
+		}
+		if err := tx.Model(&collectionModels.CollectionEntry{}).
+			Where("collection_id = ? AND artwork_id = ?", collection.ID, id).
+			Updates(map[string]interface{}{"position": i + 1, "updated_at": now}).Error; err != nil {
+			return 0, fmt.Errorf("failed to reorder entries: %w", err)
+		}
+	}
+	return len(newIDs), nil
+}
+
+// applyEntryInput copies the provided fields of input onto entry
+func applyEntryInput(entry *collectionModels.CollectionEntry, input EntryInput) {
+	if input.Section != nil {
+		entry.Section = strings.TrimSpace(*input.Section)
+	}
+	if input.Notes != nil {
+		entry.Notes = strings.TrimSpace(*input.Notes)
+	}
+}
+
+// checkArtistPermissions verifies that every artwork exists and that its
+// artist lets actorID place it in the collection
+func checkArtistPermissions(tx *gorm.DB, collectionID, actorID uuid.UUID, artworkIDs []uuid.UUID) error {
+	if len(artworkIDs) == 0 {
+		return nil
+	}
+
+	var artworks []art.Artwork
+	if err := tx.Select("id, user_id, title").
+		Where("id IN ?", artworkIDs).
+		Find(&artworks).Error; err != nil {
+		return fmt.Errorf("failed to retrieve artworks: %w", err)
+	}
+	if len(artworks) != len(artworkIDs) {
+		return fiber.NewError(fiber.StatusNotFound, "One or more artworks were not found")
+	}
+
+	permitted := make(map[uuid.UUID]bool)
+	for _, artwork := range artworks {
+		allowed, checked := permitted[artwork.UserID]
+		if !checked {
+			var err error
+			allowed, err = collectionModels.MayInclude(tx, collectionID, artwork.UserID, actorID)
+			if err != nil {
+				return fmt.Errorf("failed to check artist permission: %w", err)
+			}
+			permitted[artwork.UserID] = allowed
+		}
+		if !allowed {
+			return fiber.NewError(fiber.StatusForbidden,
+				fmt.Sprintf("The artist of %q has not allowed this collection to include their work", artwork.Title))
+		}
+	}
+	return nil
+}
+
+// AddCollectionArtworksHandler adds artworks to a collection
+// @Summary Add artworks to a collection
+// @Description Appends artworks to the end of the collection, optionally under a section heading. Requires the editor role. Artworks by other artists can only be added once the artist has granted the collection permission. Artworks already in the collection are left where they are.
+// @Tags Collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Collection ID"
+// @Param request body AddCollectionArtworksRequest true "Artwork IDs"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/artworks [post]
+func AddCollectionArtworksHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req AddCollectionArtworksRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		if len(req.ArtworkIDs) == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "At least one artwork ID is required"))
+		}
+		if len(req.ArtworkIDs) > maxArtworksPerRequest {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest,
+					fmt.Sprintf("At most %d artworks can be added at once", maxArtworksPerRequest)))
+		}
+
+		collection, _, err := authorizeCollection(db, c.Params("id"), user.ID, collectionModels.RoleEditor)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		changes := UpdateEntriesRequest{Upsert: make([]EntryInput, len(req.ArtworkIDs))}
+		for i, id := range req.ArtworkIDs {
+			changes.Upsert[i] = EntryInput{ArtworkID: id, Section: req.Section}
+		}
+
+		var added int
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			added, err = applyEntryChanges(tx, collection, user.ID, changes)
+			return err
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Artworks added to collection",
+			"added":   added,
+		}, nil)
+	}
+}
+
+// UpdateCollectionEntriesHandler adds, removes and reorders entries at once
+// @Summary Update a collection's layout
+// @Description Applies removals, additions, edits of section headings and curator notes, and a new order to the collection's entries in one transaction. Requires the editor role. Returns the resulting layout.
+// @Tags Collections
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Collection ID"
+// @Param request body UpdateEntriesRequest true "Changes to apply"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/entries [patch]
+func UpdateCollectionEntriesHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req UpdateEntriesRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		if len(req.Upsert) == 0 && len(req.Remove) == 0 && len(req.Order) == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "No changes provided"))
+		}
+		if len(req.Upsert) > maxArtworksPerRequest {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest,
+					fmt.Sprintf("At most %d artworks can be added or edited at once", maxArtworksPerRequest)))
+		}
+
+		collection, _, err := authorizeCollection(db, c.Params("id"), user.ID, collectionModels.RoleEditor)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		if err := db.Transaction(func(tx *gorm.DB) error {
+			_, err := applyEntryChanges(tx, collection, user.ID, req)
+			return err
+		}); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		sections, err := collectionLayout(db, collection.ID, user.ID, true)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":  "Collection layout updated",
+			"sections": sections,
+		}, nil)
+	}
+}
+
+// RemoveCollectionArtworkHandler takes an artwork out of a collection
+// @Summary Remove an artwork from a collection
+// @Description Removes an artwork from the collection. The artwork itself and its place in other collections are not affected. Requires the editor role; artists can also remove their own artworks from any collection.
+// @Tags Collections
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Collection ID"
+// @Param artworkId path string true "Artwork ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/artworks/{artworkId} [delete]
+func RemoveCollectionArtworkHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		collectionID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid collection ID"))
+		}
+		artworkID, err := uuid.Parse(c.Params("artworkId"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid artwork ID"))
+		}
+
+		var artwork art.Artwork
+		if err := db.Select("artworks.id, artworks.user_id").
+			Joins("JOIN collection_entries ON collection_entries.artwork_id = artworks.id").
+			Where("artworks.id = ? AND collection_entries.collection_id = ?", artworkID, collectionID).
+			First(&artwork).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Artwork not found in this collection"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve artwork: %w", err))
+		}
+
+		// Artists can always take their own work back out
+		if artwork.UserID != user.ID {
+			if _, _, err := authorizeCollection(db, collectionID.String(), user.ID, collectionModels.RoleEditor); err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("collection_id = ? AND artwork_id = ?", collectionID, artworkID).
+				Delete(&collectionModels.CollectionEntry{}).Error; err != nil {
+				return fmt.Errorf("failed to remove artwork from collection: %w", err)
+			}
+			return collectionModels.Renumber(tx, collectionID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Artwork removed from collection",
+		}, nil)
+	}
+}
+
+// GrantArtistPermissionHandler lets the editors of a collection add the
+// authenticated artist's artworks
+// @Summary Allow a collection to include your artworks
+// @Description Grants the collection's owners and editors permission to add the authenticated user's artworks to it
+// @Tags Collections
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Collection ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/artist-permission [put]
+func GrantArtistPermissionHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var collection collectionModels.Collection
+		if err := db.Where("id = ?", c.Params("id")).First(&collection).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Collection not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve collection: %w", err))
+		}
+		if collection.UserID == user.ID {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "You already curate your own collection"))
+		}
+
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).
+			Create(&collectionModels.CollectionArtistGrant{
+				CollectionID: collection.ID,
+				ArtistID:     user.ID,
+			}).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to grant permission: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "The collection may now include your artworks",
+		}, nil)
+	}
+}
+
+// RevokeArtistPermissionHandler withdraws an artist's permission
+// @Summary Stop a collection from including your artworks
+// @Description Withdraws the permission to add the authenticated user's artworks to the collection. Artworks already in it stay until removed.
+// @Tags Collections
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Collection ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /collections/{id}/artist-permission [delete]
+func RevokeArtistPermissionHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		result := db.Where("collection_id = ? AND artist_id = ?", c.Params("id"), user.ID).
+			Delete(&collectionModels.CollectionArtistGrant{})
+		if result.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to revoke permission: %w", result.Error))
+		}
+		if result.RowsAffected == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "No permission granted to this collection"))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Permission revoked",
+		}, nil)
+	}
+}
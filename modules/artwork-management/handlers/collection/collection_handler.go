@@ -5,9 +5,11 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	art "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	"github.com/google/uuid"
 	collectionModels "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
+	organizations "github.com/muga20/artsMarket/modules/organizations/models"
 	models "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/privacy"
 
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"gorm.io/gorm"
@@ -15,7 +17,7 @@ import (
 
 // GetAllCollectionsHandler handles fetching all collections
 // @Summary Get all user collections
-// @Description Retrieves all collections belonging to the authenticated user
+// @Description Retrieves the collections the authenticated user created, collaborates on or manages for an organization, with their role on each
 // @Tags Collections
 // @Accept json
 // @Produce json
@@ -34,6 +36,7 @@ func GetAllCollectionsHandler(db *gorm.DB, responseHandler *handlers.ResponseHan
 		var collections []struct {
 			ID              string    `json:"id"`
 			UserID          string    `json:"user_id"`
+			Role            string    `json:"role"`
 			Name            string    `json:"name"`
 			Slug            string    `json:"slug"`
 			Description     string    `json:"description"`
@@ -42,11 +45,25 @@ func GetAllCollectionsHandler(db *gorm.DB, responseHandler *handlers.ResponseHan
 			UpdatedAt       time.Time `json:"updated_at"`
 			CoverImageURL   string    `json:"cover_image_url"`
 			PrimaryImageURL string    `json:"primary_image_url"`
+			OrganizationID  *string   `json:"organization_id"`
 		}
 
+		// Shared collections appear alongside the user's own once the invitation
+		// is accepted, as do those of the user's organizations
 		if err := db.Table("collections").
-			Select("id, user_id, name, description, status, slug, created_at, updated_at, cover_image_url, primary_image_url").
-			Where("user_id = ?", user.ID).
+			Select("collections.id, collections.user_id, collections.name, collections.description, collections.status, "+
+				"collections.slug, collections.created_at, collections.updated_at, collections.cover_image_url, "+
+				"collections.primary_image_url, collections.organization_id, "+
+				"CASE WHEN collections.user_id = ? OR om.role = ? OR cc.role = ? THEN ? "+
+				"WHEN om.role = ? OR cc.role = ? THEN ? ELSE cc.role END AS role",
+				user.ID, organizations.RoleAdmin, collectionModels.RoleOwner, collectionModels.RoleOwner,
+				organizations.RoleStaff, collectionModels.RoleEditor, collectionModels.RoleEditor).
+			Joins("LEFT JOIN collection_collaborators cc ON cc.collection_id = collections.id AND cc.user_id = ? AND cc.status = ?",
+				user.ID, collectionModels.InvitationAccepted).
+			Joins("LEFT JOIN organization_members om ON om.organization_id = collections.organization_id AND om.user_id = ?",
+				user.ID).
+			Where("collections.user_id = ? OR cc.id IS NOT NULL OR om.id IS NOT NULL", user.ID).
+			Order("collections.created_at DESC").
 			Find(&collections).Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
 				fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve collections"))
@@ -59,30 +76,33 @@ func GetAllCollectionsHandler(db *gorm.DB, responseHandler *handlers.ResponseHan
 	}
 }
 
-// GetCollectionByIDHandler handles fetching a collection by ID
-// @Summary Get collection by ID
-// @Description Retrieves a collection by its ID. Collections can be viewed if public or if owned by the authenticated user.
+// GetCollectionByIDHandler handles fetching a collection by ID or slug
+// @Summary Get a collection and its layout
+// @Description Retrieves a collection by its ID or slug with its artworks in curated order, grouped into sections with curator notes. Collections can be viewed if published or by anyone collaborating on them; other viewers only see artworks visible to them.
 // @Tags Collections
 // @Accept json
 // @Produce json
-// @Param id path string true "Collection ID" example("550e8400-e29b-41d4-a716-446655440000")
+// @Param identifier path string true "Collection ID or slug" example("summer-in-lamu")
 // @Success 200 {object} map[string]interface{}
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
 // @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
-// @Router /collections/{id} [get]
+// @Router /collections/{identifier} [get]
 func GetCollectionByIDHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		user, ok := c.Locals("user").(models.User)
-		collectionID := c.Params("id")
+		identifier := c.Params("identifier")
 		var collection collectionModels.Collection
 
 		// Load collection with basic user info
-		if err := db.
-			Preload("User").
-			Where("id = ?", collectionID).
-			First(&collection).Error; err != nil {
+		query := db.Preload("User")
+		if id, parseErr := uuid.Parse(identifier); parseErr == nil {
+			query = query.Where("id = ?", id)
+		} else {
+			query = query.Where("slug = ?", identifier)
+		}
+		if err := query.First(&collection).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return responseHandler.HandleResponse(c, nil,
 					fiber.NewError(fiber.StatusNotFound, "Collection not found"))
@@ -91,11 +111,25 @@ func GetCollectionByIDHandler(db *gorm.DB, responseHandler *handlers.ResponseHan
 				fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve collection"))
 		}
 
-		// Check if requester is the owner
-		isOwner := ok && collection.UserID == user.ID
+		// Collaborators see the collection as its owner does
+		var role collectionModels.CollaboratorRole
+		if ok {
+			var err error
+			if role, err = collectionModels.RoleOf(db, &collection, user.ID); err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusInternalServerError, "Failed to check collection access"))
+			}
+		}
+		isMember := role != ""
+
+		// Content of deactivated accounts is hidden from everyone else
+		if !isMember && !collection.User.IsActive {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Collection not found"))
+		}
 
 		// Access control
-		if !isOwner && collection.Status != collectionModels.PublishedStatus {
+		if !isMember && collection.Status != collectionModels.PublishedStatus {
 			return responseHandler.HandleResponse(c, nil,
 				fiber.NewError(fiber.StatusForbidden, "This collection is not published"))
 		}
@@ -110,6 +144,35 @@ func GetCollectionByIDHandler(db *gorm.DB, responseHandler *handlers.ResponseHan
 			}
 		}
 
+		// Owner contact details follow their privacy settings
+		owner := privacy.UserView{
+			ID:           collection.User.ID,
+			Username:     collection.User.Username,
+			ProfileImage: userDetail.ProfileImage,
+			FirstName:    userDetail.FirstName,
+			LastName:     userDetail.LastName,
+		}
+		policy, err := privacy.ForRequest(c, db)
+		if err == nil {
+			err = policy.Load(collection.UserID)
+		}
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusInternalServerError, "Failed to apply privacy settings"))
+		}
+
+		// Private accounts share their collections with approved followers only
+		if !isMember && !policy.CanSeeContent(collection.UserID) {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusForbidden, "This account is private"))
+		}
+		policy.Apply(&owner)
+
+		sections, err := collectionLayout(db, collection.ID, user.ID, isMember)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
 		// Prepare response
 		response := fiber.Map{
 			"data": fiber.Map{
@@ -122,14 +185,10 @@ func GetCollectionByIDHandler(db *gorm.DB, responseHandler *handlers.ResponseHan
 				"cover_image_url":   collection.CoverImageURL,
 				"primary_image_url": collection.PrimaryImageURL,
 				"created_at":        collection.CreatedAt,
+				"role":              role,
+				"sections":          sections,
 
-				"user": fiber.Map{
-					"id":            collection.User.ID,
-					"username":      collection.User.Username,
-					"profile_image": userDetail.ProfileImage,
-					"first_name":    userDetail.FirstName,
-					"last_name":     userDetail.LastName,
-				},
+				"user": owner,
 			},
 		}
 
@@ -139,7 +198,7 @@ func GetCollectionByIDHandler(db *gorm.DB, responseHandler *handlers.ResponseHan
 
 // DeleteCollectionHandler handles deleting a collection
 // @Summary Delete a collection
-// @Description Deletes a user's collection if it's empty. Requires authentication and ownership of the collection.
+// @Description Deletes a collection. Its artworks stay on the platform and in other collections. Requires the owner role on the collection.
 // @Tags Collections
 // @Accept json
 // @Produce json
@@ -148,7 +207,6 @@ func GetCollectionByIDHandler(db *gorm.DB, responseHandler *handlers.ResponseHan
 // @Success 200 {object} map[string]string "Returns success message"
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
-// @Failure 409 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /collections/{id} [delete]
 func DeleteCollectionHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
@@ -156,29 +214,13 @@ func DeleteCollectionHandler(db *gorm.DB, responseHandler *handlers.ResponseHand
 		user := c.Locals("user").(models.User)
 		collectionID := c.Params("id")
 
-		var collection collectionModels.Collection
-		if err := db.Where("id = ? AND user_id = ?", collectionID, user.ID).First(&collection).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return responseHandler.HandleResponse(c, nil,
-					fiber.NewError(fiber.StatusNotFound, "Collection not found or you don't have permission"))
-			}
-			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve collection"))
-		}
-
-		// Check if collection is empty
-		var count int64
-		if err := db.Model(&art.Artwork{}).Where("collection_id = ?", collectionID).Count(&count).Error; err != nil {
-			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusInternalServerError, "Failed to check collection contents"))
-		}
-
-		if count > 0 {
-			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusConflict, "Cannot delete collection - it contains artworks"))
+		// Only owners can delete a collection
+		collection, _, err := authorizeCollection(db, collectionID, user.ID, collectionModels.RoleOwner)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
 		}
 
-		if err := db.Delete(&collection).Error; err != nil {
+		if err := db.Delete(collection).Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
 				fiber.NewError(fiber.StatusInternalServerError, "Failed to delete collection"))
 		}
@@ -1,7 +1,6 @@
 package collection
 
 import (
-	"errors"
 	"fmt"
 	"log"
 	"time"
@@ -46,15 +45,10 @@ func UpdateCollectionImagesHandler(db *gorm.DB, cld *config.CloudinaryClient, re
 				fiber.NewError(fiber.StatusBadRequest, "Collection ID is required"))
 		}
 
-		// Validate collection ownership
-		var collection collectionModels.Collection
-		if err := db.Where("id = ? AND user_id = ?", collectionID, user.ID).First(&collection).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return responseHandler.HandleResponse(c, nil,
-					fiber.NewError(fiber.StatusNotFound, "Collection not found or you don't have permission"))
-			}
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to retrieve collection: %w", err))
+		// Editors can change the collection images
+		collection, _, err := authorizeCollection(db, collectionID, user.ID, collectionModels.RoleEditor)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
 		}
 
 		// Parse image type from form data
@@ -100,7 +94,7 @@ func UpdateCollectionImagesHandler(db *gorm.DB, cld *config.CloudinaryClient, re
 		}
 		collection.UpdatedAt = time.Now()
 
-		if err := tx.Save(&collection).Error; err != nil {
+		if err := tx.Save(collection).Error; err != nil {
 			tx.Rollback()
 			return responseHandler.HandleResponse(c, nil,
 				fmt.Errorf("failed to update collection image: %w", err))
@@ -157,15 +151,10 @@ func RemoveCollectionImageHandler(db *gorm.DB, cld *config.CloudinaryClient, res
 				fiber.NewError(fiber.StatusBadRequest, "Invalid image type. Must be 'cover' or 'primary'"))
 		}
 
-		// Validate collection ownership
-		var collection collectionModels.Collection
-		if err := db.Where("id = ? AND user_id = ?", collectionID, user.ID).First(&collection).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return responseHandler.HandleResponse(c, nil,
-					fiber.NewError(fiber.StatusNotFound, "Collection not found or you don't have permission"))
-			}
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to retrieve collection: %w", err))
+		// Editors can change the collection images
+		collection, _, err := authorizeCollection(db, collectionID, user.ID, collectionModels.RoleEditor)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
 		}
 
 		// Start transaction
@@ -194,7 +183,7 @@ func RemoveCollectionImageHandler(db *gorm.DB, cld *config.CloudinaryClient, res
 
 		// Update collection in database
 		collection.UpdatedAt = time.Now()
-		if err := tx.Save(&collection).Error; err != nil {
+		if err := tx.Save(collection).Error; err != nil {
 			tx.Rollback()
 			return responseHandler.HandleResponse(c, nil,
 				fmt.Errorf("failed to update collection: %w", err))
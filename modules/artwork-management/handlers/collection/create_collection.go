@@ -8,15 +8,17 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	collection "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
+	organizations "github.com/muga20/artsMarket/modules/organizations/models"
 	models "github.com/muga20/artsMarket/modules/users/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"gorm.io/gorm"
 )
 
 type CreateCollectionRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	IsPublic    bool   `json:"is_public"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	IsPublic       bool   `json:"is_public"`
+	OrganizationID string `json:"organization_id"` // Share the collection with an organization the user is a member of
 }
 
 // CollectionStatus represents the possible statuses of a collection
@@ -30,7 +32,7 @@ const (
 
 // CreateCollectionHandler handles creating a new collection
 // @Summary Create a new collection
-// @Description Creates a new art collection for the authenticated user
+// @Description Creates a new art collection for the authenticated user, optionally shared with an organization they belong to
 // @Tags Collections
 // @Accept json
 // @Produce json
@@ -73,14 +75,29 @@ func CreateCollectionHandler(db *gorm.DB, responseHandler *handlers.ResponseHand
 				fmt.Errorf("failed to check collection existence: %w", err))
 		}
 
+		var organizationID *uuid.UUID
+		if req.OrganizationID != "" {
+			organization, _, err := organizations.Authorize(db, req.OrganizationID, user.ID, organizations.RoleStaff)
+			if err != nil {
+				if errors.Is(err, organizations.ErrOrganizationNotFound) {
+					return responseHandler.HandleResponse(c, nil,
+						fiber.NewError(fiber.StatusNotFound, "Organization not found"))
+				}
+				return responseHandler.HandleResponse(c, nil,
+					fmt.Errorf("failed to check organization membership: %w", err))
+			}
+			organizationID = &organization.ID
+		}
+
 		newCollection := collection.Collection{
-			ID:          uuid.New(),
-			UserID:      user.ID,
-			Name:        req.Name,
-			Description: req.Description,
-			Status:      collection.CollectionStatus(DraftStatus),
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			ID:             uuid.New(),
+			UserID:         user.ID,
+			Name:           req.Name,
+			Description:    req.Description,
+			Status:         collection.CollectionStatus(DraftStatus),
+			OrganizationID: organizationID,
+			CreatedAt:      time.Now(),
+			UpdatedAt:      time.Now(),
 		}
 
 		if err := db.Create(&newCollection).Error; err != nil {
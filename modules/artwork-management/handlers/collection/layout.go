@@ -0,0 +1,89 @@
+package collection
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	art "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	collectionModels "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
+	"gorm.io/gorm"
+)
+
+// LayoutEntry is one artwork in a collection's layout
+type LayoutEntry struct {
+	Position       int       `json:"position"`
+	ArtworkID      uuid.UUID `json:"artwork_id"`
+	Title          string    `json:"title"`
+	Slug           string    `json:"slug"`
+	ImageURL       string    `json:"image_url"`
+	Price          *float64  `json:"price"`
+	IsForSale      bool      `json:"is_for_sale"`
+	ArtistID       uuid.UUID `json:"artist_id"`
+	ArtistUsername string    `json:"artist_username"`
+	Notes          string    `json:"notes"`
+}
+
+// LayoutSection groups consecutive entries under the same heading. Entries
+// before the first heading form a section with an empty heading.
+type LayoutSection struct {
+	Heading string        `json:"heading"`
+	Entries []LayoutEntry `json:"entries"`
+}
+
+// collectionLayout returns the collection's entries in order, grouped into
+// sections. Members see every entry; other viewers only see artworks
+// visible to them.
+func collectionLayout(db *gorm.DB, collectionID, viewerID uuid.UUID, isMember bool) ([]LayoutSection, error) {
+	query := db.Where("collection_id = ?", collectionID)
+	if !isMember {
+		query = query.Where("artwork_id IN (?)", art.VisibleTo(db, viewerID).Select("artworks.id"))
+	}
+	var entries []collectionModels.CollectionEntry
+	if err := query.Order("position ASC, created_at ASC").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load collection entries: %w", err)
+	}
+
+	sections := []LayoutSection{}
+	if len(entries) == 0 {
+		return sections, nil
+	}
+
+	artworkIDs := make([]uuid.UUID, len(entries))
+	for i, entry := range entries {
+		artworkIDs[i] = entry.ArtworkID
+	}
+	var artworks []art.Artwork
+	if err := db.Preload("Images").Preload("User").
+		Where("id IN ?", artworkIDs).
+		Find(&artworks).Error; err != nil {
+		return nil, fmt.Errorf("failed to load collection artworks: %w", err)
+	}
+	byID := make(map[uuid.UUID]*art.Artwork, len(artworks))
+	for i := range artworks {
+		byID[artworks[i].ID] = &artworks[i]
+	}
+
+	for _, entry := range entries {
+		artwork := byID[entry.ArtworkID]
+		if artwork == nil {
+			continue
+		}
+		if len(sections) == 0 || sections[len(sections)-1].Heading != entry.Section {
+			sections = append(sections, LayoutSection{Heading: entry.Section})
+		}
+		current := &sections[len(sections)-1]
+		current.Entries = append(current.Entries, LayoutEntry{
+			Position:       entry.Position,
+			ArtworkID:      artwork.ID,
+			Title:          artwork.Title,
+			Slug:           artwork.Slug,
+			ImageURL:       artwork.PrimaryImageURL(),
+			Price:          artwork.Price,
+			IsForSale:      artwork.IsForSale,
+			ArtistID:       artwork.UserID,
+			ArtistUsername: artwork.User.Username,
+			Notes:          entry.Notes,
+		})
+	}
+	return sections, nil
+}
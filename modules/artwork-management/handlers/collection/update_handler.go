@@ -7,8 +7,9 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
-	art "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
 	collectionModels "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
+	feed "github.com/muga20/artsMarket/modules/feed/models"
+	"github.com/muga20/artsMarket/modules/feed/timeline"
 	userModels "github.com/muga20/artsMarket/modules/users/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"gorm.io/gorm"
@@ -29,7 +30,7 @@ type UpdateCollectionRequest struct {
 
 // UpdateCollectionHandler handles updating collection details
 // @Summary Update a collection
-// @Description Updates basic information about a collection (name, description, visibility)
+// @Description Updates basic information about a collection (name, description, visibility). Requires the editor role.
 // @Tags Collections
 // @Accept json
 // @Produce json
@@ -64,15 +65,10 @@ func UpdateCollectionHandler(db *gorm.DB, responseHandler *handlers.ResponseHand
 				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
 		}
 
-		// Verify collection exists and belongs to user
-		var collection collectionModels.Collection
-		if err := db.Where("id = ? AND user_id = ?", collectionID, user.ID).First(&collection).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return responseHandler.HandleResponse(c, nil,
-					fiber.NewError(fiber.StatusNotFound, "Collection not found or you don't have permission"))
-			}
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to retrieve collection: %w", err))
+		// Editors can change the collection details
+		collection, _, err := authorizeCollection(db, collectionID, user.ID, collectionModels.RoleEditor)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
 		}
 
 		// Update collection fields if they are provided in the request
@@ -84,18 +80,18 @@ func UpdateCollectionHandler(db *gorm.DB, responseHandler *handlers.ResponseHand
 		}
 		collection.UpdatedAt = time.Now()
 
-		// Validate collection name is unique for this user
+		// Validate collection name is unique among the creator's collections
 		var existingCollection collectionModels.Collection
-		if err := db.Where("user_id = ? AND name = ? AND id != ?", user.ID, collection.Name, collectionID).First(&existingCollection).Error; err == nil {
+		if err := db.Where("user_id = ? AND name = ? AND id != ?", collection.UserID, collection.Name, collectionID).First(&existingCollection).Error; err == nil {
 			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusConflict, "You already have a collection with this name"))
+				fiber.NewError(fiber.StatusConflict, "The collection owner already has a collection with this name"))
 		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
 			return responseHandler.HandleResponse(c, nil,
 				fmt.Errorf("failed to check for existing collections: %w", err))
 		}
 
 		// Save updated collection
-		if err := db.Save(&collection).Error; err != nil {
+		if err := db.Save(collection).Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
 				fmt.Errorf("failed to update collection: %w", err))
 		}
@@ -108,7 +104,7 @@ func UpdateCollectionHandler(db *gorm.DB, responseHandler *handlers.ResponseHand
 
 // UpdateCollectionStatusHandler handles updating collection status
 // @Summary Update collection status
-// @Description Change the status of a collection (draft/published/archived)
+// @Description Change the status of a collection (draft/published/archived). Requires the owner role.
 // @Tags Collections
 // @Accept json
 // @Produce json
@@ -156,21 +152,16 @@ func UpdateCollectionStatusHandler(db *gorm.DB, responseHandler *handlers.Respon
 				fiber.NewError(fiber.StatusBadRequest, "Invalid status. Must be publish, archive, or draft"))
 		}
 
-		// Verify collection exists and belongs to user
-		var collection collectionModels.Collection
-		if err := db.Where("id = ? AND user_id = ?", collectionID, user.ID).First(&collection).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return responseHandler.HandleResponse(c, nil,
-					fiber.NewError(fiber.StatusNotFound, "Collection not found or you don't have permission"))
-			}
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to retrieve collection: %w", err))
+		// Publishing and archiving are left to owners
+		collection, _, err := authorizeCollection(db, collectionID, user.ID, collectionModels.RoleOwner)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
 		}
 
 		// Additional business rule: Can't publish empty collections
 		if newStatus == collectionModels.PublishedStatus {
-			var artworkCount int64
-			if err := db.Model(&art.Artwork{}).Where("collection_id = ?", collectionID).Count(&artworkCount).Error; err != nil {
+			artworkCount, err := collectionModels.CountEntries(db, collection.ID)
+			if err != nil {
 				return responseHandler.HandleResponse(c, nil,
 					fiber.NewError(fiber.StatusInternalServerError, "Failed to verify collection contents"))
 			}
@@ -182,12 +173,23 @@ func UpdateCollectionStatusHandler(db *gorm.DB, responseHandler *handlers.Respon
 		}
 
 		// Update collection
+		wasPublished := collection.Status == collectionModels.PublishedStatus
 		collection.Status = newStatus
 		collection.UpdatedAt = time.Now()
 
-		if err := db.Save(&collection).Error; err != nil {
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to update collection status: %w", err))
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Save(collection).Error; err != nil {
+				return fmt.Errorf("failed to update collection status: %w", err)
+			}
+
+			// The creator's followers see newly published collections in their feed
+			if newStatus == collectionModels.PublishedStatus && !wasPublished {
+				return timeline.Record(tx, collection.UserID, feed.ActivityCollectionPublished, feed.EntityCollection, collection.ID, nil)
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
 		}
 
 		return responseHandler.HandleResponse(c, fiber.Map{
@@ -0,0 +1,147 @@
+package dimensions
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	models "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	"github.com/muga20/artsMarket/modules/notifications/services"
+	user_models "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tasks"
+	"gorm.io/gorm"
+)
+
+// StartDimensionMigrationHandler starts a run of the dimension migration job
+// @Summary Migrate legacy artwork dimensions
+// @Description Starts a background job that parses the free-text dimensions of artworks without structured ones (admin only). Artworks it cannot parse are listed in the run's report. Only one run can be in progress at a time.
+// @Tags Artworks
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/artworks/dimension-migrations [post]
+func StartDimensionMigrationHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(user_models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var migration models.DimensionMigration
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var running int64
+			if err := tx.Model(&models.DimensionMigration{}).
+				Where("status IN ?", []string{models.DimensionMigrationPending, models.DimensionMigrationProcessing}).
+				Count(&running).Error; err != nil {
+				return fmt.Errorf("failed to check running migrations: %w", err)
+			}
+			if running > 0 {
+				return fiber.NewError(fiber.StatusConflict, "A dimension migration is already in progress")
+			}
+
+			migration = models.DimensionMigration{
+				RequestedBy: user.ID,
+				Status:      models.DimensionMigrationPending,
+			}
+			if err := tx.Create(&migration).Error; err != nil {
+				return fmt.Errorf("failed to create dimension migration: %w", err)
+			}
+
+			_, err := services.WriteOutbox(tx, tasks.TypeMigrateDimensions, tasks.MigrateDimensionsPayload{
+				MigrationID: migration.ID.String(),
+			})
+			return err
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":   "Dimension migration started",
+			"migration": migration,
+		}, nil)
+	}
+}
+
+// ListDimensionMigrationsHandler lists the runs of the dimension migration job
+// @Summary List dimension migrations
+// @Description Lists runs of the dimension migration job, newest first (admin only)
+// @Tags Artworks
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/artworks/dimension-migrations [get]
+func ListDimensionMigrationsHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var migrations []models.DimensionMigration
+		if err := db.Order("created_at DESC").Limit(50).Find(&migrations).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to list dimension migrations: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"migrations": migrations,
+		}, nil)
+	}
+}
+
+// GetDimensionMigrationHandler returns a run of the dimension migration job and the artworks it could not parse
+// @Summary Get a dimension migration report
+// @Description Returns the progress of a dimension migration run and a page of the artworks whose dimensions it could not parse, with the reason (admin only)
+// @Tags Artworks
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Migration ID"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 50, max: 200)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/artworks/dimension-migrations/{id} [get]
+func GetDimensionMigrationHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page := c.QueryInt("page", 1)
+		pageSize := c.QueryInt("page_size", 50)
+		if page < 1 {
+			page = 1
+		}
+		if pageSize < 1 || pageSize > 200 {
+			pageSize = 50
+		}
+
+		var migration models.DimensionMigration
+		if err := db.Where("id = ?", c.Params("id")).First(&migration).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Dimension migration not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve dimension migration: %w", err))
+		}
+
+		var failures []models.DimensionParseFailure
+		if err := db.Where("migration_id = ?", migration.ID).
+			Order("created_at, artwork_id").
+			Offset((page - 1) * pageSize).
+			Limit(pageSize).
+			Find(&failures).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to list unparsed artworks: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"migration": migration,
+			"unparsed":  failures,
+			"page":      page,
+			"page_size": pageSize,
+		}, nil)
+	}
+}
@@ -0,0 +1,364 @@
+package tags
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	models "github.com/muga20/artsMarket/modules/artwork-management/models/tags"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultAutocompleteLimit = 10
+	maxAutocompleteLimit     = 25
+)
+
+// MergeTagRequest names the tag another tag is merged into
+type MergeTagRequest struct {
+	TargetID string `json:"target_id" validate:"required"`
+}
+
+// AddAliasRequest is another name for a tag
+type AddAliasRequest struct {
+	Alias string `json:"alias" validate:"required"`
+}
+
+// TagSuggestion is an autocomplete match and how many artworks use it
+type TagSuggestion struct {
+	ID         uuid.UUID `json:"id"`
+	TagName    string    `json:"tag_name"`
+	UsageCount int64     `json:"usage_count"`
+}
+
+// DuplicateGroup is a set of tags whose names normalize to the same key
+type DuplicateGroup struct {
+	NormalizedKey string       `json:"normalized_key"`
+	Tags          []models.Tag `json:"tags"`
+}
+
+// findTag loads a tag by ID, answering 404 when it does not exist
+func findTag(db *gorm.DB, id string) (*models.Tag, error) {
+	var tag models.Tag
+	if err := db.Where("id = ?", id).First(&tag).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Tag not found")
+		}
+		return nil, fmt.Errorf("failed to retrieve tag: %w", err)
+	}
+	return &tag, nil
+}
+
+// AutocompleteTagsHandler suggests tags starting with the typed text
+// @Summary Autocomplete tags
+// @Description Suggests approved, active tags whose name or an alias starts with the query, ignoring case, spaces and punctuation. The most used tags come first.
+// @Tags Tags
+// @Produce  json
+// @Param q query string true "Text typed so far"
+// @Param limit query int false "Number of suggestions (default 10, max 25)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /tags/autocomplete [get]
+func AutocompleteTagsHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		limit := c.QueryInt("limit", defaultAutocompleteLimit)
+		if limit < 1 || limit > maxAutocompleteLimit {
+			limit = defaultAutocompleteLimit
+		}
+
+		suggestions := make([]TagSuggestion, 0, limit)
+		prefix := models.NormalizeTag(c.Query("q"))
+		if prefix == "" {
+			return responseHandler.HandleResponse(c, fiber.Map{"suggestions": suggestions}, nil)
+		}
+
+		// Keys hold only letters and digits, so the prefix needs no LIKE escaping
+		if err := db.Model(&models.Tag{}).
+			Select("tags.id, tags.tag_name, COUNT(artwork_tags.id) AS usage_count").
+			Joins("LEFT JOIN artwork_tags ON artwork_tags.tag_id = tags.id").
+			Where("tags.status = ? AND tags.is_active = ?", models.TagStatusApproved, true).
+			Where("tags.normalized_key LIKE ? OR tags.id IN (?)", prefix+"%",
+				db.Model(&models.TagAlias{}).Select("tag_id").Where("normalized_key LIKE ?", prefix+"%")).
+			Group("tags.id, tags.tag_name").
+			Order("usage_count DESC, tags.tag_name").
+			Limit(limit).
+			Scan(&suggestions).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to autocomplete tags: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{"suggestions": suggestions}, nil)
+	}
+}
+
+// ListTagAliasesHandler lists the other names of a tag
+// @Summary List tag aliases
+// @Description Lists the synonyms and other spellings that resolve to the tag
+// @Tags Tags
+// @Produce  json
+// @Param id path string true "Tag ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{id}/aliases [get]
+func ListTagAliasesHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		tag, err := findTag(db, c.Params("id"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		var aliases []models.TagAlias
+		if err := db.Where("tag_id = ?", tag.ID).Order("alias").Find(&aliases).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to list tag aliases: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"tag":     tag,
+			"aliases": aliases,
+		}, nil)
+	}
+}
+
+// AddTagAliasHandler maps another name to a tag
+// @Summary Add a tag alias
+// @Description Makes another name resolve to the tag (admin only), so artists typing it are offered the tag instead of creating a new one
+// @Tags Tags
+// @Accept  json
+// @Produce  json
+// @Param id path string true "Tag ID"
+// @Param request body AddAliasRequest true "Alias"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{id}/aliases [post]
+func AddTagAliasHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req AddAliasRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request payload"))
+		}
+
+		tag, err := findTag(db, c.Params("id"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		alias, err := models.AddAlias(db, tag.ID, req.Alias)
+		switch {
+		case errors.Is(err, models.ErrEmptyTagName):
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		case errors.Is(err, models.ErrTagNameTaken):
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusConflict, err.Error()))
+		case err != nil:
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Tag alias added successfully",
+			"data":    alias,
+		}, nil)
+	}
+}
+
+// DeleteTagAliasHandler removes another name of a tag
+// @Summary Delete a tag alias
+// @Description Stops an alias resolving to the tag (admin only)
+// @Tags Tags
+// @Produce  json
+// @Param id path string true "Tag ID"
+// @Param aliasId path string true "Alias ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{id}/aliases/{aliasId} [delete]
+func DeleteTagAliasHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		result := db.Where("id = ? AND tag_id = ?", c.Params("aliasId"), c.Params("id")).Delete(&models.TagAlias{})
+		if result.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to delete tag alias: %w", result.Error))
+		}
+		if result.RowsAffected == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Tag alias not found"))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Tag alias deleted successfully",
+		}, nil)
+	}
+}
+
+// MergeTagHandler folds a tag into another
+// @Summary Merge tags
+// @Description Merges the tag into the target tag (admin only). Artworks tagged with it are retagged with the target in one transaction, and its name and aliases become aliases of the target. The merged tag is deleted.
+// @Tags Tags
+// @Accept  json
+// @Produce  json
+// @Param id path string true "ID of the tag to merge away"
+// @Param request body MergeTagRequest true "Tag to keep"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{id}/merge [post]
+func MergeTagHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req MergeTagRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request payload"))
+		}
+		sourceID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid tag ID"))
+		}
+		targetID, err := uuid.Parse(req.TargetID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid target tag ID"))
+		}
+
+		var moved int64
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			moved, err = models.Merge(tx, sourceID, targetID)
+			return err
+		})
+		switch {
+		case errors.Is(err, models.ErrMergeIntoSelf):
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusNotFound, "Tag not found"))
+		case err != nil:
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":        "Tags merged successfully",
+			"target_id":      targetID,
+			"artworks_moved": moved,
+		}, nil)
+	}
+}
+
+// ModerateTagHandler approves or rejects a tag created by an artist
+// @Summary Moderate a tag
+// @Description Approves or rejects a tag (admin only). Approved tags are offered in autocomplete. Rejected tags are removed from every artwork and their name cannot be used again.
+// @Tags Tags
+// @Produce  json
+// @Param id path string true "Tag ID"
+// @Param action path string true "approve or reject"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /tags/{id}/moderate/{action} [put]
+func ModerateTagHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var status string
+		switch strings.ToLower(c.Params("action")) {
+		case "approve":
+			status = models.TagStatusApproved
+		case "reject":
+			status = models.TagStatusRejected
+		default:
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid action. Must be approve or reject"))
+		}
+
+		tag, err := findTag(db, c.Params("id"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Model(tag).Updates(map[string]interface{}{
+				"status":     status,
+				"is_active":  status == models.TagStatusApproved,
+				"updated_at": time.Now(),
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update tag status: %w", err)
+			}
+			if status == models.TagStatusRejected {
+				if err := tx.Where("tag_id = ?", tag.ID).Delete(&models.ArtworkTag{}).Error; err != nil {
+					return fmt.Errorf("failed to remove rejected tag from artworks: %w", err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": fmt.Sprintf("Tag %s", status),
+			"data":    tag,
+		}, nil)
+	}
+}
+
+// ListDuplicateTagsHandler lists tags that normalize to the same key
+// @Summary List duplicate tags
+// @Description Lists groups of tags whose names differ only in case, spacing or punctuation (admin only). They date from before names were normalized and can be merged.
+// @Tags Tags
+// @Produce  json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /tags/duplicates [get]
+func ListDuplicateTagsHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Only tags created before keys existed can share one, and each of
+		// those groups has a tag left without a key
+		var unkeyed []models.Tag
+		if err := db.Where("normalized_key IS NULL").Find(&unkeyed).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to list tags: %w", err))
+		}
+
+		groups := make(map[string][]models.Tag)
+		for _, tag := range unkeyed {
+			if key := models.NormalizeTag(tag.TagName); key != "" {
+				groups[key] = append(groups[key], tag)
+			}
+		}
+		keys := make([]string, 0, len(groups))
+		for key := range groups {
+			keys = append(keys, key)
+		}
+		var keyed []models.Tag
+		if len(keys) > 0 {
+			if err := db.Where("normalized_key IN ?", keys).Find(&keyed).Error; err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fmt.Errorf("failed to list tags: %w", err))
+			}
+		}
+		for _, tag := range keyed {
+			groups[*tag.NormalizedKey] = append([]models.Tag{tag}, groups[*tag.NormalizedKey]...)
+		}
+
+		sort.Strings(keys)
+		duplicates := make([]DuplicateGroup, 0, len(keys))
+		for _, key := range keys {
+			if len(groups[key]) > 1 {
+				duplicates = append(duplicates, DuplicateGroup{NormalizedKey: key, Tags: groups[key]})
+			}
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"duplicates": duplicates,
+			"count":      len(duplicates),
+		}, nil)
+	}
+}
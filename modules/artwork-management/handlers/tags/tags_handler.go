@@ -3,11 +3,13 @@ package tags
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	models "github.com/muga20/artsMarket/modules/artwork-management/models/tags"
+	userModels "github.com/muga20/artsMarket/modules/users/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"gorm.io/gorm"
 )
@@ -24,7 +26,7 @@ type UpdateTagRequest struct {
 
 // CreateTagHandler handles creating a new tag
 // @Summary Create a new tag
-// @Description Create a new tag with name and active status
+// @Description Create a new tag with name and active status. Names that differ from an existing tag or alias only in case, spacing or punctuation are rejected. Tags created by anyone but an admin await moderation.
 // @Tags Tags
 // @Accept  json
 // @Produce  json
@@ -35,20 +37,50 @@ type UpdateTagRequest struct {
 // @Router /tags [post]
 func CreateTagHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
 		var req CreateTagRequest
 		if err := c.BodyParser(&req); err != nil {
 			return responseHandler.HandleResponse(c, nil,
 				fiber.NewError(fiber.StatusBadRequest, "Invalid request payload"))
 		}
 
+		// Near-duplicates such as "Oil Painting" and "oil-painting" share a key
+		if existing, err := models.Resolve(db, req.TagName); err == nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, fmt.Sprintf("Tag with this name already exists as %q", existing.TagName)))
+		} else if errors.Is(err, models.ErrEmptyTagName) {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, err.Error()))
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to check tag names: %w", err))
+		}
+
 		tag := models.Tag{
 			ID:        uuid.New(),
-			TagName:   req.TagName,
+			TagName:   strings.TrimSpace(req.TagName),
+			Status:    models.TagStatusApproved,
 			IsActive:  true,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
 
+		// Tags from anyone but an admin go through moderation, like inline tags
+		isAdmin, err := userModels.UserHasRole(db, user.ID, userModels.AdminRoleName)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to check user role: %w", err))
+		}
+		if !isAdmin {
+			tag.Status = models.TagStatusPending
+			tag.CreatedBy = &user.ID
+		}
+
 		if err := db.Create(&tag).Error; err != nil {
 			if errors.Is(err, gorm.ErrDuplicatedKey) {
 				return responseHandler.HandleResponse(c, nil,
@@ -60,6 +92,7 @@ func CreateTagHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fi
 
 		return responseHandler.HandleResponse(c, fiber.Map{
 			"message": "Tag created successfully",
+			"status":  tag.Status,
 		}, nil)
 	}
 }
@@ -70,13 +103,19 @@ func CreateTagHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fi
 // @Tags Tags
 // @Accept  json
 // @Produce  json
+// @Param status query string false "Only list tags with this moderation status" Enums(approved,pending,rejected)
 // @Success 200 {object} map[string]interface{}
 // @Failure 500 {object} map[string]string
 // @Router /tags [get]
 func GetAllTagsHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		query := db.Model(&models.Tag{})
+		if status := c.Query("status"); status != "" {
+			query = query.Where("status = ?", status)
+		}
+
 		var tags []models.Tag
-		if err := db.Find(&tags).Error; err != nil {
+		if err := query.Find(&tags).Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
 				fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve tags"))
 		}
@@ -160,9 +199,23 @@ func UpdateTagHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fi
 				fiber.NewError(fiber.StatusInternalServerError, "Failed to retrieve tag"))
 		}
 
-		// Update tag name if provided
+		// Update tag name if provided, keeping keys unique
 		if req.TagName != "" {
-			tag.TagName = req.TagName
+			key := models.NormalizeTag(req.TagName)
+			if key == "" {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, models.ErrEmptyTagName.Error()))
+			}
+			taken, err := models.KeyTaken(db, key, tag.ID)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+			if taken {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusConflict, "Tag with this name already exists"))
+			}
+			tag.TagName = strings.TrimSpace(req.TagName)
+			tag.NormalizedKey = &key
 		}
 		tag.UpdatedAt = time.Now()
 
@@ -269,7 +322,7 @@ func DeleteTagHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fi
 
 		if count > 0 {
 			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusConflict, "Cannot delete tag - it's being used in artworks"))
+				fiber.NewError(fiber.StatusConflict, "Cannot delete tag - it's being used in artworks. Merge it into another tag instead"))
 		}
 
 		// Perform the delete
@@ -24,3 +24,17 @@ func (ai *ArtworkImage) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// PrimaryImageURL returns the image marked primary, or the first one when
+// none is. Images must be preloaded.
+func (a *Artwork) PrimaryImageURL() string {
+	for _, image := range a.Images {
+		if image.IsPrimary {
+			return image.ImageURL
+		}
+	}
+	if len(a.Images) > 0 {
+		return a.Images[0].ImageURL
+	}
+	return ""
+}
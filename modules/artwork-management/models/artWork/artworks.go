@@ -9,6 +9,7 @@ import (
 	collection "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
 	medium "github.com/muga20/artsMarket/modules/artwork-management/models/medium"
 	technique "github.com/muga20/artsMarket/modules/artwork-management/models/technique"
+	organizations "github.com/muga20/artsMarket/modules/organizations/models"
 	user "github.com/muga20/artsMarket/modules/users/models"
 	"gorm.io/gorm"
 )
@@ -52,7 +53,6 @@ const (
 type Artwork struct {
 	ID             uuid.UUID     `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
 	UserID         uuid.UUID     `gorm:"type:char(36);not null" json:"user_id"`
-	CollectionID   *uuid.UUID    `gorm:"type:char(36);not null" json:"collection_id"`
 	Title          string        `gorm:"type:varchar(255);not null" json:"title"`
 	Slug           string        `gorm:"type:varchar(255);not null;uniqueIndex" json:"slug"`
 	Description    string        `gorm:"type:text" json:"description"`
@@ -61,8 +61,15 @@ type Artwork struct {
 	IsForSale      bool          `gorm:"type:boolean;not null;default:false" json:"is_for_sale"`
 	Status         ArtworkStatus `gorm:"type:enum('pending','approved','rejected');default:'pending'" json:"status"`
 	Type           ArtworkType   `gorm:"type:enum('traditional','digital','photography','mixed_media','sculpture','performance');not null" json:"type"`
-	Dimensions     string        `gorm:"type:varchar(255)" json:"dimensions"`
+	Dimensions     string        `gorm:"type:varchar(255)" json:"dimensions"` // Free-text label, kept for display
+	Height         *float64      `gorm:"type:decimal(8,2)" json:"height"`
+	Width          *float64      `gorm:"type:decimal(8,2)" json:"width"`
+	Depth          *float64      `gorm:"type:decimal(8,2)" json:"depth"`
+	DimensionUnit  LengthUnit    `gorm:"type:enum('cm','in');default:'cm'" json:"dimension_unit"`
+	MaxDimensionCm *float64      `gorm:"type:decimal(10,2);index" json:"max_dimension_cm"` // Longest side in centimeters, for size filters
+	SizeClass      *SizeClass    `gorm:"type:enum('small','medium','large','oversized');index" json:"size_class"`
 	Weight         *float64      `gorm:"type:decimal(6,2)" json:"weight"`
+	WeightUnit     WeightUnit    `gorm:"type:enum('kg','lb');default:'kg'" json:"weight_unit"`
 	IsFramed       bool          `gorm:"type:boolean;not null;default:false" json:"is_framed"`
 	Condition      ConditionType `gorm:"type:enum('pristine','excellent','good','acceptable','restored','damaged');default:'pristine'" json:"condition"`
 	MediumID       *uuid.UUID    `gorm:"type:char(36)" json:"medium_id"`
@@ -71,15 +78,22 @@ type Artwork struct {
 	LicenseDetails string        `gorm:"type:text" json:"license_details"`
 	ViewCount      int           `gorm:"type:int;not null;default:0" json:"view_count"`
 
+	// OrganizationID is the gallery listing the artwork for the artist, whose
+	// share of the proceeds is set by CommissionRate as agreed at listing
+	OrganizationID *uuid.UUID `gorm:"type:char(36);index" json:"organization_id"`
+	CommissionRate *float64   `gorm:"type:decimal(5,2)" json:"commission_rate"`
+
 	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 
-	User       user.User             `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Collection collection.Collection `gorm:"foreignKey:CollectionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
-	Images     []ArtworkImage        `gorm:"foreignKey:ArtworkID;constraint:OnDelete:CASCADE"`
-	Medium     medium.Medium         `gorm:"foreignKey:MediumID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
-	Technique  technique.Technique   `gorm:"foreignKey:TechniqueID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
-	Editions   []Edition             `gorm:"foreignKey:ArtworkID;constraint:OnDelete:CASCADE"`
+	User      user.User                    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Images    []ArtworkImage               `gorm:"foreignKey:ArtworkID;constraint:OnDelete:CASCADE"`
+	Medium    medium.Medium                `gorm:"foreignKey:MediumID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Technique technique.Technique          `gorm:"foreignKey:TechniqueID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"`
+	Editions  []Edition                    `gorm:"foreignKey:ArtworkID;constraint:OnDelete:CASCADE"`
+	Entries   []collection.CollectionEntry `gorm:"foreignKey:ArtworkID;constraint:OnDelete:CASCADE" json:"-"` // Collections the artwork appears in
+
+	Organization *organizations.Organization `gorm:"foreignKey:OrganizationID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"organization,omitempty"`
 }
 
 func (a *Artwork) BeforeCreate(tx *gorm.DB) (err error) {
@@ -103,6 +117,14 @@ func (a *Artwork) BeforeCreate(tx *gorm.DB) (err error) {
 		a.LicenseType = AllRightsReserved
 	}
 
+	if a.DimensionUnit == "" {
+		a.DimensionUnit = Centimeters
+	}
+
+	if a.WeightUnit == "" {
+		a.WeightUnit = Kilograms
+	}
+
 	// Generate slug from title if empty
 	if a.Slug == "" {
 		a.Slug = slug.Make(a.Title)
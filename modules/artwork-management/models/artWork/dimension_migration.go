@@ -0,0 +1,59 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Dimension migration statuses stored in DimensionMigration.Status
+const (
+	DimensionMigrationPending    = "pending"
+	DimensionMigrationProcessing = "processing"
+	DimensionMigrationCompleted  = "completed"
+	DimensionMigrationFailed     = "failed"
+)
+
+// DimensionMigration is one run of the job that turns legacy free-text
+// dimensions into structured ones
+type DimensionMigration struct {
+	ID          uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	RequestedBy uuid.UUID  `gorm:"type:char(36);not null" json:"requested_by"`
+	Status      string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	Scanned     int        `gorm:"type:int;not null;default:0" json:"scanned"`  // Artworks with text but no structured dimensions
+	Parsed      int        `gorm:"type:int;not null;default:0" json:"parsed"`   // Artworks given structured dimensions
+	Unparsed    int        `gorm:"type:int;not null;default:0" json:"unparsed"` // Artworks left for their owners to fix
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	CompletedAt *time.Time `gorm:"type:timestamp" json:"completed_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (m *DimensionMigration) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return
+}
+
+// DimensionParseFailure records an artwork whose dimensions a migration could not read
+type DimensionParseFailure struct {
+	ID          uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	MigrationID uuid.UUID `gorm:"type:char(36);not null;index" json:"migration_id"`
+	ArtworkID   uuid.UUID `gorm:"type:char(36);not null" json:"artwork_id"`
+	Dimensions  string    `gorm:"type:varchar(255)" json:"dimensions"`
+	Reason      string    `gorm:"type:varchar(255)" json:"reason"`
+	CreatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Migration DimensionMigration `gorm:"foreignKey:MigrationID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (f *DimensionParseFailure) BeforeCreate(tx *gorm.DB) (err error) {
+	if f.ID == uuid.Nil {
+		f.ID = uuid.New()
+	}
+	return
+}
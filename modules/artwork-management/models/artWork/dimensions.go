@@ -0,0 +1,300 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type LengthUnit string
+type WeightUnit string
+type SizeClass string
+
+const (
+	// Length Units
+	Centimeters LengthUnit = "cm"
+	Inches      LengthUnit = "in"
+
+	// Weight Units
+	Kilograms WeightUnit = "kg"
+	Pounds    WeightUnit = "lb"
+
+	// Size Classes, by the longest side
+	SmallSize     SizeClass = "small"     // Up to 40cm, such as prints and small studies
+	MediumSize    SizeClass = "medium"    // Up to 100cm
+	LargeSize     SizeClass = "large"     // Up to 200cm
+	OversizedSize SizeClass = "oversized" // Longer than 200cm, usually needing freight shipping
+
+	centimetersPerInch = 2.54
+	kilogramsPerPound  = 0.45359237
+)
+
+// sizeClassLimits is the longest side in centimeters each class allows
+var sizeClassLimits = []struct {
+	class SizeClass
+	maxCm float64
+}{
+	{SmallSize, 40},
+	{MediumSize, 100},
+	{LargeSize, 200},
+}
+
+// Valid reports whether the unit is one artworks can be measured in
+func (u LengthUnit) Valid() bool {
+	return u == Centimeters || u == Inches
+}
+
+// Valid reports whether the unit is one artworks can be weighed in
+func (u WeightUnit) Valid() bool {
+	return u == Kilograms || u == Pounds
+}
+
+// ToCentimeters converts a length in the given unit to centimeters
+func ToCentimeters(value float64, unit LengthUnit) float64 {
+	if unit == Inches {
+		return value * centimetersPerInch
+	}
+	return value
+}
+
+// FromCentimeters converts a length in centimeters to the given unit
+func FromCentimeters(value float64, unit LengthUnit) float64 {
+	if unit == Inches {
+		return value / centimetersPerInch
+	}
+	return value
+}
+
+// ToKilograms converts a weight in the given unit to kilograms
+func ToKilograms(value float64, unit WeightUnit) float64 {
+	if unit == Pounds {
+		return value * kilogramsPerPound
+	}
+	return value
+}
+
+// FromKilograms converts a weight in kilograms to the given unit
+func FromKilograms(value float64, unit WeightUnit) float64 {
+	if unit == Pounds {
+		return value / kilogramsPerPound
+	}
+	return value
+}
+
+// ClassifySize returns the size class of an artwork whose longest side is
+// the given length in centimeters
+func ClassifySize(longestCm float64) SizeClass {
+	for _, limit := range sizeClassLimits {
+		if longestCm <= limit.maxCm {
+			return limit.class
+		}
+	}
+	return OversizedSize
+}
+
+// Measurements are an artwork's height, width and optional depth in one unit
+type Measurements struct {
+	Height float64    `json:"height"`
+	Width  float64    `json:"width"`
+	Depth  *float64   `json:"depth,omitempty"`
+	Unit   LengthUnit `json:"unit"`
+}
+
+// Validate checks the measurements are positive and in a known unit
+func (m Measurements) Validate() error {
+	if !m.Unit.Valid() {
+		return fmt.Errorf("dimension unit must be cm or in")
+	}
+	if m.Height <= 0 || m.Width <= 0 || (m.Depth != nil && *m.Depth <= 0) {
+		return fmt.Errorf("dimensions must be greater than zero")
+	}
+	if m.LongestCm() > 100000 {
+		return fmt.Errorf("dimensions are too large")
+	}
+	return nil
+}
+
+// LongestCm returns the longest side in centimeters
+func (m Measurements) LongestCm() float64 {
+	longest := math.Max(m.Height, m.Width)
+	if m.Depth != nil {
+		longest = math.Max(longest, *m.Depth)
+	}
+	return ToCentimeters(longest, m.Unit)
+}
+
+// Measurements returns the artwork's structured dimensions, if it has them
+func (a *Artwork) Measurements() (Measurements, bool) {
+	if a.Height == nil || a.Width == nil {
+		return Measurements{}, false
+	}
+	return Measurements{Height: *a.Height, Width: *a.Width, Depth: a.Depth, Unit: a.DimensionUnit}, true
+}
+
+// SetMeasurements stores structured dimensions on the artwork together with
+// the longest side in centimeters and the size class used for filtering
+func (a *Artwork) SetMeasurements(m Measurements) {
+	height, width := round2(m.Height), round2(m.Width)
+	a.Height, a.Width, a.Depth = &height, &width, nil
+	if m.Depth != nil {
+		depth := round2(*m.Depth)
+		a.Depth = &depth
+	}
+	a.DimensionUnit = m.Unit
+
+	longest := round2(m.LongestCm())
+	class := ClassifySize(longest)
+	a.MaxDimensionCm = &longest
+	a.SizeClass = &class
+	if a.Dimensions == "" {
+		a.Dimensions = m.String()
+	}
+}
+
+// MeasurementColumns returns the columns SetMeasurements changes, for updates
+func (a *Artwork) MeasurementColumns() map[string]interface{} {
+	return map[string]interface{}{
+		"height":           a.Height,
+		"width":            a.Width,
+		"depth":            a.Depth,
+		"dimension_unit":   a.DimensionUnit,
+		"max_dimension_cm": a.MaxDimensionCm,
+		"size_class":       a.SizeClass,
+		"dimensions":       a.Dimensions,
+	}
+}
+
+// String formats the measurements the way artworks are usually labeled, as in 50 x 70 cm
+func (m Measurements) String() string {
+	parts := []string{formatLength(m.Height), formatLength(m.Width)}
+	if m.Depth != nil {
+		parts = append(parts, formatLength(*m.Depth))
+	}
+	return strings.Join(parts, " x ") + " " + string(m.Unit)
+}
+
+func formatLength(value float64) string {
+	return strconv.FormatFloat(round2(value), 'f', -1, 64)
+}
+
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+var (
+	// ErrNoDimensions is returned for text without any measurements
+	ErrNoDimensions = errors.New("no measurements found")
+	// ErrNoUnit is returned when the text does not say what unit it is in
+	ErrNoUnit = errors.New("no unit given")
+
+	dimensionToken = regexp.MustCompile(`\d+(?:[.,]\d+)?|[a-z]+|["'×*]`)
+
+	// dimensionUnits maps the unit spellings found in legacy text to a unit
+	// and the factor converting to it
+	dimensionUnits = map[string]struct {
+		unit   LengthUnit
+		factor float64
+	}{
+		"mm": {Centimeters, 0.1}, "cm": {Centimeters, 1}, "cms": {Centimeters, 1},
+		"m": {Centimeters, 100}, "in": {Inches, 1}, "inch": {Inches, 1},
+		"inches": {Inches, 1}, `"`: {Inches, 1}, "ft": {Inches, 12},
+		"feet": {Inches, 12}, "foot": {Inches, 12}, "'": {Inches, 12},
+	}
+	dimensionLabels = map[string]string{
+		"h": "height", "height": "height", "w": "width", "width": "width",
+		"d": "depth", "depth": "depth", "l": "width", "length": "width",
+	}
+	// dimensionFillers are words legacy text may contain that do not change its meaning
+	dimensionFillers = map[string]bool{"x": true, "×": true, "*": true, "by": true, "approx": true, "approximately": true, "ca": true}
+)
+
+// ParseDimensions reads free-text dimensions such as "50 x 70 cm",
+// "20in x 30in", `24" x 36"` or "H 50 W 70 D 3 cm". Unlabeled numbers are
+// read as height, width and depth in that order, and numbers without their
+// own unit take the last unit given. Millimeters and meters are converted to
+// centimeters, and feet to inches.
+func ParseDimensions(text string) (Measurements, error) {
+	type reading struct {
+		value float64
+		label string
+		unit  string
+	}
+	isNumber := func(token string) bool { return token[0] >= '0' && token[0] <= '9' }
+
+	tokens := dimensionToken.FindAllString(strings.ToLower(text), -1)
+	var readings []reading
+	lastUnit := ""
+	pendingLabel := ""
+	for i, token := range tokens {
+		switch _, isUnit := dimensionUnits[token]; {
+		case isNumber(token):
+			value, err := strconv.ParseFloat(strings.Replace(token, ",", ".", 1), 64)
+			if err != nil {
+				return Measurements{}, fmt.Errorf("invalid number %q", token)
+			}
+			readings = append(readings, reading{value: value, label: pendingLabel})
+			pendingLabel = ""
+		case isUnit:
+			if i == 0 || !isNumber(tokens[i-1]) {
+				return Measurements{}, fmt.Errorf("unit %q without a number", token)
+			}
+			readings[len(readings)-1].unit = token
+			lastUnit = token
+		case dimensionLabels[token] != "":
+			pendingLabel = dimensionLabels[token]
+		case !dimensionFillers[token]:
+			return Measurements{}, fmt.Errorf("unexpected %q", token)
+		}
+	}
+
+	if len(readings) == 0 {
+		return Measurements{}, ErrNoDimensions
+	}
+	if len(readings) < 2 || len(readings) > 3 {
+		return Measurements{}, fmt.Errorf("expected 2 or 3 measurements, found %d", len(readings))
+	}
+	if lastUnit == "" {
+		return Measurements{}, ErrNoUnit
+	}
+
+	var unit LengthUnit
+	byLabel := map[string]float64{}
+	ordered := []string{"height", "width", "depth"}
+	for i, r := range readings {
+		if r.unit == "" {
+			r.unit = lastUnit
+		}
+		converted := dimensionUnits[r.unit]
+		if unit != "" && unit != converted.unit {
+			return Measurements{}, fmt.Errorf("mixed metric and imperial units")
+		}
+		unit = converted.unit
+
+		label := r.label
+		if label == "" {
+			label = ordered[i]
+		}
+		if _, taken := byLabel[label]; taken {
+			return Measurements{}, fmt.Errorf("%s given twice", label)
+		}
+		byLabel[label] = r.value * converted.factor
+	}
+
+	height, hasHeight := byLabel["height"]
+	width, hasWidth := byLabel["width"]
+	if !hasHeight || !hasWidth {
+		return Measurements{}, fmt.Errorf("height and width are required")
+	}
+	m := Measurements{Height: round2(height), Width: round2(width), Unit: unit}
+	if depth, ok := byLabel["depth"]; ok {
+		depth = round2(depth)
+		m.Depth = &depth
+	}
+	if err := m.Validate(); err != nil {
+		return Measurements{}, err
+	}
+	return m, nil
+}
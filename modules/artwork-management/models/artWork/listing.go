@@ -0,0 +1,32 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	organizations "github.com/muga20/artsMarket/modules/organizations/models"
+	"gorm.io/gorm"
+)
+
+// EditableBy selects the artworks userID may edit: their own, and those
+// listed by an organization they belong to for an artist it still represents
+func EditableBy(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Model(&Artwork{}).
+		Where("(artworks.user_id = ? OR EXISTS (?))", userID,
+			db.Model(&organizations.OrganizationMember{}).
+				Select("1").
+				Joins("JOIN organization_representations ON organization_representations.organization_id = organization_members.organization_id").
+				Where("organization_members.user_id = ?", userID).
+				Where("organization_members.organization_id = artworks.organization_id").
+				Where("organization_representations.artist_id = artworks.user_id AND organization_representations.status = ?",
+					organizations.RepresentationActive))
+}
+
+// Proceeds returns how a sale at the artwork's price is split between the
+// listing organization and the artist. ok is false for artworks without a
+// price or not listed by an organization.
+func (a *Artwork) Proceeds() (organizationShare, artistShare float64, ok bool) {
+	if a.Price == nil || a.OrganizationID == nil || a.CommissionRate == nil {
+		return 0, 0, false
+	}
+	organizationShare, artistShare = organizations.Split(*a.Price, *a.CommissionRate)
+	return organizationShare, artistShare, true
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	user "github.com/muga20/artsMarket/modules/users/models"
+	"gorm.io/gorm"
+)
+
+// VisibleTo selects the artworks the viewer may browse: approved artworks of
+// active accounts with no block between the viewer and the artist, and of
+// private accounts only when the viewer follows them or is the artist
+func VisibleTo(db *gorm.DB, viewerID uuid.UUID) *gorm.DB {
+	return db.Model(&Artwork{}).
+		Select("artworks.*").
+		Joins("JOIN users ON users.id = artworks.user_id").
+		Where("artworks.status = ? AND users.is_active = ?", ApprovedStatus, true).
+		Where("artworks.user_id NOT IN (?)", db.Model(&user.BlockedUser{}).
+			Select("blocked_user_id").
+			Where("user_id = ?", viewerID)).
+		Where("artworks.user_id NOT IN (?)", db.Model(&user.BlockedUser{}).
+			Select("user_id").
+			Where("blocked_user_id = ?", viewerID)).
+		Where("artworks.user_id = ? OR artworks.user_id NOT IN (?) OR artworks.user_id IN (?)",
+			viewerID,
+			db.Model(&user.UserPrivacySetting{}).
+				Select("user_id").
+				Where("is_private = ?", true),
+			db.Model(&user.Follower{}).
+				Select("following_id").
+				Where("follower_id = ? AND status = ?", viewerID, user.FollowStatusAccepted))
+}
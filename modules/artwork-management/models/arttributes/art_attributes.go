@@ -8,10 +8,11 @@ import (
 )
 
 type ArtworkAttribute struct {
-	ID          uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
-	ArtworkID   uuid.UUID `gorm:"type:char(36);not null;index" json:"artwork_id"`
-	AttributeID uuid.UUID `gorm:"type:char(36);not null;index" json:"attribute_id"`
-	Value       string    `gorm:"type:varchar(255)" json:"value"`
+	ID           uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	ArtworkID    uuid.UUID `gorm:"type:char(36);not null;index" json:"artwork_id"`
+	AttributeID  uuid.UUID `gorm:"type:char(36);not null;index;index:idx_attribute_value,priority:1;index:idx_attribute_numeric,priority:1" json:"attribute_id"`
+	Value        string    `gorm:"type:varchar(255);index:idx_attribute_value,priority:2" json:"value"`
+	NumericValue *float64  `gorm:"type:double;index:idx_attribute_numeric,priority:2" json:"numeric_value,omitempty"` // Set for integer and float attributes so they can be range-filtered
 
 	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
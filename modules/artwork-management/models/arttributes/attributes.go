@@ -1,16 +1,38 @@
 package models
 
 import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/gosimple/slug"
 	"gorm.io/gorm"
 )
 
+// Attribute types
+const (
+	TypeString  = "string"
+	TypeInteger = "integer"
+	TypeFloat   = "float"
+	TypeBoolean = "boolean"
+	TypeEnum    = "enum"
+)
+
+// Attribute defines a property artworks can have, such as height or
+// material, and the values it accepts
 type Attribute struct {
 	ID            uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
 	AttributeName string    `gorm:"type:varchar(255);not null" json:"attribute_name"`
-	Type          string    `gorm:"type:enum('string','integer','float','boolean');default:'string'" json:"type"`
+	Slug          string    `gorm:"type:varchar(255);not null;default:'';index" json:"slug"` // Name used in search filters
+	Type          string    `gorm:"type:enum('string','integer','float','boolean','enum');default:'string'" json:"type"`
+	Unit          string    `gorm:"type:varchar(32)" json:"unit,omitempty"`                    // Unit of numeric values, such as cm or kg
+	AllowedValues []string  `gorm:"type:json;serializer:json" json:"allowed_values,omitempty"` // Values an enum attribute accepts
+	MinValue      *float64  `gorm:"type:double" json:"min_value,omitempty"`                    // Lowest numeric value accepted
+	MaxValue      *float64  `gorm:"type:double" json:"max_value,omitempty"`                    // Highest numeric value accepted
+	RequiredFor   []string  `gorm:"type:json;serializer:json" json:"required_for,omitempty"`   // Artwork types that must set this attribute
 
 	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
@@ -21,5 +43,107 @@ func (a *Attribute) BeforeCreate(tx *gorm.DB) (err error) {
 	if a.ID == uuid.Nil {
 		a.ID = uuid.New()
 	}
+	if a.Slug == "" {
+		a.Slug = slug.Make(a.AttributeName)
+	}
 	return
 }
+
+// IsNumeric reports whether the attribute holds numbers, which can be range-filtered
+func (a *Attribute) IsNumeric() bool {
+	return a.Type == TypeInteger || a.Type == TypeFloat
+}
+
+// IsRequiredFor reports whether artworks of the given type must set the attribute
+func (a *Attribute) IsRequiredFor(artworkType string) bool {
+	for _, required := range a.RequiredFor {
+		if required == artworkType {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckDefinition validates the attribute's own settings
+func (a *Attribute) CheckDefinition() error {
+	switch a.Type {
+	case TypeString, TypeBoolean:
+		if a.MinValue != nil || a.MaxValue != nil {
+			return fmt.Errorf("only integer and float attributes can have bounds")
+		}
+	case TypeInteger, TypeFloat:
+		if a.MinValue != nil && a.MaxValue != nil && *a.MinValue > *a.MaxValue {
+			return fmt.Errorf("min_value cannot be greater than max_value")
+		}
+	case TypeEnum:
+		if len(a.AllowedValues) == 0 {
+			return fmt.Errorf("enum attributes need allowed_values")
+		}
+		if a.MinValue != nil || a.MaxValue != nil {
+			return fmt.Errorf("only integer and float attributes can have bounds")
+		}
+	default:
+		return fmt.Errorf("invalid type %q", a.Type)
+	}
+	if a.Type != TypeEnum && len(a.AllowedValues) > 0 {
+		return fmt.Errorf("only enum attributes can have allowed_values")
+	}
+	return nil
+}
+
+// Normalize checks a raw value against the attribute's type, allowed values
+// and bounds, and returns the value to store with its numeric form for
+// integer and float attributes
+func (a *Attribute) Normalize(raw string) (string, *float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", nil, fmt.Errorf("%s cannot be empty", a.AttributeName)
+	}
+
+	switch a.Type {
+	case TypeInteger, TypeFloat:
+		number, err := strconv.ParseFloat(raw, 64)
+		if err != nil || math.IsNaN(number) || math.IsInf(number, 0) {
+			return "", nil, fmt.Errorf("%s must be a number", a.AttributeName)
+		}
+		if a.Type == TypeInteger && number != math.Trunc(number) {
+			return "", nil, fmt.Errorf("%s must be a whole number", a.AttributeName)
+		}
+		if a.MinValue != nil && number < *a.MinValue {
+			return "", nil, fmt.Errorf("%s must be at least %s", a.AttributeName, a.format(*a.MinValue))
+		}
+		if a.MaxValue != nil && number > *a.MaxValue {
+			return "", nil, fmt.Errorf("%s must be at most %s", a.AttributeName, a.format(*a.MaxValue))
+		}
+		return strconv.FormatFloat(number, 'f', -1, 64), &number, nil
+
+	case TypeBoolean:
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			return "", nil, fmt.Errorf("%s must be true or false", a.AttributeName)
+		}
+		return strconv.FormatBool(value), nil, nil
+
+	case TypeEnum:
+		for _, allowed := range a.AllowedValues {
+			if strings.EqualFold(allowed, raw) {
+				return allowed, nil, nil
+			}
+		}
+		return "", nil, fmt.Errorf("%s must be one of: %s", a.AttributeName, strings.Join(a.AllowedValues, ", "))
+	}
+
+	if len(raw) > 255 {
+		return "", nil, fmt.Errorf("%s cannot be longer than 255 characters", a.AttributeName)
+	}
+	return raw, nil, nil
+}
+
+// format writes a bound with the attribute's unit
+func (a *Attribute) format(value float64) string {
+	formatted := strconv.FormatFloat(value, 'f', -1, 64)
+	if a.Unit != "" {
+		formatted += a.Unit
+	}
+	return formatted
+}
@@ -0,0 +1,107 @@
+package models
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ValueInput is a value submitted for one attribute of an artwork
+type ValueInput struct {
+	AttributeID string `json:"id"`
+	Value       string `json:"value"`
+}
+
+// ValidationError lists every problem found with submitted attribute values
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// ResolveValues checks submitted values against their attribute definitions
+// and the attributes required for the artwork type, and returns the rows to
+// store for the artwork. Problems with the values are returned together as a
+// *ValidationError.
+func ResolveValues(db *gorm.DB, artworkType string, inputs []ValueInput) ([]ArtworkAttribute, error) {
+	// Attributes are a short admin-defined list, so load them all to check
+	// the required ones too
+	var definitions []Attribute
+	if err := db.Find(&definitions).Error; err != nil {
+		return nil, fmt.Errorf("failed to load attributes: %w", err)
+	}
+	byID := make(map[uuid.UUID]*Attribute, len(definitions))
+	for i := range definitions {
+		byID[definitions[i].ID] = &definitions[i]
+	}
+
+	var problems []string
+	rows := make([]ArtworkAttribute, 0, len(inputs))
+	seen := make(map[uuid.UUID]bool, len(inputs))
+	for _, input := range inputs {
+		id, err := uuid.Parse(input.AttributeID)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("invalid attribute ID format: %s", input.AttributeID))
+			continue
+		}
+		attribute, ok := byID[id]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("attribute %s does not exist", input.AttributeID))
+			continue
+		}
+		if seen[id] {
+			problems = append(problems, fmt.Sprintf("%s is set more than once", attribute.AttributeName))
+			continue
+		}
+		seen[id] = true
+
+		value, number, err := attribute.Normalize(input.Value)
+		if err != nil {
+			problems = append(problems, err.Error())
+			continue
+		}
+		rows = append(rows, ArtworkAttribute{
+			AttributeID:  id,
+			Value:        value,
+			NumericValue: number,
+		})
+	}
+
+	var missing []string
+	for _, attribute := range definitions {
+		if attribute.IsRequiredFor(artworkType) && !seen[attribute.ID] {
+			missing = append(missing, attribute.AttributeName)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		problems = append(problems, fmt.Sprintf("%s artworks require: %s", artworkType, strings.Join(missing, ", ")))
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
+	return rows, nil
+}
+
+// ReplaceValues swaps the artwork's attribute values for the resolved rows
+func ReplaceValues(tx *gorm.DB, artworkID uuid.UUID, rows []ArtworkAttribute) error {
+	if err := tx.Where("artwork_id = ?", artworkID).Delete(&ArtworkAttribute{}).Error; err != nil {
+		return fmt.Errorf("failed to clear artwork attributes: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	for i := range rows {
+		rows[i].ArtworkID = artworkID
+	}
+	if err := tx.Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to save artwork attributes: %w", err)
+	}
+	return nil
+}
@@ -10,13 +10,16 @@ import (
 )
 
 type Category struct {
-	ID           uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
-	CategoryName string    `gorm:"type:varchar(255);not null" json:"category_name"`
-	Slug         string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"slug"`
-	Description  string    `gorm:"type:text" json:"description"`
-	IsActive     bool      `gorm:"type:boolean;not null;default:true" json:"is_active"`
-	CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	ID           uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	CategoryName string     `gorm:"type:varchar(255);not null" json:"category_name"`
+	Slug         string     `gorm:"type:varchar(255);not null;uniqueIndex" json:"slug"`
+	Description  string     `gorm:"type:text" json:"description"`
+	ParentID     *uuid.UUID `gorm:"type:char(36);index" json:"parent_id"`
+	Path         string     `gorm:"type:varchar(760);not null;default:'';index" json:"path"` // IDs from the root down, as /root/.../id/
+	Depth        int        `gorm:"type:int;not null;default:0" json:"depth"`
+	IsActive     bool       `gorm:"type:boolean;not null;default:true" json:"is_active"`
+	CreatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
 // BeforeCreate hook to generate default values
@@ -25,6 +28,13 @@ func (c *Category) BeforeCreate(tx *gorm.DB) (err error) {
 		c.ID = uuid.New()
 	}
 
+	// Place the category under its parent
+	if c.Path == "" {
+		if err := c.placeUnder(tx); err != nil {
+			return err
+		}
+	}
+
 	// Generate slug from category name if empty
 	if c.Slug == "" {
 		c.Slug = slug.Make(c.CategoryName)
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxCategoryDepth bounds how deep categories nest. It keeps paths well
+// within their column.
+const MaxCategoryDepth = 10
+
+var (
+	// ErrParentNotFound is returned when the requested parent category does not exist
+	ErrParentNotFound = errors.New("parent category not found")
+	// ErrCategoryCycle is returned when a category would be moved under itself
+	// or one of its descendants
+	ErrCategoryCycle = errors.New("a category cannot be moved under itself or its descendants")
+	// ErrCategoryTooDeep is returned when a category would nest deeper than MaxCategoryDepth
+	ErrCategoryTooDeep = fmt.Errorf("categories cannot be nested more than %d levels deep", MaxCategoryDepth+1)
+)
+
+// pathFor returns the path of a category with the given ID under the parent
+func pathFor(parent *Category, id uuid.UUID) string {
+	if parent == nil {
+		return "/" + id.String() + "/"
+	}
+	return parent.Path + id.String() + "/"
+}
+
+// placeUnder sets the path and depth of a new category from its parent
+func (c *Category) placeUnder(tx *gorm.DB) error {
+	if c.ParentID == nil {
+		c.Path = pathFor(nil, c.ID)
+		c.Depth = 0
+		return nil
+	}
+
+	var parent Category
+	if err := tx.Select("id, path, depth").Where("id = ?", *c.ParentID).First(&parent).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrParentNotFound
+		}
+		return fmt.Errorf("failed to load parent category: %w", err)
+	}
+	if parent.Depth+1 > MaxCategoryDepth {
+		return ErrCategoryTooDeep
+	}
+	c.Path = pathFor(&parent, c.ID)
+	c.Depth = parent.Depth + 1
+	return nil
+}
+
+// AncestorIDs returns the IDs of the category's ancestors, root first
+func (c *Category) AncestorIDs() []uuid.UUID {
+	var ids []uuid.UUID
+	for _, part := range strings.Split(strings.Trim(c.Path, "/"), "/") {
+		if id, err := uuid.Parse(part); err == nil && id != c.ID {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// Subtree selects the category and all of its descendants
+func Subtree(db *gorm.DB, c *Category) *gorm.DB {
+	return db.Model(&Category{}).Where("path LIKE ?", c.Path+"%")
+}
+
+// SubtreeIDs returns the IDs of the category and all of its descendants
+func SubtreeIDs(db *gorm.DB, c *Category) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := Subtree(db, c).Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to load subcategories: %w", err)
+	}
+	return ids, nil
+}
+
+// Breadcrumb returns the category's ancestors followed by the category itself
+func Breadcrumb(db *gorm.DB, c *Category) ([]Category, error) {
+	ancestorIDs := c.AncestorIDs()
+	if len(ancestorIDs) == 0 {
+		return []Category{*c}, nil
+	}
+
+	var ancestors []Category
+	if err := db.Where("id IN ?", ancestorIDs).Order("depth").Find(&ancestors).Error; err != nil {
+		return nil, fmt.Errorf("failed to load parent categories: %w", err)
+	}
+	return append(ancestors, *c), nil
+}
+
+// LockForMove loads the category for a move or delete, locking its row so
+// concurrent moves cannot combine into a cycle
+func LockForMove(tx *gorm.DB, id interface{}) (*Category, error) {
+	var category Category
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// Move places the category and its whole subtree under a new parent, or at
+// the root when parent is nil. The parent must be locked with LockForMove in
+// the same transaction.
+func Move(tx *gorm.DB, c *Category, parent *Category) error {
+	if parent != nil && strings.HasPrefix(parent.Path, c.Path) {
+		return ErrCategoryCycle
+	}
+
+	newDepth := 0
+	var parentID *uuid.UUID
+	if parent != nil {
+		newDepth = parent.Depth + 1
+		parentID = &parent.ID
+	}
+	delta := newDepth - c.Depth
+
+	var deepest int
+	if err := Subtree(tx, c).Select("COALESCE(MAX(depth), 0)").Scan(&deepest).Error; err != nil {
+		return fmt.Errorf("failed to measure category subtree: %w", err)
+	}
+	if deepest+delta > MaxCategoryDepth {
+		return ErrCategoryTooDeep
+	}
+
+	// Rewrite the path prefix of every category in the subtree at once
+	newPath := pathFor(parent, c.ID)
+	if err := tx.Exec("UPDATE categories SET path = CONCAT(?, SUBSTRING(path, ?)), depth = depth + ? WHERE path LIKE ?",
+		newPath, len(c.Path)+1, delta, c.Path+"%").Error; err != nil {
+		return fmt.Errorf("failed to move category subtree: %w", err)
+	}
+	if err := tx.Model(&Category{}).Where("id = ?", c.ID).Update("parent_id", parentID).Error; err != nil {
+		return fmt.Errorf("failed to move category: %w", err)
+	}
+
+	c.ParentID = parentID
+	c.Path = newPath
+	c.Depth = newDepth
+	return nil
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	organizations "github.com/muga20/artsMarket/modules/organizations/models"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"gorm.io/gorm"
+)
+
+// CollaboratorRole is the access a collaborator has to a shared collection.
+// The user who created the collection is always an owner.
+type CollaboratorRole string
+
+const (
+	RoleViewer CollaboratorRole = "viewer"
+	RoleEditor CollaboratorRole = "editor"
+	RoleOwner  CollaboratorRole = "owner"
+)
+
+// Invitation statuses stored in CollectionCollaborator.Status
+const (
+	InvitationPending  = "pending"
+	InvitationAccepted = "accepted"
+	InvitationDeclined = "declined"
+)
+
+var (
+	// ErrCollectionNotFound is returned when the collection does not exist
+	// or the user has no access to it at all
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrInsufficientRole is returned when the user collaborates on the
+	// collection with a role below the one required
+	ErrInsufficientRole = errors.New("insufficient collection role")
+)
+
+// Valid reports whether r is a known role
+func (r CollaboratorRole) Valid() bool {
+	return r == RoleViewer || r == RoleEditor || r == RoleOwner
+}
+
+// Allows reports whether r grants at least the access of need
+func (r CollaboratorRole) Allows(need CollaboratorRole) bool {
+	return r.rank() >= need.rank()
+}
+
+func (r CollaboratorRole) rank() int {
+	switch r {
+	case RoleOwner:
+		return 3
+	case RoleEditor:
+		return 2
+	case RoleViewer:
+		return 1
+	}
+	return 0
+}
+
+// CollectionCollaborator is a user invited to work on someone else's
+// collection. The role only applies once the invitation is accepted.
+type CollectionCollaborator struct {
+	ID           uuid.UUID        `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	CollectionID uuid.UUID        `gorm:"type:char(36);not null;uniqueIndex:idx_collection_collaborator" json:"collection_id"`
+	UserID       uuid.UUID        `gorm:"type:char(36);not null;uniqueIndex:idx_collection_collaborator;index" json:"user_id"`
+	Role         CollaboratorRole `gorm:"type:enum('owner','editor','viewer');not null;default:'viewer'" json:"role"`
+	Status       string           `gorm:"type:enum('pending','accepted','declined');not null;default:'pending';index" json:"status"`
+	InvitedBy    uuid.UUID        `gorm:"type:char(36);not null" json:"invited_by"`
+	RespondedAt  *time.Time       `gorm:"type:timestamp" json:"responded_at,omitempty"`
+	CreatedAt    time.Time        `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time        `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	Collection Collection  `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE" json:"-"`
+	User       models.User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+	Inviter    models.User `gorm:"foreignKey:InvitedBy;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// CollectionArtistGrant records that an artist allows the editors of a
+// collection to add their artworks to it
+type CollectionArtistGrant struct {
+	ID           uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	CollectionID uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_collection_artist" json:"collection_id"`
+	ArtistID     uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_collection_artist;index" json:"artist_id"`
+	CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Collection Collection  `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE" json:"-"`
+	Artist     models.User `gorm:"foreignKey:ArtistID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID and default status
+func (cc *CollectionCollaborator) BeforeCreate(tx *gorm.DB) (err error) {
+	if cc.ID == uuid.Nil {
+		cc.ID = uuid.New()
+	}
+	if cc.Status == "" {
+		cc.Status = InvitationPending
+	}
+	return
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (g *CollectionArtistGrant) BeforeCreate(tx *gorm.DB) (err error) {
+	if g.ID == uuid.Nil {
+		g.ID = uuid.New()
+	}
+	return
+}
+
+// RoleOf returns the role userID holds on the collection, or an empty role
+// when the user neither created it, accepted an invitation to it nor
+// belongs to the organization it is shared with
+func RoleOf(db *gorm.DB, collection *Collection, userID uuid.UUID) (CollaboratorRole, error) {
+	if collection.UserID == userID {
+		return RoleOwner, nil
+	}
+
+	var role CollaboratorRole
+	if collection.OrganizationID != nil {
+		memberRole, err := organizations.RoleOf(db, *collection.OrganizationID, userID)
+		if err != nil {
+			return "", err
+		}
+		switch memberRole {
+		case organizations.RoleAdmin:
+			return RoleOwner, nil
+		case organizations.RoleStaff:
+			role = RoleEditor
+		}
+	}
+
+	var roles []CollaboratorRole
+	if err := db.Model(&CollectionCollaborator{}).
+		Where("collection_id = ? AND user_id = ? AND status = ?", collection.ID, userID, InvitationAccepted).
+		Limit(1).
+		Pluck("role", &roles).Error; err != nil {
+		return "", err
+	}
+	if len(roles) > 0 && roles[0].Allows(role) {
+		role = roles[0]
+	}
+	return role, nil
+}
+
+// Authorize loads the collection and checks that userID holds at least the
+// need role on it. Users without any access get ErrCollectionNotFound so
+// private collections are not revealed.
+func Authorize(db *gorm.DB, collectionID interface{}, userID uuid.UUID, need CollaboratorRole) (*Collection, CollaboratorRole, error) {
+	var collection Collection
+	if err := db.Where("id = ?", collectionID).First(&collection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrCollectionNotFound
+		}
+		return nil, "", err
+	}
+
+	role, err := RoleOf(db, &collection, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if role == "" {
+		return nil, "", ErrCollectionNotFound
+	}
+	if !role.Allows(need) {
+		return &collection, role, ErrInsufficientRole
+	}
+	return &collection, role, nil
+}
+
+// MayInclude reports whether actorID may place artworks by artistID in the
+// collection: artists can always curate their own work, anyone else needs
+// the artist's grant
+func MayInclude(db *gorm.DB, collectionID, artistID, actorID uuid.UUID) (bool, error) {
+	if artistID == actorID {
+		return true, nil
+	}
+
+	var count int64
+	err := db.Model(&CollectionArtistGrant{}).
+		Where("collection_id = ? AND artist_id = ?", collectionID, artistID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gosimple/slug"
+	organizations "github.com/muga20/artsMarket/modules/organizations/models"
 	"github.com/muga20/artsMarket/modules/users/models"
 	"gorm.io/gorm"
 )
@@ -29,8 +30,12 @@ type Collection struct {
 	UpdatedAt       time.Time        `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 	CoverImageURL   string           `gorm:"type:varchar(255)" json:"cover_image_url"`
 	PrimaryImageURL string           `gorm:"type:varchar(255)" json:"primary_image_url"`
+	// OrganizationID shares the collection with an organization's members:
+	// admins manage it like its creator and staff edit it
+	OrganizationID *uuid.UUID `gorm:"type:char(36);index" json:"organization_id"`
 
-	User models.User `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	User         models.User                 `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Organization *organizations.Organization `gorm:"foreignKey:OrganizationID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL" json:"-"`
 }
 
 type CreateCollectionRequest struct {
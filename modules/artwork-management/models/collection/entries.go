@@ -0,0 +1,74 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxEntries bounds the size of a curated collection
+const MaxEntries = 500
+
+// CollectionEntry places an artwork in a collection. An artwork can appear
+// in any number of collections; within one collection entries are laid out
+// by Position, and consecutive entries sharing a Section form one section
+// under that heading.
+type CollectionEntry struct {
+	ID           uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	CollectionID uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_collection_entry_artwork;index:idx_collection_entry_position,priority:1" json:"collection_id"`
+	ArtworkID    uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_collection_entry_artwork;index" json:"artwork_id"`
+	Position     int        `gorm:"type:int;not null;default:0;index:idx_collection_entry_position,priority:2" json:"position"`
+	Section      string     `gorm:"type:varchar(255)" json:"section"`
+	Notes        string     `gorm:"type:text" json:"notes"` // Curator notes shown alongside the artwork
+	AddedBy      *uuid.UUID `gorm:"type:char(36)" json:"added_by"`
+	CreatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	Collection Collection `gorm:"foreignKey:CollectionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (e *CollectionEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return
+}
+
+// NextPosition returns the position after the last entry of the collection
+func NextPosition(db *gorm.DB, collectionID uuid.UUID) (int, error) {
+	var last int
+	err := db.Model(&CollectionEntry{}).
+		Where("collection_id = ?", collectionID).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&last).Error
+	return last + 1, err
+}
+
+// Renumber rewrites the positions of the collection's entries as 1..n,
+// keeping their current order
+func Renumber(tx *gorm.DB, collectionID uuid.UUID) error {
+	var ids []uuid.UUID
+	if err := tx.Model(&CollectionEntry{}).
+		Where("collection_id = ?", collectionID).
+		Order("position ASC, created_at ASC").
+		Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+	for i, id := range ids {
+		if err := tx.Model(&CollectionEntry{}).
+			Where("id = ? AND position <> ?", id, i+1).
+			Update("position", i+1).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CountEntries returns how many artworks the collection holds
+func CountEntries(db *gorm.DB, collectionID uuid.UUID) (int64, error) {
+	var count int64
+	err := db.Model(&CollectionEntry{}).Where("collection_id = ?", collectionID).Count(&count).Error
+	return count, err
+}
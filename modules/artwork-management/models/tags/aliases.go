@@ -0,0 +1,183 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrTagNameTaken is returned when a name normalizes to the key of another tag or alias
+	ErrTagNameTaken = errors.New("a tag or alias with this name already exists")
+	// ErrEmptyTagName is returned for names without any letters or digits
+	ErrEmptyTagName = errors.New("tag names need at least one letter or digit")
+	// ErrMergeIntoSelf is returned when a tag would be merged into itself
+	ErrMergeIntoSelf = errors.New("a tag cannot be merged into itself")
+)
+
+// TagAlias maps another spelling or synonym of a tag to it, such as
+// "oils" to "oil painting"
+type TagAlias struct {
+	ID            uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	TagID         uuid.UUID `gorm:"type:char(36);not null;index" json:"tag_id"`
+	Alias         string    `gorm:"type:varchar(255);not null" json:"alias"`
+	NormalizedKey string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"normalized_key"`
+	CreatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Tag Tag `gorm:"foreignKey:TagID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID and normalized key if not set
+func (a *TagAlias) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	if a.NormalizedKey == "" {
+		a.NormalizedKey = NormalizeTag(a.Alias)
+	}
+	return
+}
+
+// NormalizeTag reduces a tag name to its letters and digits in lower case,
+// so "Oil Painting", "oil-painting" and "oilpainting" share one key
+func NormalizeTag(name string) string {
+	var key strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			key.WriteRune(r)
+		}
+	}
+	return key.String()
+}
+
+// Resolve finds the tag a name refers to, through its normalized key or an
+// alias. It returns gorm.ErrRecordNotFound when no tag matches.
+func Resolve(db *gorm.DB, name string) (*Tag, error) {
+	key := NormalizeTag(name)
+	if key == "" {
+		return nil, ErrEmptyTagName
+	}
+
+	var tag Tag
+	err := db.Where("normalized_key = ?", key).
+		Or("id = (?)", db.Model(&TagAlias{}).Select("tag_id").Where("normalized_key = ?", key)).
+		First(&tag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// KeyTaken reports whether the name's key is used by a tag other than
+// except, or by an alias of any tag
+func KeyTaken(db *gorm.DB, key string, except uuid.UUID) (bool, error) {
+	var tags, aliases int64
+	if err := db.Model(&Tag{}).Where("normalized_key = ? AND id != ?", key, except).Count(&tags).Error; err != nil {
+		return false, fmt.Errorf("failed to check tag names: %w", err)
+	}
+	if err := db.Model(&TagAlias{}).Where("normalized_key = ?", key).Count(&aliases).Error; err != nil {
+		return false, fmt.Errorf("failed to check tag aliases: %w", err)
+	}
+	return tags+aliases > 0, nil
+}
+
+// AddAlias maps another name to the tag
+func AddAlias(tx *gorm.DB, tagID uuid.UUID, name string) (*TagAlias, error) {
+	key := NormalizeTag(name)
+	if key == "" {
+		return nil, ErrEmptyTagName
+	}
+	taken, err := KeyTaken(tx, key, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrTagNameTaken
+	}
+
+	alias := TagAlias{TagID: tagID, Alias: strings.TrimSpace(name), NormalizedKey: key}
+	if err := tx.Create(&alias).Error; err != nil {
+		return nil, fmt.Errorf("failed to create tag alias: %w", err)
+	}
+	return &alias, nil
+}
+
+// Merge folds the source tag into the target: artworks tagged with the
+// source are tagged with the target instead, and the source's name and
+// aliases become aliases of the target. Run it in a transaction.
+func Merge(tx *gorm.DB, sourceID, targetID uuid.UUID) (moved int64, err error) {
+	if sourceID == targetID {
+		return 0, ErrMergeIntoSelf
+	}
+
+	// Lock both tags, in a fixed order so concurrent merges cannot deadlock
+	var locked []Tag
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", []uuid.UUID{sourceID, targetID}).
+		Order("id").
+		Find(&locked).Error; err != nil {
+		return 0, fmt.Errorf("failed to lock tags: %w", err)
+	}
+	var source *Tag
+	for i := range locked {
+		if locked[i].ID == sourceID {
+			source = &locked[i]
+		}
+	}
+	if len(locked) != 2 || source == nil {
+		return 0, gorm.ErrRecordNotFound
+	}
+
+	// Artworks carrying both tags keep only the target's row. MySQL cannot
+	// delete from a table it reads in a subquery, so the IDs are loaded first.
+	var bothTagged []uuid.UUID
+	if err := tx.Model(&ArtworkTag{}).
+		Where("tag_id = ? AND artwork_id IN (?)", sourceID,
+			tx.Model(&ArtworkTag{}).Select("artwork_id").Where("tag_id = ?", targetID)).
+		Pluck("artwork_id", &bothTagged).Error; err != nil {
+		return 0, fmt.Errorf("failed to find duplicate artwork tags: %w", err)
+	}
+	if len(bothTagged) > 0 {
+		if err := tx.Where("tag_id = ? AND artwork_id IN ?", sourceID, bothTagged).
+			Delete(&ArtworkTag{}).Error; err != nil {
+			return 0, fmt.Errorf("failed to remove duplicate artwork tags: %w", err)
+		}
+	}
+	result := tx.Model(&ArtworkTag{}).Where("tag_id = ?", sourceID).
+		Updates(map[string]interface{}{"tag_id": targetID, "updated_at": time.Now()})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to move artwork tags: %w", result.Error)
+	}
+
+	if err := tx.Model(&TagAlias{}).Where("tag_id = ?", sourceID).Update("tag_id", targetID).Error; err != nil {
+		return 0, fmt.Errorf("failed to move tag aliases: %w", err)
+	}
+	if err := tx.Delete(&Tag{}, "id = ?", sourceID).Error; err != nil {
+		return 0, fmt.Errorf("failed to delete merged tag: %w", err)
+	}
+
+	// The merged name keeps resolving, now to the target
+	key := NormalizeTag(source.TagName)
+	if key != "" {
+		var target Tag
+		if err := tx.Where("id = ?", targetID).First(&target).Error; err != nil {
+			return 0, fmt.Errorf("failed to load target tag: %w", err)
+		}
+		if target.NormalizedKey == nil || *target.NormalizedKey != key {
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&TagAlias{
+				TagID:         targetID,
+				Alias:         source.TagName,
+				NormalizedKey: key,
+			}).Error; err != nil {
+				return 0, fmt.Errorf("failed to keep merged tag name: %w", err)
+			}
+		}
+	}
+	return result.RowsAffected, nil
+}
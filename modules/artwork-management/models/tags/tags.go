@@ -7,19 +7,36 @@ import (
 	"gorm.io/gorm"
 )
 
+// Tag moderation statuses stored in Tag.Status
+const (
+	TagStatusApproved = "approved"
+	TagStatusPending  = "pending"  // Created by an artist while tagging an artwork, awaiting review
+	TagStatusRejected = "rejected" // Removed by a moderator; the name cannot be used again
+)
+
 type Tag struct {
-	ID       uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
-	TagName  string    `gorm:"type:varchar(255);unique;not null;column:tag_name" json:"tag_name"`
-	IsActive bool      `gorm:"type:boolean;not null;default:true" json:"is_active"`
+	ID            uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	TagName       string     `gorm:"type:varchar(255);unique;not null;column:tag_name" json:"tag_name"`
+	NormalizedKey *string    `gorm:"type:varchar(255);uniqueIndex" json:"normalized_key"` // Name reduced by NormalizeTag, so near-duplicates collide
+	Status        string     `gorm:"type:enum('approved','pending','rejected');not null;default:'approved';index" json:"status"`
+	CreatedBy     *uuid.UUID `gorm:"type:char(36);index" json:"created_by,omitempty"` // Artist who created the tag inline, if any
+	IsActive      bool       `gorm:"type:boolean;not null;default:true" json:"is_active"`
 
 	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
 	UpdatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
 }
 
-// BeforeCreate hook to generate UUID if not set
+// BeforeCreate hook to generate UUID and normalized key if not set
 func (t *Tag) BeforeCreate(tx *gorm.DB) (err error) {
 	if t.ID == uuid.Nil {
 		t.ID = uuid.New()
 	}
+	if t.NormalizedKey == nil {
+		key := NormalizeTag(t.TagName)
+		t.NormalizedKey = &key
+	}
+	if t.Status == "" {
+		t.Status = TagStatusApproved
+	}
 	return
 }
@@ -50,11 +50,11 @@ func (r *artworkRepository) GetByID(id uuid.UUID) (*models.Artwork, error) {
 	var artwork models.Artwork
 	err := r.db.
 		Preload("User").
-		Preload("Collection").
 		Preload("Images").
 		Preload("Medium").
 		Preload("Technique").
 		Preload("Editions").
+		Preload("Organization").
 		First(&artwork, "id = ?", id).Error
 
 	if err != nil {
@@ -67,11 +67,11 @@ func (r *artworkRepository) GetBySlug(slug string) (*models.Artwork, error) {
 	var artwork models.Artwork
 	err := r.db.
 		Preload("User").
-		Preload("Collection").
 		Preload("Images").
 		Preload("Medium").
 		Preload("Technique").
 		Preload("Editions").
+		Preload("Organization").
 		First(&artwork, "slug = ?", slug).Error
 
 	if err != nil {
@@ -84,7 +84,6 @@ func (r *artworkRepository) GetByUserID(userID uuid.UUID) ([]models.Artwork, err
 	var artworks []models.Artwork
 	err := r.db.
 		Preload("User").
-		Preload("Collection").
 		Preload("Images").
 		Preload("Medium").
 		Preload("Technique").
@@ -5,6 +5,8 @@ import (
 	"github.com/muga20/artsMarket/config"
 	"github.com/muga20/artsMarket/modules/artwork-management/handlers/artworks"
 	"github.com/muga20/artsMarket/modules/artwork-management/repository"
+	"github.com/muga20/artsMarket/modules/users/accesstokens"
+	"github.com/muga20/artsMarket/modules/users/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"github.com/muga20/artsMarket/pkg/middleware"
 	"gorm.io/gorm"
@@ -14,12 +16,24 @@ import (
 func ArtWorksRoutes(apiGroup fiber.Router, db *gorm.DB, cld *config.CloudinaryClient, responseHandler *handlers.ResponseHandler) {
 	artWork := apiGroup.Group("/artworks")
 
-	// Apply the AuthMiddleware to all routes under /artworks
-	artWork.Use(middleware.AuthMiddleware(db, responseHandler))
+	// Artwork routes authenticate individually so integrations can use
+	// personal access tokens carrying the matching scope
+	canRead := middleware.AuthMiddleware(db, responseHandler, accesstokens.ScopeArtworksRead)
+	canWrite := middleware.AuthMiddleware(db, responseHandler, accesstokens.ScopeArtworksWrite)
+	// Publishing and pricing artworks requires a verified email
+	requireVerified := middleware.RequireVerifiedEmail(db, responseHandler)
 
 	// Initialize repository
 	artworkRepo := repository.NewArtworkRepository(db)
 
 	// Artwork Management Endpoints
-	artWork.Post("/", artworks.CreateArtworkHandler(db, cld, responseHandler, artworkRepo))
+	artWork.Post("/", canWrite, requireVerified, artworks.CreateArtworkHandler(db, cld, responseHandler, artworkRepo))
+	artWork.Get("/search", canRead, artworks.SearchArtworksHandler(db, responseHandler))
+	artWork.Get("/:identifier", canRead, artworks.GetArtworkHandler(db, responseHandler, artworkRepo))
+	artWork.Put("/:id/price", canWrite, requireVerified, artworks.UpdateArtworkPriceHandler(db, responseHandler))
+	artWork.Put("/:id/attributes", canWrite, requireVerified, artworks.UpdateArtworkAttributesHandler(db, responseHandler))
+	artWork.Put("/:id/dimensions", canWrite, requireVerified, artworks.UpdateArtworkDimensionsHandler(db, responseHandler))
+
+	// Moderation
+	artWork.Put("/:id/status/:status", canWrite, middleware.RequireRole(db, responseHandler, models.AdminRoleName), artworks.UpdateArtworkStatusHandler(db, responseHandler))
 }
@@ -14,6 +14,9 @@ func SetupCategoryRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *ha
 	categoryGroup.Use(middleware.AuthMiddleware(db, responseHandler))
 
 	categoryGroup.Post("/", category.CreateCategoryHandler(db, responseHandler))
+	categoryGroup.Get("/tree", category.GetCategoryTreeHandler(db, responseHandler))
+	categoryGroup.Put("/:id/move", category.MoveCategoryHandler(db, responseHandler))
+	categoryGroup.Get("/:identifier/artworks", category.GetCategoryArtworksHandler(db, responseHandler))
 	categoryGroup.Put("/:id", category.UpdateCategoryHandler(db, responseHandler))
 	categoryGroup.Put("/:id/toggle-active", category.ToggleCategoryActiveHandler(db, responseHandler))
 	categoryGroup.Delete("/:id", category.DeleteCategoryHandler(db, responseHandler))
@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/muga20/artsMarket/config"
 	"github.com/muga20/artsMarket/modules/artwork-management/handlers/collection"
+	"github.com/muga20/artsMarket/modules/users/accesstokens"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"github.com/muga20/artsMarket/pkg/middleware"
 	"gorm.io/gorm"
@@ -12,19 +13,42 @@ import (
 // SetupCollectionRoutes sets up collection-related routes
 func SetupCollectionRoutes(apiGroup fiber.Router, db *gorm.DB, cld *config.CloudinaryClient, responseHandler *handlers.ResponseHandler) {
 	collectionGroup := apiGroup.Group("/collections")
-	collectionGroup.Use(middleware.AuthMiddleware(db, responseHandler))
+
+	// Collection routes authenticate individually so integrations can use
+	// personal access tokens carrying the matching scope
+	canRead := middleware.AuthMiddleware(db, responseHandler, accesstokens.ScopeCollectionsRead)
+	canWrite := middleware.AuthMiddleware(db, responseHandler, accesstokens.ScopeCollectionsWrite)
+	// Published collections are public; signed-in collaborators also see drafts
+	maybeRead := middleware.OptionalAuthMiddleware(db, responseHandler, accesstokens.ScopeCollectionsRead)
+
+	// Invitations to other users' collections, registered before "/:id"
+	collectionGroup.Get("/invitations", canRead, collection.ListCollectionInvitationsHandler(db, responseHandler))
 
 	// Basic CRUD operations
-	collectionGroup.Post("/", collection.CreateCollectionHandler(db, responseHandler))
-	collectionGroup.Put("/:id", collection.UpdateCollectionHandler(db, responseHandler))
-	collectionGroup.Delete("/:id", collection.DeleteCollectionHandler(db, responseHandler))
-	collectionGroup.Get("/:id", collection.GetCollectionByIDHandler(db, responseHandler))
-	collectionGroup.Get("/", collection.GetAllCollectionsHandler(db, responseHandler))
+	collectionGroup.Post("/", canWrite, collection.CreateCollectionHandler(db, responseHandler))
+	collectionGroup.Put("/:id", canWrite, collection.UpdateCollectionHandler(db, responseHandler))
+	collectionGroup.Delete("/:id", canWrite, collection.DeleteCollectionHandler(db, responseHandler))
+	collectionGroup.Get("/:identifier", maybeRead, collection.GetCollectionByIDHandler(db, responseHandler))
+	collectionGroup.Get("/", canRead, collection.GetAllCollectionsHandler(db, responseHandler))
 
-	// Status management
-	collectionGroup.Put("/:id/status/:status", collection.UpdateCollectionStatusHandler(db, responseHandler))
+	// Status management; only verified accounts may publish
+	collectionGroup.Put("/:id/status/:status", canWrite, middleware.RequireVerifiedEmail(db, responseHandler), collection.UpdateCollectionStatusHandler(db, responseHandler))
 
 	// Image uploads
-	collectionGroup.Put("/:id/images", collection.UpdateCollectionImagesHandler(db, cld, responseHandler))
-	collectionGroup.Delete("/:id/images", collection.RemoveCollectionImageHandler(db, cld, responseHandler))
+	collectionGroup.Put("/:id/images", canWrite, collection.UpdateCollectionImagesHandler(db, cld, responseHandler))
+	collectionGroup.Delete("/:id/images", canWrite, collection.RemoveCollectionImageHandler(db, cld, responseHandler))
+
+	// Collaborators and invitations
+	collectionGroup.Get("/:id/collaborators", canRead, collection.ListCollaboratorsHandler(db, responseHandler))
+	collectionGroup.Post("/:id/collaborators", canWrite, collection.InviteCollaboratorHandler(db, responseHandler))
+	collectionGroup.Put("/:id/collaborators/:userId", canWrite, collection.UpdateCollaboratorHandler(db, responseHandler))
+	collectionGroup.Delete("/:id/collaborators/:userId", canWrite, collection.RemoveCollaboratorHandler(db, responseHandler))
+	collectionGroup.Put("/:id/invitation/:action", canWrite, collection.RespondToInvitationHandler(db, responseHandler))
+
+	// Curated layout and shared curation
+	collectionGroup.Post("/:id/artworks", canWrite, collection.AddCollectionArtworksHandler(db, responseHandler))
+	collectionGroup.Patch("/:id/entries", canWrite, collection.UpdateCollectionEntriesHandler(db, responseHandler))
+	collectionGroup.Delete("/:id/artworks/:artworkId", canWrite, collection.RemoveCollectionArtworkHandler(db, responseHandler))
+	collectionGroup.Put("/:id/artist-permission", canWrite, collection.GrantArtistPermissionHandler(db, responseHandler))
+	collectionGroup.Delete("/:id/artist-permission", canWrite, collection.RevokeArtistPermissionHandler(db, responseHandler))
 }
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/artwork-management/handlers/dimensions"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/middleware"
+	"gorm.io/gorm"
+)
+
+// SetupDimensionRoutes sets up the admin routes for migrating legacy artwork dimensions
+func SetupDimensionRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *handlers.ResponseHandler) {
+	migrationGroup := apiGroup.Group("/admin/artworks/dimension-migrations")
+	migrationGroup.Use(middleware.AuthMiddleware(db, responseHandler))
+	migrationGroup.Use(middleware.RequireRole(db, responseHandler, models.AdminRoleName))
+
+	migrationGroup.Post("/", dimensions.StartDimensionMigrationHandler(db, responseHandler))
+	migrationGroup.Get("/", dimensions.ListDimensionMigrationsHandler(db, responseHandler))
+	migrationGroup.Get("/:id", dimensions.GetDimensionMigrationHandler(db, responseHandler))
+}
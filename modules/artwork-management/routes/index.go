@@ -18,4 +18,5 @@ func ArtsManagementSetupRoutes(apiGroup fiber.Router, db *gorm.DB, cld *config.C
 	SetupTechniqueRoutes(apiGroup, db, responseHandler)
 	SetupCollectionRoutes(apiGroup, db, cld, responseHandler)
 	ArtWorksRoutes(apiGroup, db, cld, responseHandler)
+	SetupDimensionRoutes(apiGroup, db, responseHandler)
 }
@@ -3,6 +3,7 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/muga20/artsMarket/modules/artwork-management/handlers/tags"
+	"github.com/muga20/artsMarket/modules/users/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"github.com/muga20/artsMarket/pkg/middleware"
 	"gorm.io/gorm"
@@ -14,6 +15,11 @@ func SetupTagRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *handler
 
 	// Apply the AuthMiddleware to all routes under /tags
 	tagGroup.Use(middleware.AuthMiddleware(db, responseHandler))
+	adminOnly := middleware.RequireRole(db, responseHandler, models.AdminRoleName)
+
+	// Lookups registered before /:id so their paths are not read as IDs
+	tagGroup.Get("/autocomplete", tags.AutocompleteTagsHandler(db, responseHandler))
+	tagGroup.Get("/duplicates", adminOnly, tags.ListDuplicateTagsHandler(db, responseHandler))
 
 	// Tag Management Endpoints
 	tagGroup.Post("/", tags.CreateTagHandler(db, responseHandler))
@@ -23,4 +29,10 @@ func SetupTagRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *handler
 	tagGroup.Get("/", tags.GetAllTagsHandler(db, responseHandler))
 	tagGroup.Delete("/:id", tags.DeleteTagHandler(db, responseHandler))
 
+	// Synonyms, merging and moderation
+	tagGroup.Get("/:id/aliases", tags.ListTagAliasesHandler(db, responseHandler))
+	tagGroup.Post("/:id/aliases", adminOnly, tags.AddTagAliasHandler(db, responseHandler))
+	tagGroup.Delete("/:id/aliases/:aliasId", adminOnly, tags.DeleteTagAliasHandler(db, responseHandler))
+	tagGroup.Post("/:id/merge", adminOnly, tags.MergeTagHandler(db, responseHandler))
+	tagGroup.Put("/:id/moderate/:action", adminOnly, tags.ModerateTagHandler(db, responseHandler))
 }
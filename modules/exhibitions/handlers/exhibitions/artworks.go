@@ -0,0 +1,259 @@
+package exhibitions
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	art "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	exhibitions "github.com/muga20/artsMarket/modules/exhibitions/models"
+	notifications "github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+const maxExhibitionArtworks = 300
+
+// HangingInput places one artwork in an exhibition
+type HangingInput struct {
+	ArtworkID uuid.UUID  `json:"artwork_id"`
+	RoomID    *uuid.UUID `json:"room_id"`
+	Label     string     `json:"label"`
+}
+
+// SetArtworksRequest is the complete, ordered list of an exhibition's artworks
+type SetArtworksRequest struct {
+	Artworks []HangingInput `json:"artworks"`
+}
+
+// SetExhibitionArtworks godoc
+// @Summary Set an exhibition's artworks
+// @Description Replaces the exhibition's artworks with the given ordered list, each optionally placed in a room with a wall label. Any artist's work can be included as long as it is publicly visible. Artists are notified when their work is first included.
+// @Tags Exhibitions
+// @Accept json
+// @Produce json
+// @Param id path string true "Exhibition ID"
+// @Param body body SetArtworksRequest true "Artworks in order"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /exhibitions/{id}/artworks [put]
+func SetExhibitionArtworks(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req SetArtworksRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		if len(req.Artworks) > maxExhibitionArtworks {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest,
+					fmt.Sprintf("An exhibition can hold at most %d artworks", maxExhibitionArtworks)))
+		}
+
+		artworkIDs := make([]uuid.UUID, len(req.Artworks))
+		seen := make(map[uuid.UUID]bool, len(req.Artworks))
+		for i, input := range req.Artworks {
+			if input.ArtworkID == uuid.Nil || seen[input.ArtworkID] {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Every artwork must be listed exactly once"))
+			}
+			seen[input.ArtworkID] = true
+			artworkIDs[i] = input.ArtworkID
+		}
+
+		exhibition, err := loadCuratedExhibition(db, c.Params("id"), user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if !exhibition.IsEditable() {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, "Archived exhibitions cannot be changed"))
+		}
+
+		var added int
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var roomIDs []uuid.UUID
+			if err := tx.Model(&exhibitions.ExhibitionRoom{}).
+				Where("exhibition_id = ?", exhibition.ID).
+				Pluck("id", &roomIDs).Error; err != nil {
+				return fmt.Errorf("failed to load exhibition rooms: %w", err)
+			}
+			rooms := make(map[uuid.UUID]bool, len(roomIDs))
+			for _, id := range roomIDs {
+				rooms[id] = true
+			}
+			for _, input := range req.Artworks {
+				if input.RoomID != nil && !rooms[*input.RoomID] {
+					return fiber.NewError(fiber.StatusBadRequest,
+						fmt.Sprintf("Room %s is not part of this exhibition", input.RoomID))
+				}
+			}
+
+			// Only work every visitor can see may be shown, and never work
+			// from an artist the curator has a block with
+			var artworks []art.Artwork
+			if len(artworkIDs) > 0 {
+				if err := tx.Where("id IN ?", artworkIDs).
+					Where("id IN (?)", art.VisibleTo(tx, uuid.Nil).Select("artworks.id")).
+					Where("id IN (?)", art.VisibleTo(tx, user.ID).Select("artworks.id")).
+					Find(&artworks).Error; err != nil {
+					return fmt.Errorf("failed to load artworks: %w", err)
+				}
+			}
+			if len(artworks) != len(artworkIDs) {
+				return fiber.NewError(fiber.StatusBadRequest,
+					"Some artworks were not found or are not publicly visible")
+			}
+			byID := make(map[uuid.UUID]*art.Artwork, len(artworks))
+			for i := range artworks {
+				byID[artworks[i].ID] = &artworks[i]
+			}
+
+			var existing []exhibitions.ExhibitionArtwork
+			if err := tx.Where("exhibition_id = ?", exhibition.ID).Find(&existing).Error; err != nil {
+				return fmt.Errorf("failed to load exhibition artworks: %w", err)
+			}
+			current := make(map[uuid.UUID]*exhibitions.ExhibitionArtwork, len(existing))
+			for i := range existing {
+				current[existing[i].ArtworkID] = &existing[i]
+			}
+
+			var removed []uuid.UUID
+			for _, item := range existing {
+				if !seen[item.ArtworkID] {
+					removed = append(removed, item.ID)
+				}
+			}
+			if len(removed) > 0 {
+				if err := tx.Where("id IN ?", removed).Delete(&exhibitions.ExhibitionArtwork{}).Error; err != nil {
+					return fmt.Errorf("failed to remove exhibition artworks: %w", err)
+				}
+			}
+
+			for position, input := range req.Artworks {
+				label := strings.TrimSpace(input.Label)
+				if item := current[input.ArtworkID]; item != nil {
+					if err := tx.Model(item).Updates(map[string]interface{}{
+						"room_id":  input.RoomID,
+						"position": position,
+						"label":    label,
+					}).Error; err != nil {
+						return fmt.Errorf("failed to update exhibition artwork: %w", err)
+					}
+					continue
+				}
+
+				item := exhibitions.ExhibitionArtwork{
+					ExhibitionID: exhibition.ID,
+					ArtworkID:    input.ArtworkID,
+					RoomID:       input.RoomID,
+					Position:     position,
+					Label:        label,
+				}
+				if err := tx.Create(&item).Error; err != nil {
+					return fmt.Errorf("failed to add exhibition artwork: %w", err)
+				}
+				added++
+
+				artwork := byID[input.ArtworkID]
+				if artwork.UserID == user.ID {
+					continue
+				}
+				message := fmt.Sprintf("%s included your artwork %q in the exhibition %q",
+					user.Username, artwork.Title, exhibition.Title)
+				if err := notifications.WriteNotificationFrom(tx, artwork.UserID, user.ID, "exhibition_artwork_added",
+					message, "exhibition", exhibition.ID); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		rooms, err := exhibitionLayout(db, exhibition.ID, user.ID, true, true)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": fmt.Sprintf("Exhibition artworks updated, %d added", added),
+			"rooms":   rooms,
+		}, nil)
+	}
+}
+
+// WithdrawExhibitionArtwork godoc
+// @Summary Remove an artwork from an exhibition
+// @Description The curator can remove any artwork. Artists can withdraw their own work from any exhibition that has not been archived.
+// @Tags Exhibitions
+// @Produce json
+// @Param id path string true "Exhibition ID"
+// @Param artworkId path string true "Artwork ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /exhibitions/{id}/artworks/{artworkId} [delete]
+func WithdrawExhibitionArtwork(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var item exhibitions.ExhibitionArtwork
+		if err := db.Preload("Exhibition").Preload("Artwork").
+			Where("exhibition_id = ? AND artwork_id = ?", c.Params("id"), c.Params("artworkId")).
+			First(&item).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Artwork is not part of this exhibition"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve exhibition artwork: %w", err))
+		}
+
+		isCurator := item.Exhibition.CuratorID == user.ID
+		if !isCurator && item.Artwork.UserID != user.ID {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Artwork is not part of this exhibition"))
+		}
+		if !item.Exhibition.IsEditable() {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, "Archived exhibitions cannot be changed"))
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&item).Error; err != nil {
+				return fmt.Errorf("failed to remove exhibition artwork: %w", err)
+			}
+			if isCurator {
+				return nil
+			}
+			message := fmt.Sprintf("%s withdrew %q from your exhibition %q",
+				user.Username, item.Artwork.Title, item.Exhibition.Title)
+			return notifications.WriteNotificationFrom(tx, item.Exhibition.CuratorID, user.ID, "exhibition_artwork_withdrawn",
+				message, "exhibition", item.ExhibitionID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Artwork removed from exhibition",
+		}, nil)
+	}
+}
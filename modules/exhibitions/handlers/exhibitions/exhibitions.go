@@ -0,0 +1,627 @@
+package exhibitions
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	artworkServices "github.com/muga20/artsMarket/modules/artwork-management/services"
+	exhibitions "github.com/muga20/artsMarket/modules/exhibitions/models"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/privacy"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// CreateExhibitionRequest describes a new exhibition. Times are RFC 3339.
+type CreateExhibitionRequest struct {
+	Title     string     `json:"title" example:"Coastlines"`
+	Statement string     `json:"statement" example:"Six artists on the changing shore"`
+	OpensAt   *time.Time `json:"opens_at" example:"2026-11-01T18:00:00Z"`
+	ClosesAt  *time.Time `json:"closes_at" example:"2026-12-01T18:00:00Z"`
+}
+
+// UpdateExhibitionRequest changes an exhibition. Omitted fields are kept.
+type UpdateExhibitionRequest struct {
+	Title     *string    `json:"title"`
+	Statement *string    `json:"statement"`
+	OpensAt   *time.Time `json:"opens_at"`
+	ClosesAt  *time.Time `json:"closes_at"`
+}
+
+// ExhibitionSummary is an exhibition as listed
+type ExhibitionSummary struct {
+	ID              uuid.UUID                    `json:"id"`
+	Title           string                       `json:"title"`
+	Slug            string                       `json:"slug"`
+	CoverImageURL   string                       `json:"cover_image_url"`
+	Status          exhibitions.ExhibitionStatus `json:"status"`
+	OpensAt         *time.Time                   `json:"opens_at"`
+	ClosesAt        *time.Time                   `json:"closes_at"`
+	CuratorID       uuid.UUID                    `json:"curator_id"`
+	CuratorUsername string                       `json:"curator_username"`
+	CreatedAt       time.Time                    `json:"created_at"`
+}
+
+// ExhibitionDetail is an exhibition with its curator and layout
+type ExhibitionDetail struct {
+	ID            uuid.UUID                    `json:"id"`
+	Title         string                       `json:"title"`
+	Slug          string                       `json:"slug"`
+	Statement     string                       `json:"statement"`
+	CoverImageURL string                       `json:"cover_image_url"`
+	Status        exhibitions.ExhibitionStatus `json:"status"`
+	OpensAt       *time.Time                   `json:"opens_at"`
+	ClosesAt      *time.Time                   `json:"closes_at"`
+	Curator       privacy.UserView             `json:"curator"`
+	RSVPCount     int64                        `json:"rsvp_count"`
+	HasRSVP       bool                         `json:"has_rsvp"`
+	Rooms         []RoomView                   `json:"rooms"`
+	CreatedAt     time.Time                    `json:"created_at"`
+}
+
+// listing describes how one view of the exhibition list is ordered
+type listing struct {
+	status exhibitions.ExhibitionStatus
+	column string
+	desc   bool
+}
+
+var listings = map[string]listing{
+	"upcoming": {status: exhibitions.ScheduledStatus, column: "opens_at"},
+	"open":     {status: exhibitions.PublishedStatus, column: "opens_at", desc: true},
+	"past":     {status: exhibitions.ArchivedStatus, column: "closes_at", desc: true},
+}
+
+// loadCuratedExhibition loads an exhibition curated by the user
+func loadCuratedExhibition(db *gorm.DB, exhibitionID string, userID uuid.UUID) (*exhibitions.Exhibition, error) {
+	var exhibition exhibitions.Exhibition
+	if err := db.Where("id = ? AND curator_id = ?", exhibitionID, userID).First(&exhibition).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Exhibition not found or you don't have permission")
+		}
+		return nil, fmt.Errorf("failed to retrieve exhibition: %w", err)
+	}
+	return &exhibition, nil
+}
+
+// scheduleError turns a schedule validation error into a client error
+func scheduleError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return fiber.NewError(fiber.StatusBadRequest, strings.ToUpper(err.Error()[:1])+err.Error()[1:])
+}
+
+func pageSize(c *fiber.Ctx) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
+
+// CreateExhibition godoc
+// @Summary Create an exhibition
+// @Description Creates a draft exhibition curated by the authenticated user. Opening and closing times can be set now or before scheduling.
+// @Tags Exhibitions
+// @Accept json
+// @Produce json
+// @Param body body CreateExhibitionRequest true "Exhibition"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /exhibitions [post]
+func CreateExhibition(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req CreateExhibitionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		req.Title = strings.TrimSpace(req.Title)
+		if req.Title == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Exhibition title is required"))
+		}
+
+		exhibition := exhibitions.Exhibition{
+			CuratorID: user.ID,
+			Title:     req.Title,
+			Statement: strings.TrimSpace(req.Statement),
+			OpensAt:   req.OpensAt,
+			ClosesAt:  req.ClosesAt,
+		}
+		if exhibition.OpensAt != nil || exhibition.ClosesAt != nil {
+			if err := exhibition.CheckSchedule(time.Now()); err != nil {
+				return responseHandler.HandleResponse(c, nil, scheduleError(err))
+			}
+		}
+
+		if err := db.Create(&exhibition).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to create exhibition: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":    "Exhibition created successfully",
+			"exhibition": exhibition,
+		}, nil)
+	}
+}
+
+// UpdateExhibition godoc
+// @Summary Update an exhibition
+// @Description Changes an exhibition's title, statement or times. Opening times can only change before the exhibition opens; an open exhibition can still be extended. Archived exhibitions cannot change.
+// @Tags Exhibitions
+// @Accept json
+// @Produce json
+// @Param id path string true "Exhibition ID"
+// @Param body body UpdateExhibitionRequest true "Changes"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /exhibitions/{id} [put]
+func UpdateExhibition(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req UpdateExhibitionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+
+		exhibition, err := loadCuratedExhibition(db, c.Params("id"), user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if !exhibition.IsEditable() {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, "Archived exhibitions cannot be changed"))
+		}
+
+		if req.Title != nil {
+			title := strings.TrimSpace(*req.Title)
+			if title == "" {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Exhibition title cannot be empty"))
+			}
+			exhibition.Title = title
+		}
+		if req.Statement != nil {
+			exhibition.Statement = strings.TrimSpace(*req.Statement)
+		}
+
+		if req.OpensAt != nil || req.ClosesAt != nil {
+			if req.OpensAt != nil && exhibition.Status == exhibitions.PublishedStatus {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusConflict, "The exhibition has already opened"))
+			}
+			if req.OpensAt != nil {
+				exhibition.OpensAt = req.OpensAt
+			}
+			if req.ClosesAt != nil {
+				exhibition.ClosesAt = req.ClosesAt
+			}
+			if err := exhibition.CheckSchedule(time.Now()); err != nil {
+				return responseHandler.HandleResponse(c, nil, scheduleError(err))
+			}
+		}
+
+		exhibition.UpdatedAt = time.Now()
+		if err := db.Save(exhibition).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to update exhibition: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":    "Exhibition updated successfully",
+			"exhibition": exhibition,
+		}, nil)
+	}
+}
+
+// UpdateExhibitionStatus godoc
+// @Summary Schedule, unschedule or close an exhibition
+// @Description schedule moves a draft to scheduled; it opens automatically at its opening time and is archived at its closing time. draft takes a scheduled exhibition back to draft. close archives it immediately.
+// @Tags Exhibitions
+// @Produce json
+// @Param id path string true "Exhibition ID"
+// @Param action path string true "schedule, draft or close"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /exhibitions/{id}/status/{action} [put]
+func UpdateExhibitionStatus(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		exhibition, err := loadCuratedExhibition(db, c.Params("id"), user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		now := time.Now()
+		var from []exhibitions.ExhibitionStatus
+		var to exhibitions.ExhibitionStatus
+		switch strings.ToLower(c.Params("action")) {
+		case "schedule":
+			from, to = []exhibitions.ExhibitionStatus{exhibitions.DraftStatus}, exhibitions.ScheduledStatus
+			if err := exhibition.CheckSchedule(now); err != nil {
+				return responseHandler.HandleResponse(c, nil, scheduleError(err))
+			}
+			var artworkCount int64
+			if err := db.Model(&exhibitions.ExhibitionArtwork{}).
+				Where("exhibition_id = ?", exhibition.ID).
+				Count(&artworkCount).Error; err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fmt.Errorf("failed to count exhibition artworks: %w", err))
+			}
+			if artworkCount == 0 {
+				return responseHandler.HandleResponse(c, nil, scheduleError(exhibitions.ErrNoArtworks))
+			}
+		case "draft":
+			from, to = []exhibitions.ExhibitionStatus{exhibitions.ScheduledStatus}, exhibitions.DraftStatus
+		case "close":
+			from, to = []exhibitions.ExhibitionStatus{exhibitions.ScheduledStatus, exhibitions.PublishedStatus}, exhibitions.ArchivedStatus
+		default:
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid action. Must be schedule, draft or close"))
+		}
+
+		// The scheduler may have moved the exhibition on since it was loaded
+		result := db.Model(&exhibitions.Exhibition{}).
+			Where("id = ? AND status IN ?", exhibition.ID, from).
+			Updates(map[string]interface{}{"status": to, "updated_at": now})
+		if result.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to update exhibition status: %w", result.Error))
+		}
+		if result.RowsAffected == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict,
+					fmt.Sprintf("A %s exhibition cannot be moved to %s", exhibition.Status, to)))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Exhibition status updated successfully",
+			"status":  to,
+		}, nil)
+	}
+}
+
+// DeleteExhibition godoc
+// @Summary Delete an exhibition
+// @Description Deletes an exhibition that is not currently open. The artworks are not affected.
+// @Tags Exhibitions
+// @Produce json
+// @Param id path string true "Exhibition ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /exhibitions/{id} [delete]
+func DeleteExhibition(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		exhibition, err := loadCuratedExhibition(db, c.Params("id"), user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		result := db.Where("id = ? AND status <> ?", exhibition.ID, exhibitions.PublishedStatus).
+			Delete(&exhibitions.Exhibition{})
+		if result.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to delete exhibition: %w", result.Error))
+		}
+		if result.RowsAffected == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, "Close the exhibition before deleting it"))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Exhibition deleted successfully",
+		}, nil)
+	}
+}
+
+// UpdateExhibitionCover godoc
+// @Summary Upload an exhibition cover image
+// @Description Uploads the cover image shown in listings and at the top of the exhibition
+// @Tags Exhibitions
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Exhibition ID"
+// @Param image formData file true "Image file"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /exhibitions/{id}/cover [put]
+func UpdateExhibitionCover(db *gorm.DB, cld *config.CloudinaryClient, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		exhibition, err := loadCuratedExhibition(db, c.Params("id"), user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if !exhibition.IsEditable() {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, "Archived exhibitions cannot be changed"))
+		}
+
+		file, err := c.FormFile("image")
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "No image file provided"))
+		}
+		if err := artworkServices.ValidateImageFile(file); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		fileURL, err := cld.UploadFile(file, fmt.Sprintf("exhibitions/%s", exhibition.ID))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to upload cover image: %w", err))
+		}
+
+		previous := exhibition.CoverImageURL
+		if err := db.Model(exhibition).Updates(map[string]interface{}{
+			"cover_image_url": fileURL,
+			"updated_at":      time.Now(),
+		}).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to update cover image: %w", err))
+		}
+		if previous != "" {
+			if err := cld.DeleteFile(previous); err != nil {
+				// The new cover is saved; an orphaned file is only wasted storage
+				fmt.Printf("Failed to delete previous exhibition cover %s: %v\n", previous, err)
+			}
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":         "Cover image updated successfully",
+			"cover_image_url": fileURL,
+		}, nil)
+	}
+}
+
+// ListExhibitions godoc
+// @Summary List exhibitions
+// @Description Lists open exhibitions (newest opening first), upcoming ones (soonest first) or past ones (most recently closed first). mine lists every exhibition the authenticated user curates, drafts included.
+// @Tags Exhibitions
+// @Produce json
+// @Param when query string false "open (default), upcoming, past or mine"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /exhibitions [get]
+func ListExhibitions(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		when := c.Query("when", "open")
+		view, known := listings[when]
+		switch {
+		case when == "mine":
+			view = listing{column: "created_at", desc: true}
+		case !known:
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid filter. Must be open, upcoming, past or mine"))
+		}
+
+		limit := pageSize(c)
+		column := "exhibitions." + view.column
+		query := db.Table("exhibitions").
+			Select("exhibitions.id, exhibitions.title, exhibitions.slug, exhibitions.cover_image_url, exhibitions.status, " +
+				"exhibitions.opens_at, exhibitions.closes_at, exhibitions.curator_id, users.username AS curator_username, " +
+				"exhibitions.created_at").
+			Joins("JOIN users ON users.id = exhibitions.curator_id")
+		if when == "mine" {
+			query = query.Where("exhibitions.curator_id = ?", user.ID)
+		} else {
+			query = query.Where("exhibitions.status = ? AND users.is_active = ?", view.status, true)
+		}
+
+		if cursor := c.Query("cursor"); cursor != "" {
+			at, id, err := utils.DecodeCursor(cursor)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid cursor"))
+			}
+			op := ">"
+			if view.desc {
+				op = "<"
+			}
+			query = query.Where(fmt.Sprintf("%s %s ? OR (%s = ? AND exhibitions.id %s ?)", column, op, column, op), at, at, id)
+		}
+
+		direction := "ASC"
+		if view.desc {
+			direction = "DESC"
+		}
+		var summaries []ExhibitionSummary
+		if err := query.
+			Order(fmt.Sprintf("%s %s, exhibitions.id %s", column, direction, direction)).
+			Limit(limit + 1).
+			Scan(&summaries).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to list exhibitions: %w", err))
+		}
+
+		nextCursor := ""
+		if len(summaries) > limit {
+			summaries = summaries[:limit]
+			last := summaries[limit-1]
+			var at time.Time
+			switch view.column {
+			case "opens_at":
+				at = *last.OpensAt
+			case "closes_at":
+				at = *last.ClosesAt
+			default:
+				at = last.CreatedAt
+			}
+			nextCursor = utils.EncodeCursor(at, last.ID)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"exhibitions": summaries,
+			"next_cursor": nextCursor,
+		}, nil)
+	}
+}
+
+// GetExhibition godoc
+// @Summary Get an exhibition
+// @Description Retrieves an exhibition by ID or slug with its rooms and artworks. Upcoming exhibitions show their rooms but keep the artworks hidden until opening. Drafts are only visible to their curator.
+// @Tags Exhibitions
+// @Produce json
+// @Param identifier path string true "Exhibition ID or slug"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /exhibitions/{identifier} [get]
+func GetExhibition(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, _ := c.Locals("user").(models.User)
+		identifier := c.Params("identifier")
+
+		query := db.Preload("Curator")
+		if id, err := uuid.Parse(identifier); err == nil {
+			query = query.Where("id = ?", id)
+		} else {
+			query = query.Where("slug = ?", identifier)
+		}
+		var exhibition exhibitions.Exhibition
+		if err := query.First(&exhibition).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Exhibition not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve exhibition: %w", err))
+		}
+
+		isCurator := exhibition.CuratorID == user.ID
+		if !isCurator && (exhibition.Status == exhibitions.DraftStatus || !exhibition.Curator.IsActive) {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Exhibition not found"))
+		}
+
+		var curatorDetail models.UserDetail
+		if err := db.Where("user_id = ?", exhibition.CuratorID).First(&curatorDetail).Error; err != nil &&
+			!errors.Is(err, gorm.ErrRecordNotFound) {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve curator details: %w", err))
+		}
+		curator := privacy.UserView{
+			ID:           exhibition.CuratorID,
+			Username:     exhibition.Curator.Username,
+			ProfileImage: curatorDetail.ProfileImage,
+			FirstName:    curatorDetail.FirstName,
+			LastName:     curatorDetail.LastName,
+		}
+		policy, err := privacy.ForRequest(c, db)
+		if err == nil {
+			err = policy.Load(exhibition.CuratorID)
+		}
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to apply privacy settings: %w", err))
+		}
+		if !policy.CanSeeContent(exhibition.CuratorID) {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusForbidden, "This account is private"))
+		}
+		policy.Apply(&curator)
+
+		// Upcoming exhibitions are announced without revealing the works
+		showArtworks := isCurator || exhibition.Status == exhibitions.PublishedStatus ||
+			exhibition.Status == exhibitions.ArchivedStatus
+		rooms, err := exhibitionLayout(db, exhibition.ID, user.ID, isCurator, showArtworks)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		detail := ExhibitionDetail{
+			ID:            exhibition.ID,
+			Title:         exhibition.Title,
+			Slug:          exhibition.Slug,
+			Statement:     exhibition.Statement,
+			CoverImageURL: exhibition.CoverImageURL,
+			Status:        exhibition.Status,
+			OpensAt:       exhibition.OpensAt,
+			ClosesAt:      exhibition.ClosesAt,
+			Curator:       curator,
+			Rooms:         rooms,
+			CreatedAt:     exhibition.CreatedAt,
+		}
+		if err := db.Model(&exhibitions.ExhibitionRSVP{}).
+			Where("exhibition_id = ?", exhibition.ID).
+			Count(&detail.RSVPCount).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to count RSVPs: %w", err))
+		}
+		if user.ID != uuid.Nil {
+			var mine int64
+			if err := db.Model(&exhibitions.ExhibitionRSVP{}).
+				Where("exhibition_id = ? AND user_id = ?", exhibition.ID, user.ID).
+				Count(&mine).Error; err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fmt.Errorf("failed to check RSVP: %w", err))
+			}
+			detail.HasRSVP = mine > 0
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"exhibition": detail,
+		}, nil)
+	}
+}
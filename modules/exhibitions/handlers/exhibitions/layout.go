@@ -0,0 +1,111 @@
+package exhibitions
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	art "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	exhibitions "github.com/muga20/artsMarket/modules/exhibitions/models"
+	"gorm.io/gorm"
+)
+
+// ExhibitedArtwork is one artwork hanging in an exhibition
+type ExhibitedArtwork struct {
+	Position       int       `json:"position"`
+	ArtworkID      uuid.UUID `json:"artwork_id"`
+	Title          string    `json:"title"`
+	Slug           string    `json:"slug"`
+	ImageURL       string    `json:"image_url"`
+	ArtistID       uuid.UUID `json:"artist_id"`
+	ArtistUsername string    `json:"artist_username"`
+	Label          string    `json:"label"`
+}
+
+// RoomView is a room with the artworks hung in it. Artworks without a room
+// are returned in a first room with no ID.
+type RoomView struct {
+	ID          *uuid.UUID         `json:"id"`
+	Title       string             `json:"title"`
+	Description string             `json:"description"`
+	Artworks    []ExhibitedArtwork `json:"artworks"`
+}
+
+// exhibitionLayout returns the exhibition's rooms in order with their
+// artworks. The curator sees every artwork; other viewers only see artworks
+// visible to them. Without showArtworks the rooms are returned empty.
+func exhibitionLayout(db *gorm.DB, exhibitionID, viewerID uuid.UUID, isCurator, showArtworks bool) ([]RoomView, error) {
+	var rooms []exhibitions.ExhibitionRoom
+	if err := db.Where("exhibition_id = ?", exhibitionID).
+		Order("position ASC, created_at ASC").
+		Find(&rooms).Error; err != nil {
+		return nil, fmt.Errorf("failed to load exhibition rooms: %w", err)
+	}
+
+	views := make([]RoomView, 0, len(rooms)+1)
+	views = append(views, RoomView{Artworks: []ExhibitedArtwork{}})
+	roomIndex := make(map[uuid.UUID]int, len(rooms))
+	for i := range rooms {
+		roomIndex[rooms[i].ID] = len(views)
+		views = append(views, RoomView{
+			ID:          &rooms[i].ID,
+			Title:       rooms[i].Title,
+			Description: rooms[i].Description,
+			Artworks:    []ExhibitedArtwork{},
+		})
+	}
+
+	if showArtworks {
+		query := db.Where("exhibition_id = ?", exhibitionID)
+		if !isCurator {
+			query = query.Where("artwork_id IN (?)", art.VisibleTo(db, viewerID).Select("artworks.id"))
+		}
+		var hung []exhibitions.ExhibitionArtwork
+		if err := query.Order("position ASC, created_at ASC").Find(&hung).Error; err != nil {
+			return nil, fmt.Errorf("failed to load exhibition artworks: %w", err)
+		}
+
+		if len(hung) > 0 {
+			artworkIDs := make([]uuid.UUID, len(hung))
+			for i, item := range hung {
+				artworkIDs[i] = item.ArtworkID
+			}
+			var artworks []art.Artwork
+			if err := db.Preload("Images").Preload("User").
+				Where("id IN ?", artworkIDs).
+				Find(&artworks).Error; err != nil {
+				return nil, fmt.Errorf("failed to load exhibition artworks: %w", err)
+			}
+			byID := make(map[uuid.UUID]*art.Artwork, len(artworks))
+			for i := range artworks {
+				byID[artworks[i].ID] = &artworks[i]
+			}
+
+			for _, item := range hung {
+				artwork := byID[item.ArtworkID]
+				if artwork == nil {
+					continue
+				}
+				index := 0
+				if item.RoomID != nil {
+					index = roomIndex[*item.RoomID]
+				}
+				views[index].Artworks = append(views[index].Artworks, ExhibitedArtwork{
+					Position:       item.Position,
+					ArtworkID:      artwork.ID,
+					Title:          artwork.Title,
+					Slug:           artwork.Slug,
+					ImageURL:       artwork.PrimaryImageURL(),
+					ArtistID:       artwork.UserID,
+					ArtistUsername: artwork.User.Username,
+					Label:          item.Label,
+				})
+			}
+		}
+	}
+
+	// Only keep the unassigned room when something hangs in it
+	if len(views[0].Artworks) == 0 {
+		views = views[1:]
+	}
+	return views, nil
+}
@@ -0,0 +1,143 @@
+package exhibitions
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	exhibitions "github.com/muga20/artsMarket/modules/exhibitions/models"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+const maxRooms = 50
+
+// RoomInput is one room in a room layout. Rooms without an ID are created.
+type RoomInput struct {
+	ID          *uuid.UUID `json:"id"`
+	Title       string     `json:"title" example:"Low tide"`
+	Description string     `json:"description"`
+}
+
+// SetRoomsRequest is the complete, ordered list of an exhibition's rooms
+type SetRoomsRequest struct {
+	Rooms []RoomInput `json:"rooms"`
+}
+
+// SetExhibitionRooms godoc
+// @Summary Set an exhibition's rooms
+// @Description Replaces the exhibition's rooms with the given ordered list. Rooms with an ID are updated, rooms without one are created and rooms left out are removed; their artworks move to the unassigned area.
+// @Tags Exhibitions
+// @Accept json
+// @Produce json
+// @Param id path string true "Exhibition ID"
+// @Param body body SetRoomsRequest true "Rooms in order"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /exhibitions/{id}/rooms [put]
+func SetExhibitionRooms(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req SetRoomsRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		if len(req.Rooms) > maxRooms {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("An exhibition can have at most %d rooms", maxRooms)))
+		}
+		for i := range req.Rooms {
+			req.Rooms[i].Title = strings.TrimSpace(req.Rooms[i].Title)
+			if req.Rooms[i].Title == "" {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Every room needs a title"))
+			}
+		}
+
+		exhibition, err := loadCuratedExhibition(db, c.Params("id"), user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if !exhibition.IsEditable() {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, "Archived exhibitions cannot be changed"))
+		}
+
+		var rooms []exhibitions.ExhibitionRoom
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var existing []exhibitions.ExhibitionRoom
+			if err := tx.Where("exhibition_id = ?", exhibition.ID).Find(&existing).Error; err != nil {
+				return fmt.Errorf("failed to load exhibition rooms: %w", err)
+			}
+			byID := make(map[uuid.UUID]*exhibitions.ExhibitionRoom, len(existing))
+			for i := range existing {
+				byID[existing[i].ID] = &existing[i]
+			}
+
+			now := time.Now()
+			kept := make(map[uuid.UUID]bool, len(req.Rooms))
+			for position, input := range req.Rooms {
+				if input.ID == nil {
+					room := exhibitions.ExhibitionRoom{
+						ExhibitionID: exhibition.ID,
+						Title:        input.Title,
+						Description:  strings.TrimSpace(input.Description),
+						Position:     position,
+					}
+					if err := tx.Create(&room).Error; err != nil {
+						return fmt.Errorf("failed to create room: %w", err)
+					}
+					rooms = append(rooms, room)
+					continue
+				}
+
+				room := byID[*input.ID]
+				if room == nil || kept[room.ID] {
+					return fiber.NewError(fiber.StatusBadRequest,
+						fmt.Sprintf("Room %s is not part of this exhibition or is listed twice", input.ID))
+				}
+				kept[room.ID] = true
+				room.Title = input.Title
+				room.Description = strings.TrimSpace(input.Description)
+				room.Position = position
+				room.UpdatedAt = now
+				if err := tx.Save(room).Error; err != nil {
+					return fmt.Errorf("failed to update room: %w", err)
+				}
+				rooms = append(rooms, *room)
+			}
+
+			var removed []uuid.UUID
+			for _, room := range existing {
+				if !kept[room.ID] {
+					removed = append(removed, room.ID)
+				}
+			}
+			if len(removed) > 0 {
+				if err := tx.Where("id IN ?", removed).Delete(&exhibitions.ExhibitionRoom{}).Error; err != nil {
+					return fmt.Errorf("failed to remove rooms: %w", err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Exhibition rooms updated successfully",
+			"rooms":   rooms,
+		}, nil)
+	}
+}
@@ -0,0 +1,96 @@
+package exhibitions
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	exhibitions "github.com/muga20/artsMarket/modules/exhibitions/models"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RSVP godoc
+// @Summary RSVP to an exhibition opening
+// @Description Registers the authenticated user for an upcoming exhibition's opening. They are reminded a day before and notified when it opens.
+// @Tags Exhibitions
+// @Produce json
+// @Param id path string true "Exhibition ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /exhibitions/{id}/rsvp [post]
+func RSVP(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var exhibition exhibitions.Exhibition
+		if err := db.Preload("Curator").Where("id = ?", c.Params("id")).First(&exhibition).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Exhibition not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve exhibition: %w", err))
+		}
+		if exhibition.Status == exhibitions.DraftStatus || !exhibition.Curator.IsActive {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Exhibition not found"))
+		}
+		if !exhibition.AcceptsRSVPs(time.Now()) {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, "This exhibition is no longer taking RSVPs"))
+		}
+
+		rsvp := exhibitions.ExhibitionRSVP{ExhibitionID: exhibition.ID, UserID: user.ID}
+		if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&rsvp).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to save RSVP: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "RSVP saved",
+		}, nil)
+	}
+}
+
+// CancelRSVP godoc
+// @Summary Cancel an exhibition RSVP
+// @Description Removes the authenticated user's RSVP to an exhibition
+// @Tags Exhibitions
+// @Produce json
+// @Param id path string true "Exhibition ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /exhibitions/{id}/rsvp [delete]
+func CancelRSVP(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		result := db.Where("exhibition_id = ? AND user_id = ?", c.Params("id"), user.ID).
+			Delete(&exhibitions.ExhibitionRSVP{})
+		if result.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to cancel RSVP: %w", result.Error))
+		}
+		if result.RowsAffected == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "You have not RSVP'd to this exhibition"))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "RSVP cancelled",
+		}, nil)
+	}
+}
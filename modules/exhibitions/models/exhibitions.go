@@ -0,0 +1,169 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gosimple/slug"
+	art "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"gorm.io/gorm"
+)
+
+// ExhibitionStatus follows the collection lifecycle with an extra scheduled
+// step: a scheduled exhibition is published automatically at its opening
+// time and archived at its closing time.
+type ExhibitionStatus string
+
+const (
+	DraftStatus     ExhibitionStatus = "draft"
+	ScheduledStatus ExhibitionStatus = "scheduled"
+	PublishedStatus ExhibitionStatus = "published"
+	ArchivedStatus  ExhibitionStatus = "archived"
+)
+
+var (
+	ErrMissingSchedule  = errors.New("opening and closing times are required")
+	ErrClosesBeforeOpen = errors.New("closing time must be after opening time")
+	ErrClosingInPast    = errors.New("closing time must be in the future")
+	ErrNoArtworks       = errors.New("an exhibition needs at least one artwork")
+)
+
+// Exhibition is a curated show of artworks by any number of artists, open
+// to visitors between its opening and closing times
+type Exhibition struct {
+	ID            uuid.UUID        `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	CuratorID     uuid.UUID        `gorm:"type:char(36);not null;index" json:"curator_id"`
+	Title         string           `gorm:"type:varchar(255);not null" json:"title"`
+	Slug          string           `gorm:"type:varchar(255);not null;uniqueIndex" json:"slug"`
+	Statement     string           `gorm:"type:text" json:"statement"` // Curatorial statement
+	CoverImageURL string           `gorm:"type:varchar(255)" json:"cover_image_url"`
+	Status        ExhibitionStatus `gorm:"type:enum('draft','scheduled','published','archived');default:'draft';index:idx_exhibition_status_times,priority:1" json:"status"`
+	OpensAt       *time.Time       `gorm:"type:timestamp;index:idx_exhibition_status_times,priority:2" json:"opens_at"`
+	ClosesAt      *time.Time       `gorm:"type:timestamp;index:idx_exhibition_status_times,priority:3" json:"closes_at"`
+	CreatedAt     time.Time        `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time        `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	Curator models.User `gorm:"foreignKey:CuratorID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+}
+
+// ExhibitionRoom is a named room or section of an exhibition
+type ExhibitionRoom struct {
+	ID           uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	ExhibitionID uuid.UUID `gorm:"type:char(36);not null;index:idx_exhibition_room_position,priority:1" json:"exhibition_id"`
+	Title        string    `gorm:"type:varchar(255);not null" json:"title"`
+	Description  string    `gorm:"type:text" json:"description"`
+	Position     int       `gorm:"type:int;not null;default:0;index:idx_exhibition_room_position,priority:2" json:"position"`
+	CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	Exhibition Exhibition `gorm:"foreignKey:ExhibitionID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ExhibitionArtwork hangs an artwork in an exhibition. Artworks without a
+// room are shown before the first room.
+type ExhibitionArtwork struct {
+	ID           uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	ExhibitionID uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_exhibition_artwork" json:"exhibition_id"`
+	ArtworkID    uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_exhibition_artwork;index" json:"artwork_id"`
+	RoomID       *uuid.UUID `gorm:"type:char(36);index" json:"room_id"`
+	Position     int        `gorm:"type:int;not null;default:0" json:"position"`
+	Label        string     `gorm:"type:text" json:"label"` // Wall label written by the curator
+	CreatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Exhibition Exhibition     `gorm:"foreignKey:ExhibitionID;constraint:OnDelete:CASCADE" json:"-"`
+	Room       ExhibitionRoom `gorm:"foreignKey:RoomID;constraint:OnDelete:SET NULL" json:"-"`
+	Artwork    art.Artwork    `gorm:"foreignKey:ArtworkID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ExhibitionRSVP records that a user plans to attend an exhibition's opening
+type ExhibitionRSVP struct {
+	ID           uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	ExhibitionID uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_exhibition_rsvp" json:"exhibition_id"`
+	UserID       uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_exhibition_rsvp;index" json:"user_id"`
+	RemindedAt   *time.Time `gorm:"type:timestamp" json:"reminded_at,omitempty"`
+	CreatedAt    time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	Exhibition Exhibition  `gorm:"foreignKey:ExhibitionID;constraint:OnDelete:CASCADE" json:"-"`
+	User       models.User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName keeps the acronym readable
+func (ExhibitionRSVP) TableName() string {
+	return "exhibition_rsvps"
+}
+
+// BeforeCreate hook to generate UUID and a unique slug
+func (e *Exhibition) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.Status == "" {
+		e.Status = DraftStatus
+	}
+
+	if e.Slug == "" {
+		e.Slug = slug.Make(e.Title)
+
+		var count int64
+		tx.Model(&Exhibition{}).
+			Where("slug = ?", e.Slug).
+			Count(&count)
+
+		if count > 0 {
+			e.Slug = slug.Make(e.Title + "-" + strings.Split(e.ID.String(), "-")[0])
+		}
+	}
+	return
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (r *ExhibitionRoom) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (a *ExhibitionArtwork) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (r *ExhibitionRSVP) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
+
+// CheckSchedule validates the opening and closing times
+func (e *Exhibition) CheckSchedule(now time.Time) error {
+	if e.OpensAt == nil || e.ClosesAt == nil {
+		return ErrMissingSchedule
+	}
+	if !e.ClosesAt.After(*e.OpensAt) {
+		return ErrClosesBeforeOpen
+	}
+	if !e.ClosesAt.After(now) {
+		return ErrClosingInPast
+	}
+	return nil
+}
+
+// IsEditable reports whether the exhibition's content can still change.
+// Archived exhibitions are kept as they were shown.
+func (e *Exhibition) IsEditable() bool {
+	return e.Status != ArchivedStatus
+}
+
+// AcceptsRSVPs reports whether visitors can still RSVP to the opening
+func (e *Exhibition) AcceptsRSVPs(now time.Time) bool {
+	return e.Status == ScheduledStatus && e.OpensAt != nil && e.OpensAt.After(now)
+}
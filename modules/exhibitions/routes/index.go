@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/modules/exhibitions/handlers/exhibitions"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/middleware"
+	"gorm.io/gorm"
+)
+
+// ExhibitionsSetupRoutes sets up exhibition routes
+func ExhibitionsSetupRoutes(apiGroup fiber.Router, db *gorm.DB, cld *config.CloudinaryClient, responseHandler *handlers.ResponseHandler) {
+	exhibitionGroup := apiGroup.Group("/exhibitions")
+
+	exhibitionGroup.Use(middleware.AuthMiddleware(db, responseHandler))
+
+	// Exhibitions
+	exhibitionGroup.Post("/", middleware.RequireVerifiedEmail(db, responseHandler), exhibitions.CreateExhibition(db, responseHandler))
+	exhibitionGroup.Get("/", exhibitions.ListExhibitions(db, responseHandler))
+	exhibitionGroup.Get("/:identifier", exhibitions.GetExhibition(db, responseHandler))
+	exhibitionGroup.Put("/:id", exhibitions.UpdateExhibition(db, responseHandler))
+	exhibitionGroup.Delete("/:id", exhibitions.DeleteExhibition(db, responseHandler))
+	exhibitionGroup.Put("/:id/status/:action", exhibitions.UpdateExhibitionStatus(db, responseHandler))
+	exhibitionGroup.Put("/:id/cover", exhibitions.UpdateExhibitionCover(db, cld, responseHandler))
+
+	// Rooms and artworks
+	exhibitionGroup.Put("/:id/rooms", exhibitions.SetExhibitionRooms(db, responseHandler))
+	exhibitionGroup.Put("/:id/artworks", exhibitions.SetExhibitionArtworks(db, responseHandler))
+	exhibitionGroup.Delete("/:id/artworks/:artworkId", exhibitions.WithdrawExhibitionArtwork(db, responseHandler))
+
+	// Opening RSVPs
+	exhibitionGroup.Post("/:id/rsvp", exhibitions.RSVP(db, responseHandler))
+	exhibitionGroup.Delete("/:id/rsvp", exhibitions.CancelRSVP(db, responseHandler))
+}
@@ -0,0 +1,146 @@
+// Package schedule moves exhibitions through their lifecycle as their
+// opening and closing times pass, and reminds visitors who RSVP'd.
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	exhibitions "github.com/muga20/artsMarket/modules/exhibitions/models"
+	notifications "github.com/muga20/artsMarket/modules/notifications/services"
+	"gorm.io/gorm"
+)
+
+// ReminderLead is how long before an opening visitors who RSVP'd are reminded
+const ReminderLead = 24 * time.Hour
+
+// batchSize bounds how many exhibitions or reminders one run handles, so a
+// backlog drains over a few runs
+const batchSize = 200
+
+// Advance opens scheduled exhibitions whose opening time has passed,
+// archives those whose closing time has passed and sends opening reminders.
+// It is safe to run concurrently: every transition only applies to rows
+// still in the expected status.
+func Advance(ctx context.Context, db *gorm.DB, now time.Time) error {
+	db = db.WithContext(ctx)
+
+	if err := closeDue(db, now); err != nil {
+		return err
+	}
+	if err := openDue(db, now); err != nil {
+		return err
+	}
+	return sendReminders(db, now)
+}
+
+// closeDue archives exhibitions past their closing time, including
+// scheduled ones whose whole run was missed
+func closeDue(db *gorm.DB, now time.Time) error {
+	result := db.Model(&exhibitions.Exhibition{}).
+		Where("status IN ? AND closes_at <= ?",
+			[]exhibitions.ExhibitionStatus{exhibitions.ScheduledStatus, exhibitions.PublishedStatus}, now).
+		Updates(map[string]interface{}{"status": exhibitions.ArchivedStatus, "updated_at": now})
+	if result.Error != nil {
+		return fmt.Errorf("failed to close exhibitions: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("Archived %d exhibitions past their closing time", result.RowsAffected)
+	}
+	return nil
+}
+
+// openDue publishes scheduled exhibitions whose opening time has passed and
+// tells the visitors who RSVP'd
+func openDue(db *gorm.DB, now time.Time) error {
+	var due []exhibitions.Exhibition
+	if err := db.Where("status = ? AND opens_at <= ?", exhibitions.ScheduledStatus, now).
+		Order("opens_at ASC").
+		Limit(batchSize).
+		Find(&due).Error; err != nil {
+		return fmt.Errorf("failed to find exhibitions to open: %w", err)
+	}
+
+	for _, exhibition := range due {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&exhibitions.Exhibition{}).
+				Where("id = ? AND status = ?", exhibition.ID, exhibitions.ScheduledStatus).
+				Updates(map[string]interface{}{"status": exhibitions.PublishedStatus, "updated_at": now})
+			if result.Error != nil {
+				return fmt.Errorf("failed to open exhibition: %w", result.Error)
+			}
+			// Another run opened it first
+			if result.RowsAffected == 0 {
+				return nil
+			}
+
+			message := fmt.Sprintf("%q is now open", exhibition.Title)
+			return notifyAttendees(tx, exhibition.ID, "exhibition_opened", message)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to open exhibition %s: %w", exhibition.ID, err)
+		}
+	}
+	return nil
+}
+
+// sendReminders reminds visitors of openings within ReminderLead. Each RSVP
+// is reminded once.
+func sendReminders(db *gorm.DB, now time.Time) error {
+	var due []struct {
+		ID           uuid.UUID
+		UserID       uuid.UUID
+		ExhibitionID uuid.UUID
+		Title        string
+		OpensAt      time.Time
+	}
+	if err := db.Table("exhibition_rsvps").
+		Select("exhibition_rsvps.id, exhibition_rsvps.user_id, exhibition_rsvps.exhibition_id, exhibitions.title, exhibitions.opens_at").
+		Joins("JOIN exhibitions ON exhibitions.id = exhibition_rsvps.exhibition_id").
+		Where("exhibition_rsvps.reminded_at IS NULL AND exhibitions.status = ? AND exhibitions.opens_at > ? AND exhibitions.opens_at <= ?",
+			exhibitions.ScheduledStatus, now, now.Add(ReminderLead)).
+		Limit(batchSize).
+		Scan(&due).Error; err != nil {
+		return fmt.Errorf("failed to find opening reminders: %w", err)
+	}
+
+	for _, rsvp := range due {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&exhibitions.ExhibitionRSVP{}).
+				Where("id = ? AND reminded_at IS NULL", rsvp.ID).
+				Update("reminded_at", now)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return nil
+			}
+
+			message := fmt.Sprintf("%q opens on %s UTC", rsvp.Title, rsvp.OpensAt.UTC().Format("Mon 2 Jan at 15:04"))
+			return notifications.WriteNotification(tx, rsvp.UserID, "exhibition_reminder", message, "exhibition", rsvp.ExhibitionID)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send opening reminder %s: %w", rsvp.ID, err)
+		}
+	}
+	return nil
+}
+
+// notifyAttendees notifies everyone who RSVP'd to the exhibition
+func notifyAttendees(tx *gorm.DB, exhibitionID uuid.UUID, notificationType, message string) error {
+	var userIDs []uuid.UUID
+	if err := tx.Model(&exhibitions.ExhibitionRSVP{}).
+		Where("exhibition_id = ?", exhibitionID).
+		Pluck("user_id", &userIDs).Error; err != nil {
+		return fmt.Errorf("failed to find attendees: %w", err)
+	}
+	for _, userID := range userIDs {
+		if err := notifications.WriteNotification(tx, userID, notificationType, message, "exhibition", exhibitionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
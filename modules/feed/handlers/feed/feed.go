@@ -0,0 +1,207 @@
+package feed
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	collection "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
+	feedModels "github.com/muga20/artsMarket/modules/feed/models"
+	"github.com/muga20/artsMarket/modules/feed/timeline"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/privacy"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 50
+)
+
+// ArtworkSummary is the artwork an activity is about
+type ArtworkSummary struct {
+	ID        uuid.UUID `json:"id"`
+	Title     string    `json:"title"`
+	Slug      string    `json:"slug"`
+	Price     *float64  `json:"price"`
+	IsForSale bool      `json:"is_for_sale"`
+	ImageURL  string    `json:"image_url,omitempty"`
+}
+
+// CollectionSummary is the collection an activity is about
+type CollectionSummary struct {
+	ID            uuid.UUID `json:"id"`
+	Name          string    `json:"name"`
+	Slug          string    `json:"slug"`
+	CoverImageURL string    `json:"cover_image_url,omitempty"`
+}
+
+// FeedItem is one activity in the feed with everything needed to show it
+type FeedItem struct {
+	ID         uuid.UUID          `json:"id"`
+	Type       string             `json:"type"`
+	Actor      privacy.UserView   `json:"actor"`
+	Artwork    *ArtworkSummary    `json:"artwork,omitempty"`
+	Collection *CollectionSummary `json:"collection,omitempty"`
+	Metadata   json.RawMessage    `json:"metadata,omitempty"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// GetFeed godoc
+// @Summary Get the home feed
+// @Description Returns recent activity of the accounts the authenticated user follows, newest first: newly approved artworks, published collections and price drops. Activity of blocked users is excluded.
+// @Tags Feed
+// @Produce json
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 50)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /feed [get]
+func GetFeed(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		limit := c.QueryInt("limit", defaultPageSize)
+		if limit < 1 || limit > maxPageSize {
+			limit = defaultPageSize
+		}
+
+		activities, nextCursor, err := timeline.Page(c.Context(), db, user.ID, c.Query("cursor"), limit)
+		if err != nil {
+			if errors.Is(err, utils.ErrInvalidCursor) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid cursor"))
+			}
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		items, err := hydrate(c, db, activities)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"items":       items,
+			"next_cursor": nextCursor,
+		}, nil)
+	}
+}
+
+// hydrate loads the actors and entities of the activities. Activities whose
+// artwork or collection was removed or is no longer public are dropped.
+func hydrate(c *fiber.Ctx, db *gorm.DB, activities []feedModels.Activity) ([]FeedItem, error) {
+	items := make([]FeedItem, 0, len(activities))
+	if len(activities) == 0 {
+		return items, nil
+	}
+
+	var actorIDs, artworkIDs, collectionIDs []uuid.UUID
+	for _, activity := range activities {
+		actorIDs = append(actorIDs, activity.ActorID)
+		switch activity.EntityType {
+		case feedModels.EntityArtwork:
+			artworkIDs = append(artworkIDs, activity.EntityID)
+		case feedModels.EntityCollection:
+			collectionIDs = append(collectionIDs, activity.EntityID)
+		}
+	}
+
+	var actors []privacy.UserView
+	if err := db.Table("users").
+		Select(`
+                users.id,
+                users.username,
+                user_details.first_name,
+                user_details.last_name,
+                user_details.profile_image
+            `).
+		Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
+		Where("users.id IN ?", actorIDs).
+		Scan(&actors).Error; err != nil {
+		return nil, fmt.Errorf("failed to load activity actors: %w", err)
+	}
+
+	// Contact details follow each actor's privacy settings
+	policy, err := privacy.ForRequest(c, db)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.ApplyAll(actors); err != nil {
+		return nil, err
+	}
+	actorsByID := make(map[uuid.UUID]privacy.UserView, len(actors))
+	for _, actor := range actors {
+		actorsByID[actor.ID] = actor
+	}
+
+	artworks := make(map[uuid.UUID]*ArtworkSummary)
+	if len(artworkIDs) > 0 {
+		var found []artwork.Artwork
+		if err := db.Preload("Images").
+			Where("id IN ? AND status = ?", artworkIDs, artwork.ApprovedStatus).
+			Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load artworks: %w", err)
+		}
+		for _, a := range found {
+			artworks[a.ID] = &ArtworkSummary{
+				ID:        a.ID,
+				Title:     a.Title,
+				Slug:      a.Slug,
+				Price:     a.Price,
+				IsForSale: a.IsForSale,
+				ImageURL:  a.PrimaryImageURL(),
+			}
+		}
+	}
+
+	collections := make(map[uuid.UUID]*CollectionSummary)
+	if len(collectionIDs) > 0 {
+		var found []collection.Collection
+		if err := db.Where("id IN ? AND status = ?", collectionIDs, collection.PublishedStatus).
+			Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load collections: %w", err)
+		}
+		for _, col := range found {
+			collections[col.ID] = &CollectionSummary{
+				ID:            col.ID,
+				Name:          col.Name,
+				Slug:          col.Slug,
+				CoverImageURL: col.CoverImageURL,
+			}
+		}
+	}
+
+	for _, activity := range activities {
+		item := FeedItem{
+			ID:        activity.ID,
+			Type:      activity.Type,
+			Actor:     actorsByID[activity.ActorID],
+			Metadata:  activity.Metadata,
+			CreatedAt: activity.CreatedAt,
+		}
+		switch activity.EntityType {
+		case feedModels.EntityArtwork:
+			if item.Artwork = artworks[activity.EntityID]; item.Artwork == nil {
+				continue
+			}
+		case feedModels.EntityCollection:
+			if item.Collection = collections[activity.EntityID]; item.Collection == nil {
+				continue
+			}
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
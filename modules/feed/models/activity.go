@@ -0,0 +1,49 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Activity types shown in followers' feeds
+const (
+	ActivityArtworkPublished    = "artwork_published"
+	ActivityCollectionPublished = "collection_published"
+	ActivityPriceDrop           = "price_drop"
+)
+
+// Entity types an activity can be about
+const (
+	EntityArtwork    = "artwork"
+	EntityCollection = "collection"
+)
+
+// Activity is something a user did that their followers see in their feed.
+// The table is the source the Redis timelines are fanned out from and
+// rebuilt from.
+type Activity struct {
+	ID         uuid.UUID       `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	ActorID    uuid.UUID       `gorm:"type:char(36);not null;index:idx_activity_actor_created" json:"actor_id"`
+	Type       string          `gorm:"type:varchar(50);not null" json:"type"`
+	EntityType string          `gorm:"type:varchar(50);not null;index:idx_activity_entity" json:"entity_type"`
+	EntityID   uuid.UUID       `gorm:"type:char(36);not null;index:idx_activity_entity" json:"entity_id"`
+	Metadata   json.RawMessage `gorm:"type:json" json:"metadata,omitempty"`
+	CreatedAt  time.Time       `gorm:"type:timestamp(3);default:CURRENT_TIMESTAMP(3);index:idx_activity_actor_created" json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (a *Activity) BeforeCreate(tx *gorm.DB) (err error) {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return
+}
+
+// PriceDrop is the metadata of a price drop activity
+type PriceDrop struct {
+	OldPrice float64 `json:"old_price"`
+	NewPrice float64 `json:"new_price"`
+}
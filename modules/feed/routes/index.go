@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/feed/handlers/feed"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/middleware"
+	"gorm.io/gorm"
+)
+
+// FeedSetupRoutes sets up the home feed routes
+func FeedSetupRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *handlers.ResponseHandler) {
+	apiGroup.Get("/feed", middleware.AuthMiddleware(db, responseHandler), feed.GetFeed(db, responseHandler))
+}
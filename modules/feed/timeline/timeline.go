@@ -0,0 +1,288 @@
+// Package timeline maintains each user's home feed. Activities are stored in
+// the database and fanned out on write into a Redis sorted set per follower,
+// scored by time. A timeline missing from Redis is rebuilt from the database,
+// and reads fall back to the database entirely when Redis is unavailable.
+package timeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/modules/feed/models"
+	"github.com/muga20/artsMarket/modules/notifications/services"
+	users "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/tasks"
+	"github.com/muga20/artsMarket/pkg/utils"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// maxEntries is how many activities a Redis timeline keeps, and so how far
+	// back a feed reaches
+	maxEntries = 500
+	// timelineTTL lets the timelines of inactive users expire
+	timelineTTL = 7 * 24 * time.Hour
+	// fanOutBatch is how many followers are written per Redis round trip
+	fanOutBatch = 1000
+	// tieBuffer covers activities sharing the cursor's millisecond
+	tieBuffer = 20
+)
+
+func timelineKey(userID uuid.UUID) string {
+	return "feed:timeline:" + userID.String()
+}
+
+// builtKey marks a timeline as complete. Without it the timeline only holds
+// what was fanned out since it expired and is rebuilt before it is read.
+func builtKey(userID uuid.UUID) string {
+	return "feed:built:" + userID.String()
+}
+
+// Record stores an activity and schedules its fan-out inside the caller's
+// transaction, so followers only see activity that was committed
+func Record(tx *gorm.DB, actorID uuid.UUID, activityType, entityType string, entityID uuid.UUID, metadata interface{}) error {
+	activity := models.Activity{
+		ActorID:    actorID,
+		Type:       activityType,
+		EntityType: entityType,
+		EntityID:   entityID,
+		// Timeline scores have millisecond precision
+		CreatedAt: time.Now().Truncate(time.Millisecond),
+	}
+	if metadata != nil {
+		raw, err := json.Marshal(metadata)
+		if err != nil {
+			return fmt.Errorf("failed to encode activity metadata: %w", err)
+		}
+		activity.Metadata = raw
+	}
+	if err := tx.Create(&activity).Error; err != nil {
+		return fmt.Errorf("failed to record activity: %w", err)
+	}
+
+	_, err := services.WriteOutbox(tx, tasks.TypeFanOutActivity, tasks.FanOutActivityPayload{
+		ActivityID: activity.ID.String(),
+	})
+	return err
+}
+
+// FanOut adds an activity to the timeline of every approved follower of its actor
+func FanOut(ctx context.Context, db *gorm.DB, activityID uuid.UUID) error {
+	var activity models.Activity
+	if err := db.Where("id = ?", activityID).First(&activity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The actor's account was purged before the fan-out ran
+			return nil
+		}
+		return fmt.Errorf("failed to load activity: %w", err)
+	}
+
+	entry := redis.Z{Score: float64(activity.CreatedAt.UnixMilli()), Member: activity.ID.String()}
+	var followers []users.Follower
+	return db.Select("id, follower_id").
+		Where("following_id = ? AND status = ?", activity.ActorID, users.FollowStatusAccepted).
+		FindInBatches(&followers, fanOutBatch, func(tx *gorm.DB, batch int) error {
+			pipe := config.Redis().Pipeline()
+			for _, follower := range followers {
+				key := timelineKey(follower.FollowerID)
+				pipe.ZAdd(ctx, key, entry)
+				pipe.ZRemRangeByRank(ctx, key, 0, -(maxEntries + 1))
+				pipe.Expire(ctx, key, timelineTTL)
+			}
+			if _, err := pipe.Exec(ctx); err != nil {
+				return fmt.Errorf("failed to write timelines: %w", err)
+			}
+			return nil
+		}).Error
+}
+
+// Invalidate makes the next read rebuild the user's timeline from the
+// database, for example after they follow someone new
+func Invalidate(ctx context.Context, userID uuid.UUID) {
+	if err := config.Redis().Del(ctx, builtKey(userID)).Err(); err != nil {
+		log.Printf("Failed to invalidate feed of user %s: %v", userID, err)
+	}
+}
+
+// Page returns up to limit activities older than the cursor, newest first,
+// with the cursor of the next page. Activities of accounts the user no
+// longer follows, has blocked or was blocked by, or that were deactivated
+// are left out, so a page may hold fewer than limit activities.
+func Page(ctx context.Context, db *gorm.DB, userID uuid.UUID, cursor string, limit int) ([]models.Activity, string, error) {
+	var before time.Time
+	var beforeID uuid.UUID
+	if cursor != "" {
+		var err error
+		if before, beforeID, err = utils.DecodeCursor(cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	activities, next, err := pageFromRedis(ctx, db, userID, before, beforeID, limit)
+	if err == nil {
+		return activities, next, nil
+	}
+	var redisErr redisError
+	if !errors.As(err, &redisErr) {
+		return nil, "", err
+	}
+	log.Printf("Reading feed of user %s from the database: %v", userID, err)
+	return pageFromDatabase(db, userID, before, beforeID, limit)
+}
+
+// redisError marks failures of Redis itself, which the database can stand in for
+type redisError struct{ error }
+
+func pageFromRedis(ctx context.Context, db *gorm.DB, userID uuid.UUID, before time.Time, beforeID uuid.UUID, limit int) ([]models.Activity, string, error) {
+	rdb := config.Redis()
+
+	built, err := rdb.Exists(ctx, builtKey(userID)).Result()
+	if err != nil {
+		return nil, "", redisError{err}
+	}
+	if built == 0 {
+		if err := rebuild(ctx, db, userID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	max := "+inf"
+	if !before.IsZero() {
+		max = strconv.FormatInt(before.UnixMilli(), 10)
+	}
+	entries, err := rdb.ZRevRangeByScoreWithScores(ctx, timelineKey(userID), &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   max,
+		Count: int64(limit + 1 + tieBuffer),
+	}).Result()
+	if err != nil {
+		return nil, "", redisError{err}
+	}
+
+	// Activities sharing the cursor's millisecond come in descending ID order;
+	// skip the ones the previous page already returned
+	ids := make([]uuid.UUID, 0, limit+1)
+	scores := make(map[uuid.UUID]int64, limit+1)
+	for _, entry := range entries {
+		id, err := uuid.Parse(fmt.Sprint(entry.Member))
+		if err != nil {
+			continue
+		}
+		score := int64(entry.Score)
+		if !before.IsZero() && score == before.UnixMilli() && id.String() >= beforeID.String() {
+			continue
+		}
+		ids = append(ids, id)
+		scores[id] = score
+		if len(ids) > limit {
+			break
+		}
+	}
+
+	next := ""
+	if len(ids) > limit {
+		ids = ids[:limit]
+		last := ids[limit-1]
+		next = utils.EncodeCursor(time.UnixMilli(scores[last]), last)
+	}
+	if len(ids) == 0 {
+		return nil, next, nil
+	}
+
+	var found []models.Activity
+	if err := visible(db, userID).Where("activities.id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to load activities: %w", err)
+	}
+	byID := make(map[uuid.UUID]models.Activity, len(found))
+	for _, activity := range found {
+		byID[activity.ID] = activity
+	}
+	activities := make([]models.Activity, 0, len(ids))
+	for _, id := range ids {
+		if activity, ok := byID[id]; ok {
+			activities = append(activities, activity)
+		}
+	}
+	return activities, next, nil
+}
+
+func pageFromDatabase(db *gorm.DB, userID uuid.UUID, before time.Time, beforeID uuid.UUID, limit int) ([]models.Activity, string, error) {
+	query := visible(db, userID)
+	if !before.IsZero() {
+		query = query.Where("activities.created_at < ? OR (activities.created_at = ? AND activities.id < ?)", before, before, beforeID)
+	}
+
+	var activities []models.Activity
+	if err := query.
+		Order("activities.created_at DESC, activities.id DESC").
+		Limit(limit + 1).
+		Find(&activities).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to load feed: %w", err)
+	}
+
+	next := ""
+	if len(activities) > limit {
+		activities = activities[:limit]
+		last := activities[limit-1]
+		next = utils.EncodeCursor(last.CreatedAt, last.ID)
+	}
+	return activities, next, nil
+}
+
+// rebuild replaces the user's Redis timeline with their latest activities
+// from the database
+func rebuild(ctx context.Context, db *gorm.DB, userID uuid.UUID) error {
+	var latest []models.Activity
+	if err := visible(db, userID).
+		Select("activities.id, activities.created_at").
+		Order("activities.created_at DESC, activities.id DESC").
+		Limit(maxEntries).
+		Find(&latest).Error; err != nil {
+		return fmt.Errorf("failed to load activities: %w", err)
+	}
+
+	key := timelineKey(userID)
+	_, err := config.Redis().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, key)
+		if len(latest) > 0 {
+			entries := make([]redis.Z, len(latest))
+			for i, activity := range latest {
+				entries[i] = redis.Z{Score: float64(activity.CreatedAt.UnixMilli()), Member: activity.ID.String()}
+			}
+			pipe.ZAdd(ctx, key, entries...)
+			pipe.Expire(ctx, key, timelineTTL)
+		}
+		pipe.Set(ctx, builtKey(userID), 1, timelineTTL)
+		return nil
+	})
+	if err != nil {
+		return redisError{err}
+	}
+	return nil
+}
+
+// visible selects the activities the user may see in their feed: those of
+// active accounts they follow with no block between them in either direction
+func visible(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Model(&models.Activity{}).
+		Select("activities.*").
+		Joins("JOIN users ON users.id = activities.actor_id").
+		Where("users.is_active = ?", true).
+		Where("activities.actor_id IN (?)", db.Model(&users.Follower{}).
+			Select("following_id").
+			Where("follower_id = ? AND status = ?", userID, users.FollowStatusAccepted)).
+		Where("activities.actor_id NOT IN (?)", db.Model(&users.BlockedUser{}).
+			Select("blocked_user_id").
+			Where("user_id = ?", userID)).
+		Where("activities.actor_id NOT IN (?)", db.Model(&users.BlockedUser{}).
+			Select("user_id").
+			Where("blocked_user_id = ?", userID))
+}
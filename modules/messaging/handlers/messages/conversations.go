@@ -0,0 +1,281 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	messaging "github.com/muga20/artsMarket/modules/messaging/models"
+	"github.com/muga20/artsMarket/modules/messaging/services"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/privacy"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/utils"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultPageSize = 30
+	maxPageSize     = 100
+)
+
+// StartConversationRequest names the user to start a conversation with
+type StartConversationRequest struct {
+	RecipientID string `json:"recipient_id"`
+}
+
+// ConversationSummary is a conversation as listed in the inbox
+type ConversationSummary struct {
+	ID            uuid.UUID          `json:"id"`
+	Participant   privacy.UserView   `json:"participant"`
+	LastMessage   *messaging.Message `json:"last_message,omitempty"`
+	UnreadCount   int64              `json:"unread_count"`
+	LastMessageAt time.Time          `json:"last_message_at"`
+}
+
+// StartConversation godoc
+// @Summary Start a conversation
+// @Description Returns the conversation between the authenticated user and the recipient, starting one if needed. The recipient's message settings and blocks are enforced.
+// @Tags Messaging
+// @Accept json
+// @Produce json
+// @Param body body StartConversationRequest true "Recipient"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /messages/conversations [post]
+func StartConversation(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req StartConversationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		recipientID, err := uuid.Parse(req.RecipientID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid recipient ID"))
+		}
+		if recipientID == user.ID {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "You cannot message yourself"))
+		}
+
+		if err := services.CanMessage(db, user.ID, recipientID); err != nil {
+			return responseHandler.HandleResponse(c, nil, messagingError(err))
+		}
+
+		conversation, err := services.FindOrCreateConversation(db, user.ID, recipientID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		summaries, err := summarize(c, db, user.ID, []messaging.Conversation{*conversation})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"conversation": summaries[0],
+		}, nil)
+	}
+}
+
+// ListConversations godoc
+// @Summary List conversations
+// @Description Lists the authenticated user's conversations, most recently active first, with the last message and unread count of each
+// @Tags Messaging
+// @Produce json
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 30, max 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Router /messages/conversations [get]
+func ListConversations(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		limit := pageSize(c)
+
+		// Conversations with deactivated users drop out of the inbox
+		query := db.Model(&messaging.Conversation{}).
+			Select("conversations.*").
+			Joins("JOIN users ON users.id = CASE WHEN conversations.user_one_id = ? THEN conversations.user_two_id ELSE conversations.user_one_id END", user.ID).
+			Where("(conversations.user_one_id = ? OR conversations.user_two_id = ?) AND users.is_active = ?", user.ID, user.ID, true)
+
+		if cursor := c.Query("cursor"); cursor != "" {
+			at, id, err := utils.DecodeCursor(cursor)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid cursor"))
+			}
+			query = query.Where("conversations.last_message_at < ? OR (conversations.last_message_at = ? AND conversations.id < ?)", at, at, id)
+		}
+
+		var conversations []messaging.Conversation
+		if err := query.
+			Order("conversations.last_message_at DESC, conversations.id DESC").
+			Limit(limit + 1).
+			Find(&conversations).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to list conversations: %w", err))
+		}
+
+		nextCursor := ""
+		if len(conversations) > limit {
+			conversations = conversations[:limit]
+			last := conversations[limit-1]
+			nextCursor = utils.EncodeCursor(last.LastMessageAt, last.ID)
+		}
+
+		summaries, err := summarize(c, db, user.ID, conversations)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"conversations": summaries,
+			"next_cursor":   nextCursor,
+		}, nil)
+	}
+}
+
+// summarize adds the other participant, last message and unread count to
+// each conversation
+func summarize(c *fiber.Ctx, db *gorm.DB, userID uuid.UUID, conversations []messaging.Conversation) ([]ConversationSummary, error) {
+	summaries := make([]ConversationSummary, len(conversations))
+	if len(conversations) == 0 {
+		return summaries, nil
+	}
+
+	conversationIDs := make([]uuid.UUID, len(conversations))
+	participantIDs := make([]uuid.UUID, len(conversations))
+	for i, conversation := range conversations {
+		conversationIDs[i] = conversation.ID
+		participantIDs[i] = conversation.OtherParticipant(userID)
+	}
+
+	var participants []privacy.UserView
+	if err := db.Table("users").
+		Select(`
+                users.id,
+                users.username,
+                user_details.first_name,
+                user_details.last_name,
+                user_details.profile_image
+            `).
+		Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
+		Where("users.id IN ?", participantIDs).
+		Scan(&participants).Error; err != nil {
+		return nil, fmt.Errorf("failed to load participants: %w", err)
+	}
+
+	// Contact details follow each participant's privacy settings
+	policy, err := privacy.ForRequest(c, db)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.ApplyAll(participants); err != nil {
+		return nil, err
+	}
+	participantsByID := make(map[uuid.UUID]privacy.UserView, len(participants))
+	for _, participant := range participants {
+		participantsByID[participant.ID] = participant
+	}
+
+	var unread []struct {
+		ConversationID uuid.UUID
+		Count          int64
+	}
+	if err := db.Model(&messaging.Message{}).
+		Select("conversation_id, COUNT(*) as count").
+		Where("conversation_id IN ? AND sender_id != ? AND read_at IS NULL", conversationIDs, userID).
+		Group("conversation_id").
+		Scan(&unread).Error; err != nil {
+		return nil, fmt.Errorf("failed to count unread messages: %w", err)
+	}
+	unreadByID := make(map[uuid.UUID]int64, len(unread))
+	for _, u := range unread {
+		unreadByID[u.ConversationID] = u.Count
+	}
+
+	for i, conversation := range conversations {
+		summaries[i] = ConversationSummary{
+			ID:            conversation.ID,
+			Participant:   participantsByID[participantIDs[i]],
+			UnreadCount:   unreadByID[conversation.ID],
+			LastMessageAt: conversation.LastMessageAt,
+		}
+
+		var last messaging.Message
+		err := db.Where("conversation_id = ?", conversation.ID).
+			Order("created_at DESC, id DESC").
+			First(&last).Error
+		if err == nil {
+			summaries[i].LastMessage = &last
+		} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to load last message: %w", err)
+		}
+	}
+	return summaries, nil
+}
+
+// findConversation loads a conversation the user takes part in
+func findConversation(db *gorm.DB, conversationID string, userID uuid.UUID) (*messaging.Conversation, error) {
+	id, err := uuid.Parse(conversationID)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid conversation ID")
+	}
+
+	var conversation messaging.Conversation
+	if err := db.Where("id = ?", id).First(&conversation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "Conversation not found")
+		}
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+	if !conversation.Includes(userID) {
+		return nil, fiber.NewError(fiber.StatusNotFound, "Conversation not found")
+	}
+	return &conversation, nil
+}
+
+// messagingError turns a refused message into the response the client sees.
+// Blocks are reported without saying which user set them.
+func messagingError(err error) error {
+	switch {
+	case errors.Is(err, services.ErrRecipientNotFound):
+		return fiber.NewError(fiber.StatusNotFound, "User not found")
+	case errors.Is(err, services.ErrBlocked):
+		return fiber.NewError(fiber.StatusForbidden, "You cannot message this user")
+	case errors.Is(err, services.ErrMessagesNotAllowed):
+		return fiber.NewError(fiber.StatusForbidden, "This user does not accept messages from you")
+	default:
+		return err
+	}
+}
+
+// pageSize reads the limit query parameter within the allowed range
+func pageSize(c *fiber.Ctx) int {
+	limit := c.QueryInt("limit", defaultPageSize)
+	if limit < 1 {
+		return defaultPageSize
+	}
+	if limit > maxPageSize {
+		return maxPageSize
+	}
+	return limit
+}
@@ -0,0 +1,279 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	artworkServices "github.com/muga20/artsMarket/modules/artwork-management/services"
+	messaging "github.com/muga20/artsMarket/modules/messaging/models"
+	"github.com/muga20/artsMarket/modules/messaging/services"
+	notifications "github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// maxMessageLength bounds the text of a single message
+const maxMessageLength = 5000
+
+// SendMessageRequest is the text and optional artwork reference of a message
+type SendMessageRequest struct {
+	Body      string `json:"body" form:"body"`
+	ArtworkID string `json:"artwork_id" form:"artwork_id"`
+}
+
+// ListMessages godoc
+// @Summary List messages in a conversation
+// @Description Lists the messages of a conversation the authenticated user takes part in, newest first
+// @Tags Messaging
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 30, max 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /messages/conversations/{id}/messages [get]
+func ListMessages(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		conversation, err := findConversation(db, c.Params("id"), user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		limit := pageSize(c)
+		query := db.Where("conversation_id = ?", conversation.ID)
+		if cursor := c.Query("cursor"); cursor != "" {
+			at, id, err := utils.DecodeCursor(cursor)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid cursor"))
+			}
+			query = query.Where("created_at < ? OR (created_at = ? AND id < ?)", at, at, id)
+		}
+
+		var messages []messaging.Message
+		if err := query.
+			Order("created_at DESC, id DESC").
+			Limit(limit + 1).
+			Find(&messages).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to list messages: %w", err))
+		}
+
+		nextCursor := ""
+		if len(messages) > limit {
+			messages = messages[:limit]
+			last := messages[limit-1]
+			nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"messages":    messages,
+			"next_cursor": nextCursor,
+		}, nil)
+	}
+}
+
+// SendMessage godoc
+// @Summary Send a message
+// @Description Sends a message in a conversation. A message may reference an artwork of either participant, for example to inquire about it, and may carry one image attachment. The recipient is notified.
+// @Tags Messaging
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param body formData string false "Message text"
+// @Param artwork_id formData string false "Artwork the message is about"
+// @Param attachment formData file false "Image attachment"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /messages/conversations/{id}/messages [post]
+func SendMessage(db *gorm.DB, cld *config.CloudinaryClient, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		conversation, err := findConversation(db, c.Params("id"), user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		recipientID := conversation.OtherParticipant(user.ID)
+
+		var req SendMessageRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		req.Body = strings.TrimSpace(req.Body)
+		if len(req.Body) > maxMessageLength {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("Message cannot be longer than %d characters", maxMessageLength)))
+		}
+
+		// The attachment is optional, so a missing file is not an error
+		attachment, _ := c.FormFile("attachment")
+		if req.Body == "" && attachment == nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "A message needs text or an attachment"))
+		}
+		if attachment != nil {
+			if err := artworkServices.ValidateImageFile(attachment); err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+		}
+
+		// Settings and blocks may have changed since the conversation started
+		if err := services.CanMessage(db, user.ID, recipientID); err != nil {
+			return responseHandler.HandleResponse(c, nil, messagingError(err))
+		}
+
+		message := messaging.Message{
+			ConversationID: conversation.ID,
+			SenderID:       user.ID,
+			Body:           req.Body,
+		}
+
+		if req.ArtworkID != "" {
+			artworkID, err := uuid.Parse(req.ArtworkID)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid artwork ID"))
+			}
+			if err := checkArtworkReference(db, artworkID, user.ID, recipientID); err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+			message.ArtworkID = &artworkID
+		}
+
+		if attachment != nil {
+			fileURL, err := cld.UploadFile(attachment, fmt.Sprintf("messages/%s", conversation.ID))
+			if err != nil {
+				log.Printf("Cloudinary upload failed: %v", err)
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusInternalServerError, "Failed to upload attachment"))
+			}
+			message.AttachmentURL = fileURL
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&message).Error; err != nil {
+				return fmt.Errorf("failed to save message: %w", err)
+			}
+			if err := tx.Model(&messaging.Conversation{}).
+				Where("id = ?", conversation.ID).
+				Updates(map[string]interface{}{
+					"last_message_at": message.CreatedAt,
+					"updated_at":      time.Now(),
+				}).Error; err != nil {
+				return fmt.Errorf("failed to update conversation: %w", err)
+			}
+
+			// The notification only goes out if the message is saved
+			return notifications.WriteNotificationFrom(tx, recipientID, user.ID, "message",
+				messageNotification(db, user), "conversation", conversation.ID)
+		})
+		if err != nil {
+			if message.AttachmentURL != "" {
+				if deleteErr := cld.DeleteFile(message.AttachmentURL); deleteErr != nil {
+					log.Printf("Failed to delete orphaned attachment %s: %v", message.AttachmentURL, deleteErr)
+				}
+			}
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": message,
+		}, nil)
+	}
+}
+
+// MarkConversationRead godoc
+// @Summary Mark a conversation as read
+// @Description Sets the read receipt on every unread message the other participant sent in the conversation
+// @Tags Messaging
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /messages/conversations/{id}/read [post]
+func MarkConversationRead(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		conversation, err := findConversation(db, c.Params("id"), user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		readAt := time.Now()
+		result := db.Model(&messaging.Message{}).
+			Where("conversation_id = ? AND sender_id != ? AND read_at IS NULL", conversation.ID, user.ID).
+			Update("read_at", readAt)
+		if result.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to mark messages as read: %w", result.Error))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"marked_read": result.RowsAffected,
+			"read_at":     readAt,
+		}, nil)
+	}
+}
+
+// checkArtworkReference makes sure a message only refers to an artwork of one
+// of the participants that the sender is allowed to see
+func checkArtworkReference(db *gorm.DB, artworkID, senderID, recipientID uuid.UUID) error {
+	var referenced artwork.Artwork
+	if err := db.Select("id, user_id, status").Where("id = ?", artworkID).First(&referenced).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fiber.NewError(fiber.StatusNotFound, "Artwork not found")
+		}
+		return fmt.Errorf("failed to load artwork: %w", err)
+	}
+	if referenced.UserID != senderID && referenced.UserID != recipientID {
+		return fiber.NewError(fiber.StatusBadRequest, "The artwork must belong to one of the participants")
+	}
+	if referenced.UserID != senderID && referenced.Status != artwork.ApprovedStatus {
+		return fiber.NewError(fiber.StatusNotFound, "Artwork not found")
+	}
+	return nil
+}
+
+// messageNotification formats the new message notification, including the
+// sender's profile photo when they have one
+func messageNotification(db *gorm.DB, sender models.User) string {
+	var profilePhoto string
+	if err := db.Model(&models.UserDetail{}).
+		Where("user_id = ?", sender.ID).
+		Select("profile_image").
+		First(&profilePhoto).Error; err != nil {
+		return fmt.Sprintf("%s sent you a message", sender.Username)
+	}
+	return fmt.Sprintf("%s|%s sent you a message", sender.Username, profilePhoto)
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Conversation is a one-to-one message thread. The participants are stored
+// in a fixed order so each pair of users has at most one conversation.
+type Conversation struct {
+	ID            uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	UserOneID     uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_conversation_pair" json:"user_one_id"`
+	UserTwoID     uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_conversation_pair;index" json:"user_two_id"`
+	LastMessageAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index" json:"last_message_at"`
+	CreatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (c *Conversation) BeforeCreate(tx *gorm.DB) (err error) {
+	if c.ID == uuid.Nil {
+		c.ID = uuid.New()
+	}
+	return
+}
+
+// OrderParticipants returns the two users in the order a conversation stores them
+func OrderParticipants(a, b uuid.UUID) (uuid.UUID, uuid.UUID) {
+	if a.String() > b.String() {
+		return b, a
+	}
+	return a, b
+}
+
+// Includes reports whether the user takes part in the conversation
+func (c *Conversation) Includes(userID uuid.UUID) bool {
+	return c.UserOneID == userID || c.UserTwoID == userID
+}
+
+// OtherParticipant returns the participant who is not the given user
+func (c *Conversation) OtherParticipant(userID uuid.UUID) uuid.UUID {
+	if c.UserOneID == userID {
+		return c.UserTwoID
+	}
+	return c.UserOneID
+}
+
+// Message is a single message in a conversation. ReadAt is set once the
+// recipient has read it.
+type Message struct {
+	ID             uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	ConversationID uuid.UUID  `gorm:"type:char(36);not null;index:idx_message_conversation_created" json:"conversation_id"`
+	SenderID       uuid.UUID  `gorm:"type:char(36);not null;index" json:"sender_id"`
+	Body           string     `gorm:"type:text" json:"body"`
+	ArtworkID      *uuid.UUID `gorm:"type:char(36);index" json:"artwork_id,omitempty"` // Artwork the message asks about
+	AttachmentURL  string     `gorm:"type:varchar(255)" json:"attachment_url,omitempty"`
+	ReadAt         *time.Time `gorm:"type:timestamp" json:"read_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_message_conversation_created" json:"created_at"`
+
+	// Foreign key relations
+	Conversation Conversation `gorm:"foreignKey:ConversationID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (m *Message) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return
+}
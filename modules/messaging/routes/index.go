@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/modules/messaging/handlers/messages"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/middleware"
+	"gorm.io/gorm"
+)
+
+// MessagingSetupRoutes sets up direct messaging routes
+func MessagingSetupRoutes(apiGroup fiber.Router, db *gorm.DB, cld *config.CloudinaryClient, responseHandler *handlers.ResponseHandler) {
+	messageGroup := apiGroup.Group("/messages")
+
+	messageGroup.Use(middleware.AuthMiddleware(db, responseHandler))
+
+	// Only verified accounts may contact other users
+	requireVerified := middleware.RequireVerifiedEmail(db, responseHandler)
+
+	// Conversations
+	messageGroup.Get("/conversations", messages.ListConversations(db, responseHandler))
+	messageGroup.Post("/conversations", requireVerified, messages.StartConversation(db, responseHandler))
+
+	// Messages and read receipts
+	messageGroup.Get("/conversations/:id/messages", messages.ListMessages(db, responseHandler))
+	messageGroup.Post("/conversations/:id/messages", requireVerified, messages.SendMessage(db, cld, responseHandler))
+	messageGroup.Post("/conversations/:id/read", messages.MarkConversationRead(db, responseHandler))
+}
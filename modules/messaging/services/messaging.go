@@ -0,0 +1,91 @@
+// Package services holds the rules of direct messaging: who may message whom
+// and finding the conversation between two users.
+package services
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	messaging "github.com/muga20/artsMarket/modules/messaging/models"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRecipientNotFound is returned when the recipient does not exist or is deactivated
+	ErrRecipientNotFound = errors.New("recipient not found")
+	// ErrBlocked is returned when either user has blocked the other
+	ErrBlocked = errors.New("messaging is blocked between these users")
+	// ErrMessagesNotAllowed is returned when the recipient's settings exclude the sender
+	ErrMessagesNotAllowed = errors.New("recipient does not accept messages from this user")
+)
+
+// CanMessage checks that the sender may send a message to the recipient under
+// the recipient's AllowMessagesFrom setting and the block lists of both users
+func CanMessage(db *gorm.DB, senderID, recipientID uuid.UUID) error {
+	var active int64
+	if err := db.Model(&models.User{}).
+		Where("id = ? AND is_active = ?", recipientID, true).
+		Count(&active).Error; err != nil {
+		return fmt.Errorf("failed to find recipient: %w", err)
+	}
+	if active == 0 {
+		return ErrRecipientNotFound
+	}
+
+	var blocks int64
+	if err := db.Model(&models.BlockedUser{}).
+		Where("(user_id = ? AND blocked_user_id = ?) OR (user_id = ? AND blocked_user_id = ?)",
+			senderID, recipientID, recipientID, senderID).
+		Count(&blocks).Error; err != nil {
+		return fmt.Errorf("failed to check block status: %w", err)
+	}
+	if blocks > 0 {
+		return ErrBlocked
+	}
+
+	// Users who never saved their settings accept messages from everyone
+	allowFrom := models.MessagesFromEveryone
+	var settings models.UserPrivacySetting
+	if err := db.Where("user_id = ?", recipientID).First(&settings).Error; err == nil {
+		allowFrom = settings.AllowMessagesFrom
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check privacy settings: %w", err)
+	}
+
+	var allowed bool
+	var err error
+	switch allowFrom {
+	case models.MessagesFromEveryone:
+		allowed = true
+	case models.MessagesFromFollowing:
+		allowed, err = models.IsApprovedFollower(db, recipientID, senderID)
+	case models.MessagesFromFollowers:
+		allowed, err = models.IsApprovedFollower(db, senderID, recipientID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check follow status: %w", err)
+	}
+	if !allowed {
+		return ErrMessagesNotAllowed
+	}
+	return nil
+}
+
+// FindOrCreateConversation returns the conversation between two users,
+// starting one if they have never messaged each other
+func FindOrCreateConversation(db *gorm.DB, a, b uuid.UUID) (*messaging.Conversation, error) {
+	userOne, userTwo := messaging.OrderParticipants(a, b)
+	conversation := messaging.Conversation{UserOneID: userOne, UserTwoID: userTwo}
+
+	// Two first messages sent at once both land on the same row
+	if err := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&conversation).Error; err != nil {
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+	if err := db.Where("user_one_id = ? AND user_two_id = ?", userOne, userTwo).First(&conversation).Error; err != nil {
+		return nil, fmt.Errorf("failed to load conversation: %w", err)
+	}
+	return &conversation, nil
+}
@@ -0,0 +1,48 @@
+package emails
+
+import (
+	"fmt"
+	"net/smtp"
+
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+)
+
+// SendAccountDeletionScheduledEmail confirms a deletion request and explains how to undo it
+func SendAccountDeletionScheduledEmail(toEmail string, purgeAt string, responseHandler *handlers.ResponseHandler) error {
+	// Get SMTP configuration from the config package
+	smtpHost := config.Envs.SMTPHost
+	smtpPort := config.Envs.SMTPPort
+	fromEmail := config.Envs.SMTPUser
+	fromPassword := config.Envs.SMTPPassword
+	clientURL := config.Envs.ClientURL
+
+	// Logging in during the grace period offers to reactivate the account
+	loginLink := fmt.Sprintf("%s/login", clientURL)
+
+	// Set up authentication information
+	auth := smtp.PlainAuth("", fromEmail, fromPassword, smtpHost)
+
+	// Compose the email
+	subject := "Your Account Is Scheduled for Deletion"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<p>We received a request to delete your account. It has been deactivated and you have been signed out of every device.</p>
+			<p>Your profile, artworks and personal data will be permanently deleted on %s.</p>
+			<p>Changed your mind? <a href="%s" style="color: blue; text-decoration: none;">Log in</a> before then to reactivate your account.</p>
+			<p>If you did not request this, log in and reactivate your account, then change your password.</p>
+		</body>
+		</html>`, purgeAt, loginLink)
+
+	// Format the email message
+	message := []byte(fmt.Sprintf("Subject: %s\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s", subject, body))
+
+	// Send the email
+	err := smtp.SendMail(fmt.Sprintf("%s:%s", smtpHost, smtpPort), auth, fromEmail, []string{toEmail}, message)
+	if err != nil {
+		// Handle the error using the provided responseHandler
+		return responseHandler.Handle(nil, nil, fmt.Errorf("failed to send account deletion email: %v", err))
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package emails
+
+import (
+	"fmt"
+	"net/smtp"
+
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+)
+
+// SendAccountUnlockEmail tells the user their account was locked and sends a link to unlock it
+func SendAccountUnlockEmail(toEmail string, unlockToken string, lockedUntil string, responseHandler *handlers.ResponseHandler) error {
+	// Get SMTP configuration from the config package
+	smtpHost := config.Envs.SMTPHost
+	smtpPort := config.Envs.SMTPPort
+	fromEmail := config.Envs.SMTPUser
+	fromPassword := config.Envs.SMTPPassword
+	clientURL := config.Envs.ClientURL
+
+	// Generate the unlock link
+	unlockLink := fmt.Sprintf("%s/unlock-account?token=%s", clientURL, unlockToken)
+
+	// Set up authentication information
+	auth := smtp.PlainAuth("", fromEmail, fromPassword, smtpHost)
+
+	// Compose the email
+	subject := "Your Account Has Been Locked"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<p>We locked your account after several failed sign-in attempts.</p>
+			<p>It will unlock automatically at %s. If these attempts were you, you can unlock it now:</p>
+			<p><a href="%s" style="color: blue; text-decoration: none;">Unlock Your Account</a></p>
+			<p>This link will expire in 24 hours.</p>
+			<p>If you did not try to sign in, we recommend resetting your password.</p>
+		</body>
+		</html>`, lockedUntil, unlockLink)
+
+	// Format the email message
+	message := []byte(fmt.Sprintf("Subject: %s\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s", subject, body))
+
+	// Send the email
+	err := smtp.SendMail(fmt.Sprintf("%s:%s", smtpHost, smtpPort), auth, fromEmail, []string{toEmail}, message)
+	if err != nil {
+		// Handle the error using the provided responseHandler
+		return responseHandler.Handle(nil, nil, fmt.Errorf("failed to send account unlock email: %v", err))
+	}
+	return nil
+}
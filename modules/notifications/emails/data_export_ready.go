@@ -0,0 +1,44 @@
+package emails
+
+import (
+	"fmt"
+	"net/smtp"
+
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+)
+
+// SendDataExportReadyEmail sends the download link for a finished data export
+func SendDataExportReadyEmail(toEmail string, downloadLink string, expiresAt string, responseHandler *handlers.ResponseHandler) error {
+	// Get SMTP configuration from the config package
+	smtpHost := config.Envs.SMTPHost
+	smtpPort := config.Envs.SMTPPort
+	fromEmail := config.Envs.SMTPUser
+	fromPassword := config.Envs.SMTPPassword
+
+	// Set up authentication information
+	auth := smtp.PlainAuth("", fromEmail, fromPassword, smtpHost)
+
+	// Compose the email
+	subject := "Your Data Export Is Ready"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<p>The copy of your data you requested is ready to download:</p>
+			<p><a href="%s" style="color: blue; text-decoration: none;">Download Your Data</a></p>
+			<p>The link expires at %s, after which the file is deleted. You can request a new export at any time.</p>
+			<p>If you did not request this export, please change your password.</p>
+		</body>
+		</html>`, downloadLink, expiresAt)
+
+	// Format the email message
+	message := []byte(fmt.Sprintf("Subject: %s\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s", subject, body))
+
+	// Send the email
+	err := smtp.SendMail(fmt.Sprintf("%s:%s", smtpHost, smtpPort), auth, fromEmail, []string{toEmail}, message)
+	if err != nil {
+		// Handle the error using the provided responseHandler
+		return responseHandler.Handle(nil, nil, fmt.Errorf("failed to send data export email: %v", err))
+	}
+	return nil
+}
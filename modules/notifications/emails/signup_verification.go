@@ -0,0 +1,48 @@
+package emails
+
+import (
+	"fmt"
+	"net/smtp"
+
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+)
+
+// SendSignupVerificationEmail sends the verification link to a newly registered account
+func SendSignupVerificationEmail(toEmail string, verificationToken string, responseHandler *handlers.ResponseHandler) error {
+	// Get SMTP configuration from the config package
+	smtpHost := config.Envs.SMTPHost
+	smtpPort := config.Envs.SMTPPort
+	fromEmail := config.Envs.SMTPUser
+	fromPassword := config.Envs.SMTPPassword
+	clientURL := config.Envs.ClientURL
+
+	// Generate the verification link
+	verifyLink := fmt.Sprintf("%s/verify-email?token=%s", clientURL, verificationToken)
+
+	// Set up authentication information
+	auth := smtp.PlainAuth("", fromEmail, fromPassword, smtpHost)
+
+	// Compose the email
+	subject := "Welcome! Please Verify Your Email Address"
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<p>Thanks for signing up. Please confirm your email address by clicking the link below:</p>
+			<p><a href="%s" style="color: blue; text-decoration: none;">Verify Your Email</a></p>
+			<p>This link will expire in 24 hours. Until your email is verified you will not be able to publish or sell artworks, comment, or send messages.</p>
+			<p>If you did not create an account, please ignore this email.</p>
+		</body>
+		</html>`, verifyLink)
+
+	// Format the email message
+	message := []byte(fmt.Sprintf("Subject: %s\r\nContent-Type: text/html; charset=\"UTF-8\"\r\n\r\n%s", subject, body))
+
+	// Send the email
+	err := smtp.SendMail(fmt.Sprintf("%s:%s", smtpHost, smtpPort), auth, fromEmail, []string{toEmail}, message)
+	if err != nil {
+		// Handle the error using the provided responseHandler
+		return responseHandler.Handle(nil, nil, fmt.Errorf("failed to send signup verification email: %v", err))
+	}
+	return nil
+}
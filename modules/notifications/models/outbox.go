@@ -0,0 +1,44 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Outbox statuses track a message from the moment it is written alongside
+// the business change until the worker has delivered it.
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusEnqueued  = "enqueued"
+	OutboxStatusDelivered = "delivered"
+	OutboxStatusDead      = "dead"
+	OutboxStatusDiscarded = "discarded"
+)
+
+// OutboxMessage is a background task recorded in the same transaction as the
+// change that produced it, so a crash between commit and enqueue can no longer
+// lose the task. The relay picks pending rows up and hands them to asynq.
+type OutboxMessage struct {
+	ID            uuid.UUID       `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	TaskType      string          `gorm:"type:varchar(100);not null;index" json:"task_type"`
+	Payload       json.RawMessage `gorm:"type:json;not null" json:"payload"`
+	Status        string          `gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_status_next" json:"status"`
+	Attempts      int             `gorm:"type:int;not null;default:0" json:"attempts"`
+	LastError     string          `gorm:"type:text" json:"last_error,omitempty"`
+	NextAttemptAt time.Time       `gorm:"type:timestamp;default:CURRENT_TIMESTAMP;index:idx_outbox_status_next" json:"next_attempt_at"`
+	EnqueuedAt    *time.Time      `gorm:"type:timestamp" json:"enqueued_at,omitempty"`
+	DeliveredAt   *time.Time      `gorm:"type:timestamp" json:"delivered_at,omitempty"`
+	CreatedAt     time.Time       `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt     time.Time       `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (o *OutboxMessage) BeforeCreate(tx *gorm.DB) (err error) {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,225 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/modules/notifications/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// OutboxTaskMaxRetry is how many times the worker retries a task before
+	// asynq archives it as a dead letter.
+	OutboxTaskMaxRetry = 8
+
+	// outboxMaxEnqueueAttempts bounds how long the relay keeps trying to reach
+	// Redis before it gives up on a message and marks it dead.
+	outboxMaxEnqueueAttempts = 10
+
+	// outboxEnqueueTimeout is how long a message stays enqueued before the
+	// relay asks asynq whether it still has the task. A task asynq no longer
+	// knows was lost, for instance by an instance that died between claiming
+	// the row and enqueueing it, and is enqueued again.
+	outboxEnqueueTimeout = 5 * time.Minute
+
+	// outboxQueue is the asynq queue outbox tasks are enqueued on
+	outboxQueue = "default"
+
+	outboxPollInterval = 2 * time.Second
+	outboxBatchSize    = 50
+)
+
+// WriteOutbox records a task inside the caller's transaction. The task is only
+// enqueued once the transaction commits and the relay picks it up.
+func WriteOutbox(tx *gorm.DB, taskType string, payload interface{}) (*models.OutboxMessage, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal outbox payload: %w", err)
+	}
+
+	message := models.OutboxMessage{
+		TaskType:      taskType,
+		Payload:       data,
+		Status:        models.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := tx.Create(&message).Error; err != nil {
+		return nil, fmt.Errorf("failed to write outbox message: %w", err)
+	}
+	return &message, nil
+}
+
+// OutboxRelay moves committed outbox messages onto the asynq queue.
+type OutboxRelay struct {
+	db        *gorm.DB
+	client    *asynq.Client
+	inspector *asynq.Inspector
+}
+
+// NewOutboxRelay creates a relay backed by the shared Redis configuration
+func NewOutboxRelay(db *gorm.DB) *OutboxRelay {
+	return &OutboxRelay{
+		db:        db,
+		client:    asynq.NewClient(*config.RedisConfig),
+		inspector: asynq.NewInspector(*config.RedisConfig),
+	}
+}
+
+// Start polls for pending messages until the process exits
+func (r *OutboxRelay) Start() {
+	ticker := time.NewTicker(outboxPollInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if err := r.RelayPending(); err != nil {
+			log.Printf("Outbox relay failed: %v", err)
+		}
+	}
+}
+
+// RelayPending enqueues one batch of due messages, then checks one batch of
+// messages that have been enqueued for longer than outboxEnqueueTimeout
+func (r *OutboxRelay) RelayPending() error {
+	now := time.Now()
+	var messages []models.OutboxMessage
+	if err := r.db.Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, now).
+		Order("created_at ASC").
+		Limit(outboxBatchSize).
+		Find(&messages).Error; err != nil {
+		return fmt.Errorf("failed to load outbox messages: %w", err)
+	}
+	for _, message := range messages {
+		r.relay(message)
+	}
+
+	var stale []models.OutboxMessage
+	if err := r.db.Where("status = ? AND enqueued_at <= ?", models.OutboxStatusEnqueued, now.Add(-outboxEnqueueTimeout)).
+		Order("enqueued_at ASC").
+		Limit(outboxBatchSize).
+		Find(&stale).Error; err != nil {
+		return fmt.Errorf("failed to load enqueued outbox messages: %w", err)
+	}
+	for _, message := range stale {
+		r.reconcile(message)
+	}
+	return nil
+}
+
+// reconcile brings a message that has been enqueued for a while in line with
+// its task. Tasks asynq is still running or retrying are left alone until
+// the next check; only a task asynq no longer has is enqueued again.
+func (r *OutboxRelay) reconcile(message models.OutboxMessage) {
+	info, err := r.inspector.GetTaskInfo(outboxQueue, message.ID.String())
+	switch {
+	case errors.Is(err, asynq.ErrTaskNotFound), errors.Is(err, asynq.ErrQueueNotFound):
+		r.relay(message)
+		return
+	case err != nil:
+		log.Printf("Failed to look up task for outbox message %s: %v", message.ID, err)
+		return
+	}
+
+	var updateErr error
+	switch info.State {
+	case asynq.TaskStateArchived:
+		var lastErr error
+		if info.LastErr != "" {
+			lastErr = errors.New(info.LastErr)
+		}
+		updateErr = MarkOutboxStatus(r.db, message.ID, models.OutboxStatusDead, lastErr)
+	case asynq.TaskStateCompleted:
+		updateErr = MarkOutboxDelivered(r.db, message.ID)
+	default:
+		updateErr = r.db.Model(&models.OutboxMessage{}).
+			Where("id = ? AND status = ?", message.ID, models.OutboxStatusEnqueued).
+			Update("enqueued_at", time.Now()).Error
+	}
+	if updateErr != nil {
+		log.Printf("Failed to update outbox message %s: %v", message.ID, updateErr)
+	}
+}
+
+func (r *OutboxRelay) relay(message models.OutboxMessage) {
+	// Claim the row first so that several API instances never enqueue it
+	// twice; the attempt count changes with every claim
+	now := time.Now()
+	claim := r.db.Model(&models.OutboxMessage{}).
+		Where("id = ? AND status = ? AND attempts = ?", message.ID, message.Status, message.Attempts).
+		Updates(map[string]interface{}{
+			"status":      models.OutboxStatusEnqueued,
+			"enqueued_at": now,
+			"attempts":    gorm.Expr("attempts + 1"),
+		})
+	if claim.Error != nil || claim.RowsAffected == 0 {
+		return
+	}
+
+	task := asynq.NewTask(message.TaskType, message.Payload)
+	_, err := r.client.Enqueue(task,
+		asynq.Queue(outboxQueue),
+		asynq.TaskID(message.ID.String()),
+		asynq.MaxRetry(OutboxTaskMaxRetry),
+	)
+	if err == nil || errors.Is(err, asynq.ErrTaskIDConflict) {
+		return
+	}
+
+	// Back off exponentially while Redis is unavailable
+	attempts := message.Attempts + 1
+	updates := map[string]interface{}{
+		"status":          models.OutboxStatusPending,
+		"enqueued_at":     nil,
+		"last_error":      err.Error(),
+		"next_attempt_at": now.Add(BackoffDelay(attempts)),
+	}
+	if attempts >= outboxMaxEnqueueAttempts {
+		updates["status"] = models.OutboxStatusDead
+	}
+	if updateErr := r.db.Model(&models.OutboxMessage{}).Where("id = ?", message.ID).Updates(updates).Error; updateErr != nil {
+		log.Printf("Failed to reschedule outbox message %s: %v", message.ID, updateErr)
+	}
+}
+
+// MarkOutboxDelivered records that the worker completed the task
+func MarkOutboxDelivered(db *gorm.DB, id uuid.UUID) error {
+	return db.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":       models.OutboxStatusDelivered,
+		"delivered_at": time.Now(),
+		"last_error":   "",
+	}).Error
+}
+
+// MarkOutboxStatus updates the status of a message, keeping the last error if given
+func MarkOutboxStatus(db *gorm.DB, id uuid.UUID, status string, lastErr error) error {
+	updates := map[string]interface{}{"status": status}
+	if status == models.OutboxStatusEnqueued {
+		// Restart the claim timeout so the relay leaves the task to asynq
+		updates["enqueued_at"] = time.Now()
+	}
+	if lastErr != nil {
+		updates["last_error"] = lastErr.Error()
+	}
+	return db.Model(&models.OutboxMessage{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// BackoffDelay returns an exponential delay (5s, 10s, 20s, ...) capped at one hour
+func BackoffDelay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	if attempt > 10 {
+		return time.Hour
+	}
+	delay := 5 * time.Second * time.Duration(1<<uint(attempt-1))
+	if delay > time.Hour {
+		return time.Hour
+	}
+	return delay
+}
@@ -9,8 +9,12 @@ import (
 	"github.com/muga20/artsMarket/config"
 	"github.com/muga20/artsMarket/modules/notifications/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
 )
 
+// TypeSendNotification is the task type that persists a notification
+const TypeSendNotification = "notification:send"
+
 // NotificationService holds the necessary components for handling notifications
 type NotificationService struct {
 	RedisClient     *asynq.Client
@@ -65,7 +69,7 @@ func (s *NotificationService) EnqueueNotification(userID, senderID, notification
 	}
 
 	// Create a new task for the notification
-	task := asynq.NewTask("notification:send", payload)
+	task := asynq.NewTask(TypeSendNotification, payload)
 
 	// Enqueue the task for background processing
 	_, err = s.RedisClient.Enqueue(task)
@@ -77,3 +81,29 @@ func (s *NotificationService) EnqueueNotification(userID, senderID, notification
 
 	return nil
 }
+
+// WriteNotification records a system notification inside the caller's
+// transaction so it is only delivered if the transaction commits
+func WriteNotification(tx *gorm.DB, userID uuid.UUID, notificationType, message, entityType string, entityID uuid.UUID) error {
+	return writeNotification(tx, userID, nil, notificationType, message, entityType, entityID)
+}
+
+// WriteNotificationFrom records a notification about another user's action
+// inside the caller's transaction
+func WriteNotificationFrom(tx *gorm.DB, userID, senderID uuid.UUID, notificationType, message, entityType string, entityID uuid.UUID) error {
+	return writeNotification(tx, userID, &senderID, notificationType, message, entityType, entityID)
+}
+
+func writeNotification(tx *gorm.DB, userID uuid.UUID, senderID *uuid.UUID, notificationType, message, entityType string, entityID uuid.UUID) error {
+	notification := models.Notification{
+		UserID:            userID,
+		SenderID:          senderID,
+		NotificationType:  notificationType,
+		Message:           message,
+		EntityType:        entityType,
+		EntityID:          &entityID,
+		IsSystemGenerated: true,
+	}
+	_, err := WriteOutbox(tx, TypeSendNotification, &notification)
+	return err
+}
@@ -0,0 +1,328 @@
+package organizations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	notifications "github.com/muga20/artsMarket/modules/notifications/services"
+	orgModels "github.com/muga20/artsMarket/modules/organizations/models"
+	userModels "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddMemberRequest adds a user by username or email
+type AddMemberRequest struct {
+	User string `json:"user" example:"jane_doe"`
+	Role string `json:"role" example:"staff" enums:"admin,staff"`
+}
+
+// UpdateMemberRequest changes a member's role
+type UpdateMemberRequest struct {
+	Role string `json:"role" example:"admin" enums:"admin,staff"`
+}
+
+// Member is an organization member with their public profile
+type Member struct {
+	UserID       uuid.UUID            `json:"user_id"`
+	Username     string               `json:"username"`
+	ProfileImage string               `json:"profile_image"`
+	Role         orgModels.MemberRole `json:"role"`
+	CreatedAt    time.Time            `json:"created_at"`
+}
+
+// parseRole reads a member role from a request
+func parseRole(value string) (orgModels.MemberRole, error) {
+	role := orgModels.MemberRole(strings.ToLower(strings.TrimSpace(value)))
+	if !role.Valid() {
+		return "", fiber.NewError(fiber.StatusBadRequest, "Invalid role. Must be admin or staff")
+	}
+	return role, nil
+}
+
+// keepAnAdmin fails when removing or demoting the member would leave the
+// organization without an admin. It locks the admin rows so two admins
+// cannot step down at once.
+func keepAnAdmin(tx *gorm.DB, organizationID, memberID uuid.UUID) error {
+	var admins []uuid.UUID
+	if err := tx.Model(&orgModels.OrganizationMember{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("organization_id = ? AND role = ?", organizationID, orgModels.RoleAdmin).
+		Pluck("user_id", &admins).Error; err != nil {
+		return fmt.Errorf("failed to check organization admins: %w", err)
+	}
+	if len(admins) == 1 && admins[0] == memberID {
+		return fiber.NewError(fiber.StatusBadRequest, "An organization must keep at least one admin")
+	}
+	return nil
+}
+
+// ListMembers godoc
+// @Summary List organization members
+// @Description Lists an organization's admins and staff. Available to members.
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/{id}/members [get]
+func ListMembers(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		organization, _, err := authorizeOrganization(db, c.Params("id"), user.ID, orgModels.RoleStaff)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		var members []Member
+		if err := db.Table("organization_members").
+			Select("organization_members.user_id, users.username, COALESCE(user_details.profile_image, '') AS profile_image, "+
+				"organization_members.role, organization_members.created_at").
+			Joins("JOIN users ON users.id = organization_members.user_id").
+			Joins("LEFT JOIN user_details ON user_details.user_id = users.id").
+			Where("organization_members.organization_id = ?", organization.ID).
+			Order("organization_members.created_at ASC").
+			Scan(&members).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to load members: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"members": members,
+			"count":   len(members),
+		}, nil)
+	}
+}
+
+// AddMember godoc
+// @Summary Add an organization member
+// @Description Adds a user, found by username or email, to the organization as an admin or staff member. Staff can list and edit artworks for the represented artists; admins also manage the organization, its members and its artists. Only admins can add members.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param body body AddMemberRequest true "Member and role"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/{id}/members [post]
+func AddMember(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req AddMemberRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		req.User = strings.TrimSpace(req.User)
+		if req.User == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Username or email of the member is required"))
+		}
+		role, err := parseRole(req.Role)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		organization, _, err := authorizeOrganization(db, c.Params("id"), user.ID, orgModels.RoleAdmin)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		member, err := findActiveUser(db, req.User)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&orgModels.OrganizationMember{
+				OrganizationID: organization.ID,
+				UserID:         member.ID,
+				Role:           role,
+			})
+			if result.Error != nil {
+				return fmt.Errorf("failed to add member: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return fiber.NewError(fiber.StatusConflict, "This user is already a member of the organization")
+			}
+
+			message := fmt.Sprintf("%s added you to %s as %s", user.Username, organization.Name, role)
+			return notifications.WriteNotificationFrom(tx, member.ID, user.ID, "organization_member_added",
+				message, "organization", organization.ID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Member added successfully",
+		}, nil)
+	}
+}
+
+// UpdateMember godoc
+// @Summary Change a member's role
+// @Description Changes a member between admin and staff. The last admin cannot be demoted. Only admins can change roles.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param userId path string true "Member user ID"
+// @Param body body UpdateMemberRequest true "New role"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/{id}/members/{userId} [put]
+func UpdateMember(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req UpdateMemberRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		role, err := parseRole(req.Role)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		memberID, err := uuid.Parse(c.Params("userId"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid user ID"))
+		}
+
+		organization, _, err := authorizeOrganization(db, c.Params("id"), user.ID, orgModels.RoleAdmin)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if role != orgModels.RoleAdmin {
+				if err := keepAnAdmin(tx, organization.ID, memberID); err != nil {
+					return err
+				}
+			}
+
+			result := tx.Model(&orgModels.OrganizationMember{}).
+				Where("organization_id = ? AND user_id = ?", organization.ID, memberID).
+				Updates(map[string]interface{}{"role": role, "updated_at": time.Now()})
+			if result.Error != nil {
+				return fmt.Errorf("failed to update member: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return fiber.NewError(fiber.StatusNotFound, "Member not found")
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Member role updated successfully",
+		}, nil)
+	}
+}
+
+// RemoveMember godoc
+// @Summary Remove an organization member
+// @Description Removes a member from the organization. Admins can remove anyone; members can remove themselves to leave. The last admin cannot leave.
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param userId path string true "Member user ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/{id}/members/{userId} [delete]
+func RemoveMember(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		memberID, err := uuid.Parse(c.Params("userId"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid user ID"))
+		}
+
+		// Leaving only needs membership, removing others needs admin
+		need := orgModels.RoleAdmin
+		if memberID == user.ID {
+			need = orgModels.RoleStaff
+		}
+		organization, _, err := authorizeOrganization(db, c.Params("id"), user.ID, need)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := keepAnAdmin(tx, organization.ID, memberID); err != nil {
+				return err
+			}
+
+			result := tx.Where("organization_id = ? AND user_id = ?", organization.ID, memberID).
+				Delete(&orgModels.OrganizationMember{})
+			if result.Error != nil {
+				return fmt.Errorf("failed to remove member: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return fiber.NewError(fiber.StatusNotFound, "Member not found")
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Member removed successfully",
+		}, nil)
+	}
+}
+
+// findActiveUser looks up an active user by username or email
+func findActiveUser(db *gorm.DB, usernameOrEmail string) (*userModels.User, error) {
+	var user userModels.User
+	if err := db.Where("(username = ? OR email = ?) AND is_active = ?", usernameOrEmail, usernameOrEmail, true).
+		First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusNotFound, "User not found")
+		}
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	return &user, nil
+}
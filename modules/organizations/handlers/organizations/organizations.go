@@ -0,0 +1,418 @@
+package organizations
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	orgModels "github.com/muga20/artsMarket/modules/organizations/models"
+	userModels "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+const (
+	maxNameLength        = 255
+	maxDescriptionLength = 5000
+)
+
+// CreateOrganizationRequest describes a new organization
+type CreateOrganizationRequest struct {
+	Name                  string   `json:"name" example:"Harbour Gallery"`
+	Description           string   `json:"description" example:"Contemporary East African painting"`
+	Website               string   `json:"website" example:"https://harbour.gallery"`
+	DefaultCommissionRate *float64 `json:"default_commission_rate" example:"40"`
+}
+
+// UpdateOrganizationRequest changes an organization. Omitted fields are kept.
+type UpdateOrganizationRequest struct {
+	Name                  *string  `json:"name"`
+	Description           *string  `json:"description"`
+	Website               *string  `json:"website"`
+	DefaultCommissionRate *float64 `json:"default_commission_rate" example:"35"`
+}
+
+// OrganizationSummary is an organization the user belongs to
+type OrganizationSummary struct {
+	ID                    uuid.UUID            `json:"id"`
+	Name                  string               `json:"name"`
+	Slug                  string               `json:"slug"`
+	DefaultCommissionRate float64              `json:"default_commission_rate"`
+	Role                  orgModels.MemberRole `json:"role"`
+	CreatedAt             time.Time            `json:"created_at"`
+}
+
+// RepresentedArtist is an artist an organization represents
+type RepresentedArtist struct {
+	ArtistID       uuid.UUID  `json:"artist_id"`
+	Username       string     `json:"username"`
+	ProfileImage   string     `json:"profile_image"`
+	Status         string     `json:"status"`
+	CommissionRate *float64   `json:"commission_rate"`
+	RespondedAt    *time.Time `json:"responded_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// authorizeOrganization loads an organization the user holds at least the
+// need role in, turning permission failures into client errors
+func authorizeOrganization(db *gorm.DB, organizationID string, userID uuid.UUID, need orgModels.MemberRole) (*orgModels.Organization, orgModels.MemberRole, error) {
+	organization, role, err := orgModels.Authorize(db, organizationID, userID, need)
+	switch {
+	case errors.Is(err, orgModels.ErrOrganizationNotFound):
+		return nil, "", fiber.NewError(fiber.StatusNotFound, "Organization not found or you don't have permission")
+	case errors.Is(err, orgModels.ErrInsufficientRole):
+		return nil, "", fiber.NewError(fiber.StatusForbidden,
+			fmt.Sprintf("This action requires the %s role in the organization", need))
+	case err != nil:
+		return nil, "", fmt.Errorf("failed to retrieve organization: %w", err)
+	}
+	return organization, role, nil
+}
+
+// checkCommissionRate rejects commission percentages outside 0 to 100
+func checkCommissionRate(rate *float64) error {
+	if rate != nil && !orgModels.ValidCommissionRate(*rate) {
+		return fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("Commission rate must be between 0 and %d percent", orgModels.MaxCommissionRate))
+	}
+	return nil
+}
+
+// checkWebsite rejects websites that are not http or https links
+func checkWebsite(website string) error {
+	if website == "" {
+		return nil
+	}
+	if parsed, err := url.ParseRequestURI(website); err != nil ||
+		(parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return fiber.NewError(fiber.StatusBadRequest, "Website must be a valid http or https link")
+	}
+	return nil
+}
+
+// CreateOrganization godoc
+// @Summary Create an organization
+// @Description Creates a gallery or agency account. The authenticated user becomes its first admin and can then add staff and invite artists to be represented.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param body body CreateOrganizationRequest true "Organization"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations [post]
+func CreateOrganization(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req CreateOrganizationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		req.Name = strings.TrimSpace(req.Name)
+		req.Description = strings.TrimSpace(req.Description)
+		req.Website = strings.TrimSpace(req.Website)
+		if req.Name == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Organization name is required"))
+		}
+		if len(req.Name) > maxNameLength || len(req.Description) > maxDescriptionLength {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest,
+					fmt.Sprintf("Name cannot exceed %d characters and description %d", maxNameLength, maxDescriptionLength)))
+		}
+		if err := checkWebsite(req.Website); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if err := checkCommissionRate(req.DefaultCommissionRate); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		organization := orgModels.Organization{
+			Name:        req.Name,
+			Description: req.Description,
+			Website:     req.Website,
+			CreatedBy:   user.ID,
+		}
+		if req.DefaultCommissionRate != nil {
+			organization.DefaultCommissionRate = *req.DefaultCommissionRate
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Create(&organization).Error; err != nil {
+				return fmt.Errorf("failed to create organization: %w", err)
+			}
+			if err := tx.Create(&orgModels.OrganizationMember{
+				OrganizationID: organization.ID,
+				UserID:         user.ID,
+				Role:           orgModels.RoleAdmin,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to add organization admin: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":      "Organization created successfully",
+			"organization": organization,
+		}, nil)
+	}
+}
+
+// ListMyOrganizations godoc
+// @Summary List my organizations
+// @Description Lists the organizations the authenticated user is a member of, with their role in each
+// @Tags Organizations
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/mine [get]
+func ListMyOrganizations(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var organizations []OrganizationSummary
+		if err := db.Table("organizations").
+			Select("organizations.id, organizations.name, organizations.slug, "+
+				"organizations.default_commission_rate, organization_members.role, organizations.created_at").
+			Joins("JOIN organization_members ON organization_members.organization_id = organizations.id").
+			Where("organization_members.user_id = ?", user.ID).
+			Order("organizations.name ASC").
+			Scan(&organizations).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to load organizations: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"organizations": organizations,
+			"count":         len(organizations),
+		}, nil)
+	}
+}
+
+// GetOrganization godoc
+// @Summary Get an organization
+// @Description Returns an organization by ID or slug with the artists it actively represents. Members also see pending and past representations and their own role.
+// @Tags Organizations
+// @Produce json
+// @Param identifier path string true "Organization ID or slug"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/{identifier} [get]
+func GetOrganization(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		identifier := c.Params("identifier")
+		var organization orgModels.Organization
+		query := db.Where("slug = ?", identifier)
+		if _, err := uuid.Parse(identifier); err == nil {
+			query = db.Where("id = ?", identifier)
+		}
+		if err := query.First(&organization).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Organization not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve organization: %w", err))
+		}
+
+		role, err := orgModels.RoleOf(db, organization.ID, user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to check organization membership: %w", err))
+		}
+
+		artists, err := representedArtists(db, organization.ID, role != "")
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		response := fiber.Map{
+			"organization": organization,
+			"artists":      artists,
+		}
+		if role != "" {
+			response["role"] = role
+		}
+		return responseHandler.HandleResponse(c, response, nil)
+	}
+}
+
+// representedArtists lists the organization's artists. Outsiders only see
+// active representations and not the commission agreed.
+func representedArtists(db *gorm.DB, organizationID uuid.UUID, isMember bool) ([]RepresentedArtist, error) {
+	query := db.Table("organization_representations").
+		Select("organization_representations.artist_id, users.username, COALESCE(user_details.profile_image, '') AS profile_image, "+
+			"organization_representations.status, organization_representations.commission_rate, "+
+			"organization_representations.responded_at, organization_representations.created_at").
+		Joins("JOIN users ON users.id = organization_representations.artist_id AND users.deleted_at IS NULL").
+		Joins("LEFT JOIN user_details ON user_details.user_id = users.id").
+		Where("organization_representations.organization_id = ?", organizationID).
+		Order("users.username ASC")
+	if !isMember {
+		query = query.Where("organization_representations.status = ?", orgModels.RepresentationActive)
+	}
+
+	artists := []RepresentedArtist{}
+	if err := query.Scan(&artists).Error; err != nil {
+		return nil, fmt.Errorf("failed to load represented artists: %w", err)
+	}
+	if !isMember {
+		for i := range artists {
+			artists[i].CommissionRate = nil
+		}
+	}
+	return artists, nil
+}
+
+// UpdateOrganization godoc
+// @Summary Update an organization
+// @Description Changes an organization's name, description, website or default commission. A new default applies to artworks listed afterwards; listed artworks keep the commission they were listed with. Only admins can update.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param body body UpdateOrganizationRequest true "Changes"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/{id} [put]
+func UpdateOrganization(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req UpdateOrganizationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+
+		organization, _, err := authorizeOrganization(db, c.Params("id"), user.ID, orgModels.RoleAdmin)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		updates := map[string]interface{}{}
+		if req.Name != nil {
+			name := strings.TrimSpace(*req.Name)
+			if name == "" || len(name) > maxNameLength {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest,
+						fmt.Sprintf("Organization name must be between 1 and %d characters", maxNameLength)))
+			}
+			updates["name"] = name
+		}
+		if req.Description != nil {
+			description := strings.TrimSpace(*req.Description)
+			if len(description) > maxDescriptionLength {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest,
+						fmt.Sprintf("Description cannot exceed %d characters", maxDescriptionLength)))
+			}
+			updates["description"] = description
+		}
+		if req.Website != nil {
+			website := strings.TrimSpace(*req.Website)
+			if err := checkWebsite(website); err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+			updates["website"] = website
+		}
+		if req.DefaultCommissionRate != nil {
+			if err := checkCommissionRate(req.DefaultCommissionRate); err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+			updates["default_commission_rate"] = *req.DefaultCommissionRate
+		}
+		if len(updates) == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "No changes provided"))
+		}
+		updates["updated_at"] = time.Now()
+
+		if err := db.Model(organization).Updates(updates).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to update organization: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":      "Organization updated successfully",
+			"organization": organization,
+		}, nil)
+	}
+}
+
+// DeleteOrganization godoc
+// @Summary Delete an organization
+// @Description Deletes an organization with its memberships and representations. Its listings stay with their artists, who keep all the proceeds of later sales. Only admins can delete.
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/{id} [delete]
+func DeleteOrganization(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		organization, _, err := authorizeOrganization(db, c.Params("id"), user.ID, orgModels.RoleAdmin)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := releaseListings(tx, organization.ID, nil); err != nil {
+				return err
+			}
+			if err := tx.Delete(organization).Error; err != nil {
+				return fmt.Errorf("failed to delete organization: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Organization deleted successfully",
+		}, nil)
+	}
+}
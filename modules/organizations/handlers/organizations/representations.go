@@ -0,0 +1,452 @@
+package organizations
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	artModels "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	notifications "github.com/muga20/artsMarket/modules/notifications/services"
+	orgModels "github.com/muga20/artsMarket/modules/organizations/models"
+	userModels "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InviteArtistRequest offers representation to an artist found by username
+// or email. Without a commission rate the organization's default applies.
+type InviteArtistRequest struct {
+	User           string   `json:"user" example:"jane_doe"`
+	CommissionRate *float64 `json:"commission_rate" example:"40"`
+}
+
+// UpdateRepresentationRequest changes the commission agreed with an artist.
+// A null rate falls back to the organization's default.
+type UpdateRepresentationRequest struct {
+	CommissionRate *float64 `json:"commission_rate" example:"35"`
+}
+
+// RepresentationOffer is a representation seen from the artist's side
+type RepresentationOffer struct {
+	ID                    uuid.UUID  `json:"id"`
+	OrganizationID        uuid.UUID  `json:"organization_id"`
+	OrganizationName      string     `json:"organization_name"`
+	OrganizationSlug      string     `json:"organization_slug"`
+	Status                string     `json:"status"`
+	CommissionRate        *float64   `json:"commission_rate"`
+	DefaultCommissionRate float64    `json:"default_commission_rate"`
+	InvitedBy             uuid.UUID  `json:"invited_by"`
+	InviterName           string     `json:"inviter_username"`
+	RespondedAt           *time.Time `json:"responded_at,omitempty"`
+	CreatedAt             time.Time  `json:"created_at"`
+}
+
+// releaseListings hands the organization's listings back to their artists,
+// who then keep all the proceeds. A nil artistID releases every artist's.
+func releaseListings(tx *gorm.DB, organizationID uuid.UUID, artistID *uuid.UUID) error {
+	query := tx.Model(&artModels.Artwork{}).Where("organization_id = ?", organizationID)
+	if artistID != nil {
+		query = query.Where("user_id = ?", *artistID)
+	}
+	if err := query.Updates(map[string]interface{}{
+		"organization_id": nil,
+		"commission_rate": nil,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to release organization listings: %w", err)
+	}
+	return nil
+}
+
+// InviteArtist godoc
+// @Summary Offer representation to an artist
+// @Description Offers to represent an artist, found by username or email, at the given commission or the organization's default. The artist must accept before the organization can list their work. Only admins can invite artists.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param body body InviteArtistRequest true "Artist and commission"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/{id}/artists [post]
+func InviteArtist(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req InviteArtistRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		req.User = strings.TrimSpace(req.User)
+		if req.User == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Username or email of the artist is required"))
+		}
+		if err := checkCommissionRate(req.CommissionRate); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		organization, _, err := authorizeOrganization(db, c.Params("id"), user.ID, orgModels.RoleAdmin)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		artist, err := findActiveUser(db, req.User)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		// Blocked users in either direction cannot be invited
+		var blockCount int64
+		if err := db.Model(&userModels.BlockedUser{}).
+			Where("(user_id = ? AND blocked_user_id = ?) OR (user_id = ? AND blocked_user_id = ?)",
+				artist.ID, user.ID, user.ID, artist.ID).
+			Count(&blockCount).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to check block status: %w", err))
+		}
+		if blockCount > 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusForbidden, "You cannot invite this artist"))
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var existing orgModels.Representation
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("organization_id = ? AND artist_id = ?", organization.ID, artist.ID).
+				First(&existing).Error
+			switch {
+			case err == nil && (existing.Status == orgModels.RepresentationPending ||
+				existing.Status == orgModels.RepresentationActive):
+				return fiber.NewError(fiber.StatusConflict, "This artist is already represented or invited")
+			case err == nil:
+				// Declined and ended representations can be offered again
+				if err := tx.Model(&existing).Updates(map[string]interface{}{
+					"status":          orgModels.RepresentationPending,
+					"commission_rate": req.CommissionRate,
+					"invited_by":      user.ID,
+					"responded_at":    nil,
+					"ended_at":        nil,
+					"updated_at":      time.Now(),
+				}).Error; err != nil {
+					return fmt.Errorf("failed to renew representation offer: %w", err)
+				}
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				if err := tx.Create(&orgModels.Representation{
+					OrganizationID: organization.ID,
+					ArtistID:       artist.ID,
+					CommissionRate: req.CommissionRate,
+					InvitedBy:      user.ID,
+				}).Error; err != nil {
+					return fmt.Errorf("failed to create representation offer: %w", err)
+				}
+			default:
+				return fmt.Errorf("failed to check existing representation: %w", err)
+			}
+
+			rate := organization.DefaultCommissionRate
+			if req.CommissionRate != nil {
+				rate = *req.CommissionRate
+			}
+			message := fmt.Sprintf("%s offered to represent you at %s with a %g%% commission",
+				user.Username, organization.Name, rate)
+			return notifications.WriteNotificationFrom(tx, artist.ID, user.ID, "organization_representation_invitation",
+				message, "organization", organization.ID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Representation offer sent successfully",
+		}, nil)
+	}
+}
+
+// UpdateRepresentation godoc
+// @Summary Change an artist's commission
+// @Description Changes the commission agreed with a represented artist, or falls back to the organization's default when the rate is null. The new rate applies to artworks listed afterwards and the artist is notified. Only admins can change commissions.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param artistId path string true "Artist user ID"
+// @Param body body UpdateRepresentationRequest true "Commission"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/{id}/artists/{artistId} [put]
+func UpdateRepresentation(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req UpdateRepresentationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		if err := checkCommissionRate(req.CommissionRate); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		artistID, err := uuid.Parse(c.Params("artistId"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid artist ID"))
+		}
+
+		organization, _, err := authorizeOrganization(db, c.Params("id"), user.ID, orgModels.RoleAdmin)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&orgModels.Representation{}).
+				Where("organization_id = ? AND artist_id = ? AND status IN ?", organization.ID, artistID,
+					[]string{orgModels.RepresentationPending, orgModels.RepresentationActive}).
+				Updates(map[string]interface{}{"commission_rate": req.CommissionRate, "updated_at": time.Now()})
+			if result.Error != nil {
+				return fmt.Errorf("failed to update representation: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return fiber.NewError(fiber.StatusNotFound, "Representation not found")
+			}
+
+			rate := organization.DefaultCommissionRate
+			if req.CommissionRate != nil {
+				rate = *req.CommissionRate
+			}
+			message := fmt.Sprintf("%s changed your commission at %s to %g%% for new listings",
+				user.Username, organization.Name, rate)
+			return notifications.WriteNotificationFrom(tx, artistID, user.ID, "organization_commission_changed",
+				message, "organization", organization.ID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Commission updated successfully",
+		}, nil)
+	}
+}
+
+// EndRepresentation godoc
+// @Summary End a representation
+// @Description Ends an organization's representation of an artist or withdraws a pending offer. Admins or the artist can end it. The organization's listings for the artist stay with the artist, who keeps all the proceeds of later sales.
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param artistId path string true "Artist user ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/{id}/artists/{artistId} [delete]
+func EndRepresentation(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		artistID, err := uuid.Parse(c.Params("artistId"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid artist ID"))
+		}
+
+		// Artists can leave without being members, everyone else needs admin
+		var organization orgModels.Organization
+		if artistID == user.ID {
+			if err := db.Where("id = ?", c.Params("id")).First(&organization).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return responseHandler.HandleResponse(c, nil,
+						fiber.NewError(fiber.StatusNotFound, "Organization not found"))
+				}
+				return responseHandler.HandleResponse(c, nil,
+					fmt.Errorf("failed to retrieve organization: %w", err))
+			}
+		} else {
+			authorized, _, err := authorizeOrganization(db, c.Params("id"), user.ID, orgModels.RoleAdmin)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+			organization = *authorized
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var representation orgModels.Representation
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("organization_id = ? AND artist_id = ? AND status IN ?", organization.ID, artistID,
+					[]string{orgModels.RepresentationPending, orgModels.RepresentationActive}).
+				First(&representation).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(fiber.StatusNotFound, "Representation not found")
+				}
+				return fmt.Errorf("failed to retrieve representation: %w", err)
+			}
+
+			wasPending := representation.Status == orgModels.RepresentationPending
+			now := time.Now()
+			if err := tx.Model(&representation).Updates(map[string]interface{}{
+				"status":     orgModels.RepresentationEnded,
+				"ended_at":   now,
+				"updated_at": now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to end representation: %w", err)
+			}
+			if err := releaseListings(tx, organization.ID, &artistID); err != nil {
+				return err
+			}
+
+			if artistID == user.ID {
+				message := fmt.Sprintf("%s ended their representation by %s", user.Username, organization.Name)
+				return notifications.WriteNotificationFrom(tx, representation.InvitedBy, user.ID,
+					"organization_representation_ended", message, "organization", organization.ID)
+			}
+			message := fmt.Sprintf("%s ended its representation of you", organization.Name)
+			if wasPending {
+				message = fmt.Sprintf("%s withdrew its offer to represent you", organization.Name)
+			}
+			return notifications.WriteNotificationFrom(tx, artistID, user.ID,
+				"organization_representation_ended", message, "organization", organization.ID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Representation ended successfully",
+		}, nil)
+	}
+}
+
+// ListMyRepresentations godoc
+// @Summary List my representations
+// @Description Lists the organizations representing the authenticated artist or offering to, pending offers first
+// @Tags Organizations
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/representations [get]
+func ListMyRepresentations(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var representations []RepresentationOffer
+		if err := db.Table("organization_representations").
+			Select("organization_representations.id, organizations.id AS organization_id, "+
+				"organizations.name AS organization_name, organizations.slug AS organization_slug, "+
+				"organization_representations.status, organization_representations.commission_rate, "+
+				"organizations.default_commission_rate, organization_representations.invited_by, "+
+				"users.username AS inviter_name, organization_representations.responded_at, "+
+				"organization_representations.created_at").
+			Joins("JOIN organizations ON organizations.id = organization_representations.organization_id").
+			Joins("LEFT JOIN users ON users.id = organization_representations.invited_by").
+			Where("organization_representations.artist_id = ? AND organization_representations.status IN ?",
+				user.ID, []string{orgModels.RepresentationPending, orgModels.RepresentationActive}).
+			Order(clause.Expr{SQL: "organization_representations.status = ? DESC, organization_representations.created_at DESC",
+				Vars: []interface{}{orgModels.RepresentationPending}}).
+			Scan(&representations).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to load representations: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"representations": representations,
+			"count":           len(representations),
+		}, nil)
+	}
+}
+
+// RespondToRepresentation godoc
+// @Summary Respond to a representation offer
+// @Description Accepts or declines an organization's offer to represent the authenticated artist. Once accepted, the organization's staff can list and edit artworks for the artist. The inviter is notified either way.
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Representation ID"
+// @Param action path string true "accept or decline"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /organizations/representations/{id}/{action} [put]
+func RespondToRepresentation(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(userModels.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var status, verb string
+		switch strings.ToLower(c.Params("action")) {
+		case "accept":
+			status, verb = orgModels.RepresentationActive, "accepted"
+		case "decline":
+			status, verb = orgModels.RepresentationDeclined, "declined"
+		default:
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid action. Must be accept or decline"))
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var representation orgModels.Representation
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Preload("Organization").
+				Where("id = ? AND artist_id = ? AND status = ?",
+					c.Params("id"), user.ID, orgModels.RepresentationPending).
+				First(&representation).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(fiber.StatusNotFound, "Representation offer not found")
+				}
+				return fmt.Errorf("failed to retrieve representation offer: %w", err)
+			}
+
+			now := time.Now()
+			if err := tx.Model(&representation).Updates(map[string]interface{}{
+				"status":       status,
+				"responded_at": now,
+				"updated_at":   now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to update representation offer: %w", err)
+			}
+
+			message := fmt.Sprintf("%s %s representation by %s", user.Username, verb, representation.Organization.Name)
+			return notifications.WriteNotificationFrom(tx, representation.InvitedBy, user.ID,
+				"organization_representation_"+verb, message, "organization", representation.OrganizationID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": fmt.Sprintf("Representation %s", verb),
+		}, nil)
+	}
+}
@@ -0,0 +1,226 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/gosimple/slug"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"gorm.io/gorm"
+)
+
+// MemberRole is what a member may do on behalf of an organization. Admins
+// manage the organization, its members and its artists; staff manage
+// listings for the represented artists.
+type MemberRole string
+
+const (
+	RoleStaff MemberRole = "staff"
+	RoleAdmin MemberRole = "admin"
+)
+
+// Representation statuses stored in Representation.Status
+const (
+	RepresentationPending  = "pending"
+	RepresentationActive   = "active"
+	RepresentationDeclined = "declined"
+	RepresentationEnded    = "ended"
+)
+
+// MaxCommissionRate caps the share of proceeds an organization can keep
+const MaxCommissionRate = 100
+
+var (
+	// ErrOrganizationNotFound is returned when the organization does not
+	// exist or the user is not a member
+	ErrOrganizationNotFound = errors.New("organization not found")
+	// ErrInsufficientRole is returned when a member lacks the role required
+	ErrInsufficientRole = errors.New("insufficient organization role")
+	// ErrNotRepresented is returned when listing for an artist the
+	// organization does not actively represent
+	ErrNotRepresented = errors.New("artist is not represented by this organization")
+)
+
+// Valid reports whether r is a known role
+func (r MemberRole) Valid() bool {
+	return r == RoleStaff || r == RoleAdmin
+}
+
+// Allows reports whether r grants at least the access of need
+func (r MemberRole) Allows(need MemberRole) bool {
+	return r == RoleAdmin || (r == RoleStaff && need == RoleStaff)
+}
+
+// Organization is a gallery or agency that lists artworks on behalf of the
+// artists it represents
+type Organization struct {
+	ID          uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	Name        string    `gorm:"type:varchar(255);not null" json:"name"`
+	Slug        string    `gorm:"type:varchar(255);not null;uniqueIndex" json:"slug"`
+	Description string    `gorm:"type:text" json:"description"`
+	Website     string    `gorm:"type:varchar(255)" json:"website"`
+	// DefaultCommissionRate is the percentage of proceeds the organization
+	// keeps, unless a representation agrees on another rate
+	DefaultCommissionRate float64   `gorm:"type:decimal(5,2);not null;default:0" json:"default_commission_rate"`
+	CreatedBy             uuid.UUID `gorm:"type:char(36);not null" json:"created_by"`
+	CreatedAt             time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt             time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// OrganizationMember gives a user a role in an organization
+type OrganizationMember struct {
+	ID             uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	OrganizationID uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_organization_member" json:"organization_id"`
+	UserID         uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex:idx_organization_member;index" json:"user_id"`
+	Role           MemberRole `gorm:"type:enum('admin','staff');not null;default:'staff'" json:"role"`
+	CreatedAt      time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE" json:"-"`
+	User         models.User  `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// Representation is an agreement for an organization to list an artist's
+// work. It only takes effect once the artist accepts it.
+type Representation struct {
+	ID             uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	OrganizationID uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_organization_artist" json:"organization_id"`
+	ArtistID       uuid.UUID `gorm:"type:char(36);not null;uniqueIndex:idx_organization_artist;index" json:"artist_id"`
+	Status         string    `gorm:"type:enum('pending','active','declined','ended');not null;default:'pending';index" json:"status"`
+	// CommissionRate overrides the organization's default for this artist
+	CommissionRate *float64   `gorm:"type:decimal(5,2)" json:"commission_rate"`
+	InvitedBy      uuid.UUID  `gorm:"type:char(36);not null" json:"invited_by"`
+	RespondedAt    *time.Time `gorm:"type:timestamp" json:"responded_at,omitempty"`
+	EndedAt        *time.Time `gorm:"type:timestamp" json:"ended_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"updated_at"`
+
+	Organization Organization `gorm:"foreignKey:OrganizationID;constraint:OnDelete:CASCADE" json:"-"`
+	Artist       models.User  `gorm:"foreignKey:ArtistID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName keeps representations grouped with the other organization tables
+func (Representation) TableName() string {
+	return "organization_representations"
+}
+
+// BeforeCreate hook to generate UUID and a unique slug
+func (o *Organization) BeforeCreate(tx *gorm.DB) (err error) {
+	if o.ID == uuid.Nil {
+		o.ID = uuid.New()
+	}
+
+	if o.Slug == "" {
+		o.Slug = slug.Make(o.Name)
+
+		var count int64
+		tx.Model(&Organization{}).
+			Where("slug = ?", o.Slug).
+			Count(&count)
+
+		if count > 0 {
+			o.Slug = slug.Make(o.Name + "-" + strings.Split(o.ID.String(), "-")[0])
+		}
+	}
+	return
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (m *OrganizationMember) BeforeCreate(tx *gorm.DB) (err error) {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return
+}
+
+// BeforeCreate hook to generate UUID and default status
+func (r *Representation) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.Status == "" {
+		r.Status = RepresentationPending
+	}
+	return
+}
+
+// ValidCommissionRate reports whether rate is a percentage an organization
+// may keep
+func ValidCommissionRate(rate float64) bool {
+	return rate >= 0 && rate <= MaxCommissionRate
+}
+
+// Split divides proceeds between the organization and the artist at the
+// given commission percentage. The organization's share is rounded to the
+// cent and the artist receives the rest, so the shares always add up.
+func Split(amount, rate float64) (organizationShare, artistShare float64) {
+	organizationShare = math.Round(amount*rate) / 100
+	return organizationShare, math.Round((amount-organizationShare)*100) / 100
+}
+
+// RoleOf returns the user's role in the organization, or an empty role
+// when they are not a member
+func RoleOf(db *gorm.DB, organizationID, userID uuid.UUID) (MemberRole, error) {
+	var roles []MemberRole
+	if err := db.Model(&OrganizationMember{}).
+		Where("organization_id = ? AND user_id = ?", organizationID, userID).
+		Limit(1).
+		Pluck("role", &roles).Error; err != nil {
+		return "", err
+	}
+	if len(roles) == 0 {
+		return "", nil
+	}
+	return roles[0], nil
+}
+
+// Authorize loads the organization and checks that userID holds at least
+// the need role in it. Non-members get ErrOrganizationNotFound.
+func Authorize(db *gorm.DB, organizationID interface{}, userID uuid.UUID, need MemberRole) (*Organization, MemberRole, error) {
+	var organization Organization
+	if err := db.Where("id = ?", organizationID).First(&organization).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, "", ErrOrganizationNotFound
+		}
+		return nil, "", err
+	}
+
+	role, err := RoleOf(db, organization.ID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if role == "" {
+		return nil, "", ErrOrganizationNotFound
+	}
+	if !role.Allows(need) {
+		return &organization, role, ErrInsufficientRole
+	}
+	return &organization, role, nil
+}
+
+// ActiveRepresentation returns the organization's active representation of
+// the artist, or ErrNotRepresented
+func ActiveRepresentation(db *gorm.DB, organizationID, artistID uuid.UUID) (*Representation, error) {
+	var representation Representation
+	if err := db.Where("organization_id = ? AND artist_id = ? AND status = ?",
+		organizationID, artistID, RepresentationActive).
+		First(&representation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotRepresented
+		}
+		return nil, err
+	}
+	return &representation, nil
+}
+
+// CommissionFor returns the commission percentage that applies to the
+// representation
+func (o *Organization) CommissionFor(representation *Representation) float64 {
+	if representation != nil && representation.CommissionRate != nil {
+		return *representation.CommissionRate
+	}
+	return o.DefaultCommissionRate
+}
@@ -0,0 +1,40 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/organizations/handlers/organizations"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/middleware"
+	"gorm.io/gorm"
+)
+
+// OrganizationsSetupRoutes sets up organization routes
+func OrganizationsSetupRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *handlers.ResponseHandler) {
+	organizationGroup := apiGroup.Group("/organizations")
+
+	organizationGroup.Use(middleware.AuthMiddleware(db, responseHandler))
+
+	// Static paths come before /:identifier
+	organizationGroup.Post("/", middleware.RequireVerifiedEmail(db, responseHandler), organizations.CreateOrganization(db, responseHandler))
+	organizationGroup.Get("/mine", organizations.ListMyOrganizations(db, responseHandler))
+
+	// Representation offers, from the artist's side
+	organizationGroup.Get("/representations", organizations.ListMyRepresentations(db, responseHandler))
+	organizationGroup.Put("/representations/:id/:action", organizations.RespondToRepresentation(db, responseHandler))
+
+	// Organizations
+	organizationGroup.Get("/:identifier", organizations.GetOrganization(db, responseHandler))
+	organizationGroup.Put("/:id", organizations.UpdateOrganization(db, responseHandler))
+	organizationGroup.Delete("/:id", organizations.DeleteOrganization(db, responseHandler))
+
+	// Members
+	organizationGroup.Get("/:id/members", organizations.ListMembers(db, responseHandler))
+	organizationGroup.Post("/:id/members", organizations.AddMember(db, responseHandler))
+	organizationGroup.Put("/:id/members/:userId", organizations.UpdateMember(db, responseHandler))
+	organizationGroup.Delete("/:id/members/:userId", organizations.RemoveMember(db, responseHandler))
+
+	// Represented artists
+	organizationGroup.Post("/:id/artists", organizations.InviteArtist(db, responseHandler))
+	organizationGroup.Put("/:id/artists/:artistId", organizations.UpdateRepresentation(db, responseHandler))
+	organizationGroup.Delete("/:id/artists/:artistId", organizations.EndRepresentation(db, responseHandler))
+}
@@ -0,0 +1,185 @@
+package recommendations
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	"github.com/muga20/artsMarket/modules/recommendations/recommender"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/privacy"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLimit = 12
+	maxLimit     = 50
+)
+
+// ArtworkCard is a recommended artwork
+type ArtworkCard struct {
+	ID             uuid.UUID `json:"id"`
+	Title          string    `json:"title"`
+	Slug           string    `json:"slug"`
+	Price          *float64  `json:"price"`
+	IsForSale      bool      `json:"is_for_sale"`
+	ImageURL       string    `json:"image_url,omitempty"`
+	ArtistID       uuid.UUID `json:"artist_id"`
+	ArtistUsername string    `json:"artist_username"`
+}
+
+// GetSimilarArtworks godoc
+// @Summary Get similar artworks
+// @Description Returns artworks like the given one ("more like this"), ranked by shared tags, categories, medium, technique and price band
+// @Tags Recommendations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param id path string true "Artwork ID"
+// @Param limit query int false "Number of artworks (default 12, max 50)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /artworks/{id}/similar [get]
+func GetSimilarArtworks(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		artworkID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid artwork ID"))
+		}
+
+		// Only artworks the viewer can see have recommendations
+		var target artwork.Artwork
+		if err := db.Preload("User").Where("id = ?", artworkID).First(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusNotFound, "Artwork not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve artwork: %w", err))
+		}
+		if target.UserID != user.ID && (target.Status != artwork.ApprovedStatus || !target.User.IsActive) {
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusNotFound, "Artwork not found"))
+		}
+		policy, err := privacy.ForRequest(c, db)
+		if err == nil {
+			err = policy.Load(target.UserID)
+		}
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if !policy.CanSeeContent(target.UserID) {
+			return responseHandler.HandleResponse(c, nil, fiber.NewError(fiber.StatusForbidden, "This account is private"))
+		}
+
+		limit := pageLimit(c)
+		// Ask for more than needed since some may be hidden from this viewer
+		ids, err := recommender.Similar(c.Context(), db, artworkID, limit*2)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		cards, err := loadCards(db, user.ID, ids, limit)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"artworks": cards,
+		}, nil)
+	}
+}
+
+// GetRecommendations godoc
+// @Summary Get personal recommendations
+// @Description Returns artworks recommended to the authenticated user ("for you") from their likes, favorites, views and follows. Users with little activity get the currently popular artworks.
+// @Tags Recommendations
+// @Produce json
+// @Security ApiKeyAuth
+// @Param limit query int false "Number of artworks (default 12, max 50)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /recommendations [get]
+func GetRecommendations(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		limit := pageLimit(c)
+		ids, err := recommender.ForYou(c.Context(), db, user.ID, limit*2)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		cards, err := loadCards(db, user.ID, ids, limit)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"artworks": cards,
+		}, nil)
+	}
+}
+
+func pageLimit(c *fiber.Ctx) int {
+	limit := c.QueryInt("limit", defaultLimit)
+	if limit < 1 || limit > maxLimit {
+		return defaultLimit
+	}
+	return limit
+}
+
+// loadCards loads the ranked artworks the viewer may see, keeping their order
+func loadCards(db *gorm.DB, viewerID uuid.UUID, ids []uuid.UUID, limit int) ([]ArtworkCard, error) {
+	cards := make([]ArtworkCard, 0, limit)
+	if len(ids) == 0 {
+		return cards, nil
+	}
+
+	var found []artwork.Artwork
+	if err := recommender.Visible(db, viewerID).
+		Preload("Images").
+		Preload("User").
+		Where("artworks.id IN ?", ids).
+		Find(&found).Error; err != nil {
+		return nil, fmt.Errorf("failed to load artworks: %w", err)
+	}
+	byID := make(map[uuid.UUID]*artwork.Artwork, len(found))
+	for i := range found {
+		byID[found[i].ID] = &found[i]
+	}
+
+	for _, id := range ids {
+		a, ok := byID[id]
+		if !ok {
+			continue
+		}
+		cards = append(cards, ArtworkCard{
+			ID:             a.ID,
+			Title:          a.Title,
+			Slug:           a.Slug,
+			Price:          a.Price,
+			IsForSale:      a.IsForSale,
+			ImageURL:       a.PrimaryImageURL(),
+			ArtistID:       a.UserID,
+			ArtistUsername: a.User.Username,
+		})
+		if len(cards) == limit {
+			break
+		}
+	}
+	return cards, nil
+}
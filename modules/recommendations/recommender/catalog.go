@@ -0,0 +1,250 @@
+package recommender
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	category "github.com/muga20/artsMarket/modules/artwork-management/models/category"
+	tag "github.com/muga20/artsMarket/modules/artwork-management/models/tags"
+	"gorm.io/gorm"
+)
+
+// featureKind is what two artworks can have in common
+type featureKind uint8
+
+const (
+	tagFeature featureKind = iota
+	categoryFeature
+	mediumFeature
+	techniqueFeature
+)
+
+// featureWeights is how much sharing each kind of feature adds to the
+// similarity of two artworks. A shared tag says the most.
+var featureWeights = map[featureKind]float64{
+	tagFeature:       3,
+	categoryFeature:  2,
+	mediumFeature:    1.5,
+	techniqueFeature: 1.5,
+}
+
+const (
+	// samePriceBandWeight and nearPriceBandWeight reward artworks priced in
+	// the same or a neighbouring band
+	samePriceBandWeight = 1
+	nearPriceBandWeight = 0.5
+	// maxPosting skips features shared by so many artworks that they say
+	// little about similarity and would make scoring quadratic
+	maxPosting = 2000
+)
+
+type feature struct {
+	kind featureKind
+	id   uuid.UUID
+}
+
+// features is what an artwork is scored on
+type features struct {
+	ownerID   uuid.UUID
+	createdAt time.Time
+	shared    []feature
+	band      int // -1 when the artwork has no price
+}
+
+// catalog holds the features of a set of artworks, indexed by feature
+type catalog struct {
+	features map[uuid.UUID]*features
+	index    map[feature][]uuid.UUID
+	byOwner  map[uuid.UUID][]uuid.UUID // Newest first
+}
+
+type artworkRow struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	MediumID    *uuid.UUID
+	TechniqueID *uuid.UUID
+	Price       *float64
+	CreatedAt   time.Time
+}
+
+type featureRow struct {
+	ArtworkID uuid.UUID
+	FeatureID uuid.UUID
+}
+
+// priceBand groups prices into bands that double in size, so 60 and 90 are
+// neighbours while 60 and 6000 are not
+func priceBand(price *float64) int {
+	if price == nil || *price < 1 {
+		return -1
+	}
+	return int(math.Log2(*price))
+}
+
+func priceAffinity(a, b int) float64 {
+	switch {
+	case a < 0 || b < 0:
+		return 0
+	case a == b:
+		return samePriceBandWeight
+	case a-b == 1 || b-a == 1:
+		return nearPriceBandWeight
+	}
+	return 0
+}
+
+// loadCatalog loads the public artworks of active accounts. With around set,
+// only that artwork and the artworks sharing a feature with it are loaded,
+// which is enough to score its similar artworks.
+func loadCatalog(db *gorm.DB, around *uuid.UUID) (*catalog, error) {
+	const columns = "artworks.id, artworks.user_id, artworks.medium_id, artworks.technique_id, artworks.price, artworks.created_at"
+
+	query := db.Model(&artwork.Artwork{}).
+		Select(columns).
+		Joins("JOIN users ON users.id = artworks.user_id").
+		Where("artworks.status = ? AND users.is_active = ?", artwork.ApprovedStatus, true)
+
+	var rows []artworkRow
+	if around != nil {
+		// The artwork itself may still be pending, when its owner looks at it
+		var target artworkRow
+		if err := db.Model(&artwork.Artwork{}).Select(columns).Where("artworks.id = ?", *around).Take(&target).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return newCatalog(nil, nil, nil), nil
+			}
+			return nil, fmt.Errorf("failed to load artwork: %w", err)
+		}
+		rows = append(rows, target)
+
+		conditions := []string{
+			"artworks.id IN (?)",
+			"artworks.id IN (?)",
+		}
+		args := []interface{}{
+			db.Model(&tag.ArtworkTag{}).Select("artwork_id").
+				Where("tag_id IN (?)", db.Model(&tag.ArtworkTag{}).Select("tag_id").Where("artwork_id = ?", target.ID)),
+			db.Model(&category.ArtworkCategory{}).Select("artwork_id").
+				Where("category_id IN (?)", db.Model(&category.ArtworkCategory{}).Select("category_id").Where("artwork_id = ?", target.ID)),
+		}
+		if target.MediumID != nil {
+			conditions = append(conditions, "artworks.medium_id = ?")
+			args = append(args, *target.MediumID)
+		}
+		if target.TechniqueID != nil {
+			conditions = append(conditions, "artworks.technique_id = ?")
+			args = append(args, *target.TechniqueID)
+		}
+		query = query.Where("artworks.id != ?", target.ID).
+			Where(strings.Join(conditions, " OR "), args...)
+	}
+
+	var candidates []artworkRow
+	if err := query.Scan(&candidates).Error; err != nil {
+		return nil, fmt.Errorf("failed to load artworks: %w", err)
+	}
+	rows = append(rows, candidates...)
+
+	ids := make([]uuid.UUID, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ID
+	}
+
+	tags, err := loadFeatureRows(db, &tag.ArtworkTag{}, "tag_id", ids, around != nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load artwork tags: %w", err)
+	}
+	categories, err := loadFeatureRows(db, &category.ArtworkCategory{}, "category_id", ids, around != nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load artwork categories: %w", err)
+	}
+	return newCatalog(rows, tags, categories), nil
+}
+
+// loadFeatureRows loads the tag or category links of the artworks. The full
+// catalog loads every link rather than binding every artwork ID.
+func loadFeatureRows(db *gorm.DB, model interface{}, column string, ids []uuid.UUID, filter bool) ([]featureRow, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	query := db.Model(model).Select("artwork_id, " + column + " AS feature_id")
+	if filter {
+		query = query.Where("artwork_id IN ?", ids)
+	}
+	var rows []featureRow
+	err := query.Scan(&rows).Error
+	return rows, err
+}
+
+func newCatalog(rows []artworkRow, tags, categories []featureRow) *catalog {
+	c := &catalog{
+		features: make(map[uuid.UUID]*features, len(rows)),
+		index:    make(map[feature][]uuid.UUID),
+		byOwner:  make(map[uuid.UUID][]uuid.UUID),
+	}
+	add := func(artworkID uuid.UUID, f feature) {
+		if entry, ok := c.features[artworkID]; ok {
+			entry.shared = append(entry.shared, f)
+			c.index[f] = append(c.index[f], artworkID)
+		}
+	}
+
+	for _, row := range rows {
+		c.features[row.ID] = &features{
+			ownerID:   row.UserID,
+			createdAt: row.CreatedAt,
+			band:      priceBand(row.Price),
+		}
+		c.byOwner[row.UserID] = append(c.byOwner[row.UserID], row.ID)
+		if row.MediumID != nil {
+			add(row.ID, feature{kind: mediumFeature, id: *row.MediumID})
+		}
+		if row.TechniqueID != nil {
+			add(row.ID, feature{kind: techniqueFeature, id: *row.TechniqueID})
+		}
+	}
+	for _, row := range tags {
+		add(row.ArtworkID, feature{kind: tagFeature, id: row.FeatureID})
+	}
+	for _, row := range categories {
+		add(row.ArtworkID, feature{kind: categoryFeature, id: row.FeatureID})
+	}
+
+	for _, artworks := range c.byOwner {
+		sort.Slice(artworks, func(i, j int) bool {
+			return c.features[artworks[i]].createdAt.After(c.features[artworks[j]].createdAt)
+		})
+	}
+	return c
+}
+
+// similarTo ranks the artworks sharing features with the given one. The price
+// band only separates artworks that already have something else in common.
+func (c *catalog) similarTo(artworkID uuid.UUID) []scored {
+	target, ok := c.features[artworkID]
+	if !ok {
+		return nil
+	}
+
+	scores := make(map[uuid.UUID]float64)
+	for _, f := range target.shared {
+		posting := c.index[f]
+		if len(posting) > maxPosting {
+			continue
+		}
+		for _, other := range posting {
+			if other != artworkID {
+				scores[other] += featureWeights[f.kind]
+			}
+		}
+	}
+	for other := range scores {
+		scores[other] += priceAffinity(target.band, c.features[other].band)
+	}
+	return top(scores, similarPerArtwork)
+}
@@ -0,0 +1,224 @@
+// Package recommender ranks artworks for discovery. "More like this" lists
+// score artworks by the tags, categories, medium, technique and price band
+// they share with an artwork; "for you" lists combine those with each user's
+// likes, favorites, views and follows. Both are computed by a periodic batch
+// job and served from Redis, with on-demand fallbacks for artworks and users
+// the last run did not cover.
+package recommender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// similarPerArtwork is how many similar artworks are kept per artwork
+	similarPerArtwork = 30
+	// forYouPerUser is how many recommendations are kept per user
+	forYouPerUser = 100
+	// popularSize is how many artworks the cold start list keeps
+	popularSize = 200
+	// cacheTTL outlives the batch interval so one failed run keeps the lists
+	cacheTTL = 26 * time.Hour
+	// writeBatch is how many lists are written per Redis round trip
+	writeBatch = 500
+)
+
+func similarKey(artworkID uuid.UUID) string {
+	return "recs:similar:" + artworkID.String()
+}
+
+func forYouKey(userID uuid.UUID) string {
+	return "recs:for_you:" + userID.String()
+}
+
+const popularKey = "recs:popular"
+
+// scored is a candidate artwork and its score
+type scored struct {
+	id    uuid.UUID
+	score float64
+}
+
+// top sorts the scores and keeps the best n, breaking ties by ID so runs are stable
+func top(scores map[uuid.UUID]float64, n int) []scored {
+	ranked := make([]scored, 0, len(scores))
+	for id, score := range scores {
+		if score > 0 {
+			ranked = append(ranked, scored{id: id, score: score})
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].id.String() < ranked[j].id.String()
+	})
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// Compute rebuilds every cached list: the similar artworks of each public
+// artwork, the recommendations of each user with recent activity and the
+// popular artworks shown to everyone else
+func Compute(ctx context.Context, db *gorm.DB) error {
+	started := time.Now()
+
+	catalog, err := loadCatalog(db, nil)
+	if err != nil {
+		return err
+	}
+	similar := make(map[uuid.UUID][]scored, len(catalog.features))
+	for id := range catalog.features {
+		similar[id] = catalog.similarTo(id)
+	}
+	if err := writeLists(ctx, similar, similarKey); err != nil {
+		return err
+	}
+
+	signals, err := loadSignals(db, time.Now().Add(-signalWindow))
+	if err != nil {
+		return err
+	}
+	forYou := make(map[uuid.UUID][]scored, len(signals.users))
+	for userID := range signals.users {
+		if ranked := signals.forYou(userID, catalog, similar); len(ranked) > 0 {
+			forYou[userID] = ranked
+		}
+	}
+	if err := writeLists(ctx, forYou, forYouKey); err != nil {
+		return err
+	}
+
+	popular := signals.popular(catalog)
+	if err := writeLists(ctx, map[uuid.UUID][]scored{uuid.Nil: popular}, func(uuid.UUID) string { return popularKey }); err != nil {
+		return err
+	}
+
+	log.Printf("Computed recommendations for %d artworks and %d users in %s",
+		len(similar), len(forYou), time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+// writeLists replaces each list in Redis with its ranked artworks
+func writeLists(ctx context.Context, lists map[uuid.UUID][]scored, key func(uuid.UUID) string) error {
+	pipe := config.Redis().Pipeline()
+	queued := 0
+	for id, ranked := range lists {
+		k := key(id)
+		pipe.Del(ctx, k)
+		if len(ranked) > 0 {
+			entries := make([]redis.Z, len(ranked))
+			for i, candidate := range ranked {
+				entries[i] = redis.Z{Score: candidate.score, Member: candidate.id.String()}
+			}
+			pipe.ZAdd(ctx, k, entries...)
+			pipe.Expire(ctx, k, cacheTTL)
+		}
+
+		if queued++; queued%writeBatch == 0 {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return fmt.Errorf("failed to cache recommendations: %w", err)
+			}
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to cache recommendations: %w", err)
+	}
+	return nil
+}
+
+// readList returns the cached artwork IDs under key, best first. A missing
+// list returns redis.Nil.
+func readList(ctx context.Context, key string, limit int) ([]uuid.UUID, error) {
+	members, err := config.Redis().ZRevRange(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+	if len(members) == 0 {
+		return nil, redis.Nil
+	}
+	ids := make([]uuid.UUID, 0, len(members))
+	for _, member := range members {
+		if id, err := uuid.Parse(member); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// Similar returns up to limit artworks like the given one, best first. An
+// artwork the last batch run did not cover, such as one approved since, is
+// scored on demand.
+func Similar(ctx context.Context, db *gorm.DB, artworkID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	ids, err := readList(ctx, similarKey(artworkID), limit)
+	if err == nil {
+		return ids, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		log.Printf("Scoring similar artworks of %s from the database: %v", artworkID, err)
+	}
+
+	catalog, err := loadCatalog(db, &artworkID)
+	if err != nil {
+		return nil, err
+	}
+	ranked := catalog.similarTo(artworkID)
+	if len(ranked) > 0 {
+		if err := writeLists(ctx, map[uuid.UUID][]scored{artworkID: ranked}, similarKey); err != nil {
+			log.Printf("Failed to cache similar artworks of %s: %v", artworkID, err)
+		}
+	}
+
+	ids = make([]uuid.UUID, 0, limit)
+	for _, candidate := range ranked {
+		if len(ids) == limit {
+			break
+		}
+		ids = append(ids, candidate.id)
+	}
+	return ids, nil
+}
+
+// ForYou returns up to limit artworks recommended to the user, best first.
+// Users without enough activity for a personal list get the popular artworks.
+func ForYou(ctx context.Context, db *gorm.DB, userID uuid.UUID, limit int) ([]uuid.UUID, error) {
+	ids, err := readList(ctx, forYouKey(userID), limit)
+	if err == nil {
+		return ids, nil
+	}
+	if ids, err = readList(ctx, popularKey, limit); err == nil {
+		return ids, nil
+	}
+	if !errors.Is(err, redis.Nil) {
+		log.Printf("Reading recommendations of user %s from the database: %v", userID, err)
+	}
+
+	// Before the first batch run, the most viewed artworks stand in
+	if err := db.Model(&artwork.Artwork{}).
+		Where("status = ?", artwork.ApprovedStatus).
+		Order("view_count DESC, created_at DESC").
+		Limit(limit).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to load popular artworks: %w", err)
+	}
+	return ids, nil
+}
+
+// Visible selects the artworks the viewer may be recommended: those they may
+// browse, other than their own
+func Visible(db *gorm.DB, viewerID uuid.UUID) *gorm.DB {
+	return artwork.VisibleTo(db, viewerID).Where("artworks.user_id != ?", viewerID)
+}
@@ -0,0 +1,178 @@
+package recommender
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	analytics "github.com/muga20/artsMarket/modules/artwork-management/models/analytics"
+	engagement "github.com/muga20/artsMarket/modules/artwork-management/models/engagement"
+	users "github.com/muga20/artsMarket/modules/users/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// signalWindow is how far back likes, favorites and views count
+	signalWindow = 90 * 24 * time.Hour
+
+	// How much each kind of interaction says about a user's taste
+	favoriteWeight = 4
+	likeWeight     = 3
+	viewWeight     = 1
+	// maxViewsCounted stops repeated views of one artwork from drowning out
+	// everything else
+	maxViewsCounted = 3
+
+	// Each followed artist's newest artworks are recommended as well
+	followedArtistWeight   = 2
+	followedArtistArtworks = 10
+)
+
+// userSignals is what one user did recently
+type userSignals struct {
+	interest map[uuid.UUID]float64 // Artwork to interest weight
+	engaged  map[uuid.UUID]bool    // Liked or favorited, so not recommended again
+	follows  []uuid.UUID
+}
+
+// signals holds the recent interactions of every user who had any
+type signals struct {
+	users map[uuid.UUID]*userSignals
+}
+
+type interactionRow struct {
+	UserID    uuid.UUID
+	ArtworkID uuid.UUID
+	Count     int
+}
+
+type followRow struct {
+	FollowerID  uuid.UUID
+	FollowingID uuid.UUID
+}
+
+func (s *signals) user(userID uuid.UUID) *userSignals {
+	u, ok := s.users[userID]
+	if !ok {
+		u = &userSignals{
+			interest: make(map[uuid.UUID]float64),
+			engaged:  make(map[uuid.UUID]bool),
+		}
+		s.users[userID] = u
+	}
+	return u
+}
+
+// loadSignals loads the likes, favorites and views since the given time and
+// every approved follow
+func loadSignals(db *gorm.DB, since time.Time) (*signals, error) {
+	s := &signals{users: make(map[uuid.UUID]*userSignals)}
+
+	var likes []interactionRow
+	if err := db.Model(&engagement.ArtworkLike{}).
+		Select("user_id, artwork_id").
+		Where("created_at >= ?", since).
+		Scan(&likes).Error; err != nil {
+		return nil, fmt.Errorf("failed to load likes: %w", err)
+	}
+	for _, like := range likes {
+		u := s.user(like.UserID)
+		u.interest[like.ArtworkID] += likeWeight
+		u.engaged[like.ArtworkID] = true
+	}
+
+	var favorites []interactionRow
+	if err := db.Model(&engagement.ArtworkFavorite{}).
+		Select("user_id, artwork_id").
+		Where("created_at >= ?", since).
+		Scan(&favorites).Error; err != nil {
+		return nil, fmt.Errorf("failed to load favorites: %w", err)
+	}
+	for _, favorite := range favorites {
+		u := s.user(favorite.UserID)
+		u.interest[favorite.ArtworkID] += favoriteWeight
+		u.engaged[favorite.ArtworkID] = true
+	}
+
+	var views []interactionRow
+	if err := db.Model(&analytics.ArtworkView{}).
+		Select("user_id, artwork_id, COUNT(*) AS count").
+		Where("viewed_at >= ? AND user_id IS NOT NULL", since).
+		Group("user_id, artwork_id").
+		Scan(&views).Error; err != nil {
+		return nil, fmt.Errorf("failed to load views: %w", err)
+	}
+	for _, view := range views {
+		count := view.Count
+		if count > maxViewsCounted {
+			count = maxViewsCounted
+		}
+		s.user(view.UserID).interest[view.ArtworkID] += float64(count * viewWeight)
+	}
+
+	var follows []followRow
+	if err := db.Model(&users.Follower{}).
+		Select("follower_id, following_id").
+		Where("status = ?", users.FollowStatusAccepted).
+		Scan(&follows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load follows: %w", err)
+	}
+	for _, follow := range follows {
+		u := s.user(follow.FollowerID)
+		u.follows = append(u.follows, follow.FollowingID)
+	}
+	return s, nil
+}
+
+// forYou ranks artworks for the user: those similar to what they liked,
+// favorited and viewed, and the newest work of the artists they follow.
+// Artworks they already liked or favorited, and their own, are left out.
+func (s *signals) forYou(userID uuid.UUID, c *catalog, similar map[uuid.UUID][]scored) []scored {
+	u, ok := s.users[userID]
+	if !ok {
+		return nil
+	}
+
+	scores := make(map[uuid.UUID]float64)
+	for artworkID, weight := range u.interest {
+		candidates := similar[artworkID]
+		if len(candidates) == 0 {
+			continue
+		}
+		// Scale each list to its best match so artworks with many features
+		// do not outweigh the rest
+		best := candidates[0].score
+		for _, candidate := range candidates {
+			scores[candidate.id] += weight * candidate.score / best
+		}
+	}
+	for _, artistID := range u.follows {
+		for i, artworkID := range c.byOwner[artistID] {
+			if i == followedArtistArtworks {
+				break
+			}
+			scores[artworkID] += followedArtistWeight
+		}
+	}
+
+	for artworkID := range scores {
+		if u.engaged[artworkID] || c.features[artworkID].ownerID == userID {
+			delete(scores, artworkID)
+		}
+	}
+	return top(scores, forYouPerUser)
+}
+
+// popular ranks the public artworks by everyone's recent interest, for users
+// without a personal list
+func (s *signals) popular(c *catalog) []scored {
+	scores := make(map[uuid.UUID]float64)
+	for _, u := range s.users {
+		for artworkID, weight := range u.interest {
+			if _, ok := c.features[artworkID]; ok {
+				scores[artworkID] += weight
+			}
+		}
+	}
+	return top(scores, popularSize)
+}
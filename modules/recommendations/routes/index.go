@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/recommendations/handlers/recommendations"
+	"github.com/muga20/artsMarket/modules/users/accesstokens"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/middleware"
+	"gorm.io/gorm"
+)
+
+// RecommendationsSetupRoutes sets up the artwork discovery routes
+func RecommendationsSetupRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *handlers.ResponseHandler) {
+	apiGroup.Get("/artworks/:id/similar",
+		middleware.AuthMiddleware(db, responseHandler, accesstokens.ScopeArtworksRead),
+		recommendations.GetSimilarArtworks(db, responseHandler))
+	apiGroup.Get("/recommendations", middleware.AuthMiddleware(db, responseHandler), recommendations.GetRecommendations(db, responseHandler))
+}
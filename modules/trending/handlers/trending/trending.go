@@ -0,0 +1,237 @@
+package trending
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	category "github.com/muga20/artsMarket/modules/artwork-management/models/category"
+	tag "github.com/muga20/artsMarket/modules/artwork-management/models/tags"
+	"github.com/muga20/artsMarket/modules/trending/ranking"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/privacy"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultLimit  = 20
+	maxLimit      = 50
+	defaultWindow = "7d"
+)
+
+// TrendingItem is one ranked entry. Only the field matching the list's kind is set.
+type TrendingItem struct {
+	Rank     int               `json:"rank"`
+	Score    float64           `json:"score"`
+	Artwork  *ArtworkItem      `json:"artwork,omitempty"`
+	Artist   *privacy.UserView `json:"artist,omitempty"`
+	Tag      *NamedItem        `json:"tag,omitempty"`
+	Category *NamedItem        `json:"category,omitempty"`
+}
+
+// ArtworkItem is a trending artwork
+type ArtworkItem struct {
+	ID             uuid.UUID `json:"id"`
+	Title          string    `json:"title"`
+	Slug           string    `json:"slug"`
+	Price          *float64  `json:"price"`
+	IsForSale      bool      `json:"is_for_sale"`
+	ImageURL       string    `json:"image_url,omitempty"`
+	ArtistUsername string    `json:"artist_username"`
+}
+
+// NamedItem is a trending tag or category
+type NamedItem struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	Slug string    `json:"slug,omitempty"`
+}
+
+// GetTrending godoc
+// @Summary Get trending items
+// @Description Ranks artworks, artists, tags or categories by recent views, likes, favorites, comments and follows, with older engagement counting less. Only public artworks and accounts are listed.
+// @Tags Trending
+// @Produce json
+// @Param kind path string true "What to rank (artworks|artists|tags|categories)"
+// @Param window query string false "Period (24h|7d|30d, default 7d)"
+// @Param limit query int false "Number of items (default 20, max 50)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /trending/{kind} [get]
+func GetTrending(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		kind, ok := ranking.ParseKind(c.Params("kind"))
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid kind. Must be artworks, artists, tags, or categories"))
+		}
+		window, ok := ranking.ParseWindow(c.Query("window", defaultWindow))
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid window. Must be 24h, 7d, or 30d"))
+		}
+		limit := c.QueryInt("limit", defaultLimit)
+		if limit < 1 || limit > maxLimit {
+			limit = defaultLimit
+		}
+
+		// Ask for more than needed since hidden entries are dropped
+		entries, err := ranking.Top(c.Context(), kind, window, limit*2)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		ids := make([]uuid.UUID, len(entries))
+		for i, entry := range entries {
+			ids[i] = entry.ID
+		}
+
+		var fill func(item *TrendingItem, id uuid.UUID) bool
+		switch kind {
+		case ranking.KindArtworks:
+			fill, err = artworkItems(db, ids)
+		case ranking.KindArtists:
+			fill, err = artistItems(c, db, ids)
+		case ranking.KindTags:
+			fill, err = tagItems(db, ids)
+		case ranking.KindCategories:
+			fill, err = categoryItems(db, ids)
+		}
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		items := make([]TrendingItem, 0, limit)
+		for _, entry := range entries {
+			item := TrendingItem{
+				Rank:  len(items) + 1,
+				Score: math.Round(entry.Score*100) / 100,
+			}
+			if !fill(&item, entry.ID) {
+				continue
+			}
+			items = append(items, item)
+			if len(items) == limit {
+				break
+			}
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"kind":   kind,
+			"window": window.Name,
+			"items":  items,
+		}, nil)
+	}
+}
+
+// publicArtists selects active accounts that are not private
+func publicArtists(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.User{}).
+		Select("id").
+		Where("is_active = ?", true).
+		Where("id NOT IN (?)", db.Model(&models.UserPrivacySetting{}).
+			Select("user_id").
+			Where("is_private = ?", true))
+}
+
+func artworkItems(db *gorm.DB, ids []uuid.UUID) (func(*TrendingItem, uuid.UUID) bool, error) {
+	var found []artwork.Artwork
+	if len(ids) > 0 {
+		if err := db.Preload("Images").Preload("User").
+			Where("id IN ? AND status = ?", ids, artwork.ApprovedStatus).
+			Where("user_id IN (?)", publicArtists(db)).
+			Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load artworks: %w", err)
+		}
+	}
+	byID := make(map[uuid.UUID]*ArtworkItem, len(found))
+	for _, a := range found {
+		byID[a.ID] = &ArtworkItem{
+			ID:             a.ID,
+			Title:          a.Title,
+			Slug:           a.Slug,
+			Price:          a.Price,
+			IsForSale:      a.IsForSale,
+			ImageURL:       a.PrimaryImageURL(),
+			ArtistUsername: a.User.Username,
+		}
+	}
+	return func(item *TrendingItem, id uuid.UUID) bool {
+		item.Artwork = byID[id]
+		return item.Artwork != nil
+	}, nil
+}
+
+func artistItems(c *fiber.Ctx, db *gorm.DB, ids []uuid.UUID) (func(*TrendingItem, uuid.UUID) bool, error) {
+	var artists []privacy.UserView
+	if len(ids) > 0 {
+		if err := db.Table("users").
+			Select(`
+                users.id,
+                users.username,
+                user_details.first_name,
+                user_details.last_name,
+                user_details.profile_image
+            `).
+			Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
+			Where("users.id IN ?", ids).
+			Where("users.id IN (?)", publicArtists(db)).
+			Scan(&artists).Error; err != nil {
+			return nil, fmt.Errorf("failed to load artists: %w", err)
+		}
+	}
+
+	// Contact details follow each artist's privacy settings
+	policy, err := privacy.ForRequest(c, db)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.ApplyAll(artists); err != nil {
+		return nil, err
+	}
+	byID := make(map[uuid.UUID]*privacy.UserView, len(artists))
+	for i := range artists {
+		byID[artists[i].ID] = &artists[i]
+	}
+	return func(item *TrendingItem, id uuid.UUID) bool {
+		item.Artist = byID[id]
+		return item.Artist != nil
+	}, nil
+}
+
+func tagItems(db *gorm.DB, ids []uuid.UUID) (func(*TrendingItem, uuid.UUID) bool, error) {
+	var found []tag.Tag
+	if len(ids) > 0 {
+		if err := db.Where("id IN ? AND is_active = ? AND status = ?", ids, true, tag.TagStatusApproved).Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load tags: %w", err)
+		}
+	}
+	byID := make(map[uuid.UUID]*NamedItem, len(found))
+	for _, t := range found {
+		byID[t.ID] = &NamedItem{ID: t.ID, Name: t.TagName}
+	}
+	return func(item *TrendingItem, id uuid.UUID) bool {
+		item.Tag = byID[id]
+		return item.Tag != nil
+	}, nil
+}
+
+func categoryItems(db *gorm.DB, ids []uuid.UUID) (func(*TrendingItem, uuid.UUID) bool, error) {
+	var found []category.Category
+	if len(ids) > 0 {
+		if err := db.Where("id IN ? AND is_active = ?", ids, true).Find(&found).Error; err != nil {
+			return nil, fmt.Errorf("failed to load categories: %w", err)
+		}
+	}
+	byID := make(map[uuid.UUID]*NamedItem, len(found))
+	for _, cat := range found {
+		byID[cat.ID] = &NamedItem{ID: cat.ID, Name: cat.CategoryName, Slug: cat.Slug}
+	}
+	return func(item *TrendingItem, id uuid.UUID) bool {
+		item.Category = byID[id]
+		return item.Category != nil
+	}, nil
+}
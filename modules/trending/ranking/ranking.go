@@ -0,0 +1,219 @@
+// Package ranking keeps the trending artworks, artists, tags and categories.
+//
+// Every view, like, favorite, comment and follow adds to the score of what it
+// is about, decayed exponentially with the event's age so recent engagement
+// counts the most. Scores use forward decay: an event is stored as its weight
+// times exp(λ·(t − epoch)), which only grows with t, so scores can be added
+// to incrementally in Redis sorted sets and still rank as if every event had
+// been decayed to now. The nightly rebuild recomputes all scores from the
+// database, drops events that left their window and moves the epoch forward.
+package ranking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	category "github.com/muga20/artsMarket/modules/artwork-management/models/category"
+	tag "github.com/muga20/artsMarket/modules/artwork-management/models/tags"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// Kind is what a trending list ranks
+type Kind string
+
+const (
+	KindArtworks   Kind = "artworks"
+	KindArtists    Kind = "artists"
+	KindTags       Kind = "tags"
+	KindCategories Kind = "categories"
+)
+
+var kinds = []Kind{KindArtworks, KindArtists, KindTags, KindCategories}
+
+// ParseKind returns the kind with the given name
+func ParseKind(name string) (Kind, bool) {
+	for _, kind := range kinds {
+		if string(kind) == name {
+			return kind, true
+		}
+	}
+	return "", false
+}
+
+// Window is a trending period. Events older than Length are left out, and an
+// event's weight halves every HalfLife.
+type Window struct {
+	Name     string
+	Length   time.Duration
+	HalfLife time.Duration
+}
+
+// Windows are the trending periods, shortest first
+var Windows = []Window{
+	{Name: "24h", Length: 24 * time.Hour, HalfLife: 6 * time.Hour},
+	{Name: "7d", Length: 7 * 24 * time.Hour, HalfLife: 36 * time.Hour},
+	{Name: "30d", Length: 30 * 24 * time.Hour, HalfLife: 7 * 24 * time.Hour},
+}
+
+// ParseWindow returns the window with the given name
+func ParseWindow(name string) (Window, bool) {
+	for _, window := range Windows {
+		if window.Name == name {
+			return window, true
+		}
+	}
+	return Window{}, false
+}
+
+// growth is the forward decay factor of an event at the given time
+func (w Window) growth(at, epoch time.Time) float64 {
+	lambda := math.Ln2 / w.HalfLife.Seconds()
+	return math.Exp(lambda * at.Sub(epoch).Seconds())
+}
+
+// Event is a kind of engagement
+type Event int
+
+const (
+	EventView Event = iota
+	EventLike
+	EventFavorite
+	EventComment
+	EventFollow
+)
+
+// eventWeights is how much each kind of engagement counts. A view is the
+// cheapest signal and a comment the most deliberate one.
+var eventWeights = map[Event]float64{
+	EventView:     1,
+	EventLike:     3,
+	EventFavorite: 4,
+	EventComment:  5,
+	EventFollow:   5,
+}
+
+func listKey(kind Kind, window Window) string {
+	return "trending:" + string(kind) + ":" + window.Name
+}
+
+const epochKey = "trending:epoch"
+
+// Entry is a ranked item with its score decayed to now
+type Entry struct {
+	ID    uuid.UUID
+	Score float64
+}
+
+// RecordArtworkEvent adds engagement with an artwork to the artwork, its
+// artist and its tags and categories. Failures are logged, since trending
+// lists are rebuilt nightly anyway.
+func RecordArtworkEvent(ctx context.Context, db *gorm.DB, artworkID uuid.UUID, event Event) {
+	var owner uuid.UUID
+	if err := db.Model(&artwork.Artwork{}).
+		Where("id = ? AND status = ?", artworkID, artwork.ApprovedStatus).
+		Pluck("user_id", &owner).Error; err != nil || owner == uuid.Nil {
+		// Engagement with artworks that are not public does not trend
+		if err != nil {
+			log.Printf("Failed to load artwork %s for trending: %v", artworkID, err)
+		}
+		return
+	}
+	var tagIDs, categoryIDs []uuid.UUID
+	if err := db.Model(&tag.ArtworkTag{}).Where("artwork_id = ?", artworkID).Pluck("tag_id", &tagIDs).Error; err != nil {
+		log.Printf("Failed to load tags of artwork %s for trending: %v", artworkID, err)
+	}
+	if err := db.Model(&category.ArtworkCategory{}).Where("artwork_id = ?", artworkID).Pluck("category_id", &categoryIDs).Error; err != nil {
+		log.Printf("Failed to load categories of artwork %s for trending: %v", artworkID, err)
+	}
+
+	increment(ctx, event, map[Kind][]uuid.UUID{
+		KindArtworks:   {artworkID},
+		KindArtists:    {owner},
+		KindTags:       tagIDs,
+		KindCategories: categoryIDs,
+	})
+}
+
+// RecordFollow adds a new follower to the artist's score
+func RecordFollow(ctx context.Context, artistID uuid.UUID) {
+	increment(ctx, EventFollow, map[Kind][]uuid.UUID{KindArtists: {artistID}})
+}
+
+func increment(ctx context.Context, event Event, targets map[Kind][]uuid.UUID) {
+	epoch, err := currentEpoch(ctx)
+	if err != nil {
+		log.Printf("Failed to update trending scores: %v", err)
+		return
+	}
+
+	now := time.Now()
+	pipe := config.Redis().Pipeline()
+	for _, window := range Windows {
+		value := eventWeights[event] * window.growth(now, epoch)
+		for kind, ids := range targets {
+			for _, id := range ids {
+				pipe.ZIncrBy(ctx, listKey(kind, window), value, id.String())
+			}
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		log.Printf("Failed to update trending scores: %v", err)
+	}
+}
+
+// currentEpoch returns the time scores are decayed against, starting the
+// first epoch when there is none yet
+func currentEpoch(ctx context.Context) (time.Time, error) {
+	rdb := config.Redis()
+	if err := rdb.SetNX(ctx, epochKey, time.Now().Unix(), 0).Err(); err != nil {
+		return time.Time{}, err
+	}
+	seconds, err := rdb.Get(ctx, epochKey).Int64()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(seconds, 0), nil
+}
+
+// Top returns up to limit items of the list, highest score first
+func Top(ctx context.Context, kind Kind, window Window, limit int) ([]Entry, error) {
+	rdb := config.Redis()
+	seconds, err := rdb.Get(ctx, epochKey).Int64()
+	if errors.Is(err, redis.Nil) {
+		// Nothing has been recorded yet
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trending epoch: %w", err)
+	}
+
+	members, err := rdb.ZRevRangeWithScores(ctx, listKey(kind, window), 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load trending %s: %w", kind, err)
+	}
+
+	decay := 1 / window.growth(time.Now(), time.Unix(seconds, 0))
+	entries := make([]Entry, 0, len(members))
+	for _, member := range members {
+		id, err := uuid.Parse(fmt.Sprint(member.Member))
+		if err != nil {
+			continue
+		}
+		entries = append(entries, Entry{ID: id, Score: member.Score * decay})
+	}
+	return entries, nil
+}
+
+// epochString formats an epoch for storage
+func epochString(epoch time.Time) string {
+	return strconv.FormatInt(epoch.Unix(), 10)
+}
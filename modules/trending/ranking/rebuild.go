@@ -0,0 +1,220 @@
+package ranking
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	analytics "github.com/muga20/artsMarket/modules/artwork-management/models/analytics"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	category "github.com/muga20/artsMarket/modules/artwork-management/models/category"
+	engagement "github.com/muga20/artsMarket/modules/artwork-management/models/engagement"
+	tag "github.com/muga20/artsMarket/modules/artwork-management/models/tags"
+	users "github.com/muga20/artsMarket/modules/users/models"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+const (
+	// bucketSeconds groups events by hour, which is fine grained enough for
+	// the shortest half-life and keeps the rebuild queries small
+	bucketSeconds = 3600
+	// writeBatch is how many members are added per Redis round trip
+	writeBatch = 1000
+)
+
+// bucketRow counts the events of one target in one hour
+type bucketRow struct {
+	TargetID uuid.UUID
+	Bucket   int64
+	Count    int
+}
+
+type linkRow struct {
+	ArtworkID uuid.UUID
+	LinkedID  uuid.UUID
+}
+
+// scores holds the rebuilt scores of every list
+type scores map[string]map[uuid.UUID]float64
+
+func (s scores) add(kind Kind, window Window, id uuid.UUID, value float64) {
+	key := listKey(kind, window)
+	if s[key] == nil {
+		s[key] = make(map[uuid.UUID]float64)
+	}
+	s[key][id] += value
+}
+
+// Rebuild recomputes every trending list from the database and starts a new
+// epoch. Incremental updates made while it runs may be lost; they are small
+// next to a full window of engagement.
+func Rebuild(ctx context.Context, db *gorm.DB) error {
+	started := time.Now()
+	epoch := started.Truncate(time.Second)
+	since := epoch.Add(-Windows[len(Windows)-1].Length)
+
+	artworkEvents := map[Event][]bucketRow{}
+	sources := []struct {
+		event  Event
+		model  interface{}
+		column string
+	}{
+		{EventView, &analytics.ArtworkView{}, "viewed_at"},
+		{EventLike, &engagement.ArtworkLike{}, "created_at"},
+		{EventFavorite, &engagement.ArtworkFavorite{}, "created_at"},
+		{EventComment, &engagement.ArtworkComment{}, "created_at"},
+	}
+	for _, source := range sources {
+		rows, err := loadBuckets(db, source.model, "artwork_id", source.column, since)
+		if err != nil {
+			return err
+		}
+		artworkEvents[source.event] = rows
+	}
+	follows, err := loadBuckets(db.Where("status = ?", users.FollowStatusAccepted),
+		&users.Follower{}, "following_id", "COALESCE(accepted_at, created_at)", since)
+	if err != nil {
+		return err
+	}
+
+	// Only public artworks trend, and they carry their artist, tags and categories
+	var owners []linkRow
+	if err := db.Model(&artwork.Artwork{}).
+		Select("id AS artwork_id, user_id AS linked_id").
+		Where("status = ?", artwork.ApprovedStatus).
+		Scan(&owners).Error; err != nil {
+		return fmt.Errorf("failed to load artworks: %w", err)
+	}
+	ownerOf := make(map[uuid.UUID]uuid.UUID, len(owners))
+	for _, row := range owners {
+		ownerOf[row.ArtworkID] = row.LinkedID
+	}
+	tagsOf, err := loadLinks(db, &tag.ArtworkTag{}, "tag_id")
+	if err != nil {
+		return fmt.Errorf("failed to load artwork tags: %w", err)
+	}
+	categoriesOf, err := loadLinks(db, &category.ArtworkCategory{}, "category_id")
+	if err != nil {
+		return fmt.Errorf("failed to load artwork categories: %w", err)
+	}
+
+	rebuilt := scores{}
+	for _, window := range Windows {
+		cutoff := epoch.Add(-window.Length)
+		for event, rows := range artworkEvents {
+			for _, row := range rows {
+				at := bucketTime(row.Bucket)
+				owner, public := ownerOf[row.TargetID]
+				if !public || at.Before(cutoff) {
+					continue
+				}
+				value := eventWeights[event] * float64(row.Count) * window.growth(at, epoch)
+				rebuilt.add(KindArtworks, window, row.TargetID, value)
+				rebuilt.add(KindArtists, window, owner, value)
+				for _, tagID := range tagsOf[row.TargetID] {
+					rebuilt.add(KindTags, window, tagID, value)
+				}
+				for _, categoryID := range categoriesOf[row.TargetID] {
+					rebuilt.add(KindCategories, window, categoryID, value)
+				}
+			}
+		}
+		for _, row := range follows {
+			if at := bucketTime(row.Bucket); !at.Before(cutoff) {
+				rebuilt.add(KindArtists, window, row.TargetID, eventWeights[EventFollow]*float64(row.Count)*window.growth(at, epoch))
+			}
+		}
+	}
+
+	if err := replaceLists(ctx, rebuilt, epoch); err != nil {
+		return err
+	}
+	log.Printf("Rebuilt trending lists in %s", time.Since(started).Round(time.Millisecond))
+	return nil
+}
+
+func bucketTime(bucket int64) time.Time {
+	return time.Unix(bucket*bucketSeconds+bucketSeconds/2, 0)
+}
+
+// loadBuckets counts the events of each target per hour since the given time
+func loadBuckets(db *gorm.DB, model interface{}, targetColumn, timeColumn string, since time.Time) ([]bucketRow, error) {
+	var rows []bucketRow
+	bucket := fmt.Sprintf("FLOOR(UNIX_TIMESTAMP(%s) / %d)", timeColumn, bucketSeconds)
+	if err := db.Model(model).
+		Select(fmt.Sprintf("%s AS target_id, %s AS bucket, COUNT(*) AS count", targetColumn, bucket)).
+		Where(timeColumn+" >= ?", since).
+		Group(targetColumn + ", bucket").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load %T events: %w", model, err)
+	}
+	return rows, nil
+}
+
+// loadLinks maps each artwork to its tags or categories
+func loadLinks(db *gorm.DB, model interface{}, column string) (map[uuid.UUID][]uuid.UUID, error) {
+	var rows []linkRow
+	if err := db.Model(model).Select("artwork_id, " + column + " AS linked_id").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	links := make(map[uuid.UUID][]uuid.UUID)
+	for _, row := range rows {
+		links[row.ArtworkID] = append(links[row.ArtworkID], row.LinkedID)
+	}
+	return links, nil
+}
+
+// replaceLists writes the rebuilt lists next to the live ones, then swaps
+// them in together with the new epoch so readers never see a half-built list
+func replaceLists(ctx context.Context, rebuilt scores, epoch time.Time) error {
+	rdb := config.Redis()
+	staging := func(key string) string { return key + ":rebuild" }
+
+	pipe := rdb.Pipeline()
+	queued := 0
+	for key, members := range rebuilt {
+		pipe.Del(ctx, staging(key))
+		batch := make([]redis.Z, 0, writeBatch)
+		for id, score := range members {
+			batch = append(batch, redis.Z{Score: score, Member: id.String()})
+			if len(batch) == writeBatch {
+				pipe.ZAdd(ctx, staging(key), batch...)
+				batch = make([]redis.Z, 0, writeBatch)
+			}
+		}
+		if len(batch) > 0 {
+			pipe.ZAdd(ctx, staging(key), batch...)
+		}
+		if queued++; queued%10 == 0 {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return fmt.Errorf("failed to write trending lists: %w", err)
+			}
+		}
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to write trending lists: %w", err)
+	}
+
+	_, err := rdb.TxPipelined(ctx, func(tx redis.Pipeliner) error {
+		for _, kind := range kinds {
+			for _, window := range Windows {
+				key := listKey(kind, window)
+				if len(rebuilt[key]) > 0 {
+					tx.Rename(ctx, staging(key), key)
+				} else {
+					tx.Del(ctx, key)
+				}
+			}
+		}
+		tx.Set(ctx, epochKey, epochString(epoch), 0)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to swap trending lists: %w", err)
+	}
+	return nil
+}
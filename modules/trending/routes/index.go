@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/trending/handlers/trending"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+// TrendingSetupRoutes sets up the trending routes. They are public so the
+// landing page can show them to visitors.
+func TrendingSetupRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *handlers.ResponseHandler) {
+	apiGroup.Get("/trending/:kind", trending.GetTrending(db, responseHandler))
+}
@@ -0,0 +1,153 @@
+// Package accesstokens issues and verifies scoped personal access tokens used
+// by integrations that cannot hold a browser session.
+package accesstokens
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"gorm.io/gorm"
+)
+
+// TokenPrefix marks personal access tokens so they can be told apart from JWTs
+const TokenPrefix = "amp_"
+
+// Scopes a token can be granted. A write scope also grants the matching read scope.
+const (
+	ScopeArtworksRead     = "artworks:read"
+	ScopeArtworksWrite    = "artworks:write"
+	ScopeCollectionsRead  = "collections:read"
+	ScopeCollectionsWrite = "collections:write"
+)
+
+// AllScopes lists every scope a user may request
+var AllScopes = []string{
+	ScopeArtworksRead,
+	ScopeArtworksWrite,
+	ScopeCollectionsRead,
+	ScopeCollectionsWrite,
+}
+
+// lastUsedInterval throttles how often token use is written back
+const lastUsedInterval = time.Minute
+
+var (
+	ErrInvalidToken = errors.New("invalid or expired access token")
+	ErrUnknownScope = errors.New("unknown scope")
+	ErrNoScopes     = errors.New("at least one scope is required")
+)
+
+// IsAccessToken reports whether a bearer credential is a personal access token
+func IsAccessToken(credential string) bool {
+	return strings.HasPrefix(credential, TokenPrefix)
+}
+
+// NormalizeScopes validates the requested scopes and removes duplicates
+func NormalizeScopes(requested []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var scopes []string
+	for _, scope := range requested {
+		scope = strings.TrimSpace(scope)
+		if scope == "" || seen[scope] {
+			continue
+		}
+		if !isKnownScope(scope) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownScope, scope)
+		}
+		seen[scope] = true
+		scopes = append(scopes, scope)
+	}
+	if len(scopes) == 0 {
+		return nil, ErrNoScopes
+	}
+	sort.Strings(scopes)
+	return scopes, nil
+}
+
+// Create issues a token for the user and returns the plaintext value, which is
+// only ever shown once
+func Create(db *gorm.DB, userID uuid.UUID, name string, scopes []string, expiresAt *time.Time) (string, *models.PersonalAccessToken, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+	plaintext := TokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+
+	token := models.PersonalAccessToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Name:      name,
+		TokenHash: hashToken(plaintext),
+		Prefix:    plaintext[:len(TokenPrefix)+6],
+		Scopes:    strings.Join(scopes, " "),
+		ExpiresAt: expiresAt,
+	}
+	if err := db.Create(&token).Error; err != nil {
+		return "", nil, fmt.Errorf("failed to store access token: %w", err)
+	}
+	return plaintext, &token, nil
+}
+
+// Authenticate looks up an unrevoked, unexpired token and records its use
+func Authenticate(db *gorm.DB, plaintext, ip string) (*models.PersonalAccessToken, error) {
+	var token models.PersonalAccessToken
+	if err := db.Where("token_hash = ?", hashToken(plaintext)).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, fmt.Errorf("failed to retrieve access token: %w", err)
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || (token.ExpiresAt != nil && token.ExpiresAt.Before(now)) {
+		return nil, ErrInvalidToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedInterval || token.LastUsedIP != ip {
+		db.Model(&token).Updates(map[string]interface{}{
+			"last_used_at": now,
+			"last_used_ip": ip,
+		})
+	}
+	return &token, nil
+}
+
+// Allows reports whether the token grants every required scope
+func Allows(token *models.PersonalAccessToken, required ...string) bool {
+	granted := make(map[string]bool)
+	for _, scope := range token.ScopeList() {
+		granted[scope] = true
+		if strings.HasSuffix(scope, ":write") {
+			granted[strings.TrimSuffix(scope, ":write")+":read"] = true
+		}
+	}
+	for _, scope := range required {
+		if !granted[scope] {
+			return false
+		}
+	}
+	return true
+}
+
+func isKnownScope(scope string) bool {
+	for _, known := range AllScopes {
+		if scope == known {
+			return true
+		}
+	}
+	return false
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
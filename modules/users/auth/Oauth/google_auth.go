@@ -1 +1,18 @@
-package auth
+package oauth
+
+const googleIssuer = "https://accounts.google.com"
+
+// NewGoogleProvider configures Google sign-in. issuerURL may point at a local
+// fake OIDC server during development; it defaults to Google's issuer.
+func NewGoogleProvider(clientID, clientSecret, redirectURL, issuerURL string) *OIDCProvider {
+	if issuerURL == "" {
+		issuerURL = googleIssuer
+	}
+
+	provider := NewOIDCProvider("google", issuerURL, clientID, clientSecret, redirectURL)
+	if issuerURL == googleIssuer {
+		// Google also issues ID tokens with the scheme-less issuer
+		provider.AcceptIssuer("accounts.google.com")
+	}
+	return provider
+}
@@ -0,0 +1,325 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// discoveryDocument holds the parts of the OpenID configuration we use
+type discoveryDocument struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type jsonWebKey struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Alg string `json:"alg"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// idTokenClaims are the ID token claims we rely on
+type idTokenClaims struct {
+	Email         string      `json:"email"`
+	EmailVerified interface{} `json:"email_verified"`
+	Name          string      `json:"name"`
+	Picture       string      `json:"picture"`
+	Nonce         string      `json:"nonce"`
+	jwt.RegisteredClaims
+}
+
+// OIDCProvider signs users in with any OpenID Connect provider using the
+// authorization code flow with PKCE, verifying the ID token against the
+// provider's published keys.
+type OIDCProvider struct {
+	name         string
+	issuerURL    string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+	scopes       []string
+	httpClient   *http.Client
+
+	mu              sync.Mutex
+	discovery       *discoveryDocument
+	keys            map[string]*rsa.PublicKey
+	keysFetchedAt   time.Time
+	acceptedIssuers []string
+}
+
+// NewOIDCProvider creates a provider that discovers its endpoints from issuerURL
+func NewOIDCProvider(name, issuerURL, clientID, clientSecret, redirectURL string) *OIDCProvider {
+	return &OIDCProvider{
+		name:         name,
+		issuerURL:    strings.TrimRight(issuerURL, "/"),
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		redirectURL:  redirectURL,
+		scopes:       []string{"openid", "email", "profile"},
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AcceptIssuer allows an additional iss value on ID tokens
+func (p *OIDCProvider) AcceptIssuer(issuer string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.acceptedIssuers = append(p.acceptedIssuers, issuer)
+}
+
+// Name returns the provider identifier
+func (p *OIDCProvider) Name() string {
+	return p.name
+}
+
+// AuthCodeURL builds the authorization URL including state, nonce and the PKCE challenge
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.clientID},
+		"redirect_uri":          {p.redirectURL},
+		"scope":                 {strings.Join(p.scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+
+	separator := "?"
+	if strings.Contains(doc.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return doc.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange redeems the authorization code and verifies the returned ID token
+func (p *OIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	doc, err := p.getDiscovery(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.redirectURL},
+		"client_id":     {p.clientID},
+		"client_secret": {p.clientSecret},
+		"code_verifier": {codeVerifier},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, doc.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var tokenResponse struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokenResponse.IDToken == "" {
+		return nil, errors.New("token response did not include an id_token")
+	}
+
+	claims, err := p.verifyIDToken(ctx, doc, tokenResponse.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Nonce != nonce {
+		return nil, errors.New("id_token nonce does not match")
+	}
+
+	return &Identity{
+		Provider:      p.name,
+		Subject:       claims.Subject,
+		Email:         strings.ToLower(strings.TrimSpace(claims.Email)),
+		EmailVerified: isTrue(claims.EmailVerified),
+		Name:          claims.Name,
+		Picture:       claims.Picture,
+	}, nil
+}
+
+// verifyIDToken checks the signature, issuer, audience and expiry of an ID token
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, doc *discoveryDocument, rawToken string) (*idTokenClaims, error) {
+	claims := &idTokenClaims{}
+	token, err := jwt.ParseWithClaims(rawToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.getKey(ctx, doc, kid)
+	})
+	if err != nil || !token.Valid {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if !p.issuerAccepted(doc, claims.Issuer) {
+		return nil, errors.New("id_token issuer mismatch")
+	}
+	if !claims.VerifyAudience(p.clientID, true) {
+		return nil, errors.New("id_token audience mismatch")
+	}
+	if claims.Subject == "" {
+		return nil, errors.New("id_token has no subject")
+	}
+	return claims, nil
+}
+
+func (p *OIDCProvider) issuerAccepted(doc *discoveryDocument, issuer string) bool {
+	if issuer == doc.Issuer {
+		return true
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, accepted := range p.acceptedIssuers {
+		if issuer == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *OIDCProvider) getDiscovery(ctx context.Context) (*discoveryDocument, error) {
+	p.mu.Lock()
+	if p.discovery != nil {
+		doc := p.discovery
+		p.mu.Unlock()
+		return doc, nil
+	}
+	p.mu.Unlock()
+
+	var doc discoveryDocument
+	if err := p.getJSON(ctx, p.issuerURL+"/.well-known/openid-configuration", &doc); err != nil {
+		return nil, fmt.Errorf("failed to load OpenID configuration: %w", err)
+	}
+	if doc.AuthorizationEndpoint == "" || doc.TokenEndpoint == "" || doc.JWKSURI == "" {
+		return nil, errors.New("incomplete OpenID configuration")
+	}
+
+	p.mu.Lock()
+	p.discovery = &doc
+	p.mu.Unlock()
+	return &doc, nil
+}
+
+// getKey returns the signing key for kid, refreshing the key set when the
+// provider has rotated keys since the last fetch
+func (p *OIDCProvider) getKey(ctx context.Context, doc *discoveryDocument, kid string) (*rsa.PublicKey, error) {
+	p.mu.Lock()
+	key, ok := p.keys[kid]
+	fresh := time.Since(p.keysFetchedAt) < time.Minute
+	p.mu.Unlock()
+	if ok {
+		return key, nil
+	}
+	if fresh {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, doc.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to load signing keys: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		publicKey, err := parseRSAKey(jwk)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = publicKey
+	}
+
+	p.mu.Lock()
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+	p.mu.Unlock()
+
+	if key, ok := keys[kid]; ok {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned status %d", endpoint, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func parseRSAKey(jwk jsonWebKey) (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+	if err != nil {
+		return nil, err
+	}
+	e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+	if err != nil {
+		return nil, err
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(n),
+		E: int(new(big.Int).SetBytes(e).Int64()),
+	}, nil
+}
+
+// isTrue accepts email_verified as either a JSON boolean or the string "true"
+func isTrue(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
@@ -0,0 +1,198 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/muga20/artsMarket/modules/users/auth/Oauth/oidctest"
+)
+
+const (
+	testClientID    = "arts-market"
+	testRedirectURL = "http://localhost:8080/api/v1/auth/oauth/google/callback"
+	testNonce       = "nonce-123"
+)
+
+// newTestProvider starts a fake provider and a client configured against it
+func newTestProvider(t *testing.T) (*oidctest.Server, *OIDCProvider) {
+	t.Helper()
+
+	httpServer := httptest.NewServer(nil)
+	t.Cleanup(httpServer.Close)
+
+	fake, err := oidctest.New(httpServer.URL, "artist@example.com")
+	if err != nil {
+		t.Fatalf("failed to create fake provider: %v", err)
+	}
+	httpServer.Config.Handler = fake.Handler()
+
+	return fake, NewOIDCProvider("google", httpServer.URL, testClientID, "secret", testRedirectURL)
+}
+
+// exchange runs a sign-in against the fake provider, letting the test
+// change the code verifier and nonce the client presents
+func exchange(t *testing.T, fake *oidctest.Server, provider *OIDCProvider, verifier, nonce string) (*Identity, error) {
+	t.Helper()
+
+	realVerifier, challenge, err := NewPKCE()
+	if err != nil {
+		t.Fatalf("failed to generate PKCE: %v", err)
+	}
+	if verifier == "" {
+		verifier = realVerifier
+	}
+	code := fake.IssueCode(testClientID, testRedirectURL, testNonce, challenge, "")
+	return provider.Exchange(context.Background(), code, verifier, nonce)
+}
+
+func TestExchangeReturnsVerifiedIdentity(t *testing.T) {
+	fake, provider := newTestProvider(t)
+
+	identity, err := exchange(t, fake, provider, "", testNonce)
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	if identity.Provider != "google" || identity.Subject != "fake-artist@example.com" ||
+		identity.Email != "artist@example.com" || !identity.EmailVerified {
+		t.Fatalf("unexpected identity %+v", identity)
+	}
+}
+
+func TestExchangeReadsEmailVerified(t *testing.T) {
+	tests := []struct {
+		name     string
+		claim    interface{}
+		verified bool
+	}{
+		{"boolean true", true, true},
+		{"string true", "true", true},
+		{"boolean false", false, false},
+		{"string false", "false", false},
+		{"missing", nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, provider := newTestProvider(t)
+			fake.EmailVerified = tt.claim
+
+			identity, err := exchange(t, fake, provider, "", testNonce)
+			if err != nil {
+				t.Fatalf("Exchange failed: %v", err)
+			}
+			if identity.EmailVerified != tt.verified {
+				t.Fatalf("EmailVerified = %v, want %v", identity.EmailVerified, tt.verified)
+			}
+		})
+	}
+}
+
+func TestExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	fake, provider := newTestProvider(t)
+
+	if _, err := exchange(t, fake, provider, "not-the-verifier", testNonce); err == nil {
+		t.Fatal("Exchange accepted a wrong PKCE verifier")
+	}
+}
+
+func TestExchangeRejectsNonceMismatch(t *testing.T) {
+	fake, provider := newTestProvider(t)
+
+	_, err := exchange(t, fake, provider, "", "another-nonce")
+	if err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Fatalf("expected a nonce error, got %v", err)
+	}
+}
+
+func TestExchangeRejectsInvalidIDTokens(t *testing.T) {
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(fake *oidctest.Server)
+		wantErr string
+	}{
+		{
+			name:    "signed with another key",
+			setup:   func(fake *oidctest.Server) { fake.SigningKey = otherKey },
+			wantErr: "invalid id_token",
+		},
+		{
+			name: "unknown key id",
+			setup: func(fake *oidctest.Server) {
+				fake.Tamper = func(_ jwt.MapClaims, header map[string]interface{}) { header["kid"] = "rotated-key" }
+			},
+			wantErr: "unknown signing key",
+		},
+		{
+			name: "wrong issuer",
+			setup: func(fake *oidctest.Server) {
+				fake.Tamper = func(claims jwt.MapClaims, _ map[string]interface{}) { claims["iss"] = "https://evil.example.com" }
+			},
+			wantErr: "issuer",
+		},
+		{
+			name: "wrong audience",
+			setup: func(fake *oidctest.Server) {
+				fake.Tamper = func(claims jwt.MapClaims, _ map[string]interface{}) { claims["aud"] = "another-client" }
+			},
+			wantErr: "audience",
+		},
+		{
+			name: "expired",
+			setup: func(fake *oidctest.Server) {
+				fake.Tamper = func(claims jwt.MapClaims, _ map[string]interface{}) { claims["exp"] = 1 }
+			},
+			wantErr: "invalid id_token",
+		},
+		{
+			name: "no subject",
+			setup: func(fake *oidctest.Server) {
+				fake.Tamper = func(claims jwt.MapClaims, _ map[string]interface{}) { delete(claims, "sub") }
+			},
+			wantErr: "subject",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, provider := newTestProvider(t)
+			tt.setup(fake)
+
+			_, err := exchange(t, fake, provider, "", testNonce)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestExchangeAcceptsAdditionalIssuer(t *testing.T) {
+	fake, provider := newTestProvider(t)
+	provider.AcceptIssuer("accounts.example.com")
+	fake.Tamper = func(claims jwt.MapClaims, _ map[string]interface{}) { claims["iss"] = "accounts.example.com" }
+
+	if _, err := exchange(t, fake, provider, "", testNonce); err != nil {
+		t.Fatalf("Exchange rejected an accepted issuer: %v", err)
+	}
+}
+
+func TestAuthCodeURLCarriesStateNonceAndChallenge(t *testing.T) {
+	_, provider := newTestProvider(t)
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", testNonce, "challenge-1")
+	if err != nil {
+		t.Fatalf("AuthCodeURL failed: %v", err)
+	}
+	for _, want := range []string{"state=state-1", "nonce=" + testNonce, "code_challenge=challenge-1", "code_challenge_method=S256"} {
+		if !strings.Contains(authURL, want) {
+			t.Errorf("authorization URL %q is missing %q", authURL, want)
+		}
+	}
+}
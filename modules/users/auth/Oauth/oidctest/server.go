@@ -0,0 +1,214 @@
+// Package oidctest provides a minimal OpenID Connect provider for exercising
+// the social login flow, both from tests and from the local fakeoidc command.
+// Every authorization request is approved immediately.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// KeyID identifies the signing key published in the key set
+const KeyID = "fake-oidc-key"
+
+type authorization struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	email         string
+}
+
+// Server is a fake OpenID Connect provider. Its fields may be changed
+// between requests to make it misbehave.
+type Server struct {
+	Issuer string
+	// Email is used when the authorization request has no login_hint
+	Email string
+	// EmailVerified is sent as the email_verified claim
+	EmailVerified interface{}
+	// SigningKey signs ID tokens; PublishedKey is the key in the key set.
+	// They are the same key unless a test swaps one.
+	SigningKey   *rsa.PrivateKey
+	PublishedKey *rsa.PrivateKey
+	// Tamper edits the ID token claims and header before signing
+	Tamper func(claims jwt.MapClaims, header map[string]interface{})
+
+	mu    sync.Mutex
+	codes map[string]authorization
+}
+
+// New creates a provider for issuer with a fresh signing key
+func New(issuer, email string) (*Server, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		Issuer:        issuer,
+		Email:         email,
+		EmailVerified: true,
+		SigningKey:    key,
+		PublishedKey:  key,
+		codes:         make(map[string]authorization),
+	}, nil
+}
+
+// Handler serves the discovery, authorization, token and key set endpoints
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", s.discovery)
+	mux.HandleFunc("/authorize", s.authorize)
+	mux.HandleFunc("/token", s.token)
+	mux.HandleFunc("/jwks", s.jwks)
+	return mux
+}
+
+// IssueCode approves an authorization request without the browser redirect
+// and returns its code
+func (s *Server) IssueCode(clientID, redirectURI, nonce, codeChallenge, email string) string {
+	if email == "" {
+		email = s.Email
+	}
+
+	code := randomString()
+	s.mu.Lock()
+	s.codes[code] = authorization{
+		clientID:      clientID,
+		redirectURI:   redirectURI,
+		nonce:         nonce,
+		codeChallenge: codeChallenge,
+		email:         email,
+	}
+	s.mu.Unlock()
+	return code
+}
+
+func (s *Server) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                s.Issuer,
+		"authorization_endpoint":                s.Issuer + "/authorize",
+		"token_endpoint":                        s.Issuer + "/token",
+		"jwks_uri":                              s.Issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// authorize approves every request and redirects straight back with a code
+func (s *Server) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI := query.Get("redirect_uri")
+	if redirectURI == "" || query.Get("code_challenge_method") != "S256" {
+		http.Error(w, "redirect_uri and an S256 code_challenge are required", http.StatusBadRequest)
+		return
+	}
+
+	target, err := url.Parse(redirectURI)
+	if err != nil {
+		http.Error(w, "invalid redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	code := s.IssueCode(query.Get("client_id"), redirectURI, query.Get("nonce"),
+		query.Get("code_challenge"), query.Get("login_hint"))
+
+	params := target.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	target.RawQuery = params.Encode()
+
+	http.Redirect(w, r, target.String(), http.StatusFound)
+}
+
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	code := r.PostForm.Get("code")
+	s.mu.Lock()
+	auth, ok := s.codes[code]
+	delete(s.codes, code)
+	s.mu.Unlock()
+	if !ok || auth.redirectURI != r.PostForm.Get("redirect_uri") || auth.clientID != r.PostForm.Get("client_id") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.codeChallenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant", "error_description": "PKCE verification failed"})
+		return
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            s.Issuer,
+		"aud":            auth.clientID,
+		"sub":            "fake-" + auth.email,
+		"email":          auth.email,
+		"email_verified": s.EmailVerified,
+		"name":           auth.email,
+		"nonce":          auth.nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = KeyID
+	if s.Tamper != nil {
+		s.Tamper(claims, token.Header)
+	}
+
+	idToken, err := token.SignedString(s.SigningKey)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomString(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+func (s *Server) jwks(w http.ResponseWriter, r *http.Request) {
+	publicKey := s.PublishedKey.PublicKey
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kid": KeyID,
+			"kty": "RSA",
+			"alg": "RS256",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+func randomString() string {
+	buf := make([]byte, 24)
+	rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,54 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Identity is what a provider vouches for after a successful sign-in
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+	Picture       string
+}
+
+// Provider is implemented by every external identity provider we can sign in with
+type Provider interface {
+	// Name is the identifier used in routes and stored on linked identities
+	Name() string
+
+	// AuthCodeURL builds the authorization URL the user is sent to
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+
+	// Exchange trades the authorization code for a verified identity
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error)
+}
+
+var ErrUnknownProvider = errors.New("unknown identity provider")
+
+var (
+	registry   = make(map[string]Provider)
+	registryMu sync.RWMutex
+)
+
+// Register makes a provider available by name
+func Register(provider Provider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[provider.Name()] = provider
+}
+
+// Get returns the provider registered under name
+func Get(name string) (Provider, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	provider, ok := registry[name]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tasks"
+	"github.com/muga20/artsMarket/pkg/utils"
+	"github.com/muga20/artsMarket/pkg/validation"
+	"gorm.io/gorm"
+)
+
+// emailVerificationTTL is how long a verification link stays valid
+const emailVerificationTTL = 24 * time.Hour
+
+// ResendVerificationRequest represents the expected resend verification payload
+type ResendVerificationRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+// ResendVerificationHandler issues a fresh verification link for an unverified account
+// @Summary Resend email verification
+// @Description Generate a new verification token and email it to an account that has not been verified yet
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param request body ResendVerificationRequest true "Resend verification payload"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /auth/resend-verification [post]
+func ResendVerificationHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req ResendVerificationRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request payload"))
+		}
+
+		req.Email = strings.TrimSpace(req.Email)
+		if !validation.IsValidEmail(req.Email) {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid email format"))
+		}
+
+		// Rate-limiting, keyed separately from password reset requests
+		if utils.IsRateLimited("verify:" + req.Email) {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusTooManyRequests, "Too many verification requests. Please try again later"))
+		}
+
+		// Security: Always return the same message whether the account exists or not
+		genericResponse := fiber.Map{
+			"message": "If an unverified account exists with this email, a verification link has been sent",
+		}
+
+		var user models.User
+		if err := db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, genericResponse, nil)
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve user: %w", err))
+		}
+
+		verificationToken := uuid.New().String()
+		expiration := time.Now().Add(emailVerificationTTL)
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var userSecurity models.UserSecurity
+			if err := tx.Where("user_id = ?", user.ID).First(&userSecurity).Error; err != nil {
+				return fmt.Errorf("failed to retrieve user security information: %w", err)
+			}
+
+			if userSecurity.IsEmailVerifiedAt != nil {
+				return nil
+			}
+
+			userSecurity.EmailVerificationToken = &verificationToken
+			userSecurity.EmailVerificationExpiresAt = &expiration
+			if err := tx.Save(&userSecurity).Error; err != nil {
+				return fmt.Errorf("failed to update verification token: %w", err)
+			}
+
+			purpose := tasks.VerificationPurposeEmailChange
+			if user.Status == models.UserStatusPendingVerification {
+				purpose = tasks.VerificationPurposeSignup
+			}
+
+			_, err := services.WriteOutbox(tx, tasks.TypeSendEmailVerification, tasks.EmailVerificationPayload{
+				Email:   user.Email,
+				Token:   verificationToken,
+				Purpose: purpose,
+			})
+			return err
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, genericResponse, nil)
+	}
+}
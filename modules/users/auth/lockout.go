@@ -0,0 +1,188 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tasks"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	// maxFailedLoginAttempts failures in a row lock the account; every further
+	// run of failures locks it again for twice as long
+	maxFailedLoginAttempts = 5
+	baseLockoutDuration    = 15 * time.Minute
+	maxLockoutDuration     = 24 * time.Hour
+
+	// unlockTokenTTL is how long the emailed unlock link stays valid
+	unlockTokenTTL = 24 * time.Hour
+
+	securityAlertNotification = "security_alert"
+)
+
+// UnlockAccountRequest represents the expected unlock payload
+type UnlockAccountRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// lockoutDuration returns how long the account stays locked after the given
+// number of consecutive failed attempts
+func lockoutDuration(attempts int) time.Duration {
+	level := attempts / maxFailedLoginAttempts
+	if level < 1 {
+		return 0
+	}
+
+	duration := baseLockoutDuration
+	for i := 1; i < level; i++ {
+		duration *= 2
+		if duration >= maxLockoutDuration {
+			return maxLockoutDuration
+		}
+	}
+	return duration
+}
+
+// lockedUntil reports whether the account is currently locked and until when
+func lockedUntil(userSecurity *models.UserSecurity) (time.Time, bool) {
+	if !userSecurity.IsLocked || userSecurity.LockedAt == nil {
+		return time.Time{}, false
+	}
+	until := userSecurity.LockedAt.Add(lockoutDuration(userSecurity.LoginAttempts))
+	return until, time.Now().Before(until)
+}
+
+// accountLockedError is returned while a lock is in force
+func accountLockedError(c *fiber.Ctx, until time.Time) error {
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(time.Until(until).Seconds())+1))
+	return fiber.NewError(fiber.StatusLocked,
+		"Account is temporarily locked after too many failed attempts. Try again later or use the unlock link sent to your email")
+}
+
+// registerFailedLogin counts a failed attempt against the account and locks it
+// each time another run of failures is reached, emailing an unlock link and
+// notifying the user
+func registerFailedLogin(db *gorm.DB, user models.User) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var userSecurity models.UserSecurity
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", user.ID).First(&userSecurity).Error; err != nil {
+			return fmt.Errorf("failed to retrieve user security information: %w", err)
+		}
+
+		now := time.Now()
+		attempts := userSecurity.LoginAttempts + 1
+		updates := map[string]interface{}{
+			"login_attempts":        attempts,
+			"last_login_attempt_at": now,
+		}
+
+		if attempts%maxFailedLoginAttempts != 0 {
+			return tx.Model(&userSecurity).Updates(updates).Error
+		}
+
+		unlockToken := uuid.New().String()
+		unlockHash := hashUnlockToken(unlockToken)
+		until := now.Add(lockoutDuration(attempts))
+
+		updates["is_locked"] = true
+		updates["locked_at"] = now
+		updates["unlock_token"] = unlockHash
+		updates["unlock_token_expires_at"] = now.Add(unlockTokenTTL)
+		if err := tx.Model(&userSecurity).Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to lock account: %w", err)
+		}
+
+		if _, err := services.WriteOutbox(tx, tasks.TypeSendAccountUnlock, tasks.AccountUnlockPayload{
+			Email:       user.Email,
+			Token:       unlockToken,
+			LockedUntil: until.UTC().Format(time.RFC1123),
+		}); err != nil {
+			return err
+		}
+
+		message := fmt.Sprintf("Your account was locked until %s after %d failed sign-in attempts. If this wasn't you, reset your password.",
+			until.UTC().Format(time.RFC1123), attempts)
+		return services.WriteNotification(tx, user.ID, securityAlertNotification, message, "user", user.ID)
+	})
+}
+
+// recordSuccessfulLogin clears the lockout state once the user has fully signed in
+func recordSuccessfulLogin(db *gorm.DB, userID uuid.UUID) error {
+	updates := clearedLockout()
+	updates["last_successful_login_at"] = time.Now()
+	if err := db.Model(&models.UserSecurity{}).Where("user_id = ?", userID).Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to record login: %w", err)
+	}
+	return nil
+}
+
+// UnlockAccountHandler redeems the unlock link emailed when an account was locked
+// @Summary Unlock a locked account
+// @Description Clears an account lockout using the token from the unlock email
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param request body UnlockAccountRequest true "Unlock token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Router /auth/unlock [post]
+func UnlockAccountHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req UnlockAccountRequest
+		if err := c.BodyParser(&req); err != nil || req.Token == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Unlock token is required"))
+		}
+
+		var userSecurity models.UserSecurity
+		if err := db.Where("unlock_token = ?", hashUnlockToken(req.Token)).First(&userSecurity).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid or expired unlock token"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve user security information: %w", err))
+		}
+
+		if userSecurity.UnlockTokenExpiresAt == nil || userSecurity.UnlockTokenExpiresAt.Before(time.Now()) {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid or expired unlock token"))
+		}
+
+		if err := db.Model(&userSecurity).Updates(clearedLockout()).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to unlock account: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Your account has been unlocked. You can now sign in",
+		}, nil)
+	}
+}
+
+func clearedLockout() map[string]interface{} {
+	return map[string]interface{}{
+		"login_attempts":          0,
+		"is_locked":               false,
+		"locked_at":               nil,
+		"unlock_token":            nil,
+		"unlock_token_expires_at": nil,
+	}
+}
+
+func hashUnlockToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -93,12 +93,27 @@ func ResetPasswordHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler
 		userSecurity.PasswordResetExpiresAt = nil
 		userSecurity.UpdatedAt = time.Now()
 
+		// Proving ownership of the email also lifts any lockout
+		userSecurity.LoginAttempts = 0
+		userSecurity.IsLocked = false
+		userSecurity.LockedAt = nil
+		userSecurity.UnlockToken = nil
+		userSecurity.UnlockTokenExpiresAt = nil
+
 		if err := tx.Save(&userSecurity).Error; err != nil {
 			tx.Rollback()
 			return responseHandler.HandleResponse(c, nil,
 				fmt.Errorf("failed to update password: %w", err))
 		}
 
+		// Accounts created through a social login can now sign in with a password too
+		if err := tx.Model(&models.User{}).Where("id = ?", userSecurity.UserID).
+			Update("auth_type", "email").Error; err != nil {
+			tx.Rollback()
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to update auth type: %w", err))
+		}
+
 		// Commit transaction
 		if err := tx.Commit().Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
@@ -0,0 +1,560 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/modules/notifications/services"
+	oauth "github.com/muga20/artsMarket/modules/users/auth/Oauth"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/sessions"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tasks"
+	"github.com/muga20/artsMarket/pkg/tokens"
+	"gorm.io/gorm"
+)
+
+const (
+	oauthFlowCookie = "oauth_flow"
+	oauthFlowTTL    = 10 * time.Minute
+
+	oauthModeLogin = "login"
+	oauthModeLink  = "link"
+)
+
+// oauthFlowClaims carries the state, nonce and PKCE verifier of an in-flight
+// authorization request in a signed, short-lived cookie
+type oauthFlowClaims struct {
+	Provider     string `json:"provider"`
+	State        string `json:"state"`
+	Nonce        string `json:"nonce"`
+	CodeVerifier string `json:"code_verifier"`
+	Mode         string `json:"mode"`
+	tokens.RegisteredClaims
+}
+
+// RegisterOAuthProviders registers every identity provider that has credentials configured
+func RegisterOAuthProviders() {
+	if config.Envs.GoogleClientID != "" {
+		oauth.Register(oauth.NewGoogleProvider(
+			config.Envs.GoogleClientID,
+			config.Envs.GoogleClientSecret,
+			config.Envs.GoogleRedirectURL,
+			config.Envs.GoogleIssuerURL,
+		))
+	}
+}
+
+// OAuthStartHandler begins a sign-in with an external identity provider
+// @Summary Start social login
+// @Description Returns the provider authorization URL and stores the state, nonce and PKCE verifier in a short-lived cookie
+// @Tags Auth
+// @Produce  json
+// @Param provider path string true "Identity provider (e.g. google)"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /auth/oauth/{provider} [get]
+func OAuthStartHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		authURL, err := beginOAuthFlow(c, c.Params("provider"), oauthModeLogin, "")
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"authorization_url": authURL,
+		}, nil)
+	}
+}
+
+// LinkIdentityHandler begins linking an external identity to the authenticated account
+// @Summary Link a social login
+// @Description Returns the provider authorization URL; completing it links the identity to the current account
+// @Tags Account
+// @Produce  json
+// @Param provider path string true "Identity provider (e.g. google)"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /account/identities/{provider}/link [post]
+func LinkIdentityHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		authURL, err := beginOAuthFlow(c, c.Params("provider"), oauthModeLink, user.ID.String())
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"authorization_url": authURL,
+		}, nil)
+	}
+}
+
+// OAuthCallbackHandler completes a sign-in or link started by OAuthStartHandler or LinkIdentityHandler
+// @Summary Complete social login
+// @Description Verifies state, exchanges the code with PKCE, verifies the ID token, then signs in, links or creates the account
+// @Tags Auth
+// @Produce  json
+// @Param provider path string true "Identity provider (e.g. google)"
+// @Param code query string true "Authorization code"
+// @Param state query string true "State returned by the provider"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /auth/oauth/{provider}/callback [get]
+func OAuthCallbackHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		providerName := c.Params("provider")
+		provider, err := oauth.Get(providerName)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Unsupported identity provider"))
+		}
+
+		if providerError := c.Query("error"); providerError != "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Sign-in was cancelled or denied"))
+		}
+
+		flow, err := readOAuthFlow(c)
+		clearOAuthFlowCookie(c)
+		if err != nil || flow.Provider != providerName || flow.State == "" || flow.State != c.Query("state") {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid or expired sign-in request"))
+		}
+
+		code := c.Query("code")
+		if code == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Authorization code is required"))
+		}
+
+		identity, err := provider.Exchange(c.Context(), code, flow.CodeVerifier, flow.Nonce)
+		if err != nil {
+			log.Printf("OAuth exchange with %s failed: %v", providerName, err)
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Could not verify your identity with the provider"))
+		}
+
+		if flow.Mode == oauthModeLink {
+			userID, err := uuid.Parse(flow.Subject)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid or expired sign-in request"))
+			}
+			if err := linkIdentity(db, userID, identity); err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+			return responseHandler.HandleResponse(c, fiber.Map{
+				"message": fmt.Sprintf("Your %s account has been linked", providerName),
+			}, nil)
+		}
+
+		user, err := resolveOAuthUser(db, identity)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		result, err := completeLogin(c, db, user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, result, nil)
+	}
+}
+
+// ListIdentitiesHandler lists the external identities linked to the account
+// @Summary List linked social logins
+// @Description Lists the identity providers linked to the authenticated account
+// @Tags Account
+// @Produce  json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /account/identities [get]
+func ListIdentitiesHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var identities []models.UserIdentity
+		if err := db.Where("user_id = ?", user.ID).Order("created_at ASC").Find(&identities).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve linked identities: %w", err))
+		}
+
+		var security models.UserSecurity
+		if err := db.Where("user_id = ?", user.ID).First(&security).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve user security data: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"identities":   identities,
+			"has_password": security.HasPassword(),
+		}, nil)
+	}
+}
+
+// UnlinkIdentityHandler removes a linked external identity
+// @Summary Unlink a social login
+// @Description Removes a linked identity provider; the last remaining way to sign in cannot be removed
+// @Tags Account
+// @Produce  json
+// @Param provider path string true "Identity provider (e.g. google)"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /account/identities/{provider} [delete]
+func UnlinkIdentityHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		providerName := c.Params("provider")
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var identity models.UserIdentity
+			if err := tx.Where("user_id = ? AND provider = ?", user.ID, providerName).First(&identity).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fiber.NewError(fiber.StatusNotFound, "Identity provider is not linked")
+				}
+				return fmt.Errorf("failed to retrieve identity: %w", err)
+			}
+
+			// Keep at least one way to sign in
+			var otherIdentities int64
+			if err := tx.Model(&models.UserIdentity{}).
+				Where("user_id = ? AND id != ?", user.ID, identity.ID).
+				Count(&otherIdentities).Error; err != nil {
+				return fmt.Errorf("failed to count identities: %w", err)
+			}
+			var security models.UserSecurity
+			if err := tx.Where("user_id = ?", user.ID).First(&security).Error; err != nil {
+				return fmt.Errorf("failed to retrieve user security data: %w", err)
+			}
+			if !security.HasPassword() && otherIdentities == 0 {
+				return fiber.NewError(fiber.StatusConflict,
+					"Set a password before unlinking your only sign-in method")
+			}
+
+			if err := tx.Delete(&identity).Error; err != nil {
+				return fmt.Errorf("failed to unlink identity: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": fmt.Sprintf("Your %s account has been unlinked", providerName),
+		}, nil)
+	}
+}
+
+// beginOAuthFlow generates state, nonce and PKCE values, stores them in the
+// flow cookie and returns the provider authorization URL
+func beginOAuthFlow(c *fiber.Ctx, providerName, mode, userID string) (string, error) {
+	provider, err := oauth.Get(providerName)
+	if err != nil {
+		return "", fiber.NewError(fiber.StatusNotFound, "Unsupported identity provider")
+	}
+
+	state, err := oauth.RandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	nonce, err := oauth.RandomToken(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	verifier, challenge, err := oauth.NewPKCE()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate PKCE verifier: %w", err)
+	}
+
+	authURL, err := provider.AuthCodeURL(c.Context(), state, nonce, challenge)
+	if err != nil {
+		return "", fmt.Errorf("failed to build authorization URL: %w", err)
+	}
+
+	expiresAt := time.Now().Add(oauthFlowTTL)
+	claims := &oauthFlowClaims{
+		Provider:     providerName,
+		State:        state,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		Mode:         mode,
+	}
+	claims.Subject = userID
+	signed, err := tokens.Issue(claims, tokens.AudienceOAuthFlow, oauthFlowTTL)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign sign-in request: %w", err)
+	}
+
+	// Lax so the cookie survives the top-level redirect back from the provider
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthFlowCookie,
+		Value:    signed,
+		Expires:  expiresAt,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return authURL, nil
+}
+
+func readOAuthFlow(c *fiber.Ctx) (*oauthFlowClaims, error) {
+	raw := c.Cookies(oauthFlowCookie)
+	if raw == "" {
+		return nil, errors.New("missing sign-in request")
+	}
+
+	claims := &oauthFlowClaims{}
+	if err := tokens.Verify(raw, tokens.AudienceOAuthFlow, claims); err != nil {
+		return nil, errors.New("invalid sign-in request")
+	}
+	return claims, nil
+}
+
+func clearOAuthFlowCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthFlowCookie,
+		Value:    "",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+}
+
+// linkIdentity attaches an identity to an existing account
+func linkIdentity(db *gorm.DB, userID uuid.UUID, identity *oauth.Identity) error {
+	var existing models.UserIdentity
+	err := db.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&existing).Error
+	if err == nil {
+		if existing.UserID == userID {
+			return nil
+		}
+		return fiber.NewError(fiber.StatusConflict, "This account is already linked to another user")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return fmt.Errorf("failed to check linked identity: %w", err)
+	}
+
+	var count int64
+	if err := db.Model(&models.UserIdentity{}).
+		Where("user_id = ? AND provider = ?", userID, identity.Provider).
+		Count(&count).Error; err != nil {
+		return fmt.Errorf("failed to check linked identity: %w", err)
+	}
+	if count > 0 {
+		return fiber.NewError(fiber.StatusConflict, "A different account from this provider is already linked")
+	}
+
+	link := models.UserIdentity{
+		UserID:   userID,
+		Provider: identity.Provider,
+		Subject:  identity.Subject,
+		Email:    identity.Email,
+	}
+	if err := db.Create(&link).Error; err != nil {
+		return fmt.Errorf("failed to link identity: %w", err)
+	}
+	return nil
+}
+
+// resolveOAuthUser finds the account for an identity, linking by verified
+// email or creating a new account when none exists
+func resolveOAuthUser(db *gorm.DB, identity *oauth.Identity) (*models.User, error) {
+	var user models.User
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Returning user
+		var link models.UserIdentity
+		err := tx.Where("provider = ? AND subject = ?", identity.Provider, identity.Subject).First(&link).Error
+		if err == nil {
+			if err := tx.Where("id = ?", link.UserID).First(&user).Error; err != nil {
+				return fmt.Errorf("failed to retrieve user: %w", err)
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to retrieve linked identity: %w", err)
+		}
+
+		if identity.Email == "" {
+			return fiber.NewError(fiber.StatusBadRequest, "The provider did not share an email address")
+		}
+
+		now := time.Now()
+		err = tx.Where("email = ?", identity.Email).First(&user).Error
+		switch {
+		case err == nil:
+			// Only link automatically when the provider has verified the address
+			if !identity.EmailVerified {
+				return fiber.NewError(fiber.StatusConflict,
+					"An account with this email already exists. Sign in with your password and link this provider from your account settings")
+			}
+			var security models.UserSecurity
+			if err := tx.Where("user_id = ?", user.ID).First(&security).Error; err != nil {
+				return fmt.Errorf("failed to retrieve user security data: %w", err)
+			}
+			// Whoever registered an unverified address may not be its owner,
+			// so the provider's owner takes the account over from them
+			if security.IsEmailVerifiedAt == nil {
+				if err := reclaimUnverifiedAccount(tx, &user, identity.Provider, now); err != nil {
+					return err
+				}
+			}
+			if user.Status == models.UserStatusPendingVerification {
+				user.Status = models.UserStatusActive
+				if err := tx.Model(&user).Update("status", user.Status).Error; err != nil {
+					return fmt.Errorf("failed to activate account: %w", err)
+				}
+			}
+
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			if err := createOAuthUser(tx, identity, &user); err != nil {
+				return err
+			}
+
+		default:
+			return fmt.Errorf("failed to retrieve user: %w", err)
+		}
+
+		return tx.Create(&models.UserIdentity{
+			UserID:   user.ID,
+			Provider: identity.Provider,
+			Subject:  identity.Subject,
+			Email:    identity.Email,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// reclaimUnverifiedAccount hands an account whose email was never verified
+// to the owner of that address as proven by the provider. The password,
+// second factor, tokens, sessions and other identities set up before the
+// address was verified are dropped so the account cannot be pre-hijacked.
+func reclaimUnverifiedAccount(tx *gorm.DB, user *models.User, provider string, now time.Time) error {
+	if err := tx.Model(&models.UserSecurity{}).
+		Where("user_id = ?", user.ID).
+		Updates(map[string]interface{}{
+			"password":                      "",
+			"is_email_verified_at":          now,
+			"email_verification_token":      nil,
+			"email_verification_expires_at": nil,
+			"password_reset_token":          nil,
+			"password_reset_expires_at":     nil,
+			"two_factor_enabled":            false,
+			"two_factor_secret":             nil,
+			"two_factor_enabled_at":         nil,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to reset account credentials: %w", err)
+	}
+
+	for _, model := range []interface{}{
+		&models.UserRecoveryCode{},
+		&models.PersonalAccessToken{},
+		&models.UserIdentity{},
+	} {
+		if err := tx.Where("user_id = ?", user.ID).Delete(model).Error; err != nil {
+			return fmt.Errorf("failed to reset account credentials: %w", err)
+		}
+	}
+	if _, err := sessions.RevokeAllForUser(tx, user.ID, uuid.Nil); err != nil {
+		return err
+	}
+
+	user.AuthType = provider
+	if err := tx.Model(user).Update("auth_type", user.AuthType).Error; err != nil {
+		return fmt.Errorf("failed to update sign-in method: %w", err)
+	}
+	return nil
+}
+
+// createOAuthUser registers a new account for a first-time social login
+func createOAuthUser(tx *gorm.DB, identity *oauth.Identity, user *models.User) error {
+	username, err := generateUniqueUsername(tx, identity.Email)
+	if err != nil {
+		return fmt.Errorf("failed to generate unique username: %w", err)
+	}
+
+	status := models.UserStatusPendingVerification
+	var verifiedAt *time.Time
+	if identity.EmailVerified {
+		now := time.Now()
+		status = models.UserStatusActive
+		verifiedAt = &now
+	}
+
+	*user = models.User{
+		ID:        uuid.New(),
+		Email:     identity.Email,
+		Username:  username,
+		Status:    status,
+		AuthType:  identity.Provider,
+		IsActive:  true,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := tx.Create(user).Error; err != nil {
+		return fmt.Errorf("failed to create user: %w", err)
+	}
+
+	userSecurity := models.UserSecurity{
+		ID:                uuid.New(),
+		UserID:            user.ID,
+		Password:          "", // Social accounts have no password until the user sets one
+		IsEmailVerifiedAt: verifiedAt,
+	}
+	// Addresses the provider has not verified are confirmed like an email signup
+	if verifiedAt == nil {
+		verificationToken := uuid.New().String()
+		verificationExpiry := time.Now().Add(emailVerificationTTL)
+		userSecurity.EmailVerificationToken = &verificationToken
+		userSecurity.EmailVerificationExpiresAt = &verificationExpiry
+	}
+	if err := tx.Create(&userSecurity).Error; err != nil {
+		return fmt.Errorf("failed to store user security data: %w", err)
+	}
+
+	if err := assignUserRole(tx, user.ID); err != nil {
+		return fmt.Errorf("failed to assign user role: %w", err)
+	}
+
+	// Queue the verification email with the account itself
+	if userSecurity.EmailVerificationToken != nil {
+		if _, err := services.WriteOutbox(tx, tasks.TypeSendEmailVerification, tasks.EmailVerificationPayload{
+			Email:   user.Email,
+			Token:   *userSecurity.EmailVerificationToken,
+			Purpose: tasks.VerificationPurposeSignup,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,561 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	oauth "github.com/muga20/artsMarket/modules/users/auth/Oauth"
+	"github.com/muga20/artsMarket/modules/users/auth/Oauth/oidctest"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tokens"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+const (
+	testClientID    = "arts-market"
+	testRedirectURL = "http://localhost:8080/api/v1/auth/oauth/google/callback"
+	testState       = "state-123"
+	testNonce       = "nonce-123"
+)
+
+func TestMain(m *testing.M) {
+	config.Envs.JWTKeyID = "test"
+	config.Envs.JWTAlgorithm = tokens.AlgorithmHS256
+	config.Envs.JWTSecret = strings.Repeat("s", 32)
+	os.Exit(m.Run())
+}
+
+// fakeDB is a database/sql driver that answers every SELECT with the rows
+// seeded for its table, ignoring the WHERE clause, and records every
+// statement. Each test seeds only the rows its scenario should find.
+// COUNT queries return the number of seeded rows unless counts overrides it.
+type fakeDB struct {
+	mu         sync.Mutex
+	tables     map[string][]map[string]driver.Value
+	counts     map[string]int64
+	statements []string
+}
+
+var tableName = regexp.MustCompile("FROM `(\\w+)`")
+
+func newFakeDB(t *testing.T) (*fakeDB, *gorm.DB) {
+	t.Helper()
+
+	fake := &fakeDB{tables: make(map[string][]map[string]driver.Value), counts: make(map[string]int64)}
+	db, err := gorm.Open(mysql.New(mysql.Config{
+		Conn:                      sql.OpenDB(fake),
+		SkipInitializeWithVersion: true,
+	}), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatalf("failed to open fake database: %v", err)
+	}
+	return fake, db
+}
+
+func (f *fakeDB) seed(table string, row map[string]driver.Value) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.tables[table] = append(f.tables[table], row)
+}
+
+// ran reports whether a statement starting with prefix and containing every
+// fragment was executed
+func (f *fakeDB) ran(prefix string, fragments ...string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, statement := range f.statements {
+		if !strings.HasPrefix(statement, prefix) {
+			continue
+		}
+		matched := true
+		for _, fragment := range fragments {
+			if !strings.Contains(statement, fragment) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, fmt.Errorf("prepared statements are not supported: %s", query)
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return fakeTx{}, nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.statements = append(c.db.statements, query)
+	return fakeResult{}, nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.statements = append(c.db.statements, query)
+
+	var table string
+	if match := tableName.FindStringSubmatch(query); match != nil {
+		table = match[1]
+	}
+	rows := c.db.tables[table]
+
+	if strings.Contains(strings.ToLower(query), "count(") {
+		count, ok := c.db.counts[table]
+		if !ok {
+			count = int64(len(rows))
+		}
+		return &fakeRows{columns: []string{"count"}, values: [][]driver.Value{{count}}}, nil
+	}
+
+	columnSet := map[string]bool{"id": true}
+	for _, row := range rows {
+		for column := range row {
+			columnSet[column] = true
+		}
+	}
+	columns := make([]string, 0, len(columnSet))
+	for column := range columnSet {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	values := make([][]driver.Value, 0, len(rows))
+	for _, row := range rows {
+		value := make([]driver.Value, len(columns))
+		for i, column := range columns {
+			value[i] = row[column]
+		}
+		values = append(values, value)
+	}
+	return &fakeRows{columns: columns, values: values}, nil
+}
+
+// fakeResult reports one affected row for every write
+type fakeResult struct{}
+
+func (fakeResult) LastInsertId() (int64, error) { return 0, nil }
+func (fakeResult) RowsAffected() (int64, error) { return 1, nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+// seedUser adds an account with the given email verification state
+func seedUser(fake *fakeDB, email string, verified bool) uuid.UUID {
+	userID := uuid.New()
+	status := models.UserStatusActive
+	var verifiedAt driver.Value
+	if verified {
+		verifiedAt = time.Now()
+	} else {
+		status = models.UserStatusPendingVerification
+	}
+
+	fake.seed("users", map[string]driver.Value{
+		"id":        userID.String(),
+		"email":     email,
+		"username":  "artist",
+		"status":    status,
+		"auth_type": "email",
+		"is_active": true,
+	})
+	fake.seed("user_securities", map[string]driver.Value{
+		"id":                   uuid.NewString(),
+		"user_id":              userID.String(),
+		"password":             "$2a$10$known.password.hash",
+		"is_email_verified_at": verifiedAt,
+	})
+	return userID
+}
+
+// oauthTest wires a fake provider into the callback handler
+type oauthTest struct {
+	provider *oidctest.Server
+	db       *fakeDB
+	app      *fiber.App
+}
+
+func newOAuthTest(t *testing.T) *oauthTest {
+	t.Helper()
+
+	httpServer := httptest.NewServer(nil)
+	t.Cleanup(httpServer.Close)
+	provider, err := oidctest.New(httpServer.URL, "artist@example.com")
+	if err != nil {
+		t.Fatalf("failed to create fake provider: %v", err)
+	}
+	httpServer.Config.Handler = provider.Handler()
+	oauth.Register(oauth.NewOIDCProvider("google", httpServer.URL, testClientID, "secret", testRedirectURL))
+
+	fake, db := newFakeDB(t)
+	app := fiber.New()
+	app.Get("/auth/oauth/:provider/callback", OAuthCallbackHandler(db, handlers.NewResponseHandler(db)))
+
+	return &oauthTest{provider: provider, db: fake, app: app}
+}
+
+// flow is the sign-in request stored in the flow cookie
+type flow struct {
+	provider string
+	state    string
+	nonce    string
+	verifier string
+}
+
+func newFlow(t *testing.T) (flow, string) {
+	t.Helper()
+	verifier, challenge, err := oauth.NewPKCE()
+	if err != nil {
+		t.Fatalf("failed to generate PKCE: %v", err)
+	}
+	return flow{provider: "google", state: testState, nonce: testNonce, verifier: verifier}, challenge
+}
+
+// callback completes a sign-in with the given flow cookie and returned state
+func (o *oauthTest) callback(t *testing.T, f flow, code, state string) (int, map[string]interface{}) {
+	t.Helper()
+
+	cookie, err := tokens.Issue(&oauthFlowClaims{
+		Provider:     f.provider,
+		State:        f.state,
+		Nonce:        f.nonce,
+		CodeVerifier: f.verifier,
+		Mode:         oauthModeLogin,
+	}, tokens.AudienceOAuthFlow, oauthFlowTTL)
+	if err != nil {
+		t.Fatalf("failed to sign flow cookie: %v", err)
+	}
+
+	req := httptest.NewRequest(fiber.MethodGet, "/auth/oauth/google/callback?code="+code+"&state="+state, nil)
+	req.Header.Set(fiber.HeaderCookie, oauthFlowCookie+"="+cookie)
+	resp, err := o.app.Test(req, -1)
+	if err != nil {
+		t.Fatalf("callback request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return resp.StatusCode, body
+}
+
+func TestOAuthCallbackSignsInLinkedAccount(t *testing.T) {
+	o := newOAuthTest(t)
+	userID := seedUser(o.db, "artist@example.com", true)
+	o.db.seed("user_identities", map[string]driver.Value{
+		"id":       uuid.NewString(),
+		"user_id":  userID.String(),
+		"provider": "google",
+		"subject":  "fake-artist@example.com",
+	})
+
+	f, challenge := newFlow(t)
+	code := o.provider.IssueCode(testClientID, testRedirectURL, testNonce, challenge, "")
+	status, body := o.callback(t, f, code, testState)
+
+	if status != fiber.StatusOK {
+		t.Fatalf("status = %d, want 200: %v", status, body)
+	}
+	if !o.db.ran("INSERT INTO `user_sessions`") {
+		t.Fatal("no session was started")
+	}
+}
+
+func TestOAuthCallbackRejectsStateMismatch(t *testing.T) {
+	o := newOAuthTest(t)
+
+	f, challenge := newFlow(t)
+	code := o.provider.IssueCode(testClientID, testRedirectURL, testNonce, challenge, "")
+	status, _ := o.callback(t, f, code, "forged-state")
+
+	if status != fiber.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", status)
+	}
+	if o.db.ran("INSERT INTO `user_sessions`") {
+		t.Fatal("a session was started for a forged state")
+	}
+}
+
+func TestOAuthCallbackRejectsFlowForAnotherProvider(t *testing.T) {
+	o := newOAuthTest(t)
+
+	f, challenge := newFlow(t)
+	f.provider = "github"
+	code := o.provider.IssueCode(testClientID, testRedirectURL, testNonce, challenge, "")
+	status, _ := o.callback(t, f, code, testState)
+
+	if status != fiber.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", status)
+	}
+}
+
+func TestOAuthCallbackRejectsWrongCodeVerifier(t *testing.T) {
+	o := newOAuthTest(t)
+
+	f, _ := newFlow(t)
+	_, otherChallenge, err := oauth.NewPKCE()
+	if err != nil {
+		t.Fatalf("failed to generate PKCE: %v", err)
+	}
+	code := o.provider.IssueCode(testClientID, testRedirectURL, testNonce, otherChallenge, "")
+	status, _ := o.callback(t, f, code, testState)
+
+	if status != fiber.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", status)
+	}
+}
+
+func TestOAuthCallbackRejectsNonceMismatch(t *testing.T) {
+	o := newOAuthTest(t)
+
+	f, challenge := newFlow(t)
+	code := o.provider.IssueCode(testClientID, testRedirectURL, "replayed-nonce", challenge, "")
+	status, _ := o.callback(t, f, code, testState)
+
+	if status != fiber.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", status)
+	}
+}
+
+func TestOAuthCallbackRejectsForgedIDToken(t *testing.T) {
+	o := newOAuthTest(t)
+	o.provider.Tamper = func(claims jwt.MapClaims, _ map[string]interface{}) {
+		claims["aud"] = "another-client"
+	}
+
+	f, challenge := newFlow(t)
+	code := o.provider.IssueCode(testClientID, testRedirectURL, testNonce, challenge, "")
+	status, _ := o.callback(t, f, code, testState)
+
+	if status != fiber.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", status)
+	}
+}
+
+func TestOAuthCallbackRefusesToLinkUnverifiedProviderEmail(t *testing.T) {
+	o := newOAuthTest(t)
+	seedUser(o.db, "artist@example.com", true)
+	o.provider.EmailVerified = false
+
+	f, challenge := newFlow(t)
+	code := o.provider.IssueCode(testClientID, testRedirectURL, testNonce, challenge, "")
+	status, _ := o.callback(t, f, code, testState)
+
+	if status != fiber.StatusConflict {
+		t.Fatalf("status = %d, want 409", status)
+	}
+	if o.db.ran("INSERT INTO `user_identities`") {
+		t.Fatal("an unverified provider email was linked to an existing account")
+	}
+}
+
+func TestResolveOAuthUserLinksVerifiedAccount(t *testing.T) {
+	fake, db := newFakeDB(t)
+	seedUser(fake, "artist@example.com", true)
+
+	_, err := resolveOAuthUser(db, &oauth.Identity{
+		Provider:      "google",
+		Subject:       "google-1",
+		Email:         "artist@example.com",
+		EmailVerified: true,
+	})
+	if err != nil {
+		t.Fatalf("resolveOAuthUser failed: %v", err)
+	}
+	if !fake.ran("INSERT INTO `user_identities`") {
+		t.Fatal("the identity was not linked")
+	}
+	if fake.ran("UPDATE `user_securities`", "`password`=") || fake.ran("UPDATE `user_sessions`") {
+		t.Fatal("the credentials of a verified account were reset")
+	}
+}
+
+func TestResolveOAuthUserReclaimsUnverifiedAccount(t *testing.T) {
+	fake, db := newFakeDB(t)
+	seedUser(fake, "artist@example.com", false)
+
+	user, err := resolveOAuthUser(db, &oauth.Identity{
+		Provider:      "google",
+		Subject:       "google-1",
+		Email:         "artist@example.com",
+		EmailVerified: true,
+	})
+	if err != nil {
+		t.Fatalf("resolveOAuthUser failed: %v", err)
+	}
+
+	if !fake.ran("UPDATE `user_securities`", "`password`=", "`is_email_verified_at`=", "`two_factor_enabled`=") {
+		t.Error("the password set before verification was kept")
+	}
+	if !fake.ran("UPDATE `user_sessions`", "`revoked_at`=") {
+		t.Error("sessions started before verification were kept")
+	}
+	for _, table := range []string{"user_recovery_codes", "personal_access_tokens", "user_identities"} {
+		if !fake.ran("DELETE FROM `" + table + "`") {
+			t.Errorf("%s created before verification were kept", table)
+		}
+	}
+	if !fake.ran("INSERT INTO `user_identities`") {
+		t.Error("the identity was not linked")
+	}
+	if user.Status != models.UserStatusActive || user.AuthType != "google" {
+		t.Errorf("user = %+v, want an active google account", user)
+	}
+}
+
+func TestResolveOAuthUserCreatesAccountForNewEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		verified bool
+		status   string
+	}{
+		{"verified email", true, models.UserStatusActive},
+		{"unverified email", false, models.UserStatusPendingVerification},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, db := newFakeDB(t)
+
+			user, err := resolveOAuthUser(db, &oauth.Identity{
+				Provider:      "google",
+				Subject:       "google-1",
+				Email:         "new@example.com",
+				EmailVerified: tt.verified,
+			})
+			if err != nil {
+				t.Fatalf("resolveOAuthUser failed: %v", err)
+			}
+			if user.Status != tt.status {
+				t.Errorf("status = %q, want %q", user.Status, tt.status)
+			}
+			if !fake.ran("INSERT INTO `users`") || !fake.ran("INSERT INTO `user_identities`") {
+				t.Error("the account or its identity was not created")
+			}
+			if queued := fake.ran("INSERT INTO `outbox_messages`"); queued == tt.verified {
+				t.Errorf("verification email queued = %v, want %v", queued, !tt.verified)
+			}
+		})
+	}
+}
+
+// identityTest serves the identity endpoints for a signed-in social account
+// with a single linked identity
+func identityTest(t *testing.T, password string) (*fakeDB, *fiber.App) {
+	t.Helper()
+
+	fake, db := newFakeDB(t)
+	userID := uuid.New()
+	fake.seed("user_securities", map[string]driver.Value{
+		"id":       uuid.NewString(),
+		"user_id":  userID.String(),
+		"password": password,
+	})
+	fake.seed("user_identities", map[string]driver.Value{
+		"id":       uuid.NewString(),
+		"user_id":  userID.String(),
+		"provider": "google",
+		"subject":  "google-1",
+	})
+	fake.counts["user_identities"] = 0
+
+	responseHandler := handlers.NewResponseHandler(db)
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user", models.User{ID: userID, AuthType: "google"})
+		return c.Next()
+	})
+	app.Get("/account/identities", ListIdentitiesHandler(db, responseHandler))
+	app.Delete("/account/identities/:provider", UnlinkIdentityHandler(db, responseHandler))
+	return fake, app
+}
+
+func TestIdentityEndpointsReadPasswordFromStoredHash(t *testing.T) {
+	tests := []struct {
+		name         string
+		password     string
+		hasPassword  bool
+		unlinkStatus int
+	}{
+		{"no password", "", false, fiber.StatusConflict},
+		{"password set after social sign-up", "$2a$10$known.password.hash", true, fiber.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, app := identityTest(t, tt.password)
+
+			resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/account/identities", nil), -1)
+			if err != nil {
+				t.Fatalf("list request failed: %v", err)
+			}
+			var body map[string]interface{}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			resp.Body.Close()
+			if !strings.Contains(fmt.Sprint(body), fmt.Sprintf("has_password:%v", tt.hasPassword)) {
+				t.Errorf("expected has_password %v in %v", tt.hasPassword, body)
+			}
+
+			resp, err = app.Test(httptest.NewRequest(fiber.MethodDelete, "/account/identities/google", nil), -1)
+			if err != nil {
+				t.Fatalf("unlink request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.unlinkStatus {
+				t.Errorf("unlink status = %d, want %d", resp.StatusCode, tt.unlinkStatus)
+			}
+			if unlinked := fake.ran("DELETE FROM `user_identities`"); unlinked != (tt.unlinkStatus == fiber.StatusOK) {
+				t.Errorf("identity deleted = %v", unlinked)
+			}
+		})
+	}
+}
@@ -7,8 +7,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"github.com/hibiken/asynq"
-	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/modules/notifications/services"
 	"github.com/muga20/artsMarket/modules/users/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"github.com/muga20/artsMarket/pkg/tasks"
@@ -93,27 +92,21 @@ func ResetPasswordRequestHandler(db *gorm.DB, responseHandler *handlers.Response
 				fmt.Errorf("failed to update reset token: %w", err))
 		}
 
+		// Record the email task in the same transaction as the token update
+		if _, err := services.WriteOutbox(tx, tasks.TypeSendEmail, tasks.EmailTaskPayload{
+			ToEmail:    user.Email,
+			ResetToken: resetToken,
+		}); err != nil {
+			tx.Rollback()
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
 		// Commit transaction
 		if err := tx.Commit().Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
 				fmt.Errorf("failed to commit transaction: %w", err))
 		}
 
-		// Queue email task
-		task, err := tasks.NewSendEmailTask(req.Email, resetToken)
-		if err != nil {
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to create email task: %w", err))
-		}
-
-		client := asynq.NewClient(*config.RedisConfig)
-		defer client.Close()
-
-		if _, err := client.Enqueue(task); err != nil {
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to enqueue email task: %w", err))
-		}
-
 		return responseHandler.HandleResponse(c, fiber.Map{
 			"message": "If an account exists with this email, a password reset link has been sent",
 		}, nil)
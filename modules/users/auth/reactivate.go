@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/deletion"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tokens"
+	"gorm.io/gorm"
+)
+
+// reactivationTokenTTL bounds how long the user has to confirm reactivation after logging in
+const reactivationTokenTTL = 10 * time.Minute
+
+// ReactivateAccountRequest represents the expected reactivation payload
+type ReactivateAccountRequest struct {
+	ReactivationToken string `json:"reactivation_token" validate:"required"`
+}
+
+// reactivationOffer is returned instead of a session when a deactivated
+// account logs in during its deletion grace period. It is only issued once
+// every factor, including any second factor, has been checked.
+func reactivationOffer(user models.User) (fiber.Map, error) {
+	claims := &tokens.RegisteredClaims{}
+	claims.Subject = user.ID.String()
+	token, err := tokens.Issue(claims, tokens.AudienceReactivation, reactivationTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate reactivation token: %w", err)
+	}
+
+	result := fiber.Map{
+		"message":               "This account is scheduled for deletion. Reactivate it to continue",
+		"reactivation_required": true,
+		"reactivation_token":    token,
+	}
+	if user.DeletionRequestedAt != nil {
+		result["purge_at"] = deletion.PurgeAt(*user.DeletionRequestedAt)
+	}
+	return result, nil
+}
+
+// ReactivateAccountHandler cancels a pending account deletion and logs the user in
+// @Summary Reactivate an account scheduled for deletion
+// @Description Exchanges the reactivation token returned by login, or by two-factor verification, for a restored account and a session
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param request body ReactivateAccountRequest true "Reactivation token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/reactivate [post]
+func ReactivateAccountHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req ReactivateAccountRequest
+		if err := c.BodyParser(&req); err != nil || req.ReactivationToken == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request payload"))
+		}
+
+		claims := &tokens.RegisteredClaims{}
+		if err := tokens.Verify(req.ReactivationToken, tokens.AudienceReactivation, claims); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired reactivation token"))
+		}
+		userID, err := uuid.Parse(claims.Subject)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired reactivation token"))
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			return deletion.Reactivate(tx, userID)
+		})
+		if err != nil && !errors.Is(err, deletion.ErrNotScheduled) {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		// The token already proves every factor, so the session starts directly
+		result, err := finishLogin(c, db, userID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		return responseHandler.HandleResponse(c, result, nil)
+	}
+}
@@ -8,8 +8,10 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/notifications/services"
 	"github.com/muga20/artsMarket/modules/users/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tasks"
 	"github.com/muga20/artsMarket/pkg/validation"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
@@ -78,7 +80,7 @@ func SignupHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber
 			ID:        uuid.New(),
 			Email:     req.Email,
 			Username:  username,
-			Status:    "active",
+			Status:    models.UserStatusPendingVerification,
 			AuthType:  "email",
 			IsActive:  true,
 			CreatedAt: time.Now(),
@@ -99,11 +101,15 @@ func SignupHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber
 				fmt.Errorf("failed to hash password: %w", err))
 		}
 
-		// Store password
+		// Store password along with the email verification token
+		verificationToken := uuid.New().String()
+		verificationExpiry := time.Now().Add(emailVerificationTTL)
 		userSecurity := models.UserSecurity{
-			ID:       uuid.New(),
-			UserID:   newUser.ID,
-			Password: string(hashedPassword),
+			ID:                         uuid.New(),
+			UserID:                     newUser.ID,
+			Password:                   string(hashedPassword),
+			EmailVerificationToken:     &verificationToken,
+			EmailVerificationExpiresAt: &verificationExpiry,
 		}
 
 		if err := tx.Create(&userSecurity).Error; err != nil {
@@ -119,6 +125,16 @@ func SignupHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber
 				fmt.Errorf("failed to assign user role: %w", err))
 		}
 
+		// Queue the verification email with the account itself
+		if _, err := services.WriteOutbox(tx, tasks.TypeSendEmailVerification, tasks.EmailVerificationPayload{
+			Email:   newUser.Email,
+			Token:   verificationToken,
+			Purpose: tasks.VerificationPurposeSignup,
+		}); err != nil {
+			tx.Rollback()
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
 		// Commit transaction
 		if err := tx.Commit().Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
@@ -126,7 +142,7 @@ func SignupHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber
 		}
 
 		return responseHandler.HandleResponse(c, fiber.Map{
-			"message": "User created successfully",
+			"message": "User created successfully. Please check your email to verify your account.",
 		}, nil)
 	}
 }
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/sessions"
+	"github.com/muga20/artsMarket/modules/users/twofactor"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tokens"
+	"gorm.io/gorm"
+)
+
+// twoFactorChallengeTTL bounds how long a token that only proves the first factor lives
+const twoFactorChallengeTTL = 5 * time.Minute
+
+// TwoFactorVerifyRequest completes a login that requires a second factor
+type TwoFactorVerifyRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
+}
+
+// completeLogin starts a session, or returns a short-lived challenge token
+// when the account has two-factor authentication enabled. Deactivated
+// accounts get a reactivation token instead of a session, but only once the
+// second factor has been checked.
+func completeLogin(c *fiber.Ctx, db *gorm.DB, userID uuid.UUID) (fiber.Map, error) {
+	var userSecurity models.UserSecurity
+	if err := db.Where("user_id = ?", userID).First(&userSecurity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid credentials")
+		}
+		return nil, fmt.Errorf("failed to retrieve user security information: %w", err)
+	}
+
+	if userSecurity.TwoFactorEnabled {
+		challenge, err := generateTwoFactorChallenge(userID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate challenge token: %w", err)
+		}
+		return fiber.Map{
+			"message":             "Two-factor authentication required",
+			"two_factor_required": true,
+			"challenge_token":     challenge,
+		}, nil
+	}
+
+	return finishLogin(c, db, userID)
+}
+
+// finishLogin starts a session for a user who has passed every factor, or
+// offers reactivation when the account is deactivated
+func finishLogin(c *fiber.Ctx, db *gorm.DB, userID uuid.UUID) (fiber.Map, error) {
+	var user models.User
+	if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fiber.NewError(fiber.StatusUnauthorized, "Invalid credentials")
+		}
+		return nil, fmt.Errorf("failed to retrieve user: %w", err)
+	}
+	if !user.IsActive {
+		return reactivationOffer(user)
+	}
+
+	if err := sessions.Start(c, db, userID); err != nil {
+		return nil, err
+	}
+	if err := recordSuccessfulLogin(db, userID); err != nil {
+		return nil, err
+	}
+	return fiber.Map{
+		"message": "Login successful",
+	}, nil
+}
+
+// VerifyTwoFactorHandler exchanges a challenge token and second factor for a session
+// @Summary Complete two-factor login
+// @Description Verifies an authenticator or recovery code against the challenge token returned by login
+// @Tags Auth
+// @Accept  json
+// @Produce  json
+// @Param request body TwoFactorVerifyRequest true "Challenge token and code"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /auth/2fa/verify [post]
+func VerifyTwoFactorHandler(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req TwoFactorVerifyRequest
+		if err := c.BodyParser(&req); err != nil || req.ChallengeToken == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request payload"))
+		}
+
+		userID, err := parseTwoFactorChallenge(req.ChallengeToken)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired challenge token"))
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			var userSecurity models.UserSecurity
+			if err := tx.Where("user_id = ?", userID).First(&userSecurity).Error; err != nil {
+				return fmt.Errorf("failed to retrieve user security information: %w", err)
+			}
+			if !userSecurity.TwoFactorEnabled {
+				return fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired challenge token")
+			}
+			// Second-factor guesses count towards the same lockout as passwords
+			if until, locked := lockedUntil(&userSecurity); locked {
+				return accountLockedError(c, until)
+			}
+			return twofactor.Verify(tx, &userSecurity, req.Code, req.RecoveryCode)
+		})
+		if err != nil {
+			if errors.Is(err, twofactor.ErrInvalidCode) {
+				var user models.User
+				if err := db.First(&user, "id = ?", userID).Error; err != nil {
+					return responseHandler.HandleResponse(c, nil,
+						fmt.Errorf("failed to retrieve user: %w", err))
+				}
+				if err := registerFailedLogin(db, user); err != nil {
+					return responseHandler.HandleResponse(c, nil, err)
+				}
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusUnauthorized, "Invalid authentication code"))
+			}
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		result, err := finishLogin(c, db, userID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		return responseHandler.HandleResponse(c, result, nil)
+	}
+}
+
+func generateTwoFactorChallenge(userID uuid.UUID) (string, error) {
+	claims := &tokens.RegisteredClaims{}
+	claims.Subject = userID.String()
+	return tokens.Issue(claims, tokens.AudienceTwoFactorChallenge, twoFactorChallengeTTL)
+}
+
+func parseTwoFactorChallenge(tokenString string) (uuid.UUID, error) {
+	claims := &tokens.RegisteredClaims{}
+	if err := tokens.Verify(tokenString, tokens.AudienceTwoFactorChallenge, claims); err != nil {
+		return uuid.Nil, errors.New("invalid challenge token")
+	}
+	return uuid.Parse(claims.Subject)
+}
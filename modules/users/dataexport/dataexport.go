@@ -0,0 +1,269 @@
+// Package dataexport assembles everything stored about a user into a zip
+// archive of JSON files and uploaded images, for data subject access requests.
+package dataexport
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	collection "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
+	engagement "github.com/muga20/artsMarket/modules/artwork-management/models/engagement"
+	exhibitions "github.com/muga20/artsMarket/modules/exhibitions/models"
+	feed "github.com/muga20/artsMarket/modules/feed/models"
+	messaging "github.com/muga20/artsMarket/modules/messaging/models"
+	notification "github.com/muga20/artsMarket/modules/notifications/models"
+	organizations "github.com/muga20/artsMarket/modules/organizations/models"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"gorm.io/gorm"
+)
+
+// maxImageSize bounds a single downloaded image so one bad URL cannot fill the disk
+const maxImageSize = 25 << 20
+
+var imageClient = &http.Client{Timeout: 30 * time.Second}
+
+// section is one JSON file in the archive
+type section struct {
+	file   string
+	model  interface{}
+	where  string
+	omit   []string // Columns that must never leave the database, such as token hashes
+	byUser int      // How many times the user ID is bound into where
+}
+
+// sections lists every table that holds personal data, keyed by the user
+func sections() []section {
+	return []section{
+		{file: "account.json", model: &models.User{}, where: "id = ?", byUser: 1},
+		{file: "profile.json", model: &models.UserDetail{}, where: "user_id = ?", byUser: 1},
+		{file: "locations.json", model: &models.UserLocation{}, where: "user_id = ?", byUser: 1},
+		{file: "privacy_settings.json", model: &models.UserPrivacySetting{}, where: "user_id = ?", byUser: 1},
+		{file: "social_links.json", model: &models.SocialLink{}, where: "user_id = ?", byUser: 1},
+		{file: "artist_profile.json", model: &models.ArtistProfile{}, where: "user_id = ?", byUser: 1},
+		{file: "artist_cv.json", model: &models.ArtistCVEntry{}, where: "user_id = ?", byUser: 1},
+		{file: "artist_verification_requests.json", model: &models.ArtistVerificationRequest{}, where: "user_id = ?", byUser: 1},
+		{file: "artist_verification_documents.json", model: &models.ArtistVerificationDocument{}, where: "request_id IN (SELECT id FROM artist_verification_requests WHERE user_id = ?)", byUser: 1},
+		{file: "followers.json", model: &models.Follower{}, where: "follower_id = ? OR following_id = ?", byUser: 2},
+		{file: "blocks.json", model: &models.BlockedUser{}, where: "user_id = ?", byUser: 1},
+		{file: "sessions.json", model: &models.UserSession{}, where: "user_id = ?", byUser: 1, omit: []string{"session_token"}},
+		{file: "linked_identities.json", model: &models.UserIdentity{}, where: "user_id = ?", byUser: 1},
+		{file: "security.json", model: &models.UserSecurity{}, where: "user_id = ?", byUser: 1, omit: []string{
+			"password", "unlock_token", "password_reset_token", "email_verification_token", "two_factor_secret", "two_factor_last_used_step",
+		}},
+		{file: "access_tokens.json", model: &models.PersonalAccessToken{}, where: "user_id = ?", byUser: 1, omit: []string{"token_hash"}},
+		{file: "notifications.json", model: &notification.Notification{}, where: "user_id = ?", byUser: 1},
+		{file: "artworks.json", model: &artwork.Artwork{}, where: "user_id = ?", byUser: 1},
+		{file: "artwork_images.json", model: &artwork.ArtworkImage{}, where: "artwork_id IN (SELECT id FROM artworks WHERE user_id = ?)", byUser: 1},
+		{file: "collections.json", model: &collection.Collection{}, where: "user_id = ?", byUser: 1},
+		{file: "collection_entries.json", model: &collection.CollectionEntry{}, where: "collection_id IN (SELECT id FROM collections WHERE user_id = ?)", byUser: 1},
+		{file: "collection_collaborations.json", model: &collection.CollectionCollaborator{}, where: "user_id = ? OR invited_by = ?", byUser: 2},
+		{file: "collection_artist_permissions.json", model: &collection.CollectionArtistGrant{}, where: "artist_id = ?", byUser: 1},
+		{file: "comments.json", model: &engagement.ArtworkComment{}, where: "user_id = ?", byUser: 1},
+		{file: "likes.json", model: &engagement.ArtworkLike{}, where: "user_id = ?", byUser: 1},
+		{file: "favorites.json", model: &engagement.ArtworkFavorite{}, where: "user_id = ?", byUser: 1},
+		{file: "conversations.json", model: &messaging.Conversation{}, where: "user_one_id = ? OR user_two_id = ?", byUser: 2},
+		{file: "messages.json", model: &messaging.Message{}, where: "conversation_id IN (SELECT id FROM conversations WHERE user_one_id = ? OR user_two_id = ?)", byUser: 2},
+		{file: "activities.json", model: &feed.Activity{}, where: "actor_id = ?", byUser: 1},
+		{file: "exhibitions.json", model: &exhibitions.Exhibition{}, where: "curator_id = ?", byUser: 1},
+		{file: "exhibition_rsvps.json", model: &exhibitions.ExhibitionRSVP{}, where: "user_id = ?", byUser: 1},
+		{file: "organization_memberships.json", model: &organizations.OrganizationMember{}, where: "user_id = ?", byUser: 1},
+		{file: "organization_representations.json", model: &organizations.Representation{}, where: "artist_id = ?", byUser: 1},
+	}
+}
+
+// Manifest describes the archive contents
+type Manifest struct {
+	UserID        uuid.UUID         `json:"user_id"`
+	GeneratedAt   time.Time         `json:"generated_at"`
+	Files         map[string]int    `json:"files"` // File name to record count
+	Images        []string          `json:"images"`
+	MissingImages map[string]string `json:"missing_images,omitempty"` // URL to the reason it could not be included
+	Notes         []string          `json:"notes,omitempty"`
+}
+
+// Build writes the archive for the user into dir and returns its path and size
+func Build(db *gorm.DB, userID uuid.UUID, exportID uuid.UUID, dir string) (string, int64, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", 0, fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	archivePath := filepath.Join(dir, exportID.String()+".zip")
+	file, err := os.OpenFile(archivePath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create export archive: %w", err)
+	}
+
+	if err := write(db, userID, zip.NewWriter(file)); err != nil {
+		file.Close()
+		os.Remove(archivePath)
+		return "", 0, err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(archivePath)
+		return "", 0, fmt.Errorf("failed to close export archive: %w", err)
+	}
+
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to stat export archive: %w", err)
+	}
+	return archivePath, info.Size(), nil
+}
+
+func write(db *gorm.DB, userID uuid.UUID, archive *zip.Writer) error {
+	manifest := Manifest{
+		UserID:        userID,
+		GeneratedAt:   time.Now().UTC(),
+		Files:         make(map[string]int),
+		MissingImages: make(map[string]string),
+		// Orders are not stored by the platform yet; there is nothing to export
+		Notes: []string{"orders: no order records are stored for this account"},
+	}
+
+	var imageURLs []string
+	for _, s := range sections() {
+		args := make([]interface{}, s.byUser)
+		for i := range args {
+			args[i] = userID
+		}
+
+		var rows []map[string]interface{}
+		if err := db.Model(s.model).Unscoped().Where(s.where, args...).Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to export %s: %w", s.file, err)
+		}
+		for _, row := range rows {
+			for _, column := range s.omit {
+				delete(row, column)
+			}
+			imageURLs = append(imageURLs, imageColumns(row)...)
+		}
+
+		if err := writeJSON(archive, s.file, rows); err != nil {
+			return err
+		}
+		manifest.Files[s.file] = len(rows)
+	}
+
+	for _, url := range dedupe(imageURLs) {
+		name, err := writeImage(archive, url)
+		if err != nil {
+			manifest.MissingImages[url] = err.Error()
+			continue
+		}
+		manifest.Images = append(manifest.Images, name)
+	}
+
+	if err := writeJSON(archive, "manifest.json", manifest); err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("failed to finish export archive: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(archive *zip.Writer, name string, value interface{}) error {
+	entry, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	encoder := json.NewEncoder(entry)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeImage downloads an uploaded image into the images folder of the archive
+func writeImage(archive *zip.Writer, url string) (string, error) {
+	resp, err := imageClient.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("download failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("download returned status %d", resp.StatusCode)
+	}
+
+	// Hash the URL so repeated file names from different folders cannot collide
+	sum := sha256.Sum256([]byte(url))
+	extension := path.Ext(strings.SplitN(path.Base(url), "?", 2)[0])
+	name := "images/" + hex.EncodeToString(sum[:8]) + extension
+
+	// Read the whole image before adding its entry, so an oversize image is
+	// left out rather than archived truncated
+	if resp.ContentLength > maxImageSize {
+		return "", fmt.Errorf("image exceeds %d bytes", maxImageSize)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return "", fmt.Errorf("download failed: %v", err)
+	}
+	if len(data) > maxImageSize {
+		return "", fmt.Errorf("image exceeds %d bytes", maxImageSize)
+	}
+
+	entry, err := archive.Create(name)
+	if err != nil {
+		return "", fmt.Errorf("failed to add image: %v", err)
+	}
+	if _, err := entry.Write(data); err != nil {
+		return "", fmt.Errorf("failed to copy image: %v", err)
+	}
+	return name, nil
+}
+
+// NewDownloadToken returns a download token and the hash stored for it
+func NewDownloadToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate download token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken hashes a download token for lookup
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// imageColumns returns the uploaded image URLs held in a row
+func imageColumns(row map[string]interface{}) []string {
+	var urls []string
+	for _, column := range []string{"profile_image", "cover_image", "image_url", "cover_image_url", "primary_image_url", "attachment_url"} {
+		value, ok := row[column].(string)
+		if ok && strings.HasPrefix(value, "https://") {
+			urls = append(urls, value)
+		}
+	}
+	return urls
+}
+
+func dedupe(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	unique := values[:0]
+	for _, value := range values {
+		if !seen[value] {
+			seen[value] = true
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
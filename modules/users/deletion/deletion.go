@@ -0,0 +1,371 @@
+// Package deletion runs the account deletion lifecycle. Deleting an account
+// first deactivates it for a grace period in which the owner can reactivate
+// it by logging in; afterwards a scheduled job purges its personal data.
+package deletion
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	analytics "github.com/muga20/artsMarket/modules/artwork-management/models/analytics"
+	artwork "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	attributes "github.com/muga20/artsMarket/modules/artwork-management/models/arttributes"
+	collection "github.com/muga20/artsMarket/modules/artwork-management/models/collection"
+	engagement "github.com/muga20/artsMarket/modules/artwork-management/models/engagement"
+	exhibitions "github.com/muga20/artsMarket/modules/exhibitions/models"
+	feed "github.com/muga20/artsMarket/modules/feed/models"
+	messaging "github.com/muga20/artsMarket/modules/messaging/models"
+	notification "github.com/muga20/artsMarket/modules/notifications/models"
+	organizations "github.com/muga20/artsMarket/modules/organizations/models"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/sessions"
+	"gorm.io/gorm"
+)
+
+// ErrNotScheduled is returned when reactivating an account that is not
+// waiting to be purged
+var ErrNotScheduled = errors.New("account is not scheduled for deletion")
+
+// FileDeleter removes uploaded images from storage
+type FileDeleter interface {
+	DeleteFile(url string) error
+}
+
+// GracePeriod is how long a deleted account can still be reactivated
+func GracePeriod() time.Duration {
+	days := config.Envs.AccountDeletionGraceDays
+	if days <= 0 {
+		days = 30
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// PurgeAt returns when an account whose deletion was requested at the given
+// time is purged
+func PurgeAt(requestedAt time.Time) time.Time {
+	return requestedAt.Add(GracePeriod())
+}
+
+// Schedule deactivates the account, signs it out of every device and revokes
+// its access tokens. Its profile and content stop resolving immediately.
+func Schedule(tx *gorm.DB, userID uuid.UUID, now time.Time) error {
+	if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"is_active":             false,
+		"status":                models.UserStatusPendingDeletion,
+		"deletion_requested_at": now,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to deactivate user: %w", err)
+	}
+
+	if _, err := sessions.RevokeAllForUser(tx, userID, uuid.Nil); err != nil {
+		return err
+	}
+
+	if err := tx.Model(&models.PersonalAccessToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", now).Error; err != nil {
+		return fmt.Errorf("failed to revoke access tokens: %w", err)
+	}
+	return nil
+}
+
+// Reactivate cancels a pending deletion and restores the account. Accounts
+// deactivated before the grace period existed are restored the same way.
+func Reactivate(tx *gorm.DB, userID uuid.UUID) error {
+	verified, err := models.IsEmailVerified(tx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check email verification: %w", err)
+	}
+	status := models.UserStatusActive
+	if !verified {
+		status = models.UserStatusPendingVerification
+	}
+
+	result := tx.Model(&models.User{}).
+		Where("id = ? AND is_active = ?", userID, false).
+		Updates(map[string]interface{}{
+			"is_active":             true,
+			"status":                status,
+			"deletion_requested_at": nil,
+		})
+	if result.Error != nil {
+		return fmt.Errorf("failed to reactivate user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrNotScheduled
+	}
+	return nil
+}
+
+// Due returns up to limit accounts whose grace period has ended
+func Due(db *gorm.DB, now time.Time, limit int) ([]uuid.UUID, error) {
+	var userIDs []uuid.UUID
+	if err := db.Model(&models.User{}).
+		Where("status = ? AND deletion_requested_at <= ?", models.UserStatusPendingDeletion, now.Add(-GracePeriod())).
+		Order("deletion_requested_at ASC").
+		Limit(limit).
+		Pluck("id", &userIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to find accounts due for purge: %w", err)
+	}
+	return userIDs, nil
+}
+
+// Purge removes the account's personal data across every module. Artworks
+// with a sold edition, and the collections holding them, are kept for the
+// buyers' records; the user row survives only as an anonymized reference
+// for them.
+//
+// Stored images are deleted before any row, so a storage failure leaves the
+// account intact for the next run to retry.
+func Purge(db *gorm.DB, files FileDeleter, userID uuid.UUID) error {
+	var user models.User
+	if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to load user: %w", err)
+	}
+	// The owner reactivated the account after it was selected
+	if user.Status != models.UserStatusPendingDeletion {
+		return nil
+	}
+
+	var soldArtworkIDs []uuid.UUID
+	if err := db.Model(&artwork.Edition{}).
+		Distinct("artwork_id").
+		Where("status = ? AND artwork_id IN (?)", "sold",
+			db.Model(&artwork.Artwork{}).Select("id").Where("user_id = ?", userID)).
+		Pluck("artwork_id", &soldArtworkIDs).Error; err != nil {
+		return fmt.Errorf("failed to find sold artworks: %w", err)
+	}
+
+	var keptCollectionIDs []uuid.UUID
+	if len(soldArtworkIDs) > 0 {
+		if err := db.Model(&collection.CollectionEntry{}).
+			Distinct("collection_id").
+			Where("artwork_id IN ? AND collection_id IN (?)", soldArtworkIDs,
+				db.Model(&collection.Collection{}).Select("id").Where("user_id = ?", userID)).
+			Pluck("collection_id", &keptCollectionIDs).Error; err != nil {
+			return fmt.Errorf("failed to find collections with sold artworks: %w", err)
+		}
+	}
+
+	var unsoldIDs []uuid.UUID
+	artworks := db.Model(&artwork.Artwork{}).Where("user_id = ?", userID)
+	if len(soldArtworkIDs) > 0 {
+		artworks = artworks.Where("id NOT IN ?", soldArtworkIDs)
+	}
+	if err := artworks.Pluck("id", &unsoldIDs).Error; err != nil {
+		return fmt.Errorf("failed to find artworks: %w", err)
+	}
+
+	var removedCollections []collection.Collection
+	collections := db.Where("user_id = ?", userID)
+	if len(keptCollectionIDs) > 0 {
+		collections = collections.Where("id NOT IN ?", keptCollectionIDs)
+	}
+	if err := collections.Find(&removedCollections).Error; err != nil {
+		return fmt.Errorf("failed to find collections: %w", err)
+	}
+
+	images, err := storedImages(db, userID, unsoldIDs, removedCollections)
+	if err != nil {
+		return err
+	}
+	for _, url := range images {
+		if err := files.DeleteFile(url); err != nil {
+			return fmt.Errorf("failed to delete image %s: %w", url, err)
+		}
+	}
+
+	var exportFiles []string
+	if err := db.Model(&models.DataExport{}).
+		Where("user_id = ? AND file_path != ''", userID).
+		Pluck("file_path", &exportFiles).Error; err != nil {
+		return fmt.Errorf("failed to find data exports: %w", err)
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if len(unsoldIDs) > 0 {
+			// Attributes have no foreign key to cascade through
+			if err := tx.Where("artwork_id IN ?", unsoldIDs).Delete(&attributes.ArtworkAttribute{}).Error; err != nil {
+				return fmt.Errorf("failed to delete artwork attributes: %w", err)
+			}
+			if err := tx.Where("id IN ?", unsoldIDs).Delete(&artwork.Artwork{}).Error; err != nil {
+				return fmt.Errorf("failed to delete artworks: %w", err)
+			}
+		}
+
+		if len(keptCollectionIDs) > 0 {
+			if err := tx.Model(&collection.Collection{}).
+				Where("id IN ?", keptCollectionIDs).
+				Update("status", collection.ArchivedStatus).Error; err != nil {
+				return fmt.Errorf("failed to archive collections: %w", err)
+			}
+		}
+		for _, c := range removedCollections {
+			if err := tx.Delete(&collection.Collection{}, "id = ?", c.ID).Error; err != nil {
+				return fmt.Errorf("failed to delete collection: %w", err)
+			}
+		}
+
+		for _, p := range personalData() {
+			args := make([]interface{}, p.byUser)
+			for i := range args {
+				args[i] = userID
+			}
+			if err := tx.Unscoped().Where(p.where, args...).Delete(p.model).Error; err != nil {
+				return fmt.Errorf("failed to delete %T: %w", p.model, err)
+			}
+		}
+		// Views stay in the artwork statistics without pointing at anyone
+		if err := tx.Model(&analytics.ArtworkView{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+			"user_id":    nil,
+			"ip_address": "",
+			"user_agent": "",
+		}).Error; err != nil {
+			return fmt.Errorf("failed to anonymize artwork views: %w", err)
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+			"email":        fmt.Sprintf("deleted-%s@deleted.invalid", userID),
+			"username":     "",
+			"phone_number": "",
+			"auth_type":    "",
+			"status":       models.UserStatusDeleted,
+			"is_active":    false,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to anonymize user: %w", err)
+		}
+		if err := tx.Delete(&models.User{}, "id = ?", userID).Error; err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range exportFiles {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Failed to delete export archive %s: %v", file, err)
+		}
+	}
+	return nil
+}
+
+// userConversations matches the messages of every conversation the user takes part in
+const userConversations = "conversation_id IN (SELECT id FROM conversations WHERE user_one_id = ? OR user_two_id = ?)"
+
+// verificationDocuments matches the documents of the user's artist verification requests
+const verificationDocuments = "request_id IN (SELECT id FROM artist_verification_requests WHERE user_id = ?)"
+
+// personalData lists the rows removed outright, keyed by the user
+func personalData() []struct {
+	model  interface{}
+	where  string
+	byUser int
+} {
+	return []struct {
+		model  interface{}
+		where  string
+		byUser int
+	}{
+		{&models.UserDetail{}, "user_id = ?", 1},
+		{&models.UserLocation{}, "user_id = ?", 1},
+		{&models.UserPrivacySetting{}, "user_id = ?", 1},
+		{&models.SocialLink{}, "user_id = ?", 1},
+		{&models.ArtistVerificationDocument{}, verificationDocuments, 1},
+		{&models.ArtistVerificationRequest{}, "user_id = ?", 1},
+		{&models.ArtistCVEntry{}, "user_id = ?", 1},
+		{&models.ArtistProfile{}, "user_id = ?", 1},
+		{&models.Follower{}, "follower_id = ? OR following_id = ?", 2},
+		{&models.BlockedUser{}, "user_id = ? OR blocked_user_id = ?", 2},
+		{&models.UserSession{}, "user_id = ?", 1},
+		{&feed.Activity{}, "actor_id = ?", 1},
+		{&models.UserSecurity{}, "user_id = ?", 1},
+		{&models.UserIdentity{}, "user_id = ?", 1},
+		{&models.UserRecoveryCode{}, "user_id = ?", 1},
+		{&models.PersonalAccessToken{}, "user_id = ?", 1},
+		{&models.UserRole{}, "user_id = ?", 1},
+		{&models.DataExport{}, "user_id = ?", 1},
+		{&notification.Notification{}, "user_id = ?", 1},
+		{&engagement.ArtworkComment{}, "user_id = ?", 1},
+		{&engagement.ArtworkLike{}, "user_id = ?", 1},
+		{&engagement.ArtworkFavorite{}, "user_id = ?", 1},
+		{&collection.CollectionCollaborator{}, "user_id = ? OR invited_by = ?", 2},
+		{&collection.CollectionArtistGrant{}, "artist_id = ?", 1},
+		{&messaging.Message{}, userConversations, 2},
+		{&messaging.Conversation{}, "user_one_id = ? OR user_two_id = ?", 2},
+		{&exhibitions.ExhibitionRSVP{}, "user_id = ?", 1},
+		{&exhibitions.Exhibition{}, "curator_id = ?", 1},
+		{&organizations.OrganizationMember{}, "user_id = ?", 1},
+		{&organizations.Representation{}, "artist_id = ?", 1},
+	}
+}
+
+// storedImages lists the uploaded images that belong to data being purged
+func storedImages(db *gorm.DB, userID uuid.UUID, artworkIDs []uuid.UUID, collections []collection.Collection) ([]string, error) {
+	var urls []string
+
+	var details []models.UserDetail
+	if err := db.Where("user_id = ?", userID).Find(&details).Error; err != nil {
+		return nil, fmt.Errorf("failed to load user details: %w", err)
+	}
+	for _, detail := range details {
+		urls = append(urls, detail.ProfileImage, detail.CoverImage)
+	}
+
+	if len(artworkIDs) > 0 {
+		var artworkImages []string
+		if err := db.Model(&artwork.ArtworkImage{}).
+			Where("artwork_id IN ?", artworkIDs).
+			Pluck("image_url", &artworkImages).Error; err != nil {
+			return nil, fmt.Errorf("failed to load artwork images: %w", err)
+		}
+		urls = append(urls, artworkImages...)
+	}
+
+	for _, c := range collections {
+		urls = append(urls, c.CoverImageURL, c.PrimaryImageURL)
+	}
+
+	var documents []string
+	if err := db.Model(&models.ArtistVerificationDocument{}).
+		Where(verificationDocuments, userID).
+		Pluck("file_url", &documents).Error; err != nil {
+		return nil, fmt.Errorf("failed to load verification documents: %w", err)
+	}
+	urls = append(urls, documents...)
+
+	var covers []string
+	if err := db.Model(&exhibitions.Exhibition{}).
+		Where("curator_id = ? AND cover_image_url != ''", userID).
+		Pluck("cover_image_url", &covers).Error; err != nil {
+		return nil, fmt.Errorf("failed to load exhibition covers: %w", err)
+	}
+	urls = append(urls, covers...)
+
+	// Conversations are removed for both participants, with their attachments
+	var attachments []string
+	if err := db.Model(&messaging.Message{}).
+		Where(userConversations+" AND attachment_url != ''", userID, userID).
+		Pluck("attachment_url", &attachments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load message attachments: %w", err)
+	}
+	urls = append(urls, attachments...)
+
+	// Skip empty values and placeholders that were never uploaded
+	stored := urls[:0]
+	for _, url := range urls {
+		if config.PublicIDFromURL(url) != "" {
+			stored = append(stored, url)
+		}
+	}
+	return stored, nil
+}
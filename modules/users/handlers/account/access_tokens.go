@@ -0,0 +1,194 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/accesstokens"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+// maxAccessTokenLifetimeDays caps how long a personal access token may live
+const maxAccessTokenLifetimeDays = 365
+
+// CreateAccessTokenRequest describes a new personal access token
+type CreateAccessTokenRequest struct {
+	Name          string   `json:"name" validate:"required"`
+	Scopes        []string `json:"scopes" validate:"required"`
+	ExpiresInDays int      `json:"expires_in_days"` // 0 means the token does not expire
+}
+
+// AccessTokenResponse describes a personal access token without its secret
+type AccessTokenResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedIP string     `json:"last_used_ip,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func newAccessTokenResponse(token models.PersonalAccessToken) AccessTokenResponse {
+	return AccessTokenResponse{
+		ID:         token.ID,
+		Name:       token.Name,
+		Prefix:     token.Prefix,
+		Scopes:     token.ScopeList(),
+		ExpiresAt:  token.ExpiresAt,
+		LastUsedAt: token.LastUsedAt,
+		LastUsedIP: token.LastUsedIP,
+		CreatedAt:  token.CreatedAt,
+	}
+}
+
+// CreateAccessToken issues a scoped personal access token
+// @Summary Create a personal access token
+// @Description Issues a token for integrations, sent as "Authorization: Bearer <token>". The token is only shown in this response.
+// @Tags Account
+// @Accept  json
+// @Produce  json
+// @Param   body  body  CreateAccessTokenRequest  true  "Token name, scopes and lifetime"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /account/tokens [post]
+func CreateAccessToken(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req CreateAccessTokenRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request payload"))
+		}
+
+		req.Name = strings.TrimSpace(req.Name)
+		if req.Name == "" || len(req.Name) > 100 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Name is required and must be at most 100 characters"))
+		}
+
+		scopes, err := accesstokens.NormalizeScopes(req.Scopes)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, fmt.Sprintf("%s. Available scopes: %s",
+					err.Error(), strings.Join(accesstokens.AllScopes, ", "))))
+		}
+
+		if req.ExpiresInDays < 0 || req.ExpiresInDays > maxAccessTokenLifetimeDays {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest,
+					fmt.Sprintf("expires_in_days must be between 0 and %d", maxAccessTokenLifetimeDays)))
+		}
+		var expiresAt *time.Time
+		if req.ExpiresInDays > 0 {
+			expiry := time.Now().AddDate(0, 0, req.ExpiresInDays)
+			expiresAt = &expiry
+		}
+
+		plaintext, token, err := accesstokens.Create(db, user.ID, req.Name, scopes, expiresAt)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"token":        plaintext,
+			"access_token": newAccessTokenResponse(*token),
+			"message":      "Copy this token now. It will not be shown again",
+		}, nil)
+	}
+}
+
+// ListAccessTokens lists the user's active personal access tokens
+// @Summary List personal access tokens
+// @Description Lists the authenticated user's unrevoked tokens with their scopes and last use
+// @Tags Account
+// @Produce  json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /account/tokens [get]
+func ListAccessTokens(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var tokens []models.PersonalAccessToken
+		if err := db.Where("user_id = ? AND revoked_at IS NULL", user.ID).
+			Order("created_at DESC").
+			Find(&tokens).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve access tokens: %w", err))
+		}
+
+		response := make([]AccessTokenResponse, 0, len(tokens))
+		for _, token := range tokens {
+			response = append(response, newAccessTokenResponse(token))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"tokens": response,
+		}, nil)
+	}
+}
+
+// RevokeAccessToken revokes a personal access token
+// @Summary Revoke a personal access token
+// @Description Revokes one of the authenticated user's tokens immediately
+// @Tags Account
+// @Produce  json
+// @Param id path string true "Token ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /account/tokens/{id} [delete]
+func RevokeAccessToken(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		tokenID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid token ID"))
+		}
+
+		var token models.PersonalAccessToken
+		if err := db.Where("id = ? AND user_id = ? AND revoked_at IS NULL", tokenID, user.ID).
+			First(&token).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Access token not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve access token: %w", err))
+		}
+
+		if err := db.Model(&token).Update("revoked_at", time.Now()).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to revoke access token: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Access token revoked",
+		}, nil)
+	}
+}
@@ -0,0 +1,142 @@
+package account
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/modules/users/dataexport"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tasks"
+	"gorm.io/gorm"
+)
+
+// dataExportCooldown limits how often a user can request a new export
+const dataExportCooldown = 24 * time.Hour
+
+// RequestDataExport starts building a copy of everything stored about the user
+// @Summary Request a data export
+// @Description Starts a background job that packages the user's data into a zip archive and emails a time-limited download link
+// @Tags Account
+// @Produce  json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Router /account/export [post]
+func RequestDataExport(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var export models.DataExport
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var recent int64
+			if err := tx.Model(&models.DataExport{}).
+				Where("user_id = ? AND status != ? AND created_at > ?", user.ID, models.DataExportStatusFailed, time.Now().Add(-dataExportCooldown)).
+				Count(&recent).Error; err != nil {
+				return fmt.Errorf("failed to check previous exports: %w", err)
+			}
+			if recent > 0 {
+				return fiber.NewError(fiber.StatusTooManyRequests, "You can request one data export per day")
+			}
+
+			export = models.DataExport{
+				UserID: user.ID,
+				Status: models.DataExportStatusPending,
+			}
+			if err := tx.Create(&export).Error; err != nil {
+				return fmt.Errorf("failed to create data export: %w", err)
+			}
+
+			_, err := services.WriteOutbox(tx, tasks.TypeGenerateDataExport, tasks.DataExportPayload{
+				ExportID: export.ID.String(),
+			})
+			return err
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Your data export has started. We will email you a download link when it is ready",
+			"export":  export,
+		}, nil)
+	}
+}
+
+// ListDataExports lists the user's data export requests
+// @Summary List data exports
+// @Description Lists the authenticated user's data export requests and their status
+// @Tags Account
+// @Produce  json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /account/export [get]
+func ListDataExports(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var exports []models.DataExport
+		if err := db.Where("user_id = ?", user.ID).
+			Order("created_at DESC").
+			Limit(20).
+			Find(&exports).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve data exports: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"exports": exports,
+		}, nil)
+	}
+}
+
+// DownloadDataExport streams a finished export archive to the holder of its emailed link
+// @Summary Download a data export
+// @Description Downloads the zip archive using the token from the export email. No session is required.
+// @Tags Account
+// @Produce  application/zip
+// @Param id path string true "Export ID"
+// @Param token query string true "Download token"
+// @Success 200 {file} file
+// @Failure 404 {object} map[string]string
+// @Failure 410 {object} map[string]string
+// @Router /exports/{id}/download [get]
+func DownloadDataExport(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		token := c.Query("token")
+		if token == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Export not found"))
+		}
+
+		var export models.DataExport
+		if err := db.Where("id = ? AND download_token_hash = ?", c.Params("id"), dataexport.HashToken(token)).
+			First(&export).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Export not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve data export: %w", err))
+		}
+
+		if export.Status != models.DataExportStatusReady || export.ExpiresAt == nil || export.ExpiresAt.Before(time.Now()) {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusGone, "This download link has expired. Please request a new export"))
+		}
+
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.Download(export.FilePath, fmt.Sprintf("arts-market-data-%s.zip", export.CreatedAt.Format("2006-01-02")))
+	}
+}
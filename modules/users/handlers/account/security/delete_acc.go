@@ -2,26 +2,34 @@ package security
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/modules/users/deletion"
 	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/sessions"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tasks"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
 // DeleteAccountRequest defines the structure of the request body for deleting an account
 type DeleteAccountRequest struct {
-	Password string `json:"password" validate:"required"`
+	Password string `json:"password"`
+	// Accounts created through social login confirm with their email address instead
+	ConfirmEmail string `json:"confirm_email"`
 }
 
-// DeleteAccount handles the deletion of the user's account after password verification
+// DeleteAccount schedules the user's account for deletion after verification
 // @Summary Delete the authenticated user's account
-// @Description Permanently deletes the user's account and associated data after password verification
+// @Description Deactivates the account, signs out every device and schedules its data to be purged after a grace period. Logging in during the grace period offers to reactivate it.
 // @Tags Security
 // @Accept  json
 // @Produce  json
-// @Param   body  body  DeleteAccountRequest  true  "Password for verification"
+// @Param   body  body  DeleteAccountRequest  true  "Password, or email address for social login accounts"
 // @Success 200 {object} map[string]string
 // @Failure 400 {object} map[string]string
 // @Failure 401 {object} map[string]string
@@ -43,52 +51,56 @@ func DeleteAccount(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber
 				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
 		}
 
-		// Validate password is provided
-		if req.Password == "" {
-			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusBadRequest, "Password is required"))
-		}
+		if user.AuthType == "email" {
+			// Validate password is provided
+			if req.Password == "" {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Password is required"))
+			}
 
-		// Fetch user security record
-		var userSecurity models.UserSecurity
-		if err := db.Where("user_id = ?", user.ID).First(&userSecurity).Error; err != nil {
-			if err == gorm.ErrRecordNotFound {
+			// Fetch user security record
+			var userSecurity models.UserSecurity
+			if err := db.Where("user_id = ?", user.ID).First(&userSecurity).Error; err != nil {
+				if err == gorm.ErrRecordNotFound {
+					return responseHandler.HandleResponse(c, nil,
+						fiber.NewError(fiber.StatusNotFound, "User account not found"))
+				}
 				return responseHandler.HandleResponse(c, nil,
-					fiber.NewError(fiber.StatusNotFound, "User account not found"))
+					fmt.Errorf("failed to fetch user security: %w", err))
 			}
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to fetch user security: %w", err))
-		}
 
-		// Verify password
-		if err := bcrypt.CompareHashAndPassword([]byte(userSecurity.Password), []byte(req.Password)); err != nil {
+			// Verify password
+			if err := bcrypt.CompareHashAndPassword([]byte(userSecurity.Password), []byte(req.Password)); err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusUnauthorized, "Incorrect password"))
+			}
+		} else if !strings.EqualFold(strings.TrimSpace(req.ConfirmEmail), user.Email) {
 			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusUnauthorized, "Incorrect password"))
+				fiber.NewError(fiber.StatusBadRequest, "Enter your email address to confirm account deletion"))
 		}
 
-		// Start explicit transaction
-		tx := db.Begin()
-		if tx.Error != nil {
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to start transaction: %w", tx.Error))
-		}
+		now := time.Now()
+		purgeAt := deletion.PurgeAt(now)
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := deletion.Schedule(tx, user.ID, now); err != nil {
+				return err
+			}
 
-		// Soft delete by setting is_active to false
-		if err := tx.Model(&models.User{}).Where("id = ?", user.ID).
-			Update("is_active", false).Error; err != nil {
-			tx.Rollback()
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to deactivate user: %w", err))
+			_, err := services.WriteOutbox(tx, tasks.TypeSendAccountDeletionScheduled, tasks.AccountDeletionScheduledPayload{
+				Email:   user.Email,
+				PurgeAt: purgeAt.UTC().Format(time.RFC1123),
+			})
+			return err
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
 		}
 
-		// Commit transaction
-		if err := tx.Commit().Error; err != nil {
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to commit transaction: %w", err))
-		}
+		sessions.ClearCookies(c)
 
 		return responseHandler.HandleResponse(c, fiber.Map{
-			"message": "Account deactivated successfully",
+			"message":  "Your account has been deactivated and will be permanently deleted after the grace period. Log in before then to reactivate it",
+			"purge_at": purgeAt,
 		}, nil)
 	}
 }
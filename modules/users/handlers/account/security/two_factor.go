@@ -0,0 +1,278 @@
+package security
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/twofactor"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/secrets"
+	"github.com/muga20/artsMarket/pkg/totp"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// TwoFactorCodeRequest carries an authenticator code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// DisableTwoFactorRequest requires the password plus a second factor
+type DisableTwoFactorRequest struct {
+	Password     string `json:"password" validate:"required"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// EnrollTwoFactor starts two-factor enrollment by generating a new TOTP secret
+// @Summary Start two-factor enrollment
+// @Description Generates a TOTP secret and otpauth URI for an authenticator app. Enrollment completes after confirming a code.
+// @Tags Security
+// @Produce  json
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /security/2fa/enroll [post]
+func EnrollTwoFactor(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var userSecurity models.UserSecurity
+		if err := db.Where("user_id = ?", user.ID).First(&userSecurity).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to fetch user security: %w", err))
+		}
+
+		if userSecurity.TwoFactorEnabled {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, "Two-factor authentication is already enabled"))
+		}
+
+		secret, err := totp.GenerateSecret()
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to generate secret: %w", err))
+		}
+
+		encrypted, err := secrets.Encrypt(secret)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to encrypt secret: %w", err))
+		}
+
+		// The secret stays inactive until a code from it has been confirmed
+		if err := db.Model(&userSecurity).Updates(map[string]interface{}{
+			"two_factor_secret":         encrypted,
+			"two_factor_last_used_step": 0,
+		}).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to store secret: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"secret":      secret,
+			"otpauth_uri": totp.URI(twofactor.Issuer, user.Email, secret),
+		}, nil)
+	}
+}
+
+// ConfirmTwoFactor enables two-factor authentication once a code from the new secret checks out
+// @Summary Confirm two-factor enrollment
+// @Description Verifies a code from the authenticator app, enables two-factor authentication and returns recovery codes
+// @Tags Security
+// @Accept  json
+// @Produce  json
+// @Param   body  body  TwoFactorCodeRequest  true  "Authenticator code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /security/2fa/confirm [post]
+func ConfirmTwoFactor(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req TwoFactorCodeRequest
+		if err := c.BodyParser(&req); err != nil || req.Code == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Authenticator code is required"))
+		}
+
+		var recoveryCodes []string
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var userSecurity models.UserSecurity
+			if err := tx.Where("user_id = ?", user.ID).First(&userSecurity).Error; err != nil {
+				return fmt.Errorf("failed to fetch user security: %w", err)
+			}
+
+			if userSecurity.TwoFactorEnabled {
+				return fiber.NewError(fiber.StatusConflict, "Two-factor authentication is already enabled")
+			}
+			if userSecurity.TwoFactorSecret == nil {
+				return fiber.NewError(fiber.StatusBadRequest, "Start two-factor enrollment first")
+			}
+
+			if err := twofactor.VerifyCode(tx, &userSecurity, req.Code); err != nil {
+				return twoFactorError(err)
+			}
+
+			now := time.Now()
+			if err := tx.Model(&userSecurity).Updates(map[string]interface{}{
+				"two_factor_enabled":    true,
+				"two_factor_enabled_at": now,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to enable two-factor authentication: %w", err)
+			}
+
+			var err error
+			recoveryCodes, err = twofactor.GenerateRecoveryCodes(tx, user.ID)
+			return err
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message":        "Two-factor authentication enabled",
+			"recovery_codes": recoveryCodes,
+		}, nil)
+	}
+}
+
+// DisableTwoFactor turns two-factor authentication off
+// @Summary Disable two-factor authentication
+// @Description Disables two-factor authentication after checking the password and an authenticator or recovery code
+// @Tags Security
+// @Accept  json
+// @Produce  json
+// @Param   body  body  DisableTwoFactorRequest  true  "Password and second factor"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /security/2fa/disable [post]
+func DisableTwoFactor(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req DisableTwoFactorRequest
+		if err := c.BodyParser(&req); err != nil || req.Password == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Password is required"))
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var userSecurity models.UserSecurity
+			if err := tx.Where("user_id = ?", user.ID).First(&userSecurity).Error; err != nil {
+				return fmt.Errorf("failed to fetch user security: %w", err)
+			}
+
+			if !userSecurity.TwoFactorEnabled {
+				return fiber.NewError(fiber.StatusBadRequest, "Two-factor authentication is not enabled")
+			}
+
+			if err := bcrypt.CompareHashAndPassword([]byte(userSecurity.Password), []byte(req.Password)); err != nil {
+				return fiber.NewError(fiber.StatusUnauthorized, "Password is incorrect")
+			}
+
+			if err := twofactor.Verify(tx, &userSecurity, req.Code, req.RecoveryCode); err != nil {
+				return twoFactorError(err)
+			}
+
+			if err := tx.Model(&userSecurity).Updates(map[string]interface{}{
+				"two_factor_enabled":        false,
+				"two_factor_secret":         nil,
+				"two_factor_enabled_at":     nil,
+				"two_factor_last_used_step": 0,
+			}).Error; err != nil {
+				return fmt.Errorf("failed to disable two-factor authentication: %w", err)
+			}
+
+			if err := tx.Where("user_id = ?", user.ID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+				return fmt.Errorf("failed to remove recovery codes: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Two-factor authentication disabled",
+		}, nil)
+	}
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes
+// @Summary Regenerate recovery codes
+// @Description Invalidates all existing recovery codes and issues new ones after checking an authenticator code
+// @Tags Security
+// @Accept  json
+// @Produce  json
+// @Param   body  body  TwoFactorCodeRequest  true  "Authenticator code"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Router /security/2fa/recovery-codes [post]
+func RegenerateRecoveryCodes(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req TwoFactorCodeRequest
+		if err := c.BodyParser(&req); err != nil || req.Code == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Authenticator code is required"))
+		}
+
+		var recoveryCodes []string
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var userSecurity models.UserSecurity
+			if err := tx.Where("user_id = ?", user.ID).First(&userSecurity).Error; err != nil {
+				return fmt.Errorf("failed to fetch user security: %w", err)
+			}
+
+			if !userSecurity.TwoFactorEnabled {
+				return fiber.NewError(fiber.StatusBadRequest, "Two-factor authentication is not enabled")
+			}
+
+			if err := twofactor.VerifyCode(tx, &userSecurity, req.Code); err != nil {
+				return twoFactorError(err)
+			}
+
+			var err error
+			recoveryCodes, err = twofactor.GenerateRecoveryCodes(tx, user.ID)
+			return err
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"recovery_codes": recoveryCodes,
+		}, nil)
+	}
+}
+
+func twoFactorError(err error) error {
+	if errors.Is(err, twofactor.ErrInvalidCode) {
+		return fiber.NewError(fiber.StatusUnauthorized, "Invalid authentication code")
+	}
+	return err
+}
@@ -2,14 +2,12 @@ package security
 
 import (
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"github.com/hibiken/asynq"
-	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/modules/notifications/services"
 	"github.com/muga20/artsMarket/modules/users/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"github.com/muga20/artsMarket/pkg/tasks"
@@ -101,10 +99,10 @@ func UpdateEmail(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.H
 		if err := tx.Where("user_id = ?", user.ID).First(&userSecurity).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
 				userSecurity = models.UserSecurity{
-					UserID:                 user.ID,
-					IsEmailVerifiedAt:      nil,
-					PasswordResetToken:     &verificationToken,
-					PasswordResetExpiresAt: &expiration,
+					UserID:                     user.ID,
+					IsEmailVerifiedAt:          nil,
+					EmailVerificationToken:     &verificationToken,
+					EmailVerificationExpiresAt: &expiration,
 				}
 			} else {
 				tx.Rollback()
@@ -113,8 +111,8 @@ func UpdateEmail(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.H
 			}
 		} else {
 			userSecurity.IsEmailVerifiedAt = nil
-			userSecurity.PasswordResetToken = &verificationToken
-			userSecurity.PasswordResetExpiresAt = &expiration
+			userSecurity.EmailVerificationToken = &verificationToken
+			userSecurity.EmailVerificationExpiresAt = &expiration
 		}
 
 		if err := tx.Save(&userSecurity).Error; err != nil {
@@ -123,27 +121,22 @@ func UpdateEmail(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.H
 				fmt.Errorf("failed to update security record: %w", err))
 		}
 
+		// Record the verification email in the same transaction as the token update
+		if _, err := services.WriteOutbox(tx, tasks.TypeSendEmailVerification, tasks.EmailVerificationPayload{
+			Email:   req.Email,
+			Token:   verificationToken,
+			Purpose: tasks.VerificationPurposeEmailChange,
+		}); err != nil {
+			tx.Rollback()
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
 		// Commit transaction
 		if err := tx.Commit().Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
 				fmt.Errorf("failed to commit transaction: %w", err))
 		}
 
-		// Send verification email (non-blocking)
-		go func() {
-			task, err := tasks.NewSendEmailVerificationTask(req.Email, verificationToken)
-			if err != nil {
-				log.Printf("Failed to create email verification task: %v", err)
-				return
-			}
-
-			client := asynq.NewClient(*config.RedisConfig)
-			defer client.Close()
-			if _, err := client.Enqueue(task); err != nil {
-				log.Printf("Failed to enqueue email verification task: %v", err)
-			}
-		}()
-
 		return responseHandler.HandleResponse(c, fiber.Map{
 			"message": "Email updated successfully. Please verify your new email.",
 		}, nil)
@@ -162,54 +155,63 @@ func UpdateEmail(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.H
 // @Failure 500 {object} map[string]string
 // @Router /security/verify-email [get]
 func VerifyEmail(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
-    return func(c *fiber.Ctx) error {
-        // Validate token
-        token := c.Query("token")
-        if token == "" {
-            return responseHandler.HandleResponse(c, nil,
-                fiber.NewError(fiber.StatusBadRequest, "Verification token is required"))
-        }
-
-        // Start database transaction
-        tx := db.Begin()
-        if tx.Error != nil {
-            return responseHandler.HandleResponse(c, nil,
-                fmt.Errorf("failed to start transaction: %w", tx.Error))
-        }
-
-        // Find valid token
-        var userSecurity models.UserSecurity
-        if err := tx.Where("password_reset_token = ? AND password_reset_expires_at > ?", token, time.Now()).
-            First(&userSecurity).Error; err != nil {
-            tx.Rollback()
-            if err == gorm.ErrRecordNotFound {
-                return responseHandler.HandleResponse(c, nil,
-                    fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired verification token"))
-            }
-            return responseHandler.HandleResponse(c, nil,
-                fmt.Errorf("failed to verify token: %w", err))
-        }
-
-        // Update verification status
-        now := time.Now()
-        userSecurity.IsEmailVerifiedAt = &now
-        userSecurity.PasswordResetToken = nil
-        userSecurity.PasswordResetExpiresAt = nil
-
-        if err := tx.Save(&userSecurity).Error; err != nil {
-            tx.Rollback()
-            return responseHandler.HandleResponse(c, nil,
-                fmt.Errorf("failed to update verification status: %w", err))
-        }
-
-        // Commit transaction
-        if err := tx.Commit().Error; err != nil {
-            return responseHandler.HandleResponse(c, nil,
-                fmt.Errorf("failed to commit transaction: %w", err))
-        }
-
-        return responseHandler.HandleResponse(c, fiber.Map{
-            "message": "Email verified successfully",
-        }, nil)
-    }
+	return func(c *fiber.Ctx) error {
+		// Validate token
+		token := c.Query("token")
+		if token == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Verification token is required"))
+		}
+
+		// Start database transaction
+		tx := db.Begin()
+		if tx.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to start transaction: %w", tx.Error))
+		}
+
+		// Find valid token
+		var userSecurity models.UserSecurity
+		if err := tx.Where("email_verification_token = ? AND email_verification_expires_at > ?", token, time.Now()).
+			First(&userSecurity).Error; err != nil {
+			tx.Rollback()
+			if err == gorm.ErrRecordNotFound {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusUnauthorized, "Invalid or expired verification token"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to verify token: %w", err))
+		}
+
+		// Update verification status
+		now := time.Now()
+		userSecurity.IsEmailVerifiedAt = &now
+		userSecurity.EmailVerificationToken = nil
+		userSecurity.EmailVerificationExpiresAt = nil
+
+		if err := tx.Save(&userSecurity).Error; err != nil {
+			tx.Rollback()
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to update verification status: %w", err))
+		}
+
+		// Accounts created through signup become active once verified
+		if err := tx.Model(&models.User{}).
+			Where("id = ? AND status = ?", userSecurity.UserID, models.UserStatusPendingVerification).
+			Update("status", models.UserStatusActive).Error; err != nil {
+			tx.Rollback()
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to activate account: %w", err))
+		}
+
+		// Commit transaction
+		if err := tx.Commit().Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to commit transaction: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Email verified successfully",
+		}, nil)
+	}
 }
@@ -122,7 +122,7 @@ func SearchUsernames(db *gorm.DB, responseHandler *handlers.ResponseHandler) fib
 		searchPattern := "%" + strings.ToLower(keyword) + "%"
 		if err := db.Model(&models.User{}).
 			Select("username").
-			Where("LOWER(username) LIKE ?", searchPattern).
+			Where("LOWER(username) LIKE ? AND is_active = ?", searchPattern, true).
 			Limit(20).
 			Pluck("username", &usernames).Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
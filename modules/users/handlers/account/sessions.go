@@ -0,0 +1,117 @@
+package account
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/sessions"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+// SessionResponse describes one signed-in device
+type SessionResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	DeviceInfo string     `json:"device_info"`
+	IPAddress  string     `json:"ip_address"`
+	StartedAt  time.Time  `json:"started_at"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	Current    bool       `json:"current"`
+}
+
+// ListSessions lists the devices the user is signed in on
+// @Summary List active sessions
+// @Description Lists every device session of the authenticated user, marking the current one
+// @Tags Account
+// @Produce  json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Router /account/sessions [get]
+func ListSessions(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+		currentFamily, _ := c.Locals("session_id").(uuid.UUID)
+
+		active, err := sessions.ListActive(db, user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve sessions: %w", err))
+		}
+
+		response := make([]SessionResponse, 0, len(active))
+		for _, session := range active {
+			response = append(response, SessionResponse{
+				ID:         session.FamilyID,
+				DeviceInfo: session.DeviceInfo,
+				IPAddress:  session.IPAddress,
+				StartedAt:  session.StartedAt,
+				LastSeenAt: session.LastSeenAt,
+				ExpiresAt:  session.ExpiresAt,
+				Current:    session.FamilyID == currentFamily,
+			})
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"sessions": response,
+		}, nil)
+	}
+}
+
+// RevokeSession signs a single device out
+// @Summary Revoke a session
+// @Description Revokes one device session of the authenticated user
+// @Tags Account
+// @Produce  json
+// @Param id path string true "Session ID"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /account/sessions/{id} [delete]
+func RevokeSession(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		familyID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid session ID"))
+		}
+
+		var count int64
+		if err := db.Model(&models.UserSession{}).
+			Where("family_id = ? AND user_id = ? AND revoked_at IS NULL", familyID, user.ID).
+			Count(&count).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve session: %w", err))
+		}
+		if count == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Session not found"))
+		}
+
+		if err := sessions.RevokeFamily(db, familyID); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		if currentFamily, _ := c.Locals("session_id").(uuid.UUID); currentFamily == familyID {
+			sessions.ClearCookies(c)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Session revoked successfully",
+		}, nil)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/feed/timeline"
 	"github.com/muga20/artsMarket/modules/users/models"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"gorm.io/gorm"
@@ -15,6 +17,7 @@ type PrivacySettingsUpdateRequest struct {
 	ShowPhone           *bool  `json:"show_phone,omitempty"`
 	ShowLocation        *bool  `json:"show_location,omitempty"`
 	AllowFollowRequests *bool  `json:"allow_follow_requests,omitempty"`
+	IsPrivate           *bool  `json:"is_private,omitempty"`
 	AllowMessagesFrom   string `json:"allow_messages_from,omitempty"`
 }
 
@@ -60,6 +63,7 @@ func GetPrivacySettings(db *gorm.DB, responseHandler *handlers.ResponseHandler)
 				"show_phone":            privacySettings.ShowPhone,
 				"show_location":         privacySettings.ShowLocation,
 				"allow_follow_requests": privacySettings.AllowFollowRequests,
+				"is_private":            privacySettings.IsPrivate,
 				"allow_messages_from":   privacySettings.AllowMessagesFrom,
 			},
 		}, nil)
@@ -68,7 +72,7 @@ func GetPrivacySettings(db *gorm.DB, responseHandler *handlers.ResponseHandler)
 
 // UpdatePrivacySettings updates specific privacy settings
 // @Summary Update the authenticated user's privacy settings
-// @Description Allows users to update visibility and message preferences
+// @Description Allows users to update visibility and message preferences. Making a private account public approves its pending follow requests.
 // @Tags Account
 // @Accept  json
 // @Produce  json
@@ -99,6 +103,7 @@ func UpdatePrivacySettings(db *gorm.DB, responseHandler *handlers.ResponseHandle
 			ShowPhone           *bool  `json:"show_phone"`
 			ShowLocation        *bool  `json:"show_location"`
 			AllowFollowRequests *bool  `json:"allow_follow_requests"`
+			IsPrivate           *bool  `json:"is_private"`
 			AllowMessagesFrom   string `json:"allow_messages_from"`
 		}
 
@@ -111,6 +116,7 @@ func UpdatePrivacySettings(db *gorm.DB, responseHandler *handlers.ResponseHandle
 		if updateData.AllowMessagesFrom != "" &&
 			updateData.AllowMessagesFrom != "everyone" &&
 			updateData.AllowMessagesFrom != "following" &&
+			updateData.AllowMessagesFrom != "followers" &&
 			updateData.AllowMessagesFrom != "none" {
 			return responseHandler.HandleResponse(c, nil,
 				fiber.NewError(fiber.StatusBadRequest, "Invalid value for allow_messages_from"))
@@ -130,6 +136,9 @@ func UpdatePrivacySettings(db *gorm.DB, responseHandler *handlers.ResponseHandle
 		if updateData.AllowFollowRequests != nil {
 			updates["allow_follow_requests"] = *updateData.AllowFollowRequests
 		}
+		if updateData.IsPrivate != nil {
+			updates["is_private"] = *updateData.IsPrivate
+		}
 		if updateData.AllowMessagesFrom != "" {
 			updates["allow_messages_from"] = updateData.AllowMessagesFrom
 		}
@@ -141,18 +150,38 @@ func UpdatePrivacySettings(db *gorm.DB, responseHandler *handlers.ResponseHandle
 		}
 
 		// Perform the update
-		result := db.Model(&models.UserPrivacySetting{}).
-			Where("user_id = ?", user.ID).
-			Updates(updates)
+		var acceptedFollowers []uuid.UUID
+		err := db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.UserPrivacySetting{}).
+				Where("user_id = ?", user.ID).
+				Updates(updates)
+
+			if result.Error != nil {
+				return fmt.Errorf("failed to update privacy settings: %w", result.Error)
+			}
 
-		if result.Error != nil {
-			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("failed to update privacy settings: %w", result.Error))
-		}
+			if result.RowsAffected == 0 {
+				return fiber.NewError(fiber.StatusNotFound, "Privacy settings not found")
+			}
 
-		if result.RowsAffected == 0 {
-			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusNotFound, "Privacy settings not found"))
+			// Requests waiting on a private account no longer need approval
+			if updateData.IsPrivate != nil && !*updateData.IsPrivate {
+				if err := tx.Model(&models.Follower{}).
+					Where("following_id = ? AND status = ?", user.ID, models.FollowStatusPending).
+					Pluck("follower_id", &acceptedFollowers).Error; err != nil {
+					return fmt.Errorf("failed to load pending follow requests: %w", err)
+				}
+				if err := models.AcceptPendingFollowRequests(tx, user.ID); err != nil {
+					return fmt.Errorf("failed to accept pending follow requests: %w", err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		for _, followerID := range acceptedFollowers {
+			timeline.Invalidate(c.Context(), followerID)
 		}
 
 		// Return simple success message
@@ -0,0 +1,220 @@
+package artist
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+const (
+	maxCVEntries    = 200
+	maxCVFieldLen   = 255
+	earliestCVYear  = 1900
+	futureYearSlack = 5 // Degrees and shows can be announced a few years ahead
+)
+
+// CVEntryRequest describes one CV entry
+type CVEntryRequest struct {
+	Kind        string `json:"kind" example:"exhibition" enums:"education,exhibition,award,representation"`
+	Title       string `json:"title" example:"Harbour Light"`
+	Institution string `json:"institution" example:"Nairobi National Museum"`
+	Location    string `json:"location" example:"Nairobi, Kenya"`
+	StartYear   *int   `json:"start_year" example:"2023"`
+	EndYear     *int   `json:"end_year" example:"2023"`
+	URL         string `json:"url"`
+}
+
+// toEntry validates the request and copies it into entry
+func (r CVEntryRequest) toEntry(entry *models.ArtistCVEntry) error {
+	kind := strings.ToLower(strings.TrimSpace(r.Kind))
+	if !models.ValidCVKind(kind) {
+		return fiber.NewError(fiber.StatusBadRequest,
+			"Invalid kind. Must be education, exhibition, award, or representation")
+	}
+
+	title := strings.TrimSpace(r.Title)
+	institution := strings.TrimSpace(r.Institution)
+	location := strings.TrimSpace(r.Location)
+	link := strings.TrimSpace(r.URL)
+	if title == "" {
+		return fiber.NewError(fiber.StatusBadRequest, "Title is required")
+	}
+	for _, field := range []string{title, institution, location, link} {
+		if len(field) > maxCVFieldLen {
+			return fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("CV fields cannot exceed %d characters", maxCVFieldLen))
+		}
+	}
+	if link != "" {
+		if parsed, err := url.ParseRequestURI(link); err != nil ||
+			(parsed.Scheme != "http" && parsed.Scheme != "https") {
+			return fiber.NewError(fiber.StatusBadRequest, "URL must be a valid http or https link")
+		}
+	}
+
+	latest := time.Now().Year() + futureYearSlack
+	for _, year := range []*int{r.StartYear, r.EndYear} {
+		if year != nil && (*year < earliestCVYear || *year > latest) {
+			return fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("Years must be between %d and %d", earliestCVYear, latest))
+		}
+	}
+	if r.StartYear != nil && r.EndYear != nil && *r.EndYear < *r.StartYear {
+		return fiber.NewError(fiber.StatusBadRequest, "End year cannot be before start year")
+	}
+
+	entry.Kind = kind
+	entry.Title = title
+	entry.Institution = institution
+	entry.Location = location
+	entry.StartYear = r.StartYear
+	entry.EndYear = r.EndYear
+	entry.URL = link
+	return nil
+}
+
+// CreateCVEntry adds an entry to the authenticated user's CV
+// @Summary Add a CV entry
+// @Description Adds education, an exhibition, an award or a representing gallery to the authenticated user's artist CV
+// @Tags Artists
+// @Accept json
+// @Produce json
+// @Param body body CVEntryRequest true "CV entry"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /account/artist-profile/cv [post]
+func CreateCVEntry(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req CVEntryRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		entry := models.ArtistCVEntry{UserID: user.ID}
+		if err := req.toEntry(&entry); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		var count int64
+		if err := db.Model(&models.ArtistCVEntry{}).Where("user_id = ?", user.ID).Count(&count).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to count CV entries: %w", err))
+		}
+		if count >= maxCVEntries {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest,
+					fmt.Sprintf("A CV can have at most %d entries", maxCVEntries)))
+		}
+
+		if err := db.Create(&entry).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to create CV entry: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "CV entry created successfully",
+			"entry":   entry,
+		}, nil)
+	}
+}
+
+// UpdateCVEntry replaces one of the authenticated user's CV entries
+// @Summary Update a CV entry
+// @Tags Artists
+// @Accept json
+// @Produce json
+// @Param id path string true "CV entry ID"
+// @Param body body CVEntryRequest true "CV entry"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /account/artist-profile/cv/{id} [put]
+func UpdateCVEntry(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req CVEntryRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+
+		var entry models.ArtistCVEntry
+		if err := db.Where("id = ? AND user_id = ?", c.Params("id"), user.ID).First(&entry).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "CV entry not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve CV entry: %w", err))
+		}
+		if err := req.toEntry(&entry); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		if err := db.Save(&entry).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to update CV entry: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "CV entry updated successfully",
+			"entry":   entry,
+		}, nil)
+	}
+}
+
+// DeleteCVEntry removes one of the authenticated user's CV entries
+// @Summary Delete a CV entry
+// @Tags Artists
+// @Produce json
+// @Param id path string true "CV entry ID"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /account/artist-profile/cv/{id} [delete]
+func DeleteCVEntry(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		result := db.Where("id = ? AND user_id = ?", c.Params("id"), user.ID).Delete(&models.ArtistCVEntry{})
+		if result.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to delete CV entry: %w", result.Error))
+		}
+		if result.RowsAffected == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "CV entry not found"))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "CV entry deleted successfully",
+		}, nil)
+	}
+}
@@ -0,0 +1,182 @@
+package artist
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const maxStatementLength = 5000
+
+// UpdateArtistProfileRequest changes the artist statement
+type UpdateArtistProfileRequest struct {
+	Statement string `json:"statement" example:"I paint the harbour at first light"`
+}
+
+// ProfileView is an artist's profile with their CV grouped by kind
+type ProfileView struct {
+	Statement      string                 `json:"statement"`
+	IsVerified     bool                   `json:"is_verified"`
+	VerifiedAt     *time.Time             `json:"verified_at,omitempty"`
+	Education      []models.ArtistCVEntry `json:"education"`
+	Exhibitions    []models.ArtistCVEntry `json:"exhibitions"`
+	Awards         []models.ArtistCVEntry `json:"awards"`
+	Representation []models.ArtistCVEntry `json:"representation"`
+}
+
+// LoadProfile returns the user's artist profile and CV. It returns nil for
+// users who have never filled either in.
+func LoadProfile(db *gorm.DB, userID uuid.UUID) (*ProfileView, error) {
+	var profile models.ArtistProfile
+	hasProfile := true
+	if err := db.Where("user_id = ?", userID).First(&profile).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to retrieve artist profile: %w", err)
+		}
+		hasProfile = false
+	}
+
+	// Current entries first, then the most recent
+	var entries []models.ArtistCVEntry
+	if err := db.Where("user_id = ?", userID).
+		Order("end_year IS NULL DESC, COALESCE(end_year, start_year) DESC, created_at DESC").
+		Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to retrieve artist CV: %w", err)
+	}
+	if !hasProfile && len(entries) == 0 {
+		return nil, nil
+	}
+
+	view := &ProfileView{
+		Statement:      profile.Statement,
+		IsVerified:     profile.IsVerified,
+		VerifiedAt:     profile.VerifiedAt,
+		Education:      []models.ArtistCVEntry{},
+		Exhibitions:    []models.ArtistCVEntry{},
+		Awards:         []models.ArtistCVEntry{},
+		Representation: []models.ArtistCVEntry{},
+	}
+	for _, entry := range entries {
+		switch entry.Kind {
+		case models.CVEducation:
+			view.Education = append(view.Education, entry)
+		case models.CVExhibition:
+			view.Exhibitions = append(view.Exhibitions, entry)
+		case models.CVAward:
+			view.Awards = append(view.Awards, entry)
+		case models.CVRepresentation:
+			view.Representation = append(view.Representation, entry)
+		}
+	}
+	return view, nil
+}
+
+// IsVerified reports whether the user is a verified artist
+func IsVerified(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	var count int64
+	if err := models.VerifiedArtistIDs(db).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		return false, fmt.Errorf("failed to check artist verification: %w", err)
+	}
+	return count > 0, nil
+}
+
+// GetArtistProfile returns the authenticated user's artist profile
+// @Summary Get my artist profile
+// @Description Returns the authenticated user's artist statement, CV and verification status, with their verification requests newest first
+// @Tags Artists
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /account/artist-profile [get]
+func GetArtistProfile(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		profile, err := LoadProfile(db, user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if profile == nil {
+			profile = &ProfileView{
+				Education:      []models.ArtistCVEntry{},
+				Exhibitions:    []models.ArtistCVEntry{},
+				Awards:         []models.ArtistCVEntry{},
+				Representation: []models.ArtistCVEntry{},
+			}
+		}
+
+		var requests []models.ArtistVerificationRequest
+		if err := db.Preload("Documents").
+			Where("user_id = ?", user.ID).
+			Order("created_at DESC").
+			Find(&requests).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve verification requests: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"artist_profile":        profile,
+			"verification_requests": requests,
+		}, nil)
+	}
+}
+
+// UpdateArtistProfile sets the authenticated user's artist statement
+// @Summary Update my artist statement
+// @Description Sets the artist statement shown on the public profile
+// @Tags Artists
+// @Accept json
+// @Produce json
+// @Param body body UpdateArtistProfileRequest true "Artist statement"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /account/artist-profile [put]
+func UpdateArtistProfile(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req UpdateArtistProfileRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		statement := strings.TrimSpace(req.Statement)
+		if len(statement) > maxStatementLength {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest,
+					fmt.Sprintf("Artist statement cannot exceed %d characters", maxStatementLength)))
+		}
+
+		profile := models.ArtistProfile{UserID: user.ID, Statement: statement}
+		if err := db.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"statement", "updated_at"}),
+		}).Create(&profile).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to update artist profile: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Artist profile updated successfully",
+		}, nil)
+	}
+}
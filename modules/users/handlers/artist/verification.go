@@ -0,0 +1,376 @@
+package artist
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+	notifications "github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/utils"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	maxVerificationDocuments = 5
+	maxDocumentSize          = 10 << 20
+	defaultPageSize          = 20
+	maxPageSize              = 100
+)
+
+// ReviewRequest carries the reviewer's note to the artist
+type ReviewRequest struct {
+	Note string `json:"note" example:"Thanks, your gallery confirmed your representation"`
+}
+
+// VerificationRequestView is a verification request as shown to reviewers
+type VerificationRequestView struct {
+	models.ArtistVerificationRequest
+	Username string `json:"username"`
+}
+
+// validateDocument accepts images and PDFs up to maxDocumentSize
+func validateDocument(file *multipart.FileHeader) error {
+	if file.Size > maxDocumentSize {
+		return fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("%s is too large, maximum size is 10MB", file.Filename))
+	}
+	contentType := file.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, "image/") && contentType != "application/pdf" {
+		return fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("%s must be an image or a PDF", file.Filename))
+	}
+	return nil
+}
+
+// SubmitVerificationRequest asks for the authenticated user to be verified
+// @Summary Request artist verification
+// @Description Submits documents proving the authenticated user is a working artist, such as exhibition catalogues, press coverage or a gallery letter. Moderators review the request; only one can be pending at a time.
+// @Tags Artists
+// @Accept multipart/form-data
+// @Produce json
+// @Param message formData string false "Context for the reviewer"
+// @Param documents formData file true "Supporting documents, images or PDFs (up to 5, 10MB each)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /account/artist-profile/verification [post]
+func SubmitVerificationRequest(db *gorm.DB, cld *config.CloudinaryClient, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		form, err := c.MultipartForm()
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid form data"))
+		}
+		files := form.File["documents"]
+		if len(files) == 0 || len(files) > maxVerificationDocuments {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest,
+					fmt.Sprintf("Attach between 1 and %d documents", maxVerificationDocuments)))
+		}
+		for _, file := range files {
+			if err := validateDocument(file); err != nil {
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+		}
+
+		verified, err := IsVerified(db, user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if verified {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, "You are already a verified artist"))
+		}
+		var pending int64
+		if err := db.Model(&models.ArtistVerificationRequest{}).
+			Where("user_id = ? AND status = ?", user.ID, models.VerificationPending).
+			Count(&pending).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to check pending requests: %w", err))
+		}
+		if pending > 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusConflict, "You already have a verification request awaiting review"))
+		}
+
+		request := models.ArtistVerificationRequest{
+			ID:      uuid.New(),
+			UserID:  user.ID,
+			Status:  models.VerificationPending,
+			Message: strings.TrimSpace(c.FormValue("message")),
+		}
+		for _, file := range files {
+			fileURL, err := cld.UploadFile(file, fmt.Sprintf("artist-verifications/%s", request.ID))
+			if err != nil {
+				log.Printf("Cloudinary upload failed: %v", err)
+				deleteDocuments(cld, request.Documents)
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusInternalServerError, "Failed to upload documents"))
+			}
+			request.Documents = append(request.Documents, models.ArtistVerificationDocument{
+				RequestID: request.ID,
+				FileURL:   fileURL,
+				FileName:  file.Filename,
+			})
+		}
+
+		if err := db.Create(&request).Error; err != nil {
+			deleteDocuments(cld, request.Documents)
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to create verification request: %w", err))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Verification request submitted for review",
+			"request": request,
+		}, nil)
+	}
+}
+
+// deleteDocuments removes uploaded documents that were never saved
+func deleteDocuments(cld *config.CloudinaryClient, documents []models.ArtistVerificationDocument) {
+	for _, document := range documents {
+		if err := cld.DeleteFile(document.FileURL); err != nil {
+			log.Printf("Failed to delete orphaned verification document %s: %v", document.FileURL, err)
+		}
+	}
+}
+
+// ListVerificationRequests lists artist verification requests for review
+// @Summary List artist verification requests
+// @Description Lists verification requests with their documents, oldest first so the queue is worked in order. Requires the admin role.
+// @Tags Artists
+// @Produce json
+// @Param status query string false "pending (default), approved or rejected"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (default 20, max 100)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/artist-verifications [get]
+func ListVerificationRequests(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		status := c.Query("status", models.VerificationPending)
+		switch status {
+		case models.VerificationPending, models.VerificationApproved, models.VerificationRejected:
+		default:
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid status. Must be pending, approved or rejected"))
+		}
+
+		limit := c.QueryInt("limit", defaultPageSize)
+		if limit < 1 || limit > maxPageSize {
+			limit = defaultPageSize
+		}
+
+		query := db.Preload("Documents").Preload("User").Where("status = ?", status)
+		if cursor := c.Query("cursor"); cursor != "" {
+			at, id, err := utils.DecodeCursor(cursor)
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid cursor"))
+			}
+			query = query.Where("created_at > ? OR (created_at = ? AND id > ?)", at, at, id)
+		}
+
+		var requests []models.ArtistVerificationRequest
+		if err := query.Order("created_at ASC, id ASC").Limit(limit + 1).Find(&requests).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to list verification requests: %w", err))
+		}
+
+		nextCursor := ""
+		if len(requests) > limit {
+			requests = requests[:limit]
+			last := requests[limit-1]
+			nextCursor = utils.EncodeCursor(last.CreatedAt, last.ID)
+		}
+
+		views := make([]VerificationRequestView, len(requests))
+		for i, request := range requests {
+			views[i] = VerificationRequestView{ArtistVerificationRequest: request, Username: request.User.Username}
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"requests":    views,
+			"next_cursor": nextCursor,
+		}, nil)
+	}
+}
+
+// ReviewVerificationRequest approves or rejects a verification request
+// @Summary Review an artist verification request
+// @Description Approving marks the artist as verified; rejecting requires a note explaining why. The artist is notified either way. Requires the admin role.
+// @Tags Artists
+// @Accept json
+// @Produce json
+// @Param id path string true "Verification request ID"
+// @Param action path string true "approve or reject"
+// @Param body body ReviewRequest false "Note to the artist"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/artist-verifications/{id}/{action} [put]
+func ReviewVerificationRequest(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		reviewer, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		var req ReviewRequest
+		if len(c.Body()) > 0 {
+			if err := c.BodyParser(&req); err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+			}
+		}
+		note := strings.TrimSpace(req.Note)
+
+		var status string
+		switch c.Params("action") {
+		case "approve":
+			status = models.VerificationApproved
+		case "reject":
+			status = models.VerificationRejected
+			if note == "" {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusBadRequest, "A note is required when rejecting a request"))
+			}
+		default:
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid action. Must be approve or reject"))
+		}
+
+		var request models.ArtistVerificationRequest
+		if err := db.Where("id = ?", c.Params("id")).First(&request).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "Verification request not found"))
+			}
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to retrieve verification request: %w", err))
+		}
+
+		now := time.Now()
+		err := db.Transaction(func(tx *gorm.DB) error {
+			// Another reviewer may have decided first
+			result := tx.Model(&models.ArtistVerificationRequest{}).
+				Where("id = ? AND status = ?", request.ID, models.VerificationPending).
+				Updates(map[string]interface{}{
+					"status":      status,
+					"reviewer_id": reviewer.ID,
+					"review_note": note,
+					"reviewed_at": now,
+				})
+			if result.Error != nil {
+				return fmt.Errorf("failed to update verification request: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return fiber.NewError(fiber.StatusConflict, "This request has already been reviewed")
+			}
+
+			message := "Your artist verification request was not approved: " + note
+			notificationType := "artist_verification_rejected"
+			if status == models.VerificationApproved {
+				profile := models.ArtistProfile{UserID: request.UserID, IsVerified: true, VerifiedAt: &now}
+				if err := tx.Clauses(clause.OnConflict{
+					Columns:   []clause.Column{{Name: "user_id"}},
+					DoUpdates: clause.AssignmentColumns([]string{"is_verified", "verified_at", "updated_at"}),
+				}).Create(&profile).Error; err != nil {
+					return fmt.Errorf("failed to verify artist: %w", err)
+				}
+				message = "You are now a verified artist"
+				notificationType = "artist_verification_approved"
+			}
+			return notifications.WriteNotification(tx, request.UserID, notificationType, message,
+				"artist_verification", request.ID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Verification request reviewed",
+			"status":  status,
+		}, nil)
+	}
+}
+
+// RevokeVerification removes an artist's verified badge
+// @Summary Revoke artist verification
+// @Description Removes the verified badge from an artist, for instance after fraudulent documents come to light. The note is sent to the artist. Requires the admin role.
+// @Tags Artists
+// @Accept json
+// @Produce json
+// @Param userId path string true "Artist's user ID"
+// @Param body body ReviewRequest true "Reason shown to the artist"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/artist-verifications/artists/{userId} [delete]
+func RevokeVerification(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var req ReviewRequest
+		if err := c.BodyParser(&req); err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid request body"))
+		}
+		note := strings.TrimSpace(req.Note)
+		if note == "" {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "A note is required when revoking verification"))
+		}
+
+		userID, err := uuid.Parse(c.Params("userId"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid user ID"))
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.ArtistProfile{}).
+				Where("user_id = ? AND is_verified = ?", userID, true).
+				Updates(map[string]interface{}{"is_verified": false, "verified_at": nil})
+			if result.Error != nil {
+				return fmt.Errorf("failed to revoke verification: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return fiber.NewError(fiber.StatusNotFound, "Verified artist not found")
+			}
+			return notifications.WriteNotification(tx, userID, "artist_verification_revoked",
+				"Your artist verification was revoked: "+note, "user", userID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Artist verification revoked",
+		}, nil)
+	}
+}
@@ -0,0 +1,212 @@
+package engagement
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/feed/timeline"
+	"github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/modules/trending/ranking"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/privacy"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+// FollowRequest is a pending follow request and the other user involved
+type FollowRequest struct {
+	privacy.UserView
+	RequestedAt time.Time `json:"requested_at"`
+}
+
+// GetIncomingFollowRequests godoc
+// @Summary List incoming follow requests
+// @Description Lists the pending requests to follow the authenticated user, newest first
+// @Tags Engagement
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /engagement/follow-requests/incoming [get]
+func GetIncomingFollowRequests(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		requests, err := listFollowRequests(c, db, "followers.follower_id", "followers.following_id = ?", user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"requests": requests,
+			"count":    len(requests),
+		}, nil)
+	}
+}
+
+// GetOutgoingFollowRequests godoc
+// @Summary List outgoing follow requests
+// @Description Lists the authenticated user's follow requests that are still waiting for approval, newest first
+// @Tags Engagement
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Router /engagement/follow-requests/outgoing [get]
+func GetOutgoingFollowRequests(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		requests, err := listFollowRequests(c, db, "followers.following_id", "followers.follower_id = ?", user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"requests": requests,
+			"count":    len(requests),
+		}, nil)
+	}
+}
+
+// listFollowRequests loads pending requests along with the user on the other
+// side of each one, joined through otherColumn
+func listFollowRequests(c *fiber.Ctx, db *gorm.DB, otherColumn, where string, userID uuid.UUID) ([]FollowRequest, error) {
+	var requests []FollowRequest
+	if err := db.Table("followers").
+		Select(`
+                users.id,
+                users.username,
+                user_details.first_name,
+                user_details.last_name,
+                user_details.profile_image,
+                followers.created_at as requested_at
+            `).
+		Joins("JOIN users ON "+otherColumn+" = users.id").
+		Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
+		Where(where+" AND followers.status = ? AND users.is_active = ?", userID, models.FollowStatusPending, true).
+		Order("followers.created_at DESC").
+		Scan(&requests).Error; err != nil {
+		return nil, fmt.Errorf("failed to list follow requests: %w", err)
+	}
+
+	// Contact details follow each listed user's privacy settings
+	policy, err := privacy.ForRequest(c, db)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uuid.UUID, len(requests))
+	for i := range requests {
+		ids[i] = requests[i].ID
+	}
+	if err := policy.Load(ids...); err != nil {
+		return nil, err
+	}
+	for i := range requests {
+		policy.Apply(&requests[i].UserView)
+	}
+	return requests, nil
+}
+
+// ApproveFollowRequest godoc
+// @Summary Approve a follow request
+// @Description Approves a pending request from the given user to follow the authenticated user
+// @Tags Engagement
+// @Produce json
+// @Param id path string true "ID of the user who sent the request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /engagement/follow-requests/{id}/approve [post]
+func ApproveFollowRequest(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		requesterID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format"))
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			result := tx.Model(&models.Follower{}).
+				Where("follower_id = ? AND following_id = ? AND status = ?", requesterID, user.ID, models.FollowStatusPending).
+				Updates(map[string]interface{}{
+					"status":      models.FollowStatusAccepted,
+					"accepted_at": time.Now(),
+				})
+			if result.Error != nil {
+				return fmt.Errorf("failed to approve follow request: %w", result.Error)
+			}
+			if result.RowsAffected == 0 {
+				return fiber.NewError(fiber.StatusNotFound, "Follow request not found")
+			}
+
+			return services.WriteNotificationFrom(tx, requesterID, user.ID, "follow_approved",
+				followNotificationMessage(db, user, "approved your follow request"), "user", user.ID)
+		})
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		timeline.Invalidate(c.Context(), requesterID)
+		go ranking.RecordFollow(context.Background(), user.ID)
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Follow request approved",
+		}, nil)
+	}
+}
+
+// DenyFollowRequest godoc
+// @Summary Deny a follow request
+// @Description Removes a pending request from the given user to follow the authenticated user. The requester is not notified.
+// @Tags Engagement
+// @Produce json
+// @Param id path string true "ID of the user who sent the request"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]interface{}
+// @Failure 404 {object} map[string]interface{}
+// @Router /engagement/follow-requests/{id}/deny [post]
+func DenyFollowRequest(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		requesterID, err := uuid.Parse(c.Params("id"))
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format"))
+		}
+
+		result := db.Where("follower_id = ? AND following_id = ? AND status = ?", requesterID, user.ID, models.FollowStatusPending).
+			Delete(&models.Follower{})
+		if result.Error != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to deny follow request: %w", result.Error))
+		}
+		if result.RowsAffected == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "Follow request not found"))
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Follow request denied",
+		}, nil)
+	}
+}
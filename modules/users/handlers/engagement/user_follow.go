@@ -1,14 +1,18 @@
 package engagement
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/feed/timeline"
 	"github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/modules/trending/ranking"
 	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/privacy"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"gorm.io/gorm"
 )
@@ -17,7 +21,7 @@ const MaxFollowsPerDay = 100
 
 // FollowUser godoc
 // @Summary Follow a user
-// @Description Allows an authenticated user to follow another user
+// @Description Allows an authenticated user to follow another user. Following a private account sends a follow request the owner must approve.
 // @Tags Engagement
 // @Accept json
 // @Produce json
@@ -50,6 +54,19 @@ func FollowUser(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Ha
 				fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format"))
 		}
 
+		// Deactivated accounts cannot be followed
+		var targetCount int64
+		if err := db.Model(&models.User{}).
+			Where("id = ? AND is_active = ?", followingUUID, true).
+			Count(&targetCount).Error; err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to find user: %w", err))
+		}
+		if targetCount == 0 {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusNotFound, "User not found"))
+		}
+
 		// Start database transaction
 		tx := db.Begin()
 		if tx.Error != nil {
@@ -84,20 +101,22 @@ func FollowUser(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Ha
 				fiber.NewError(fiber.StatusForbidden, "This user does not allow follow requests"))
 		}
 
-		// Check existing follow
-		var count int64
-		if err := tx.Model(&models.Follower{}).
-			Where("follower_id = ? AND following_id = ?", user.ID, followingUUID).
-			Count(&count).Error; err != nil {
+		// Check existing follow or pending request
+		var existing models.Follower
+		err = tx.Where("follower_id = ? AND following_id = ?", user.ID, followingUUID).First(&existing).Error
+		if err == nil {
 			tx.Rollback()
+			if existing.Status == models.FollowStatusPending {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusConflict, "You have already requested to follow this user"))
+			}
 			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("database error checking follow status: %w", err))
+				fiber.NewError(fiber.StatusConflict, "You already follow this user"))
 		}
-
-		if count > 0 {
+		if err != gorm.ErrRecordNotFound {
 			tx.Rollback()
 			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusConflict, "You already follow this user"))
+				fmt.Errorf("database error checking follow status: %w", err))
 		}
 
 		// Check daily limit
@@ -117,10 +136,17 @@ func FollowUser(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Ha
 				fiber.NewError(fiber.StatusTooManyRequests, "Daily follow limit reached"))
 		}
 
-		// Create follow relationship
+		// Follows of private accounts wait for the owner's approval
 		newFollow := models.Follower{
 			FollowerID:  user.ID,
 			FollowingID: followingUUID,
+			Status:      models.FollowStatusAccepted,
+		}
+		if privacySettings.IsPrivate {
+			newFollow.Status = models.FollowStatusPending
+		} else {
+			now := time.Now()
+			newFollow.AcceptedAt = &now
 		}
 		if err := tx.Create(&newFollow).Error; err != nil {
 			tx.Rollback()
@@ -128,53 +154,69 @@ func FollowUser(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Ha
 				fmt.Errorf("database error creating follow: %w", err))
 		}
 
+		if newFollow.Status == models.FollowStatusPending {
+			// The request notification is only delivered if the request is saved
+			if err := services.WriteNotificationFrom(tx, followingUUID, user.ID, "follow_request",
+				followNotificationMessage(db, user, "requested to follow you"), "user", user.ID); err != nil {
+				tx.Rollback()
+				return responseHandler.HandleResponse(c, nil, err)
+			}
+		}
+
 		// Commit transaction
 		if err := tx.Commit().Error; err != nil {
 			return responseHandler.HandleResponse(c, nil,
 				fmt.Errorf("failed to commit transaction: %w", err))
 		}
 
+		if newFollow.Status == models.FollowStatusPending {
+			return responseHandler.HandleResponse(c, fiber.Map{
+				"message": "Follow request sent",
+				"status":  newFollow.Status,
+			}, nil)
+		}
+
+		// The new follow's earlier activity belongs in the follower's feed
+		timeline.Invalidate(c.Context(), user.ID)
+		go ranking.RecordFollow(context.Background(), followingUUID)
+
 		// Send notification with profile photo in message (non-blocking)
 		go func() {
-			var profilePhoto string
-			if err := db.Model(&models.UserDetail{}).
-				Where("user_id = ?", user.ID).
-				Select("profile_image").
-				First(&profilePhoto).Error; err == nil {
-
-				// Include profile photo URL in message if available
-				notificationService := services.NewNotificationService(responseHandler)
-				_ = notificationService.EnqueueNotification(
-					followingUUID.String(),
-					user.ID.String(),
-					"follow",
-					fmt.Sprintf("%s|%s followed you", user.Username, profilePhoto),
-					"user",
-					followingUUID.String(),
-				)
-			} else {
-				// Fallback without photo if there's an error
-				notificationService := services.NewNotificationService(responseHandler)
-				_ = notificationService.EnqueueNotification(
-					followingUUID.String(),
-					user.ID.String(),
-					"follow",
-					fmt.Sprintf("%s followed you", user.Username), // Original format
-					"user",
-					followingUUID.String(),
-				)
-			}
+			notificationService := services.NewNotificationService(responseHandler)
+			_ = notificationService.EnqueueNotification(
+				followingUUID.String(),
+				user.ID.String(),
+				"follow",
+				followNotificationMessage(db, user, "followed you"),
+				"user",
+				followingUUID.String(),
+			)
 		}()
 
 		return responseHandler.HandleResponse(c, fiber.Map{
 			"message": "You followed this user",
+			"status":  newFollow.Status,
 		}, nil)
 	}
 }
 
+// followNotificationMessage formats a follow notification, including the
+// actor's profile photo when they have one
+func followNotificationMessage(db *gorm.DB, actor models.User, action string) string {
+	var profilePhoto string
+	if err := db.Model(&models.UserDetail{}).
+		Where("user_id = ?", actor.ID).
+		Select("profile_image").
+		First(&profilePhoto).Error; err != nil {
+		// Fallback without photo if there's an error
+		return fmt.Sprintf("%s %s", actor.Username, action)
+	}
+	return fmt.Sprintf("%s|%s %s", actor.Username, profilePhoto, action)
+}
+
 // UnfollowUser godoc
 // @Summary Unfollow a user
-// @Description Allows an authenticated user to unfollow another user
+// @Description Allows an authenticated user to unfollow another user, or to cancel a pending follow request
 // @Tags Engagement
 // @Accept json
 // @Produce json
@@ -206,21 +248,24 @@ func UnfollowUser(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.
 				fmt.Errorf("failed to start transaction: %w", tx.Error))
 		}
 
-		// Perform unfollow operation
-		result := tx.Where("follower_id = ? AND following_id = ?", user.ID, followingUUID).
-			Delete(&models.Follower{})
-
-		if result.Error != nil {
+		// Find the follow or pending request being removed
+		var existing models.Follower
+		if err := tx.Where("follower_id = ? AND following_id = ?", user.ID, followingUUID).
+			First(&existing).Error; err != nil {
 			tx.Rollback()
+			if err == gorm.ErrRecordNotFound {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "You were not following this user"))
+			}
 			return responseHandler.HandleResponse(c, nil,
-				fmt.Errorf("database error unfollowing user: %w", result.Error))
+				fmt.Errorf("database error unfollowing user: %w", err))
 		}
 
-		// Check if any rows were actually deleted
-		if result.RowsAffected == 0 {
+		// Perform unfollow operation
+		if err := tx.Delete(&existing).Error; err != nil {
 			tx.Rollback()
 			return responseHandler.HandleResponse(c, nil,
-				fiber.NewError(fiber.StatusNotFound, "You were not following this user"))
+				fmt.Errorf("database error unfollowing user: %w", err))
 		}
 
 		// Commit transaction
@@ -229,8 +274,12 @@ func UnfollowUser(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.
 				fmt.Errorf("failed to commit transaction: %w", err))
 		}
 
+		message := "You unfollowed this user"
+		if existing.Status == models.FollowStatusPending {
+			message = "Follow request cancelled"
+		}
 		return responseHandler.HandleResponse(c, fiber.Map{
-			"message": "You unfollowed this user",
+			"message": message,
 		}, nil)
 	}
 }
@@ -256,19 +305,11 @@ func GetUserFollowStatsForAuthUser(db *gorm.DB, responseHandler *handlers.Respon
 		// Get the user's UUID
 		userUUID := user.ID
 
-		type UserDetails struct {
-			ID           uuid.UUID `json:"id"`
-			Username     string    `json:"username"`
-			FirstName    string    `json:"first_name,omitempty"`
-			LastName     string    `json:"last_name,omitempty"`
-			ProfileImage string    `json:"profile_image,omitempty"`
-		}
-
 		var stats struct {
-			FollowerCount  int64         `json:"followers_count"`
-			FollowingCount int64         `json:"following_count"`
-			Followers      []UserDetails `json:"followers,omitempty"`
-			Followings     []UserDetails `json:"followings,omitempty"`
+			FollowerCount  int64              `json:"followers_count"`
+			FollowingCount int64              `json:"following_count"`
+			Followers      []privacy.UserView `json:"followers,omitempty"`
+			Followings     []privacy.UserView `json:"followings,omitempty"`
 		}
 
 		// Create separate database connections for parallel queries
@@ -286,7 +327,7 @@ func GetUserFollowStatsForAuthUser(db *gorm.DB, responseHandler *handlers.Respon
 		go func() {
 			defer wg.Done()
 			if err := dbFollowerCount.Model(&models.Follower{}).
-				Where("following_id = ?", userUUID).
+				Where("following_id = ? AND status = ?", userUUID, models.FollowStatusAccepted).
 				Count(&stats.FollowerCount).Error; err != nil {
 				errChan <- fmt.Errorf("follower count error: %w", err)
 			}
@@ -296,7 +337,7 @@ func GetUserFollowStatsForAuthUser(db *gorm.DB, responseHandler *handlers.Respon
 		go func() {
 			defer wg.Done()
 			if err := dbFollowingCount.Model(&models.Follower{}).
-				Where("follower_id = ?", userUUID).
+				Where("follower_id = ? AND status = ?", userUUID, models.FollowStatusAccepted).
 				Count(&stats.FollowingCount).Error; err != nil {
 				errChan <- fmt.Errorf("following count error: %w", err)
 			}
@@ -315,7 +356,7 @@ func GetUserFollowStatsForAuthUser(db *gorm.DB, responseHandler *handlers.Respon
                 `).
 				Joins("JOIN users ON followers.follower_id = users.id").
 				Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
-				Where("followers.following_id = ?", userUUID).
+				Where("followers.following_id = ? AND followers.status = ? AND users.is_active = ?", userUUID, models.FollowStatusAccepted, true).
 				Scan(&stats.Followers).Error; err != nil {
 				errChan <- fmt.Errorf("follower details error: %w", err)
 			}
@@ -334,7 +375,7 @@ func GetUserFollowStatsForAuthUser(db *gorm.DB, responseHandler *handlers.Respon
                 `).
 				Joins("JOIN users ON followers.following_id = users.id").
 				Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
-				Where("followers.follower_id = ?", userUUID).
+				Where("followers.follower_id = ? AND followers.status = ? AND users.is_active = ?", userUUID, models.FollowStatusAccepted, true).
 				Scan(&stats.Followings).Error; err != nil {
 				errChan <- fmt.Errorf("following details error: %w", err)
 			}
@@ -350,6 +391,18 @@ func GetUserFollowStatsForAuthUser(db *gorm.DB, responseHandler *handlers.Respon
 			}
 		}
 
+		// Contact details follow each listed user's privacy settings
+		policy, err := privacy.ForRequest(c, db)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if err := policy.ApplyAll(stats.Followers); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if err := policy.ApplyAll(stats.Followings); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
 		return responseHandler.HandleResponse(c, stats, nil)
 	}
 }
@@ -374,31 +427,33 @@ func GetUserFollowStats(db *gorm.DB, responseHandler *handlers.ResponseHandler)
 				fiber.NewError(fiber.StatusBadRequest, "Invalid user ID format"))
 		}
 
-		type UserDetails struct {
-			ID           uuid.UUID `json:"id"`
-			Username     string    `json:"username"`
-			FirstName    string    `json:"first_name,omitempty"`
-			LastName     string    `json:"last_name,omitempty"`
-			ProfileImage string    `json:"profile_image,omitempty"`
+		// Private accounts only list their followers to approved followers
+		policy, err := privacy.ForRequest(c, db)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if err := policy.Load(userUUID); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
 		}
+		showLists := policy.CanSeeContent(userUUID)
 
 		var stats struct {
-			FollowerCount  int64         `json:"followers_count"`
-			FollowingCount int64         `json:"following_count"`
-			Followers      []UserDetails `json:"followers,omitempty"`
-			Followings     []UserDetails `json:"followings,omitempty"`
+			FollowerCount  int64              `json:"followers_count"`
+			FollowingCount int64              `json:"following_count"`
+			Followers      []privacy.UserView `json:"followers,omitempty"`
+			Followings     []privacy.UserView `json:"followings,omitempty"`
 		}
 
 		// Use parallel execution for better performance
 		errChan := make(chan error, 4)
 		var wg sync.WaitGroup
-		wg.Add(4)
+		wg.Add(2)
 
 		// Get follower count
 		go func() {
 			defer wg.Done()
 			if err := db.Model(&models.Follower{}).
-				Where("following_id = ?", userUUID).
+				Where("following_id = ? AND status = ?", userUUID, models.FollowStatusAccepted).
 				Count(&stats.FollowerCount).Error; err != nil {
 				errChan <- fmt.Errorf("follower count error: %w", err)
 			}
@@ -408,49 +463,53 @@ func GetUserFollowStats(db *gorm.DB, responseHandler *handlers.ResponseHandler)
 		go func() {
 			defer wg.Done()
 			if err := db.Model(&models.Follower{}).
-				Where("follower_id = ?", userUUID).
+				Where("follower_id = ? AND status = ?", userUUID, models.FollowStatusAccepted).
 				Count(&stats.FollowingCount).Error; err != nil {
 				errChan <- fmt.Errorf("following count error: %w", err)
 			}
 		}()
 
-		// Get followers with details
-		go func() {
-			defer wg.Done()
-			if err := db.Table("followers").
-				Select(`
-                    users.id,
-                    users.username,
-                    user_details.first_name,
-                    user_details.last_name,
-                    user_details.profile_image
-                `).
-				Joins("JOIN users ON followers.follower_id = users.id").
-				Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
-				Where("followers.following_id = ?", userUUID).
-				Scan(&stats.Followers).Error; err != nil {
-				errChan <- fmt.Errorf("follower details error: %w", err)
-			}
-		}()
-
-		// Get followings with details
-		go func() {
-			defer wg.Done()
-			if err := db.Table("followers").
-				Select(`
-                    users.id,
-                    users.username,
-                    user_details.first_name,
-                    user_details.last_name,
-                    user_details.profile_image
-                `).
-				Joins("JOIN users ON followers.following_id = users.id").
-				Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
-				Where("followers.follower_id = ?", userUUID).
-				Scan(&stats.Followings).Error; err != nil {
-				errChan <- fmt.Errorf("following details error: %w", err)
-			}
-		}()
+		if showLists {
+			wg.Add(2)
+
+			// Get followers with details
+			go func() {
+				defer wg.Done()
+				if err := db.Table("followers").
+					Select(`
+	                    users.id,
+	                    users.username,
+	                    user_details.first_name,
+	                    user_details.last_name,
+	                    user_details.profile_image
+	                `).
+					Joins("JOIN users ON followers.follower_id = users.id").
+					Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
+					Where("followers.following_id = ? AND followers.status = ? AND users.is_active = ?", userUUID, models.FollowStatusAccepted, true).
+					Scan(&stats.Followers).Error; err != nil {
+					errChan <- fmt.Errorf("follower details error: %w", err)
+				}
+			}()
+
+			// Get followings with details
+			go func() {
+				defer wg.Done()
+				if err := db.Table("followers").
+					Select(`
+	                    users.id,
+	                    users.username,
+	                    user_details.first_name,
+	                    user_details.last_name,
+	                    user_details.profile_image
+	                `).
+					Joins("JOIN users ON followers.following_id = users.id").
+					Joins("LEFT JOIN user_details ON users.id = user_details.user_id").
+					Where("followers.follower_id = ? AND followers.status = ? AND users.is_active = ?", userUUID, models.FollowStatusAccepted, true).
+					Scan(&stats.Followings).Error; err != nil {
+					errChan <- fmt.Errorf("following details error: %w", err)
+				}
+			}()
+		}
 
 		wg.Wait()
 		close(errChan)
@@ -462,6 +521,14 @@ func GetUserFollowStats(db *gorm.DB, responseHandler *handlers.ResponseHandler)
 			}
 		}
 
+		// Contact details follow each listed user's privacy settings
+		if err := policy.ApplyAll(stats.Followers); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if err := policy.ApplyAll(stats.Followings); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
 		return responseHandler.HandleResponse(c, stats, nil)
 	}
 }
@@ -3,19 +3,22 @@ package public
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/handlers/artist"
 	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/privacy"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"gorm.io/gorm"
 )
 
 // GetUserProfileHandler returns a user's profile information
 // @Summary Get user profile
-// @Description Retrieves public profile information for a user by username, email, or phone number. Checks for blocked status and profile visibility.
+// @Description Retrieves public profile information for a user by username, email, or phone number. Checks for blocked status and profile visibility. Email, phone and location are only returned, and only usable for lookup, where the user's privacy settings allow it. Artists' statements and CVs are included with the profile, and the verified artist badge is always shown.
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -45,14 +48,17 @@ func GetUserProfileHandler(db *gorm.DB, responseHandler *handlers.ResponseHandle
 
 		// First get the target user with active/deleted checks
 		var targetUser struct {
-			ID       string `gorm:"type:char(36)"`
-			IsActive bool
-			Deleted  bool
+			ID          string `gorm:"type:char(36)"`
+			Username    string
+			Email       string
+			PhoneNumber string
+			IsActive    bool
+			Deleted     bool
 		}
 
 		// Fix email search by using case-insensitive comparison
 		if err := db.Table("users").
-			Select("id, is_active, deleted_at IS NOT NULL as deleted").
+			Select("id, username, email, phone_number, is_active, deleted_at IS NOT NULL as deleted").
 			Where("(LOWER(username) = LOWER(?) OR LOWER(email) = LOWER(?) OR phone_number = ?) AND is_active = true AND deleted_at IS NULL",
 				identifier, identifier, identifier).
 			Scan(&targetUser).Error; err != nil {
@@ -96,6 +102,23 @@ func GetUserProfileHandler(db *gorm.DB, responseHandler *handlers.ResponseHandle
 				fiber.NewError(fiber.StatusNotFound, "User not found"))
 		}
 
+		// Contact details are only shown, or usable for lookup, where the
+		// owner's privacy settings allow it
+		policy, err := privacy.NewPolicy(db, requester.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if err := policy.Load(targetUserID); err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+		if !strings.EqualFold(identifier, targetUser.Username) {
+			byEmail := strings.EqualFold(identifier, targetUser.Email)
+			if (byEmail && !policy.CanSeeEmail(targetUserID)) || (!byEmail && !policy.CanSeePhone(targetUserID)) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusNotFound, "User not found"))
+			}
+		}
+
 		// Get profile visibility and basic info with active/deleted check
 		var profile struct {
 			User struct {
@@ -121,14 +144,37 @@ func GetUserProfileHandler(db *gorm.DB, responseHandler *handlers.ResponseHandle
 				fmt.Errorf("failed to get user profile: %w", err))
 		}
 
+		// The verified badge is shown even on private profiles, so collectors
+		// can tell a real artist from an impersonator
+		isVerifiedArtist, err := artist.IsVerified(db, profile.User.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil, err)
+		}
+
+		view := privacy.UserView{ID: profile.User.ID, Username: profile.User.Username}
+		policy.Apply(&view)
+		userData := fiber.Map{
+			"id":                 view.ID,
+			"username":           view.Username,
+			"member_since":       profile.User.MemberSince,
+			"relationship":       policy.Relationship(view.ID).String(),
+			"is_private":         policy.IsPrivate(view.ID),
+			"is_verified_artist": isVerifiedArtist,
+		}
+		if view.Email != "" {
+			userData["email"] = view.Email
+		}
+		if view.PhoneNumber != "" {
+			userData["phone_number"] = view.PhoneNumber
+		}
+		if view.Location != nil {
+			userData["location"] = view.Location
+		}
+
 		// If profile isn't public and requester isn't the owner
 		if !profile.User.IsProfilePublic && profile.User.ID != requester.ID {
 			return responseHandler.HandleResponse(c, fiber.Map{
-				"user": fiber.Map{
-					"id":           profile.User.ID,
-					"username":     profile.User.Username,
-					"member_since": profile.User.MemberSince,
-				},
+				"user":    userData,
 				"message": "Profile is private",
 			}, nil)
 		}
@@ -150,10 +196,11 @@ func GetUserProfileHandler(db *gorm.DB, responseHandler *handlers.ResponseHandle
 			} `json:"stats"`
 		}
 
-		// Get user details in parallel with stats
-		errChan := make(chan error, 2)
+		// Get user details in parallel with stats and the artist profile
+		var artistProfile *artist.ProfileView
+		errChan := make(chan error, 3)
 		var wg sync.WaitGroup
-		wg.Add(2)
+		wg.Add(3)
 
 		go func() {
 			defer wg.Done()
@@ -173,9 +220,9 @@ func GetUserProfileHandler(db *gorm.DB, responseHandler *handlers.ResponseHandle
 			}
 			if err := db.Raw(`
                 SELECT 
-                    (SELECT COUNT(*) FROM followers WHERE following_id = ?) as followers,
-                    (SELECT COUNT(*) FROM followers WHERE follower_id = ?) as following
-            `, profile.User.ID, profile.User.ID).Scan(&counts).Error; err != nil {
+                    (SELECT COUNT(*) FROM followers WHERE following_id = ? AND status = ?) as followers,
+                    (SELECT COUNT(*) FROM followers WHERE follower_id = ? AND status = ?) as following
+            `, profile.User.ID, models.FollowStatusAccepted, profile.User.ID, models.FollowStatusAccepted).Scan(&counts).Error; err != nil {
 				errChan <- fmt.Errorf("failed to get follower stats: %w", err)
 				return
 			}
@@ -183,6 +230,14 @@ func GetUserProfileHandler(db *gorm.DB, responseHandler *handlers.ResponseHandle
 			extended.Stats.Following = counts.Following
 		}()
 
+		go func() {
+			defer wg.Done()
+			var err error
+			if artistProfile, err = artist.LoadProfile(db, profile.User.ID); err != nil {
+				errChan <- err
+			}
+		}()
+
 		wg.Wait()
 		close(errChan)
 
@@ -194,14 +249,13 @@ func GetUserProfileHandler(db *gorm.DB, responseHandler *handlers.ResponseHandle
 
 		// Combine all profile data
 		fullProfile := fiber.Map{
-			"user": fiber.Map{
-				"id":           profile.User.ID,
-				"username":     profile.User.Username,
-				"member_since": profile.User.MemberSince,
-			},
+			"user":    userData,
 			"details": extended.Details,
 			"stats":   extended.Stats,
 		}
+		if artistProfile != nil {
+			fullProfile["artist"] = artistProfile
+		}
 
 		return responseHandler.HandleResponse(c, fullProfile, nil)
 	}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Kinds of entry stored in ArtistCVEntry.Kind
+const (
+	CVEducation      = "education"
+	CVExhibition     = "exhibition"
+	CVAward          = "award"
+	CVRepresentation = "representation"
+)
+
+// ArtistProfile extends a user's details with what collectors look for in
+// an artist. Verification is only ever granted through an approved
+// ArtistVerificationRequest.
+type ArtistProfile struct {
+	ID         uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:char(36);not null;uniqueIndex" json:"user_id"`
+	Statement  string     `gorm:"type:text" json:"statement"`
+	IsVerified bool       `gorm:"type:boolean;not null;default:false;index" json:"is_verified"`
+	VerifiedAt *time.Time `gorm:"type:timestamp" json:"verified_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Foreign key relation
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ArtistCVEntry is one line of an artist's CV: a degree, an exhibition, an
+// award or a gallery representing them. Title is the degree, show, award or
+// gallery name and Institution the school, venue or awarding body.
+type ArtistCVEntry struct {
+	ID          uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	UserID      uuid.UUID `gorm:"type:char(36);not null;index:idx_artist_cv_kind,priority:1" json:"user_id"`
+	Kind        string    `gorm:"type:enum('education','exhibition','award','representation');not null;index:idx_artist_cv_kind,priority:2" json:"kind"`
+	Title       string    `gorm:"type:varchar(255);not null" json:"title"`
+	Institution string    `gorm:"type:varchar(255)" json:"institution,omitempty"`
+	Location    string    `gorm:"type:varchar(255)" json:"location,omitempty"`
+	StartYear   *int      `gorm:"type:smallint" json:"start_year,omitempty"`
+	EndYear     *int      `gorm:"type:smallint" json:"end_year,omitempty"` // Empty for ongoing representation or study
+	URL         string    `gorm:"type:varchar(255)" json:"url,omitempty"`
+	CreatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Foreign key relation
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (p *ArtistProfile) BeforeCreate(tx *gorm.DB) (err error) {
+	if p.ID == uuid.Nil {
+		p.ID = uuid.New()
+	}
+	return
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (e *ArtistCVEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return
+}
+
+// ValidCVKind reports whether kind is a known CV entry kind
+func ValidCVKind(kind string) bool {
+	switch kind {
+	case CVEducation, CVExhibition, CVAward, CVRepresentation:
+		return true
+	}
+	return false
+}
+
+// VerifiedArtistIDs selects the IDs of verified artists, for use as a subquery
+func VerifiedArtistIDs(db *gorm.DB) *gorm.DB {
+	return db.Model(&ArtistProfile{}).Select("user_id").Where("is_verified = ?", true)
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Verification request statuses stored in ArtistVerificationRequest.Status
+const (
+	VerificationPending  = "pending"
+	VerificationApproved = "approved"
+	VerificationRejected = "rejected"
+)
+
+// ArtistVerificationRequest asks the moderators to mark an artist as
+// verified, with documents backing the claim
+type ArtistVerificationRequest struct {
+	ID         uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:char(36);not null;index" json:"user_id"`
+	Status     string     `gorm:"type:enum('pending','approved','rejected');not null;default:'pending';index" json:"status"`
+	Message    string     `gorm:"type:text" json:"message"` // What the artist wants the reviewer to know
+	ReviewerID *uuid.UUID `gorm:"type:char(36)" json:"reviewer_id,omitempty"`
+	ReviewNote string     `gorm:"type:text" json:"review_note,omitempty"` // Shown to the artist, required on rejection
+	ReviewedAt *time.Time `gorm:"type:timestamp" json:"reviewed_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+
+	Documents []ArtistVerificationDocument `gorm:"foreignKey:RequestID;constraint:OnDelete:CASCADE" json:"documents"`
+
+	// Foreign key relation
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// ArtistVerificationDocument is a file supporting a verification request,
+// such as a catalogue page, press coverage or a gallery letter
+type ArtistVerificationDocument struct {
+	ID        uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	RequestID uuid.UUID `gorm:"type:char(36);not null;index" json:"request_id"`
+	FileURL   string    `gorm:"type:varchar(500);not null" json:"file_url"`
+	FileName  string    `gorm:"type:varchar(255)" json:"file_name"`
+	CreatedAt time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (r *ArtistVerificationRequest) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (d *ArtistVerificationDocument) BeforeCreate(tx *gorm.DB) (err error) {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	return
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Data export statuses stored in DataExport.Status
+const (
+	DataExportStatusPending    = "pending"
+	DataExportStatusProcessing = "processing"
+	DataExportStatusReady      = "ready"
+	DataExportStatusFailed     = "failed"
+	DataExportStatusExpired    = "expired"
+)
+
+// DataExport tracks a "download my data" request and the archive it produced
+type DataExport struct {
+	ID                uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	UserID            uuid.UUID  `gorm:"type:char(36);not null;index" json:"user_id"`
+	Status            string     `gorm:"type:varchar(20);not null;index" json:"status"`
+	FilePath          string     `gorm:"type:varchar(500)" json:"-"`
+	FileSize          int64      `gorm:"type:bigint;not null;default:0" json:"file_size"`
+	DownloadTokenHash *string    `gorm:"type:char(64);index" json:"-"` // SHA-256 of the emailed token
+	Error             string     `gorm:"type:text" json:"-"`
+	CompletedAt       *time.Time `gorm:"type:timestamp" json:"completed_at,omitempty"`
+	ExpiresAt         *time.Time `gorm:"type:timestamp;index" json:"expires_at,omitempty"`
+	CreatedAt         time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt         time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Foreign key relation
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (e *DataExport) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	return
+}
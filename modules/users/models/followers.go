@@ -7,11 +7,20 @@ import (
 	"gorm.io/gorm"
 )
 
+// Follow statuses stored in Follower.Status. Following a private account
+// creates a pending request that the account owner approves.
+const (
+	FollowStatusPending  = "pending"
+	FollowStatusAccepted = "accepted"
+)
+
 type Follower struct {
-	ID          uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
-	FollowerID  uuid.UUID `gorm:"type:char(36);not null;index" json:"follower_id"`
-	FollowingID uuid.UUID `gorm:"type:char(36);not null;index" json:"following_id"`
-	CreatedAt   time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	ID          uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	FollowerID  uuid.UUID  `gorm:"type:char(36);not null;index" json:"follower_id"`
+	FollowingID uuid.UUID  `gorm:"type:char(36);not null;index" json:"following_id"`
+	Status      string     `gorm:"type:varchar(20);not null;default:'accepted';index" json:"status"`
+	AcceptedAt  *time.Time `gorm:"type:timestamp" json:"accepted_at,omitempty"`
+	CreatedAt   time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
 
 	// Foreign key relations
 	FollowerUser  User `gorm:"foreignKey:FollowerID;constraint:OnDelete:CASCADE"`
@@ -23,5 +32,29 @@ func (f *Follower) BeforeCreate(tx *gorm.DB) (err error) {
 	if f.ID == uuid.Nil {
 		f.ID = uuid.New()
 	}
+	if f.Status == "" {
+		f.Status = FollowStatusAccepted
+	}
 	return
 }
+
+// IsApprovedFollower reports whether followerID follows followingID with an
+// accepted follow
+func IsApprovedFollower(db *gorm.DB, followerID, followingID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&Follower{}).
+		Where("follower_id = ? AND following_id = ? AND status = ?", followerID, followingID, FollowStatusAccepted).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// AcceptPendingFollowRequests approves every pending request to follow the
+// user, used when a private account becomes public
+func AcceptPendingFollowRequests(tx *gorm.DB, userID uuid.UUID) error {
+	return tx.Model(&Follower{}).
+		Where("following_id = ? AND status = ?", userID, FollowStatusPending).
+		Updates(map[string]interface{}{
+			"status":      FollowStatusAccepted,
+			"accepted_at": time.Now(),
+		}).Error
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// PersonalAccessToken lets integrations call the API on a user's behalf with
+// a limited set of scopes. Only a hash of the token is stored.
+type PersonalAccessToken struct {
+	ID         uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	UserID     uuid.UUID  `gorm:"type:char(36);not null;index" json:"user_id"`
+	Name       string     `gorm:"type:varchar(100);not null" json:"name"`
+	TokenHash  string     `gorm:"type:char(64);not null;uniqueIndex" json:"-"`
+	Prefix     string     `gorm:"type:varchar(16);not null" json:"prefix"` // Shown so users can tell tokens apart
+	Scopes     string     `gorm:"type:varchar(500);not null" json:"-"`     // Space separated
+	ExpiresAt  *time.Time `gorm:"type:timestamp" json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `gorm:"type:timestamp" json:"last_used_at,omitempty"`
+	LastUsedIP string     `gorm:"type:varchar(45)" json:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time `gorm:"type:timestamp" json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+
+	// Foreign key relation
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (t *PersonalAccessToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return
+}
+
+// ScopeList returns the scopes granted to the token
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
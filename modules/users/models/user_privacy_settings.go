@@ -5,6 +5,14 @@ import (
 	"gorm.io/gorm"
 )
 
+// Values of UserPrivacySetting.AllowMessagesFrom
+const (
+	MessagesFromEveryone  = "everyone"
+	MessagesFromFollowing = "following" // Users the account follows
+	MessagesFromFollowers = "followers" // The account's approved followers
+	MessagesFromNone      = "none"
+)
+
 // UserPrivacySetting defines the user's privacy settings
 type UserPrivacySetting struct {
 	ID                  uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
@@ -13,6 +21,7 @@ type UserPrivacySetting struct {
 	ShowPhone           bool      `gorm:"type:boolean;not null;default:false" json:"show_phone"`
 	ShowLocation        bool      `gorm:"type:boolean;not null;default:false" json:"show_location"`
 	AllowFollowRequests bool      `gorm:"type:boolean;not null;default:true" json:"allow_follow_requests"`
+	IsPrivate           bool      `gorm:"type:boolean;not null;default:false" json:"is_private"` // Follows need approval and content is limited to approved followers
 	AllowMessagesFrom   string    `gorm:"type:varchar(20);not null;default:'everyone'" json:"allow_messages_from"`
 
 	// Foreign key relation
@@ -40,9 +49,19 @@ func CreateDefaultPrivacySetting(db *gorm.DB, userID uuid.UUID) error {
 			ShowPhone:           false,
 			ShowLocation:        false,
 			AllowFollowRequests: true,
+			IsPrivate:           false,
 			AllowMessagesFrom:   "everyone",
 		}
 		return db.Create(&setting).Error
 	}
 	return err
 }
+
+// IsPrivateAccount reports whether the user has switched on private account mode
+func IsPrivateAccount(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&UserPrivacySetting{}).
+		Where("user_id = ? AND is_private = ?", userID, true).
+		Count(&count).Error
+	return count > 0, err
+}
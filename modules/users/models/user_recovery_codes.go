@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserRecoveryCode is a single-use two-factor backup code, stored hashed
+type UserRecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	UserID    uuid.UUID  `gorm:"type:char(36);not null;index" json:"user_id"`
+	CodeHash  string     `gorm:"type:char(64);not null;index" json:"-"`
+	UsedAt    *time.Time `gorm:"type:timestamp" json:"used_at,omitempty"`
+	CreatedAt time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+
+	// Foreign key relation
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// BeforeCreate hook to generate UUID if not set
+func (r *UserRecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return
+}
@@ -23,3 +23,17 @@ func (ur *UserRole) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// AdminRoleName is the role that grants access to moderation and operations endpoints
+const AdminRoleName = "admin"
+
+// UserHasRole reports whether the user holds an active assignment of an active role
+func UserHasRole(db *gorm.DB, userID uuid.UUID, roleName string) (bool, error) {
+	var count int64
+	err := db.Model(&UserRole{}).
+		Joins("JOIN roles ON roles.id = user_roles.role_id").
+		Where("user_roles.user_id = ? AND user_roles.is_active = ? AND roles.is_active = ? AND roles.role_name = ?",
+			userID, true, true, roleName).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -8,21 +8,27 @@ import (
 )
 
 type UserSecurity struct {
-	ID                     uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
-	UserID                 uuid.UUID  `gorm:"type:char(36);not null;index" json:"user_id"`
-	Password               string     `gorm:"type:varchar(255);not null" json:"-"`
-	IsEmailVerifiedAt      *time.Time `gorm:"type:timestamp" json:"is_email_verified_at,omitempty"`
-	LoginAttempts          int        `gorm:"type:int;not null;default:0" json:"login_attempts"`
-	LastLoginAttemptAt     *time.Time `gorm:"type:timestamp" json:"last_login_attempt_at,omitempty"`
-	IsLocked               bool       `gorm:"type:boolean;not null;default:false" json:"is_locked"`
-	LockedAt               *time.Time `gorm:"type:timestamp" json:"locked_at,omitempty"`
-	UnlockToken            *string    `gorm:"type:varchar(255)" json:"unlock_token,omitempty"`
-	UnlockTokenExpiresAt   *time.Time `gorm:"type:timestamp" json:"unlock_token_expires_at,omitempty"`
-	LastSuccessfulLoginAt  *time.Time `gorm:"type:timestamp" json:"last_successful_login_at,omitempty"`
-	PasswordResetToken     *string    `gorm:"type:varchar(255)" json:"-"`
-	PasswordResetExpiresAt *time.Time `gorm:"type:timestamp" json:"password_reset_expires_at,omitempty"`
-	CreatedAt              time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt              time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
+	ID                         uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	UserID                     uuid.UUID  `gorm:"type:char(36);not null;index" json:"user_id"`
+	Password                   string     `gorm:"type:varchar(255);not null" json:"-"`
+	IsEmailVerifiedAt          *time.Time `gorm:"type:timestamp" json:"is_email_verified_at,omitempty"`
+	LoginAttempts              int        `gorm:"type:int;not null;default:0" json:"login_attempts"`
+	LastLoginAttemptAt         *time.Time `gorm:"type:timestamp" json:"last_login_attempt_at,omitempty"`
+	IsLocked                   bool       `gorm:"type:boolean;not null;default:false" json:"is_locked"`
+	LockedAt                   *time.Time `gorm:"type:timestamp" json:"locked_at,omitempty"`
+	UnlockToken                *string    `gorm:"type:varchar(255);index" json:"-"` // SHA-256 of the emailed token
+	UnlockTokenExpiresAt       *time.Time `gorm:"type:timestamp" json:"unlock_token_expires_at,omitempty"`
+	LastSuccessfulLoginAt      *time.Time `gorm:"type:timestamp" json:"last_successful_login_at,omitempty"`
+	PasswordResetToken         *string    `gorm:"type:varchar(255)" json:"-"`
+	PasswordResetExpiresAt     *time.Time `gorm:"type:timestamp" json:"password_reset_expires_at,omitempty"`
+	EmailVerificationToken     *string    `gorm:"type:varchar(255);index" json:"-"`
+	EmailVerificationExpiresAt *time.Time `gorm:"type:timestamp" json:"email_verification_expires_at,omitempty"`
+	TwoFactorEnabled           bool       `gorm:"type:boolean;not null;default:false" json:"two_factor_enabled"`
+	TwoFactorSecret            *string    `gorm:"type:varchar(255)" json:"-"` // AES-GCM encrypted, see pkg/secrets
+	TwoFactorEnabledAt         *time.Time `gorm:"type:timestamp" json:"two_factor_enabled_at,omitempty"`
+	TwoFactorLastUsedStep      int64      `gorm:"type:bigint;not null;default:0" json:"-"`
+	CreatedAt                  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt                  time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP" json:"updated_at"`
 
 	// Foreign key relation
 	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
@@ -35,3 +41,18 @@ func (u *UserSecurity) BeforeCreate(tx *gorm.DB) (err error) {
 	}
 	return
 }
+
+// HasPassword reports whether the user has set a password. Accounts created
+// through a social login have none until the user sets one.
+func (u *UserSecurity) HasPassword() bool {
+	return u.Password != ""
+}
+
+// IsEmailVerified reports whether the user has confirmed their current email address
+func IsEmailVerified(db *gorm.DB, userID uuid.UUID) (bool, error) {
+	var count int64
+	err := db.Model(&UserSecurity{}).
+		Where("user_id = ? AND is_email_verified_at IS NOT NULL", userID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -7,17 +7,25 @@ import (
 	"gorm.io/gorm"
 )
 
+// UserSession is one refresh token in a device session. Each refresh rotates
+// the token into a new row of the same family; a family is what the user sees
+// as a signed-in device and what gets revoked as a whole.
 type UserSession struct {
-	ID           uuid.UUID `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
-	UserID       uuid.UUID `gorm:"type:char(36);not null;index" json:"user_id"`
-	SessionToken string    `gorm:"type:varchar(255);not null" json:"session_token"`
-	DeviceInfo   string    `gorm:"type:varchar(255)" json:"device_info"` // e.g., "iPhone 14, iOS 16"
-	IPAddress    string    `gorm:"type:varchar(45)" json:"ip_address"`
-	CreatedAt    time.Time `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
-	ExpiresAt    time.Time `gorm:"type:timestamp;not null" json:"expires_at"`
+	ID               uuid.UUID  `gorm:"type:char(36);primaryKey;default:(UUID())" json:"id"`
+	UserID           uuid.UUID  `gorm:"type:char(36);not null;index" json:"user_id"`
+	FamilyID         uuid.UUID  `gorm:"type:char(36);not null;index" json:"family_id"`
+	RefreshTokenHash string     `gorm:"column:session_token;type:varchar(255);not null;index" json:"-"` // SHA-256 of the refresh token
+	DeviceInfo       string     `gorm:"type:varchar(255)" json:"device_info"`                           // e.g., "iPhone 14, iOS 16"
+	IPAddress        string     `gorm:"type:varchar(45)" json:"ip_address"`
+	StartedAt        time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"started_at"`
+	LastSeenAt       *time.Time `gorm:"type:timestamp" json:"last_seen_at,omitempty"`
+	RotatedAt        *time.Time `gorm:"type:timestamp" json:"rotated_at,omitempty"`
+	RevokedAt        *time.Time `gorm:"type:timestamp;index" json:"revoked_at,omitempty"`
+	CreatedAt        time.Time  `gorm:"type:timestamp;default:CURRENT_TIMESTAMP" json:"created_at"`
+	ExpiresAt        time.Time  `gorm:"type:timestamp;not null" json:"expires_at"`
 
 	// Foreign key relation
-	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
 }
 
 // BeforeCreate hook to generate UUID if not set
@@ -0,0 +1,278 @@
+// Package privacy decides which of a user's contact details another user may
+// see. Every response that carries user data builds a UserView and passes it
+// through a Policy, which fills in exactly the email, phone number and
+// location the viewer is allowed to see and clears everything else. The
+// policy also decides whether the viewer may see a private account's
+// content.
+package privacy
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"gorm.io/gorm"
+)
+
+// Relationship is how the viewer relates to the user being shown
+type Relationship int
+
+const (
+	// Stranger sees a contact field only if the owner shows it and the profile is public
+	Stranger Relationship = iota
+	// Follower is an approved follower and sees every contact field the owner
+	// chooses to show
+	Follower
+	// Self and Admin always see everything
+	Self
+	Admin
+)
+
+// String returns the relationship name used in responses
+func (r Relationship) String() string {
+	switch r {
+	case Follower:
+		return "follower"
+	case Self:
+		return "self"
+	case Admin:
+		return "admin"
+	default:
+		return "stranger"
+	}
+}
+
+// Location is the part of a user's location shared with others
+type Location struct {
+	City    string `json:"city,omitempty"`
+	State   string `json:"state,omitempty"`
+	Country string `json:"country,omitempty"`
+}
+
+// UserView is the user data a response may carry
+type UserView struct {
+	ID           uuid.UUID `json:"id"`
+	Username     string    `json:"username"`
+	FirstName    string    `json:"first_name,omitempty"`
+	LastName     string    `json:"last_name,omitempty"`
+	ProfileImage string    `json:"profile_image,omitempty"`
+	Email        string    `json:"email,omitempty"`
+	PhoneNumber  string    `json:"phone_number,omitempty"`
+	Location     *Location `gorm:"-" json:"location,omitempty"`
+}
+
+// subject is everything the policy knows about one user being shown
+type subject struct {
+	settings      models.UserPrivacySetting
+	profilePublic bool
+	followed      bool
+	email         string
+	phoneNumber   string
+	location      *Location
+}
+
+// Policy applies privacy settings for one viewer. Load the users a response
+// mentions once, then Apply each view before serializing it.
+type Policy struct {
+	db       *gorm.DB
+	viewerID uuid.UUID
+	isAdmin  bool
+	subjects map[uuid.UUID]*subject
+}
+
+// NewPolicy creates a policy for the viewer; uuid.Nil means an anonymous viewer
+func NewPolicy(db *gorm.DB, viewerID uuid.UUID) (*Policy, error) {
+	policy := &Policy{db: db, viewerID: viewerID, subjects: make(map[uuid.UUID]*subject)}
+	if viewerID != uuid.Nil {
+		isAdmin, err := models.UserHasRole(db, viewerID, models.AdminRoleName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check viewer role: %w", err)
+		}
+		policy.isAdmin = isAdmin
+	}
+	return policy, nil
+}
+
+// ForRequest creates a policy for the authenticated user of the request, if any
+func ForRequest(c *fiber.Ctx, db *gorm.DB) (*Policy, error) {
+	viewerID := uuid.Nil
+	if user, ok := c.Locals("user").(models.User); ok {
+		viewerID = user.ID
+	}
+	return NewPolicy(db, viewerID)
+}
+
+// Load fetches the settings, relationship and contact details of the given
+// users in bulk. Users already loaded are skipped.
+func (p *Policy) Load(userIDs ...uuid.UUID) error {
+	var ids []uuid.UUID
+	for _, id := range userIDs {
+		if _, loaded := p.subjects[id]; !loaded && id != uuid.Nil {
+			p.subjects[id] = &subject{settings: defaultSettings()}
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var users []models.User
+	if err := p.db.Select("id, email, phone_number").Where("id IN ?", ids).Find(&users).Error; err != nil {
+		return fmt.Errorf("failed to load users: %w", err)
+	}
+	for _, user := range users {
+		p.subjects[user.ID].email = user.Email
+		p.subjects[user.ID].phoneNumber = user.PhoneNumber
+	}
+
+	var settings []models.UserPrivacySetting
+	if err := p.db.Where("user_id IN ?", ids).Find(&settings).Error; err != nil {
+		return fmt.Errorf("failed to load privacy settings: %w", err)
+	}
+	for _, setting := range settings {
+		p.subjects[setting.UserID].settings = setting
+	}
+
+	var details []models.UserDetail
+	if err := p.db.Select("user_id, is_profile_public").Where("user_id IN ?", ids).Find(&details).Error; err != nil {
+		return fmt.Errorf("failed to load profile visibility: %w", err)
+	}
+	for _, detail := range details {
+		p.subjects[detail.UserID].profilePublic = detail.IsProfilePublic
+	}
+
+	var locations []models.UserLocation
+	if err := p.db.Where("user_id IN ?", ids).Find(&locations).Error; err != nil {
+		return fmt.Errorf("failed to load locations: %w", err)
+	}
+	for _, location := range locations {
+		if location.UserID != nil {
+			p.subjects[*location.UserID].location = &Location{
+				City:    location.City,
+				State:   location.StateName,
+				Country: location.Country,
+			}
+		}
+	}
+
+	if p.viewerID != uuid.Nil {
+		var followed []uuid.UUID
+		if err := p.db.Model(&models.Follower{}).
+			Where("follower_id = ? AND following_id IN ? AND status = ?", p.viewerID, ids, models.FollowStatusAccepted).
+			Pluck("following_id", &followed).Error; err != nil {
+			return fmt.Errorf("failed to load follow relationships: %w", err)
+		}
+		for _, id := range followed {
+			p.subjects[id].followed = true
+		}
+	}
+	return nil
+}
+
+// Relationship returns how the viewer relates to a loaded user
+func (p *Policy) Relationship(userID uuid.UUID) Relationship {
+	switch {
+	case p.viewerID != uuid.Nil && p.viewerID == userID:
+		return Self
+	case p.isAdmin:
+		return Admin
+	case p.subjects[userID] != nil && p.subjects[userID].followed:
+		return Follower
+	default:
+		return Stranger
+	}
+}
+
+// allows reports whether the viewer may see a field the owner has chosen to show or hide
+func (p *Policy) allows(userID uuid.UUID, shown bool) bool {
+	switch p.Relationship(userID) {
+	case Self, Admin:
+		return true
+	case Follower:
+		return shown
+	default:
+		s := p.subjects[userID]
+		return shown && s != nil && s.profilePublic
+	}
+}
+
+// CanSeeEmail reports whether the viewer may see the user's email address
+func (p *Policy) CanSeeEmail(userID uuid.UUID) bool {
+	s := p.subjects[userID]
+	return s != nil && p.allows(userID, s.settings.ShowEmail)
+}
+
+// CanSeePhone reports whether the viewer may see the user's phone number
+func (p *Policy) CanSeePhone(userID uuid.UUID) bool {
+	s := p.subjects[userID]
+	return s != nil && p.allows(userID, s.settings.ShowPhone)
+}
+
+// CanSeeLocation reports whether the viewer may see the user's location
+func (p *Policy) CanSeeLocation(userID uuid.UUID) bool {
+	s := p.subjects[userID]
+	return s != nil && p.allows(userID, s.settings.ShowLocation)
+}
+
+// IsPrivate reports whether a loaded user has switched on private account mode
+func (p *Policy) IsPrivate(userID uuid.UUID) bool {
+	s := p.subjects[userID]
+	return s != nil && s.settings.IsPrivate
+}
+
+// CanSeeContent reports whether the viewer may see the user's artworks,
+// collections and follower lists. Private accounts only share them with
+// approved followers.
+func (p *Policy) CanSeeContent(userID uuid.UUID) bool {
+	switch p.Relationship(userID) {
+	case Self, Admin, Follower:
+		return true
+	default:
+		return p.subjects[userID] != nil && !p.IsPrivate(userID)
+	}
+}
+
+// Apply sets the contact fields of a view to exactly what the viewer may see.
+// Users that were not loaded have every contact field removed.
+func (p *Policy) Apply(view *UserView) {
+	view.Email, view.PhoneNumber, view.Location = "", "", nil
+
+	s := p.subjects[view.ID]
+	if s == nil {
+		return
+	}
+	if p.CanSeeEmail(view.ID) {
+		view.Email = s.email
+	}
+	if p.CanSeePhone(view.ID) {
+		view.PhoneNumber = s.phoneNumber
+	}
+	if p.CanSeeLocation(view.ID) {
+		view.Location = s.location
+	}
+}
+
+// ApplyAll loads and applies the policy to every view in the slice
+func (p *Policy) ApplyAll(views []UserView) error {
+	ids := make([]uuid.UUID, len(views))
+	for i := range views {
+		ids[i] = views[i].ID
+	}
+	if err := p.Load(ids...); err != nil {
+		return err
+	}
+	for i := range views {
+		p.Apply(&views[i])
+	}
+	return nil
+}
+
+// defaultSettings mirrors the values CreateDefaultPrivacySetting stores, for
+// users who never saved their settings
+func defaultSettings() models.UserPrivacySetting {
+	return models.UserPrivacySetting{
+		AllowFollowRequests: true,
+		AllowMessagesFrom:   "everyone",
+	}
+}
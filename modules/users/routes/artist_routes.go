@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/users/handlers/artist"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/middleware"
+	"gorm.io/gorm"
+)
+
+// SetupArtistVerificationRoutes sets up the admin routes for reviewing artist verification requests
+func SetupArtistVerificationRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *handlers.ResponseHandler) {
+	verificationGroup := apiGroup.Group("/admin/artist-verifications")
+	verificationGroup.Use(middleware.AuthMiddleware(db, responseHandler))
+	verificationGroup.Use(middleware.RequireRole(db, responseHandler, models.AdminRoleName))
+
+	verificationGroup.Get("/", artist.ListVerificationRequests(db, responseHandler))
+	verificationGroup.Delete("/artists/:userId", artist.RevokeVerification(db, responseHandler))
+	verificationGroup.Put("/:id/:action", artist.ReviewVerificationRequest(db, responseHandler))
+}
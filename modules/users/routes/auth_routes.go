@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/muga20/artsMarket/modules/users/auth"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/middleware"
 	"gorm.io/gorm"
 )
 
@@ -15,7 +16,17 @@ func SetupAuthRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *handle
 	authGroup.Post("/signup", auth.SignupHandler(db, responseHandler))
 	authGroup.Post("/login", auth.LoginHandler(db, responseHandler))
 	authGroup.Post("/logout", auth.LogoutHandler(db, responseHandler))
-	authGroup.Post("/logout-other-devices", auth.LogoutOtherDevicesHandler(db, responseHandler))
+	authGroup.Post("/logout-other-devices", middleware.AuthMiddleware(db, responseHandler), auth.LogoutOtherDevicesHandler(db, responseHandler))
+	authGroup.Post("/refresh", auth.RefreshHandler(db, responseHandler))
 	authGroup.Post("/reset-password-request", auth.ResetPasswordRequestHandler(db, responseHandler))
 	authGroup.Post("/reset-password", auth.ResetPasswordHandler(db, responseHandler))
+	authGroup.Post("/resend-verification", auth.ResendVerificationHandler(db, responseHandler))
+	authGroup.Post("/2fa/verify", auth.VerifyTwoFactorHandler(db, responseHandler))
+	authGroup.Post("/unlock", auth.UnlockAccountHandler(db, responseHandler))
+	authGroup.Post("/reactivate", auth.ReactivateAccountHandler(db, responseHandler))
+
+	// Social login
+	auth.RegisterOAuthProviders()
+	authGroup.Get("/oauth/:provider", auth.OAuthStartHandler(db, responseHandler))
+	authGroup.Get("/oauth/:provider/callback", auth.OAuthCallbackHandler(db, responseHandler))
 }
@@ -19,6 +19,12 @@ func RegisterEngagementRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandle
 	engagementGroup.Delete("/unfollow/:id", engagement.UnfollowUser(db, responseHandler))
 	engagementGroup.Get("/stats/:id", engagement.GetUserFollowStats(db, responseHandler))
 
+	// Follow requests for private accounts
+	engagementGroup.Get("/follow-requests/incoming", engagement.GetIncomingFollowRequests(db, responseHandler))
+	engagementGroup.Get("/follow-requests/outgoing", engagement.GetOutgoingFollowRequests(db, responseHandler))
+	engagementGroup.Post("/follow-requests/:id/approve", engagement.ApproveFollowRequest(db, responseHandler))
+	engagementGroup.Post("/follow-requests/:id/deny", engagement.DenyFollowRequest(db, responseHandler))
+
 	// Block / Unblock
 	engagementGroup.Post("/block/:id", engagement.BlockUser(db, responseHandler))
 	engagementGroup.Delete("/unblock/:id", engagement.UnblockUser(db, responseHandler))
@@ -18,4 +18,5 @@ func UserModuleSetupRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *
 	SetupAccountSecurityRoutes(apiGroup, db, responseHandler)
 	RegisterEngagementRoutes(apiGroup, db, responseHandler)
 	SetupPublicProfileRoutes(apiGroup, db, responseHandler)
+	SetupArtistVerificationRoutes(apiGroup, db, responseHandler)
 }
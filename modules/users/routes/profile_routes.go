@@ -3,7 +3,9 @@ package routes
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/muga20/artsMarket/config" // Importing the config package for Cloudinary
+	"github.com/muga20/artsMarket/modules/users/auth"
 	"github.com/muga20/artsMarket/modules/users/handlers/account"
+	"github.com/muga20/artsMarket/modules/users/handlers/artist"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"github.com/muga20/artsMarket/pkg/middleware"
 
@@ -34,4 +36,35 @@ func SetupAccountRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *han
 	accountGroup.Get("/social-links", account.GetSocialLinks(db, responseHandler))
 	accountGroup.Put("/social-links/:id", account.UpdateSocialLink(db, responseHandler))
 	accountGroup.Delete("/social-links/:id", account.DeleteSocialLink(db, responseHandler))
+
+	// Artist profile, CV and verification
+	accountGroup.Get("/artist-profile", artist.GetArtistProfile(db, responseHandler))
+	accountGroup.Put("/artist-profile", artist.UpdateArtistProfile(db, responseHandler))
+	accountGroup.Post("/artist-profile/cv", artist.CreateCVEntry(db, responseHandler))
+	accountGroup.Put("/artist-profile/cv/:id", artist.UpdateCVEntry(db, responseHandler))
+	accountGroup.Delete("/artist-profile/cv/:id", artist.DeleteCVEntry(db, responseHandler))
+	accountGroup.Post("/artist-profile/verification", artist.SubmitVerificationRequest(db, cld, responseHandler))
+
+	// Signed-in devices
+	accountGroup.Get("/sessions", account.ListSessions(db, responseHandler))
+	accountGroup.Delete("/sessions/:id", account.RevokeSession(db, responseHandler))
+
+	// Linked identity providers
+	accountGroup.Get("/identities", auth.ListIdentitiesHandler(db, responseHandler))
+	accountGroup.Post("/identities/:provider/link", auth.LinkIdentityHandler(db, responseHandler))
+	accountGroup.Delete("/identities/:provider", auth.UnlinkIdentityHandler(db, responseHandler))
+
+	// Personal access tokens; the group's AuthMiddleware has no scopes, so a
+	// token can never be used to mint or revoke tokens
+	accountGroup.Post("/tokens", account.CreateAccessToken(db, responseHandler))
+	accountGroup.Get("/tokens", account.ListAccessTokens(db, responseHandler))
+	accountGroup.Delete("/tokens/:id", account.RevokeAccessToken(db, responseHandler))
+
+	// Data export ("download my data")
+	accountGroup.Post("/export", account.RequestDataExport(db, responseHandler))
+	accountGroup.Get("/export", account.ListDataExports(db, responseHandler))
+
+	// The emailed download link carries its own token, so it sits outside the
+	// authenticated group
+	apiGroup.Get("/exports/:id/download", account.DownloadDataExport(db, responseHandler))
 }
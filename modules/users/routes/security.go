@@ -21,4 +21,10 @@ func SetupAccountSecurityRoutes(apiGroup fiber.Router, db *gorm.DB, responseHand
 
 	securityGroup.Put("/change-password", middleware.AuthMiddleware(db, responseHandler), security.ChangePassword(db, responseHandler))
 
+	// Two-factor authentication
+	securityGroup.Post("/2fa/enroll", middleware.AuthMiddleware(db, responseHandler), security.EnrollTwoFactor(db, responseHandler))
+	securityGroup.Post("/2fa/confirm", middleware.AuthMiddleware(db, responseHandler), security.ConfirmTwoFactor(db, responseHandler))
+	securityGroup.Post("/2fa/disable", middleware.AuthMiddleware(db, responseHandler), security.DisableTwoFactor(db, responseHandler))
+	securityGroup.Post("/2fa/recovery-codes", middleware.AuthMiddleware(db, responseHandler), security.RegenerateRecoveryCodes(db, responseHandler))
+
 }
@@ -0,0 +1,283 @@
+// Package sessions issues short-lived access tokens and rotating refresh
+// tokens, and answers whether a device session is still active.
+package sessions
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/tokens"
+	"gorm.io/gorm"
+)
+
+const (
+	AccessCookie  = "auth_token"
+	RefreshCookie = "refresh_token"
+
+	// refreshCookiePath limits the refresh token to the endpoints that consume it
+	refreshCookiePath = "/api/v1/auth"
+
+	// lastSeenInterval throttles how often request activity is written back
+	lastSeenInterval = time.Minute
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+)
+
+// Claims are the access token claims; SessionID identifies the session family
+type Claims struct {
+	SessionID string `json:"sid"`
+	tokens.RegisteredClaims
+}
+
+// Start opens a new device session for the user and sets both cookies
+func Start(c *fiber.Ctx, db *gorm.DB, userID uuid.UUID) error {
+	refreshToken, refreshHash, err := newRefreshToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	session := models.UserSession{
+		ID:               uuid.New(),
+		UserID:           userID,
+		FamilyID:         uuid.New(),
+		RefreshTokenHash: refreshHash,
+		IPAddress:        c.IP(),
+		DeviceInfo:       c.Get("User-Agent"),
+		StartedAt:        now,
+		LastSeenAt:       &now,
+		CreatedAt:        now,
+		ExpiresAt:        now.Add(tokens.RefreshTokenTTL()),
+	}
+	if err := db.Create(&session).Error; err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return setCookies(c, userID, session.FamilyID, refreshToken)
+}
+
+// Refresh rotates a refresh token. Presenting a token that was already
+// rotated means it leaked, so the whole family is revoked.
+func Refresh(c *fiber.Ctx, db *gorm.DB, refreshToken string) (uuid.UUID, error) {
+	if refreshToken == "" {
+		return uuid.Nil, ErrInvalidRefreshToken
+	}
+
+	var current models.UserSession
+	if err := db.Where("session_token = ?", hashToken(refreshToken)).First(&current).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, ErrInvalidRefreshToken
+		}
+		return uuid.Nil, fmt.Errorf("failed to retrieve session: %w", err)
+	}
+
+	if current.RevokedAt != nil || current.ExpiresAt.Before(time.Now()) {
+		return uuid.Nil, ErrInvalidRefreshToken
+	}
+
+	if current.RotatedAt != nil {
+		if err := RevokeFamily(db, current.FamilyID); err != nil {
+			return uuid.Nil, err
+		}
+		log.Printf("Refresh token reuse detected for user %s, revoked session %s", current.UserID, current.FamilyID)
+		return uuid.Nil, ErrRefreshTokenReused
+	}
+
+	newToken, newHash, err := newRefreshToken()
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	now := time.Now()
+	err = db.Transaction(func(tx *gorm.DB) error {
+		// Only one concurrent refresh may win the rotation
+		result := tx.Model(&models.UserSession{}).
+			Where("id = ? AND rotated_at IS NULL AND revoked_at IS NULL", current.ID).
+			Update("rotated_at", now)
+		if result.Error != nil {
+			return fmt.Errorf("failed to rotate session: %w", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		return tx.Create(&models.UserSession{
+			ID:               uuid.New(),
+			UserID:           current.UserID,
+			FamilyID:         current.FamilyID,
+			RefreshTokenHash: newHash,
+			IPAddress:        c.IP(),
+			DeviceInfo:       c.Get("User-Agent"),
+			StartedAt:        current.StartedAt,
+			LastSeenAt:       &now,
+			CreatedAt:        now,
+			ExpiresAt:        now.Add(tokens.RefreshTokenTTL()),
+		}).Error
+	})
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			if revokeErr := RevokeFamily(db, current.FamilyID); revokeErr != nil {
+				return uuid.Nil, revokeErr
+			}
+		}
+		return uuid.Nil, err
+	}
+
+	if err := setCookies(c, current.UserID, current.FamilyID, newToken); err != nil {
+		return uuid.Nil, err
+	}
+	return current.UserID, nil
+}
+
+// FamilyForRefreshToken returns the session family a refresh token belongs to
+func FamilyForRefreshToken(db *gorm.DB, refreshToken string) (uuid.UUID, error) {
+	var session models.UserSession
+	if err := db.Select("family_id").Where("session_token = ?", hashToken(refreshToken)).First(&session).Error; err != nil {
+		return uuid.Nil, err
+	}
+	return session.FamilyID, nil
+}
+
+// RevokeFamily signs a single device out
+func RevokeFamily(db *gorm.DB, familyID uuid.UUID) error {
+	if err := db.Model(&models.UserSession{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error; err != nil {
+		return fmt.Errorf("failed to revoke session: %w", err)
+	}
+	return nil
+}
+
+// RevokeAllForUser signs every device out, optionally keeping one session
+// family, and returns the number of sessions revoked
+func RevokeAllForUser(db *gorm.DB, userID uuid.UUID, keepFamilyID uuid.UUID) (int64, error) {
+	query := db.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL AND rotated_at IS NULL AND expires_at > ?", userID, time.Now())
+	if keepFamilyID != uuid.Nil {
+		query = query.Where("family_id != ?", keepFamilyID)
+	}
+
+	result := query.Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to revoke sessions: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// ListActive returns the current token of every active device session
+func ListActive(db *gorm.DB, userID uuid.UUID) ([]models.UserSession, error) {
+	var active []models.UserSession
+	err := db.Where("user_id = ? AND revoked_at IS NULL AND rotated_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&active).Error
+	return active, err
+}
+
+// IsActive reports whether the session family can still be used, recording
+// the activity at most once a minute
+func IsActive(db *gorm.DB, familyID uuid.UUID) (bool, error) {
+	var count int64
+	now := time.Now()
+	if err := db.Model(&models.UserSession{}).
+		Where("family_id = ? AND revoked_at IS NULL AND rotated_at IS NULL AND expires_at > ?", familyID, now).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	db.Model(&models.UserSession{}).
+		Where("family_id = ? AND rotated_at IS NULL AND (last_seen_at IS NULL OR last_seen_at < ?)", familyID, now.Add(-lastSeenInterval)).
+		Update("last_seen_at", now)
+	return true, nil
+}
+
+// ParseAccessToken validates an access token and returns its claims
+func ParseAccessToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	if err := tokens.Verify(tokenString, tokens.AudienceAccess, claims); err != nil {
+		return nil, errors.New("invalid or expired token")
+	}
+	if claims.SessionID == "" {
+		return nil, errors.New("token is not bound to a session")
+	}
+	return claims, nil
+}
+
+// ClearCookies removes both session cookies
+func ClearCookies(c *fiber.Ctx) {
+	expired := time.Now().Add(-time.Hour)
+	c.Cookie(&fiber.Cookie{
+		Name:     AccessCookie,
+		Value:    "",
+		Expires:  expired,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     RefreshCookie,
+		Value:    "",
+		Path:     refreshCookiePath,
+		Expires:  expired,
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+}
+
+func setCookies(c *fiber.Ctx, userID, familyID uuid.UUID, refreshToken string) error {
+	now := time.Now()
+	claims := &Claims{SessionID: familyID.String()}
+	claims.Subject = userID.String()
+	accessToken, err := tokens.Issue(claims, tokens.AudienceAccess, tokens.AccessTokenTTL())
+	if err != nil {
+		return fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     AccessCookie,
+		Value:    accessToken,
+		Expires:  now.Add(tokens.AccessTokenTTL()),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+	c.Cookie(&fiber.Cookie{
+		Name:     RefreshCookie,
+		Value:    refreshToken,
+		Path:     refreshCookiePath,
+		Expires:  now.Add(tokens.RefreshTokenTTL()),
+		HTTPOnly: true,
+		Secure:   true,
+		SameSite: fiber.CookieSameSiteStrictMode,
+	})
+	return nil
+}
+
+func newRefreshToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(buf)
+	return token, hashToken(token), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,127 @@
+// Package twofactor holds the TOTP and recovery code checks shared by the
+// login challenge and the account security endpoints.
+package twofactor
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/secrets"
+	"github.com/muga20/artsMarket/pkg/totp"
+	"gorm.io/gorm"
+)
+
+const (
+	// Issuer is shown as the account label in authenticator apps
+	Issuer = "ArtsMarket"
+
+	// RecoveryCodeCount is how many backup codes are issued at a time
+	RecoveryCodeCount = 10
+)
+
+var ErrInvalidCode = errors.New("invalid two-factor code")
+
+// Secret returns the decrypted TOTP secret of the user
+func Secret(userSecurity *models.UserSecurity) (string, error) {
+	if userSecurity.TwoFactorSecret == nil {
+		return "", errors.New("two-factor authentication is not set up")
+	}
+	return secrets.Decrypt(*userSecurity.TwoFactorSecret)
+}
+
+// VerifyCode checks an authenticator code and records its time step so the
+// same code cannot be replayed
+func VerifyCode(tx *gorm.DB, userSecurity *models.UserSecurity, code string) error {
+	secret, err := Secret(userSecurity)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now())
+	if !ok || step <= userSecurity.TwoFactorLastUsedStep {
+		return ErrInvalidCode
+	}
+
+	userSecurity.TwoFactorLastUsedStep = step
+	return tx.Model(&models.UserSecurity{}).
+		Where("id = ?", userSecurity.ID).
+		Update("two_factor_last_used_step", step).Error
+}
+
+// UseRecoveryCode consumes one unused recovery code
+func UseRecoveryCode(tx *gorm.DB, userID uuid.UUID, code string) error {
+	result := tx.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hashRecoveryCode(code)).
+		Limit(1).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("failed to check recovery code: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidCode
+	}
+	return nil
+}
+
+// Verify accepts either an authenticator code or a recovery code
+func Verify(tx *gorm.DB, userSecurity *models.UserSecurity, code, recoveryCode string) error {
+	if recoveryCode != "" {
+		return UseRecoveryCode(tx, userSecurity.UserID, recoveryCode)
+	}
+	if code == "" {
+		return ErrInvalidCode
+	}
+	return VerifyCode(tx, userSecurity, code)
+}
+
+// GenerateRecoveryCodes replaces any existing recovery codes and returns the
+// new plaintext codes, which are shown to the user exactly once
+func GenerateRecoveryCodes(tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to remove old recovery codes: %w", err)
+	}
+
+	codes := make([]string, 0, RecoveryCodeCount)
+	records := make([]models.UserRecoveryCode, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := newRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		codes = append(codes, code)
+		records = append(records, models.UserRecoveryCode{
+			UserID:   userID,
+			CodeHash: hashRecoveryCode(code),
+		})
+	}
+
+	if err := tx.Create(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+	return codes, nil
+}
+
+// newRecoveryCode returns a code such as "k3m9q-x7p2d"
+func newRecoveryCode() (string, error) {
+	buf := make([]byte, 7)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	encoded := strings.ToLower(base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf))[:10]
+	return encoded[:5] + "-" + encoded[5:], nil
+}
+
+// Recovery codes are long random values, so a fast hash is sufficient
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -12,6 +12,10 @@ import (
 
 // Handle processes and returns a response with proper error handling
 func (rh *ResponseHandler) Handle(c *fiber.Ctx, data interface{}, err error) error {
+	// Background workers have no request context; hand the error back to the caller
+	if c == nil {
+		return err
+	}
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -153,7 +154,7 @@ func (h *ResponseHandler) getCachedCaller() (file, method string, line int) {
 	pc := pcs[0]
 
 	callerCacheLock.RLock()
-	if info, exists := callerCache[string(pc)]; exists {
+	if info, exists := callerCache[strconv.FormatUint(uint64(pc), 16)]; exists {
 		callerCacheLock.RUnlock()
 		return info.file, info.method, info.line
 	}
@@ -174,7 +175,7 @@ func (h *ResponseHandler) getCachedCaller() (file, method string, line int) {
 	}
 
 	callerCacheLock.Lock()
-	callerCache[string(pc)] = &callerInfo{
+	callerCache[strconv.FormatUint(uint64(pc), 16)] = &callerInfo{
 		file:   file,
 		method: method,
 		line:   frame.Line,
@@ -3,57 +3,119 @@ package middleware
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/muga20/artsMarket/config"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/modules/users/accesstokens"
 	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/modules/users/sessions"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
 	"gorm.io/gorm"
 )
 
-// AuthMiddleware verifies the user's JWT token and retrieves user information
-func AuthMiddleware(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+// AuthMiddleware verifies the user's access token, checks that its session has
+// not been revoked and retrieves user information.
+//
+// The token is read from the session cookie or an "Authorization: Bearer"
+// header. Personal access tokens are only accepted on routes that list the
+// scopes they require; routes registered without scopes are session-only.
+func AuthMiddleware(db *gorm.DB, responseHandler *handlers.ResponseHandler, scopes ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 
-		tokenString := c.Cookies("auth_token")
+		tokenString := BearerToken(c)
+		if tokenString == "" {
+			tokenString = c.Cookies(sessions.AccessCookie)
+		}
 
 		// If no token is found, return an error
 		if tokenString == "" {
 			return responseHandler.Handle(c, nil, errors.New("please login"))
 		}
 
-		// Parse and validate the JWT token
-		token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
-			// Ensure the signing method is correct
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
+		var userID string
+		if accesstokens.IsAccessToken(tokenString) {
+			token, err := accesstokens.Authenticate(db, tokenString, c.IP())
+			if err != nil {
+				if errors.Is(err, accesstokens.ErrInvalidToken) {
+					return responseHandler.Handle(c, nil, errors.New("invalid or expired token"))
+				}
+				return responseHandler.Handle(c, nil, err)
+			}
+			if len(scopes) == 0 {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusForbidden, "Personal access tokens cannot be used for this endpoint"))
+			}
+			if !accesstokens.Allows(token, scopes...) {
+				return responseHandler.HandleResponse(c, nil,
+					fiber.NewError(fiber.StatusForbidden, "Access token requires scope: "+strings.Join(scopes, ", ")))
 			}
-			return []byte(config.Envs.DBUser), nil // Use your actual secret key
-		})
 
-		if err != nil || !token.Valid {
-			return responseHandler.Handle(c, nil, errors.New("invalid or expired token"))
-		}
+			userID = token.UserID.String()
+			c.Locals("access_token_id", token.ID)
+		} else {
+			// Parse and validate the access token
+			claims, err := sessions.ParseAccessToken(tokenString)
+			if err != nil {
+				return responseHandler.Handle(c, nil, errors.New("invalid or expired token"))
+			}
+
+			// Reject tokens whose device session has been revoked
+			familyID, err := uuid.Parse(claims.SessionID)
+			if err != nil {
+				return responseHandler.Handle(c, nil, errors.New("invalid token claims"))
+			}
+			active, err := sessions.IsActive(db, familyID)
+			if err != nil {
+				return responseHandler.Handle(c, nil, fmt.Errorf("failed to check session: %v", err))
+			}
+			if !active {
+				return responseHandler.Handle(c, nil, errors.New("session has been revoked"))
+			}
 
-		// Extract the user ID from the JWT claims
-		claims, ok := token.Claims.(*jwt.RegisteredClaims)
-		if !ok {
-			return responseHandler.Handle(c, nil, errors.New("invalid token claims"))
+			userID = claims.Subject
+			c.Locals("session_id", familyID)
 		}
 
 		// Retrieve the user by ID from the database
 		var user models.User
-		if err := db.Where("id = ?", claims.Subject).First(&user).Error; err != nil {
+		if err := db.Where("id = ?", userID).First(&user).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
 				return responseHandler.Handle(c, nil, errors.New("user not found"))
 			}
 			return responseHandler.Handle(c, nil, fmt.Errorf("failed to retrieve user: %v", err))
 		}
 
+		// Deactivated accounts must log in again to reactivate
+		if !user.IsActive {
+			return responseHandler.Handle(c, nil, errors.New("account is deactivated"))
+		}
+
 		c.Locals("user", user)
 
 		// Proceed with the next handler
 		return c.Next()
 	}
 }
+
+// OptionalAuthMiddleware authenticates the request like AuthMiddleware when
+// it carries a token, and otherwise lets it through anonymously so public
+// handlers can still tailor the response to a signed-in user.
+func OptionalAuthMiddleware(db *gorm.DB, responseHandler *handlers.ResponseHandler, scopes ...string) fiber.Handler {
+	authenticate := AuthMiddleware(db, responseHandler, scopes...)
+	return func(c *fiber.Ctx) error {
+		if BearerToken(c) == "" && c.Cookies(sessions.AccessCookie) == "" {
+			return c.Next()
+		}
+		return authenticate(c)
+	}
+}
+
+// BearerToken returns the credential from an "Authorization: Bearer" header
+func BearerToken(c *fiber.Ctx) string {
+	header := c.Get(fiber.HeaderAuthorization)
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+// RequireRole allows the request through only when the authenticated user holds
+// the given role. It must run after AuthMiddleware.
+func RequireRole(db *gorm.DB, responseHandler *handlers.ResponseHandler, roleName string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		hasRole, err := models.UserHasRole(db, user.ID, roleName)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to check user role: %w", err))
+		}
+		if !hasRole {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusForbidden, "You do not have permission to access this resource"))
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+// RequireVerifiedEmail blocks publishing, selling, commenting and messaging for
+// accounts whose email address has not been verified. It must run after AuthMiddleware.
+func RequireVerifiedEmail(db *gorm.DB, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		user, ok := c.Locals("user").(models.User)
+		if !ok {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusUnauthorized, "Authentication required"))
+		}
+
+		verified, err := models.IsEmailVerified(db, user.ID)
+		if err != nil {
+			return responseHandler.HandleResponse(c, nil,
+				fmt.Errorf("failed to check email verification: %w", err))
+		}
+		if !verified {
+			return responseHandler.HandleResponse(c, nil,
+				fiber.NewError(fiber.StatusForbidden, "Please verify your email address before continuing"))
+		}
+
+		return c.Next()
+	}
+}
@@ -0,0 +1,67 @@
+// Package secrets encrypts small values, such as TOTP secrets, before they
+// are stored in the database.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+
+	"github.com/muga20/artsMarket/config"
+)
+
+var ErrNoKey = errors.New("DATA_ENCRYPTION_KEY is not configured")
+
+func newAEAD() (cipher.AEAD, error) {
+	if config.Envs.DataEncryptionKey == "" {
+		return nil, ErrNoKey
+	}
+	key := sha256.Sum256([]byte(config.Envs.DataEncryptionKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Encrypt seals plaintext with AES-256-GCM and returns it base64 encoded
+func Encrypt(plaintext string) (string, error) {
+	aead, err := newAEAD()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt
+func Decrypt(encoded string) (string, error) {
+	aead, err := newAEAD()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,11 @@
+package tasks
+
+import "github.com/hibiken/asynq"
+
+const TypePurgeUnverifiedAccounts = "users:purge_unverified"
+
+// NewPurgeUnverifiedAccountsTask creates the periodic task that removes accounts
+// which never verified their email address
+func NewPurgeUnverifiedAccountsTask() *asynq.Task {
+	return asynq.NewTask(TypePurgeUnverifiedAccounts, nil)
+}
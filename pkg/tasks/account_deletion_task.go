@@ -0,0 +1,38 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/modules/notifications/emails"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+)
+
+const (
+	TypeSendAccountDeletionScheduled = "email:send_account_deletion_scheduled"
+	TypePurgeDeletedAccounts         = "users:purge_deleted_accounts"
+)
+
+// AccountDeletionScheduledPayload defines the payload of the email confirming a deletion request
+type AccountDeletionScheduledPayload struct {
+	Email   string `json:"email"`
+	PurgeAt string `json:"purge_at"`
+}
+
+// HandleSendAccountDeletionScheduledTask emails the date the account will be purged
+func HandleSendAccountDeletionScheduledTask(ctx context.Context, t *asynq.Task) error {
+	var payload AccountDeletionScheduledPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to parse task payload: %v", err)
+	}
+
+	return emails.SendAccountDeletionScheduledEmail(payload.Email, payload.PurgeAt, &handlers.ResponseHandler{})
+}
+
+// NewPurgeDeletedAccountsTask creates the periodic task that purges accounts
+// whose deletion grace period has ended
+func NewPurgeDeletedAccountsTask() *asynq.Task {
+	return asynq.NewTask(TypePurgeDeletedAccounts, nil)
+}
@@ -0,0 +1,30 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/modules/notifications/emails"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+)
+
+const TypeSendAccountUnlock = "email:send_account_unlock"
+
+// AccountUnlockPayload defines the payload structure for account unlock emails
+type AccountUnlockPayload struct {
+	Email       string `json:"email"`
+	Token       string `json:"token"`
+	LockedUntil string `json:"locked_until"`
+}
+
+// HandleSendAccountUnlockTask emails the unlock link for a locked account
+func HandleSendAccountUnlockTask(ctx context.Context, t *asynq.Task) error {
+	var payload AccountUnlockPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to parse task payload: %v", err)
+	}
+
+	return emails.SendAccountUnlockEmail(payload.Email, payload.Token, payload.LockedUntil, &handlers.ResponseHandler{})
+}
@@ -0,0 +1,45 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/modules/notifications/emails"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+)
+
+const (
+	TypeGenerateDataExport  = "users:generate_data_export"
+	TypeSendDataExportReady = "email:send_data_export_ready"
+	TypePurgeExpiredExports = "users:purge_expired_exports"
+)
+
+// DataExportPayload identifies the export a job should build
+type DataExportPayload struct {
+	ExportID string `json:"export_id"`
+}
+
+// DataExportReadyPayload defines the payload of the email sent once an export is built
+type DataExportReadyPayload struct {
+	Email        string `json:"email"`
+	DownloadLink string `json:"download_link"`
+	ExpiresAt    string `json:"expires_at"`
+}
+
+// HandleSendDataExportReadyTask emails the download link for a finished export
+func HandleSendDataExportReadyTask(ctx context.Context, t *asynq.Task) error {
+	var payload DataExportReadyPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to parse task payload: %v", err)
+	}
+
+	return emails.SendDataExportReadyEmail(payload.Email, payload.DownloadLink, payload.ExpiresAt, &handlers.ResponseHandler{})
+}
+
+// NewPurgeExpiredExportsTask creates the periodic task that deletes export
+// archives whose download links have expired
+func NewPurgeExpiredExportsTask() *asynq.Task {
+	return asynq.NewTask(TypePurgeExpiredExports, nil)
+}
@@ -0,0 +1,8 @@
+package tasks
+
+const TypeMigrateDimensions = "artworks:migrate_dimensions"
+
+// MigrateDimensionsPayload identifies the dimension migration run a job should perform
+type MigrateDimensionsPayload struct {
+	MigrationID string `json:"migration_id"`
+}
@@ -1,27 +1,53 @@
 package tasks
 
 import (
-    "encoding/json"
-    "errors"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 
-    "github.com/hibiken/asynq"
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/modules/notifications/emails"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+)
+
+const TypeSendEmailVerification = "email:send_verification"
+
+// Verification purposes select which email template is sent
+const (
+	VerificationPurposeEmailChange = "email_change"
+	VerificationPurposeSignup      = "signup"
 )
 
 // EmailVerificationPayload defines the payload structure for email verification tasks
 type EmailVerificationPayload struct {
-    Email string `json:"email"`
-    Token string `json:"token"`
+	Email   string `json:"email"`
+	Token   string `json:"token"`
+	Purpose string `json:"purpose,omitempty"`
 }
 
 // NewSendEmailVerificationTask creates a new task for sending email verification
 func NewSendEmailVerificationTask(email, token string) (*asynq.Task, error) {
-    payload, err := json.Marshal(EmailVerificationPayload{
-        Email: email,
-        Token: token,
-    })
-    if err != nil {
-        return nil, errors.New("failed to marshal email verification payload")
-    }
-
-    return asynq.NewTask("email:send_verification", payload), nil
+	payload, err := json.Marshal(EmailVerificationPayload{
+		Email: email,
+		Token: token,
+	})
+	if err != nil {
+		return nil, errors.New("failed to marshal email verification payload")
+	}
+
+	return asynq.NewTask(TypeSendEmailVerification, payload), nil
+}
+
+// HandleSendEmailVerificationTask sends the verification link for a new email address
+func HandleSendEmailVerificationTask(ctx context.Context, t *asynq.Task) error {
+	var payload EmailVerificationPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to parse task payload: %v", err)
+	}
+
+	if payload.Purpose == VerificationPurposeSignup {
+		return emails.SendSignupVerificationEmail(payload.Email, payload.Token, &handlers.ResponseHandler{})
+	}
+	return emails.SendEmailVerificationEmail(payload.Email, payload.Token, &handlers.ResponseHandler{})
 }
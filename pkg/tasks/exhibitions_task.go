@@ -0,0 +1,16 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TypeAdvanceExhibitions = "exhibitions:advance"
+
+// NewAdvanceExhibitionsTask creates the task that opens and closes
+// exhibitions on schedule and sends opening reminders. It runs every minute,
+// so a failed run is simply picked up by the next one.
+func NewAdvanceExhibitionsTask() *asynq.Task {
+	return asynq.NewTask(TypeAdvanceExhibitions, nil, asynq.Timeout(5*time.Minute), asynq.MaxRetry(0))
+}
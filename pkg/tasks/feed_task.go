@@ -0,0 +1,8 @@
+package tasks
+
+const TypeFanOutActivity = "feed:fan_out_activity"
+
+// FanOutActivityPayload identifies the activity to add to followers' timelines
+type FanOutActivityPayload struct {
+	ActivityID string `json:"activity_id"`
+}
@@ -0,0 +1,16 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TypeComputeRecommendations = "recommendations:compute"
+
+// NewComputeRecommendationsTask creates the periodic task that rebuilds the
+// cached similar artworks and personal recommendations. A run scores the whole
+// catalog, so it gets longer than the default timeout.
+func NewComputeRecommendationsTask() *asynq.Task {
+	return asynq.NewTask(TypeComputeRecommendations, nil, asynq.Timeout(2*time.Hour), asynq.MaxRetry(1))
+}
@@ -0,0 +1,15 @@
+package tasks
+
+import (
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TypeRebuildTrending = "trending:rebuild"
+
+// NewRebuildTrendingTask creates the nightly task that recomputes the
+// trending lists from the engagement tables
+func NewRebuildTrendingTask() *asynq.Task {
+	return asynq.NewTask(TypeRebuildTrending, nil, asynq.Timeout(time.Hour), asynq.MaxRetry(1))
+}
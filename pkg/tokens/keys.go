@@ -0,0 +1,105 @@
+package tokens
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Supported signing algorithms
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// minSecretLength is the shortest HS256 secret accepted (256 bits)
+const minSecretLength = 32
+
+// Key is one signing key. Keys without signing material can only verify.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+
+	// RetiresAt stops a rotated-out key from verifying tokens; zero means never
+	RetiresAt time.Time
+}
+
+// NewHMACKey creates an HS256 key that can both sign and verify
+func NewHMACKey(id string, secret []byte) (*Key, error) {
+	if id == "" {
+		return nil, errors.New("key id is required")
+	}
+	if len(secret) < minSecretLength {
+		return nil, fmt.Errorf("HS256 secret for key %q must be at least %d bytes", id, minSecretLength)
+	}
+	return &Key{ID: id, Method: jwt.SigningMethodHS256, signKey: secret, verifyKey: secret}, nil
+}
+
+// NewEdDSAKey creates an Ed25519 key. Passing only the public key gives a
+// verify-only key.
+func NewEdDSAKey(id string, privateKey ed25519.PrivateKey, publicKey ed25519.PublicKey) (*Key, error) {
+	if id == "" {
+		return nil, errors.New("key id is required")
+	}
+	key := &Key{ID: id, Method: jwt.SigningMethodEdDSA}
+	if privateKey != nil {
+		key.signKey = privateKey
+		publicKey = privateKey.Public().(ed25519.PublicKey)
+	}
+	if publicKey == nil {
+		return nil, fmt.Errorf("EdDSA key %q needs a private or public key", id)
+	}
+	key.verifyKey = publicKey
+	return key, nil
+}
+
+// CanSign reports whether the key holds signing material
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// usable reports whether the key may still verify tokens at the given time
+func (k *Key) usable(now time.Time) bool {
+	return k.RetiresAt.IsZero() || now.Before(k.RetiresAt)
+}
+
+// loadKey builds a key from configuration values. For EdDSA, keyFile holds a
+// PEM private key when signing or a PEM public key when verify-only.
+func loadKey(id, algorithm, secret, keyFile string, signing bool) (*Key, error) {
+	switch algorithm {
+	case AlgorithmHS256:
+		return NewHMACKey(id, []byte(secret))
+	case AlgorithmEdDSA:
+		if keyFile == "" {
+			return nil, fmt.Errorf("EdDSA key %q needs a PEM key file", id)
+		}
+		pem, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read key file for %q: %w", id, err)
+		}
+		if signing {
+			privateKey, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse private key for %q: %w", id, err)
+			}
+			return NewEdDSAKey(id, privateKey.(ed25519.PrivateKey), nil)
+		}
+		// A private key file also works for verify-only keys
+		if privateKey, err := jwt.ParseEdPrivateKeyFromPEM(pem); err == nil {
+			return NewEdDSAKey(id, nil, privateKey.(ed25519.PrivateKey).Public().(ed25519.PublicKey))
+		}
+		publicKey, err := jwt.ParseEdPublicKeyFromPEM(pem)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key for %q: %w", id, err)
+		}
+		return NewEdDSAKey(id, nil, publicKey.(ed25519.PublicKey))
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
@@ -0,0 +1,197 @@
+// Package tokens issues and verifies every JWT the API hands out. Tokens carry
+// the key id in their header so keys can be rotated: the active key signs,
+// and the previous key keeps verifying until its grace window ends.
+package tokens
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+	"github.com/muga20/artsMarket/config"
+)
+
+// Audiences separate tokens issued for different purposes so one can never
+// be replayed as another
+const (
+	AudienceAccess             = "access"
+	AudienceTwoFactorChallenge = "2fa_challenge"
+	AudienceOAuthFlow          = "oauth_flow"
+	AudienceReactivation       = "reactivation"
+)
+
+var (
+	ErrInvalidToken  = errors.New("invalid or expired token")
+	ErrNotConfigured = errors.New("token signing is not configured")
+)
+
+// Claims is implemented by claim structs that embed RegisteredClaims
+type Claims interface {
+	jwt.Claims
+	registered() *jwt.RegisteredClaims
+}
+
+// RegisteredClaims is embedded in every claim struct issued by this package
+type RegisteredClaims struct {
+	jwt.RegisteredClaims
+}
+
+func (c *RegisteredClaims) registered() *jwt.RegisteredClaims {
+	return &c.RegisteredClaims
+}
+
+// Service signs with one active key and verifies against every known key
+type Service struct {
+	issuer string
+	active *Key
+	keys   map[string]*Key
+	now    func() time.Time
+}
+
+// NewService creates a service that signs with active and also accepts tokens
+// signed by any of the additional keys while they have not retired
+func NewService(issuer string, active *Key, additional ...*Key) (*Service, error) {
+	if active == nil || !active.CanSign() {
+		return nil, errors.New("the active key must be able to sign")
+	}
+
+	keys := map[string]*Key{active.ID: active}
+	for _, key := range additional {
+		if _, exists := keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		keys[key.ID] = key
+	}
+	return &Service{issuer: issuer, active: active, keys: keys, now: time.Now}, nil
+}
+
+// Issue fills in the standard claims and signs the token with the active key
+func (s *Service) Issue(claims Claims, audience string, ttl time.Duration) (string, error) {
+	now := s.now()
+	registered := claims.registered()
+	registered.Issuer = s.issuer
+	registered.Audience = jwt.ClaimStrings{audience}
+	registered.IssuedAt = jwt.NewNumericDate(now)
+	registered.NotBefore = jwt.NewNumericDate(now)
+	registered.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	if registered.ID == "" {
+		registered.ID = uuid.NewString()
+	}
+
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	token.Header["kid"] = s.active.ID
+	signed, err := token.SignedString(s.active.signKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, nil
+}
+
+// Verify checks the signature, expiry, issuer and audience of a token and
+// decodes it into claims
+func (s *Service) Verify(tokenString, audience string, claims Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, ok := s.keys[kid]
+		if !ok || !key.usable(s.now()) {
+			return nil, fmt.Errorf("unknown signing key %q", kid)
+		}
+		// The algorithm is pinned by the key, never taken from the token
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return key.verifyKey, nil
+	})
+	if err != nil || !token.Valid {
+		return ErrInvalidToken
+	}
+
+	registered := claims.registered()
+	if !registered.VerifyIssuer(s.issuer, true) || !registered.VerifyAudience(audience, true) {
+		return ErrInvalidToken
+	}
+	return nil
+}
+
+var (
+	defaultService *Service
+	defaultErr     error
+	defaultOnce    sync.Once
+)
+
+// Init loads the signing keys from configuration. It is safe to call more
+// than once; the first result is kept.
+func Init() error {
+	defaultOnce.Do(func() {
+		defaultService, defaultErr = fromConfig(config.Envs)
+	})
+	return defaultErr
+}
+
+// Issue signs claims with the configured service
+func Issue(claims Claims, audience string, ttl time.Duration) (string, error) {
+	if err := Init(); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrNotConfigured, err)
+	}
+	return defaultService.Issue(claims, audience, ttl)
+}
+
+// Verify checks a token with the configured service
+func Verify(tokenString, audience string, claims Claims) error {
+	if err := Init(); err != nil {
+		return fmt.Errorf("%w: %v", ErrNotConfigured, err)
+	}
+	return defaultService.Verify(tokenString, audience, claims)
+}
+
+// AccessTokenTTL is how long access tokens stay valid
+func AccessTokenTTL() time.Duration {
+	return secondsOr(config.Envs.JWTExpirationInSeconds, 15*time.Minute)
+}
+
+// RefreshTokenTTL is how long a refresh token stays valid
+func RefreshTokenTTL() time.Duration {
+	return secondsOr(config.Envs.JWTRefreshExpirationInSeconds, 30*24*time.Hour)
+}
+
+func fromConfig(cfg config.Config) (*Service, error) {
+	active, err := loadKey(cfg.JWTKeyID, cfg.JWTAlgorithm, cfg.JWTSecret, cfg.JWTPrivateKeyFile, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var additional []*Key
+	if cfg.JWTPreviousKeyID != "" {
+		previous, err := loadKey(cfg.JWTPreviousKeyID, cfg.JWTPreviousAlgorithm, cfg.JWTPreviousSecret, cfg.JWTPreviousPublicKeyFile, false)
+		if err != nil {
+			return nil, fmt.Errorf("previous key: %w", err)
+		}
+
+		// Without an explicit date, outstanding access tokens signed by the
+		// previous key stay valid for one more lifetime
+		previous.RetiresAt = time.Now().Add(AccessTokenTTL())
+		if cfg.JWTPreviousKeyRetiresAt != "" {
+			previous.RetiresAt, err = time.Parse(time.RFC3339, cfg.JWTPreviousKeyRetiresAt)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JWT_PREVIOUS_KEY_RETIRES_AT: %w", err)
+			}
+		}
+		additional = append(additional, previous)
+	}
+
+	issuer := cfg.JWTIssuer
+	if issuer == "" {
+		issuer = "arts-market-api"
+	}
+	return NewService(issuer, active, additional...)
+}
+
+func secondsOr(seconds int64, fallback time.Duration) time.Duration {
+	if seconds <= 0 {
+		return fallback
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/muga20/artsMarket/config"
+)
+
+const (
+	// maxFailedLoginsPerIP is how many failed logins one address may make per window
+	maxFailedLoginsPerIP = 20
+	loginThrottleWindow  = 15 * time.Minute
+)
+
+func loginThrottleKey(ip string) string {
+	return "login:failed:ip:" + ip
+}
+
+// IsLoginThrottled reports whether an IP address has made too many failed
+// logins in the current window. Redis being unavailable never blocks login;
+// the per-account lockout still applies.
+func IsLoginThrottled(ctx context.Context, ip string) bool {
+	count, err := config.Redis().Get(ctx, loginThrottleKey(ip)).Int()
+	if err != nil {
+		return false
+	}
+	return count >= maxFailedLoginsPerIP
+}
+
+// RecordFailedLogin counts a failed login against an IP address. The window
+// starts with the first failure and the counter expires with it.
+func RecordFailedLogin(ctx context.Context, ip string) {
+	key := loginThrottleKey(ip)
+	count, err := config.Redis().Incr(ctx, key).Result()
+	if err != nil {
+		log.Printf("Failed to record failed login for %s: %v", ip, err)
+		return
+	}
+	if count == 1 {
+		config.Redis().Expire(ctx, key, loginThrottleWindow)
+	}
+}
+
+// ResetFailedLogins clears the failed login counter for an IP address
+func ResetFailedLogins(ctx context.Context, ip string) {
+	if err := config.Redis().Del(ctx, loginThrottleKey(ip)).Err(); err != nil {
+		log.Printf("Failed to reset failed logins for %s: %v", ip, err)
+	}
+}
@@ -0,0 +1,83 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/modules/users/deletion"
+	"github.com/muga20/artsMarket/modules/users/models"
+)
+
+const purgeBatchSize = 100
+
+// handlePurgeUnverifiedAccounts removes accounts created through signup that
+// never verified their email within the retention window
+func (w *NotificationWorker) handlePurgeUnverifiedAccounts(ctx context.Context, task *asynq.Task) error {
+	retentionDays := config.Envs.UnverifiedAccountRetentionDays
+	if retentionDays <= 0 {
+		retentionDays = 7
+	}
+	cutoff := time.Now().AddDate(0, 0, -int(retentionDays))
+
+	purged := 0
+	for {
+		var userIDs []string
+		if err := w.db.Model(&models.User{}).Unscoped().
+			Where("status = ? AND created_at < ?", models.UserStatusPendingVerification, cutoff).
+			Limit(purgeBatchSize).
+			Pluck("id", &userIDs).Error; err != nil {
+			return fmt.Errorf("failed to find unverified accounts: %w", err)
+		}
+		if len(userIDs) == 0 {
+			break
+		}
+
+		// Dependent rows are removed through their ON DELETE CASCADE constraints
+		if err := w.db.Unscoped().Where("id IN ?", userIDs).Delete(&models.User{}).Error; err != nil {
+			return fmt.Errorf("failed to purge unverified accounts: %w", err)
+		}
+		purged += len(userIDs)
+
+		if len(userIDs) < purgeBatchSize {
+			break
+		}
+	}
+
+	log.Printf("Purged %d unverified accounts created before %s", purged, cutoff.Format(time.RFC3339))
+	return nil
+}
+
+// handlePurgeDeletedAccounts purges accounts whose deletion grace period has ended
+func (w *NotificationWorker) handlePurgeDeletedAccounts(ctx context.Context, task *asynq.Task) error {
+	userIDs, err := deletion.Due(w.db, time.Now(), purgeBatchSize)
+	if err != nil {
+		return err
+	}
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	cld, err := config.NewCloudinaryClient()
+	if err != nil {
+		return fmt.Errorf("failed to initialize image storage: %w", err)
+	}
+
+	// One failing account must not hold back the rest; it is retried on the next run
+	var failed int
+	for _, userID := range userIDs {
+		if err := deletion.Purge(w.db, cld, userID); err != nil {
+			log.Printf("Failed to purge account %s: %v", userID, err)
+			failed++
+		}
+	}
+
+	log.Printf("Purged %d deleted accounts", len(userIDs)-failed)
+	if failed > 0 {
+		return fmt.Errorf("failed to purge %d deleted accounts", failed)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/modules/users/dataexport"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/tasks"
+	"gorm.io/gorm"
+)
+
+// handleGenerateDataExport builds the archive for a data export request and
+// emails a time-limited download link
+func (w *NotificationWorker) handleGenerateDataExport(ctx context.Context, task *asynq.Task) error {
+	var payload tasks.DataExportPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to parse task payload: %v", err)
+	}
+
+	var export models.DataExport
+	if err := w.db.Where("id = ?", payload.ExportID).First(&export).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// The account was deleted before the export ran
+			return nil
+		}
+		return fmt.Errorf("failed to load data export: %w", err)
+	}
+	if export.Status != models.DataExportStatusPending && export.Status != models.DataExportStatusProcessing {
+		return nil
+	}
+
+	if err := w.db.Model(&export).Update("status", models.DataExportStatusProcessing).Error; err != nil {
+		return fmt.Errorf("failed to update data export: %w", err)
+	}
+
+	archivePath, size, err := dataexport.Build(w.db, export.UserID, export.ID, config.Envs.DataExportDir)
+	if err != nil {
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if retried >= maxRetry {
+			w.db.Model(&export).Updates(map[string]interface{}{
+				"status": models.DataExportStatusFailed,
+				"error":  err.Error(),
+			})
+		}
+		return err
+	}
+
+	token, tokenHash, err := dataexport.NewDownloadToken()
+	if err != nil {
+		return err
+	}
+
+	ttlHours := config.Envs.DataExportTTLHours
+	if ttlHours <= 0 {
+		ttlHours = 48
+	}
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(ttlHours) * time.Hour)
+
+	return w.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Where("id = ?", export.UserID).First(&user).Error; err != nil {
+			return fmt.Errorf("failed to load user: %w", err)
+		}
+
+		if err := tx.Model(&export).Updates(map[string]interface{}{
+			"status":              models.DataExportStatusReady,
+			"file_path":           archivePath,
+			"file_size":           size,
+			"download_token_hash": tokenHash,
+			"completed_at":        now,
+			"expires_at":          expiresAt,
+			"error":               "",
+		}).Error; err != nil {
+			return fmt.Errorf("failed to update data export: %w", err)
+		}
+
+		_, err := services.WriteOutbox(tx, tasks.TypeSendDataExportReady, tasks.DataExportReadyPayload{
+			Email:        user.Email,
+			DownloadLink: fmt.Sprintf("%s/api/v1/exports/%s/download?token=%s", config.Envs.APIBaseURL, export.ID, token),
+			ExpiresAt:    expiresAt.UTC().Format(time.RFC1123),
+		})
+		return err
+	})
+}
+
+// handlePurgeExpiredExports deletes archives whose download links have expired
+func (w *NotificationWorker) handlePurgeExpiredExports(ctx context.Context, task *asynq.Task) error {
+	var expired []models.DataExport
+	if err := w.db.Where("status = ? AND expires_at < ?", models.DataExportStatusReady, time.Now()).
+		Find(&expired).Error; err != nil {
+		return fmt.Errorf("failed to find expired exports: %w", err)
+	}
+
+	for _, export := range expired {
+		if export.FilePath != "" {
+			if err := os.Remove(export.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+				log.Printf("Failed to delete export archive %s: %v", export.FilePath, err)
+				continue
+			}
+		}
+		if err := w.db.Model(&export).Updates(map[string]interface{}{
+			"status":              models.DataExportStatusExpired,
+			"file_path":           "",
+			"download_token_hash": nil,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to expire data export %s: %w", export.ID, err)
+		}
+	}
+
+	log.Printf("Purged %d expired data exports", len(expired))
+	return nil
+}
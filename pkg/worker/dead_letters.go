@@ -0,0 +1,163 @@
+package worker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/modules/notifications/models"
+	"github.com/muga20/artsMarket/modules/notifications/services"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"gorm.io/gorm"
+)
+
+// DeadLetterResponse describes a task that exhausted its retries
+type DeadLetterResponse struct {
+	ID           string          `json:"id"`
+	Queue        string          `json:"queue"`
+	Type         string          `json:"type"`
+	Payload      json.RawMessage `json:"payload"`
+	Retried      int             `json:"retried"`
+	MaxRetry     int             `json:"max_retry"`
+	LastError    string          `json:"last_error"`
+	LastFailedAt time.Time       `json:"last_failed_at"`
+}
+
+// ListDeadLettersHandler lists archived tasks across all queues
+// @Summary List dead-lettered tasks
+// @Description Lists background tasks that exhausted their retries and were archived
+// @Tags Tasks
+// @Produce json
+// @Param queue query string false "Only list this queue"
+// @Param page query int false "Page number (default: 1)"
+// @Param page_size query int false "Page size (default: 20)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/tasks/dead-letters [get]
+func ListDeadLettersHandler(inspector *asynq.Inspector, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		page := c.QueryInt("page", 1)
+		pageSize := c.QueryInt("page_size", 20)
+		if page < 1 {
+			page = 1
+		}
+		if pageSize < 1 || pageSize > 100 {
+			pageSize = 20
+		}
+
+		queues := []string{c.Query("queue")}
+		if queues[0] == "" {
+			var err error
+			queues, err = inspector.Queues()
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fmt.Errorf("failed to list queues: %w", err))
+			}
+		}
+
+		deadLetters := make([]DeadLetterResponse, 0)
+		for _, queue := range queues {
+			archived, err := inspector.ListArchivedTasks(queue, asynq.Page(page), asynq.PageSize(pageSize))
+			if err != nil {
+				return responseHandler.HandleResponse(c, nil,
+					fmt.Errorf("failed to list archived tasks for queue %s: %w", queue, err))
+			}
+			for _, task := range archived {
+				deadLetters = append(deadLetters, DeadLetterResponse{
+					ID:           task.ID,
+					Queue:        task.Queue,
+					Type:         task.Type,
+					Payload:      json.RawMessage(task.Payload),
+					Retried:      task.Retried,
+					MaxRetry:     task.MaxRetry,
+					LastError:    task.LastErr,
+					LastFailedAt: task.LastFailedAt,
+				})
+			}
+		}
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"dead_letters": deadLetters,
+			"page":         page,
+			"page_size":    pageSize,
+		}, nil)
+	}
+}
+
+// RetryDeadLetterHandler moves an archived task back onto its queue
+// @Summary Retry a dead-lettered task
+// @Description Re-enqueues an archived task for immediate processing
+// @Tags Tasks
+// @Produce json
+// @Param queue path string true "Queue name"
+// @Param id path string true "Task ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/tasks/dead-letters/{queue}/{id}/retry [post]
+func RetryDeadLetterHandler(db *gorm.DB, inspector *asynq.Inspector, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		queue, taskID := c.Params("queue"), c.Params("id")
+
+		if err := inspector.RunTask(queue, taskID); err != nil {
+			return responseHandler.HandleResponse(c, nil, deadLetterError(err, "retry"))
+		}
+
+		markOutbox(db, taskID, models.OutboxStatusEnqueued)
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Task re-enqueued successfully",
+		}, nil)
+	}
+}
+
+// DiscardDeadLetterHandler permanently deletes an archived task
+// @Summary Discard a dead-lettered task
+// @Description Permanently removes an archived task
+// @Tags Tasks
+// @Produce json
+// @Param queue path string true "Queue name"
+// @Param id path string true "Task ID"
+// @Success 200 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/tasks/dead-letters/{queue}/{id} [delete]
+func DiscardDeadLetterHandler(db *gorm.DB, inspector *asynq.Inspector, responseHandler *handlers.ResponseHandler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		queue, taskID := c.Params("queue"), c.Params("id")
+
+		if err := inspector.DeleteTask(queue, taskID); err != nil {
+			return responseHandler.HandleResponse(c, nil, deadLetterError(err, "discard"))
+		}
+
+		markOutbox(db, taskID, models.OutboxStatusDiscarded)
+
+		return responseHandler.HandleResponse(c, fiber.Map{
+			"message": "Task discarded successfully",
+		}, nil)
+	}
+}
+
+func deadLetterError(err error, action string) error {
+	if errors.Is(err, asynq.ErrQueueNotFound) || errors.Is(err, asynq.ErrTaskNotFound) {
+		return fiber.NewError(fiber.StatusNotFound, "Task not found")
+	}
+	return fmt.Errorf("failed to %s task: %w", action, err)
+}
+
+// markOutbox keeps the outbox row in step with admin actions on its task
+func markOutbox(db *gorm.DB, taskID, status string) {
+	outboxID, err := uuid.Parse(taskID)
+	if err != nil {
+		return
+	}
+	if err := services.MarkOutboxStatus(db, outboxID, status, nil); err != nil {
+		log.Printf("Failed to update outbox message %s: %v", outboxID, err)
+	}
+}
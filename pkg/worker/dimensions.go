@@ -0,0 +1,36 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/modules/artwork-management/dimensions"
+	models "github.com/muga20/artsMarket/modules/artwork-management/models/artWork"
+	"github.com/muga20/artsMarket/pkg/tasks"
+)
+
+// handleMigrateDimensions parses legacy free-text artwork dimensions
+func (w *NotificationWorker) handleMigrateDimensions(ctx context.Context, task *asynq.Task) error {
+	var payload tasks.MigrateDimensionsPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to parse task payload: %v", err)
+	}
+	migrationID, err := uuid.Parse(payload.MigrationID)
+	if err != nil {
+		return fmt.Errorf("invalid migration ID: %v", err)
+	}
+
+	if err := dimensions.Migrate(ctx, w.db, migrationID); err != nil {
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if retried >= maxRetry {
+			w.db.Model(&models.DimensionMigration{}).Where("id = ?", migrationID).
+				Update("status", models.DimensionMigrationFailed)
+		}
+		return err
+	}
+	return nil
+}
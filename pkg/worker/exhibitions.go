@@ -0,0 +1,14 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/modules/exhibitions/schedule"
+)
+
+// handleAdvanceExhibitions opens and closes exhibitions whose times have passed
+func (w *NotificationWorker) handleAdvanceExhibitions(ctx context.Context, task *asynq.Task) error {
+	return schedule.Advance(ctx, w.db, time.Now())
+}
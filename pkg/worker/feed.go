@@ -0,0 +1,26 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/modules/feed/timeline"
+	"github.com/muga20/artsMarket/pkg/tasks"
+)
+
+// handleFanOutActivity adds a new activity to its actor's followers' timelines
+func (w *NotificationWorker) handleFanOutActivity(ctx context.Context, task *asynq.Task) error {
+	var payload tasks.FanOutActivityPayload
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to parse task payload: %v", err)
+	}
+
+	activityID, err := uuid.Parse(payload.ActivityID)
+	if err != nil {
+		return fmt.Errorf("invalid activity ID %q: %v", payload.ActivityID, err)
+	}
+	return timeline.FanOut(ctx, w.db, activityID)
+}
@@ -7,11 +7,13 @@ import (
 	"log"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/muga20/artsMarket/config"
 	"github.com/muga20/artsMarket/modules/notifications/models"
 	"github.com/muga20/artsMarket/modules/notifications/services"
 	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/tasks"
 	"gorm.io/gorm"
 )
 
@@ -45,20 +47,31 @@ func (w *NotificationWorker) Start() {
 		*config.RedisConfig,
 		asynq.Config{
 			Concurrency: 5,
+			// Back off exponentially between attempts; once a task exhausts its
+			// MaxRetry (set when enqueuing) asynq archives it as a dead letter.
 			RetryDelayFunc: func(n int, e error, t *asynq.Task) time.Duration {
-
-				return time.Second * time.Duration(n*2)
+				return services.BackoffDelay(n + 1)
 			},
-			// Removed MaxRetry as it is not a valid field in asynq.Config
 		},
 	)
 
-	// Register the task handler
+	// Every task type the application enqueues is registered on this one mux
 	mux := asynq.NewServeMux()
-	mux.HandleFunc("notification:send", w.handleNotificationTask)
-
-	// Set MaxRetry for individual tasks when enqueuing them
-	// Example: w.client.Enqueue(asynq.NewTask("notification:send", payload), asynq.MaxRetry(3))
+	mux.HandleFunc(services.TypeSendNotification, w.handleOutboxTask(w.handleNotificationTask))
+	mux.HandleFunc(tasks.TypeSendEmail, w.handleOutboxTask(tasks.HandleSendEmailTask))
+	mux.HandleFunc(tasks.TypeSendEmailVerification, w.handleOutboxTask(tasks.HandleSendEmailVerificationTask))
+	mux.HandleFunc(tasks.TypeSendAccountUnlock, w.handleOutboxTask(tasks.HandleSendAccountUnlockTask))
+	mux.HandleFunc(tasks.TypeGenerateDataExport, w.handleOutboxTask(w.handleGenerateDataExport))
+	mux.HandleFunc(tasks.TypeSendDataExportReady, w.handleOutboxTask(tasks.HandleSendDataExportReadyTask))
+	mux.HandleFunc(tasks.TypeSendAccountDeletionScheduled, w.handleOutboxTask(tasks.HandleSendAccountDeletionScheduledTask))
+	mux.HandleFunc(tasks.TypeFanOutActivity, w.handleOutboxTask(w.handleFanOutActivity))
+	mux.HandleFunc(tasks.TypeMigrateDimensions, w.handleOutboxTask(w.handleMigrateDimensions))
+	mux.HandleFunc(tasks.TypePurgeUnverifiedAccounts, w.handlePurgeUnverifiedAccounts)
+	mux.HandleFunc(tasks.TypePurgeDeletedAccounts, w.handlePurgeDeletedAccounts)
+	mux.HandleFunc(tasks.TypePurgeExpiredExports, w.handlePurgeExpiredExports)
+	mux.HandleFunc(tasks.TypeComputeRecommendations, w.handleComputeRecommendations)
+	mux.HandleFunc(tasks.TypeRebuildTrending, w.handleRebuildTrending)
+	mux.HandleFunc(tasks.TypeAdvanceExhibitions, w.handleAdvanceExhibitions)
 
 	// Start the server
 	if err := server.Start(mux); err != nil {
@@ -66,6 +79,37 @@ func (w *NotificationWorker) Start() {
 	}
 }
 
+// handleOutboxTask wraps a task handler so the originating outbox message
+// reflects whether the task was delivered or ended up dead-lettered.
+func (w *NotificationWorker) handleOutboxTask(handler asynq.HandlerFunc) asynq.HandlerFunc {
+	return func(ctx context.Context, task *asynq.Task) error {
+		err := handler(ctx, task)
+
+		taskID, _ := asynq.GetTaskID(ctx)
+		outboxID, parseErr := uuid.Parse(taskID)
+		if parseErr != nil {
+			// Enqueued directly rather than through the outbox
+			return err
+		}
+
+		if err == nil {
+			if markErr := services.MarkOutboxDelivered(w.db, outboxID); markErr != nil {
+				log.Printf("Failed to mark outbox message %s delivered: %v", outboxID, markErr)
+			}
+			return nil
+		}
+
+		retried, _ := asynq.GetRetryCount(ctx)
+		maxRetry, _ := asynq.GetMaxRetry(ctx)
+		if retried >= maxRetry {
+			if markErr := services.MarkOutboxStatus(w.db, outboxID, models.OutboxStatusDead, err); markErr != nil {
+				log.Printf("Failed to mark outbox message %s dead: %v", outboxID, markErr)
+			}
+		}
+		return err
+	}
+}
+
 // handleNotificationTask processes and persists a notification
 func (w *NotificationWorker) handleNotificationTask(ctx context.Context, task *asynq.Task) error {
 	var notification models.Notification
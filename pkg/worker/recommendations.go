@@ -0,0 +1,13 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/modules/recommendations/recommender"
+)
+
+// handleComputeRecommendations rebuilds the cached recommendation lists
+func (w *NotificationWorker) handleComputeRecommendations(ctx context.Context, task *asynq.Task) error {
+	return recommender.Compute(ctx, w.db)
+}
@@ -0,0 +1,24 @@
+package worker
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/modules/users/models"
+	"github.com/muga20/artsMarket/pkg/logs/handlers"
+	"github.com/muga20/artsMarket/pkg/middleware"
+	"gorm.io/gorm"
+)
+
+// SetupTaskAdminRoutes sets up the admin routes for inspecting background tasks
+func SetupTaskAdminRoutes(apiGroup fiber.Router, db *gorm.DB, responseHandler *handlers.ResponseHandler) {
+	inspector := asynq.NewInspector(*config.RedisConfig)
+
+	taskGroup := apiGroup.Group("/admin/tasks")
+	taskGroup.Use(middleware.AuthMiddleware(db, responseHandler))
+	taskGroup.Use(middleware.RequireRole(db, responseHandler, models.AdminRoleName))
+
+	taskGroup.Get("/dead-letters", ListDeadLettersHandler(inspector, responseHandler))
+	taskGroup.Post("/dead-letters/:queue/:id/retry", RetryDeadLetterHandler(db, inspector, responseHandler))
+	taskGroup.Delete("/dead-letters/:queue/:id", DiscardDeadLetterHandler(db, inspector, responseHandler))
+}
@@ -0,0 +1,46 @@
+package worker
+
+import (
+	"log"
+
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/config"
+	"github.com/muga20/artsMarket/pkg/tasks"
+)
+
+// StartScheduler registers the periodic maintenance tasks and starts enqueuing them
+func StartScheduler() {
+	scheduler := asynq.NewScheduler(*config.RedisConfig, nil)
+
+	if _, err := scheduler.Register("@daily", tasks.NewPurgeUnverifiedAccountsTask()); err != nil {
+		log.Printf("Failed to register %s: %v", tasks.TypePurgeUnverifiedAccounts, err)
+	}
+
+	// Each run purges one batch, so a backlog drains over a few hours
+	if _, err := scheduler.Register("@hourly", tasks.NewPurgeDeletedAccountsTask()); err != nil {
+		log.Printf("Failed to register %s: %v", tasks.TypePurgeDeletedAccounts, err)
+	}
+
+	if _, err := scheduler.Register("@hourly", tasks.NewPurgeExpiredExportsTask()); err != nil {
+		log.Printf("Failed to register %s: %v", tasks.TypePurgeExpiredExports, err)
+	}
+
+	// Recommendations are served from cache between runs
+	if _, err := scheduler.Register("@every 6h", tasks.NewComputeRecommendationsTask()); err != nil {
+		log.Printf("Failed to register %s: %v", tasks.TypeComputeRecommendations, err)
+	}
+
+	// Incremental trending scores drift as events leave their window
+	if _, err := scheduler.Register("0 3 * * *", tasks.NewRebuildTrendingTask()); err != nil {
+		log.Printf("Failed to register %s: %v", tasks.TypeRebuildTrending, err)
+	}
+
+	// Exhibitions open and close within a minute of their scheduled times
+	if _, err := scheduler.Register("@every 1m", tasks.NewAdvanceExhibitionsTask()); err != nil {
+		log.Printf("Failed to register %s: %v", tasks.TypeAdvanceExhibitions, err)
+	}
+
+	if err := scheduler.Start(); err != nil {
+		log.Printf("Error starting scheduler: %v", err)
+	}
+}
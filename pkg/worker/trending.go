@@ -0,0 +1,13 @@
+package worker
+
+import (
+	"context"
+
+	"github.com/hibiken/asynq"
+	"github.com/muga20/artsMarket/modules/trending/ranking"
+)
+
+// handleRebuildTrending recomputes the trending lists
+func (w *NotificationWorker) handleRebuildTrending(ctx context.Context, task *asynq.Task) error {
+	return ranking.Rebuild(ctx, w.db)
+}